@@ -0,0 +1,528 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Follow the binary log as a replication client.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/rand"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	gomysql "github.com/go-mysql-org/go-mysql/mysql"
+	"github.com/go-mysql-org/go-mysql/replication"
+	MySQL "github.com/go-sql-driver/mysql"
+	"github.com/prometheus/client_golang/prometheus"
+	golog "github.com/siddontang/go-log/log"
+)
+
+const (
+	// Subsystem.
+	binlogStream = "binlog_stream"
+
+	binlogStreamRetryInterval = 10 * time.Second
+	binlogStreamHeartbeat     = 30 * time.Second
+)
+
+var (
+	binlogStreamServerID = kingpin.Flag(
+		"collect.binlog_stream.server_id",
+		"Server ID the binlog stream registers as replica with, must be unique in the replication topology. Random if 0.",
+	).Default("0").Uint32()
+	binlogStreamTablesLimit = kingpin.Flag(
+		"collect.binlog_stream.tables_limit",
+		"Number of tables with the most row changes to expose row counters of, 0 for all.",
+	).Default("20").Int()
+	binlogStreamTablesAllowlist = kingpin.Flag(
+		"collect.binlog_stream.tables_allowlist",
+		"Comma separated list of schema.table to count row changes of, all tables if empty.",
+	).Default("").String()
+)
+
+// Metric descriptors.
+var (
+	binlogStreamConnectedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, binlogStream, "connected"),
+		"Whether the binlog stream is connected to the server.",
+		nil, nil,
+	)
+	binlogStreamEventsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, binlogStream, "events_total"),
+		"Binlog events received by type.",
+		[]string{"type"}, nil,
+	)
+	binlogStreamBytesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, binlogStream, "bytes_total"),
+		"Bytes of binlog events received.",
+		nil, nil,
+	)
+	binlogStreamTransactionsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, binlogStream, "transactions_total"),
+		"Transactions committed to the binlog.",
+		nil, nil,
+	)
+	binlogStreamLastEventDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, binlogStream, "last_event_timestamp_seconds"),
+		"Timestamp of the last binlog event received.",
+		nil, nil,
+	)
+	binlogStreamTransactionBytesOpts = prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: binlogStream,
+		Name:      "transaction_size_bytes",
+		Help:      "Size of the transactions committed to the binlog.",
+		Buckets:   prometheus.ExponentialBuckets(1024, 4, 10),
+	}
+	binlogStreamTransactionRowEventsOpts = prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: binlogStream,
+		Name:      "transaction_row_events",
+		Help:      "Number of row events of the transactions committed to the binlog.",
+		Buckets:   prometheus.ExponentialBuckets(1, 4, 9),
+	}
+	binlogStreamTableRowsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, binlogStream, "table_rows_total"),
+		"Rows inserted, updated and deleted by row events per table.",
+		[]string{"schema", "table", "operation"}, nil,
+	)
+)
+
+// binlogRowOperations maps row event types to the operation label.
+var binlogRowOperations = map[replication.EventType]string{
+	replication.WRITE_ROWS_EVENTv0:  "insert",
+	replication.WRITE_ROWS_EVENTv1:  "insert",
+	replication.WRITE_ROWS_EVENTv2:  "insert",
+	replication.UPDATE_ROWS_EVENTv0: "update",
+	replication.UPDATE_ROWS_EVENTv1: "update",
+	replication.UPDATE_ROWS_EVENTv2: "update",
+	replication.DELETE_ROWS_EVENTv0: "delete",
+	replication.DELETE_ROWS_EVENTv1: "delete",
+	replication.DELETE_ROWS_EVENTv2: "delete",
+}
+
+// binlogEventTypes maps binlog event types to the type label.
+var binlogEventTypes = map[replication.EventType]string{
+	replication.WRITE_ROWS_EVENTv0:              "write_rows",
+	replication.WRITE_ROWS_EVENTv1:              "write_rows",
+	replication.WRITE_ROWS_EVENTv2:              "write_rows",
+	replication.UPDATE_ROWS_EVENTv0:             "update_rows",
+	replication.UPDATE_ROWS_EVENTv1:             "update_rows",
+	replication.UPDATE_ROWS_EVENTv2:             "update_rows",
+	replication.DELETE_ROWS_EVENTv0:             "delete_rows",
+	replication.DELETE_ROWS_EVENTv1:             "delete_rows",
+	replication.DELETE_ROWS_EVENTv2:             "delete_rows",
+	replication.GTID_EVENT:                      "gtid",
+	replication.ANONYMOUS_GTID_EVENT:            "gtid",
+	replication.MARIADB_GTID_EVENT:              "gtid",
+	replication.QUERY_EVENT:                     "query",
+	replication.XID_EVENT:                       "xid",
+	replication.TABLE_MAP_EVENT:                 "table_map",
+	replication.ROTATE_EVENT:                    "rotate",
+	replication.FORMAT_DESCRIPTION_EVENT:        "format_description",
+	replication.PREVIOUS_GTIDS_EVENT:            "previous_gtids",
+	replication.MARIADB_GTID_LIST_EVENT:         "previous_gtids",
+	replication.ROWS_QUERY_EVENT:                "rows_query",
+	replication.MARIADB_ANNOTATE_ROWS_EVENT:     "rows_query",
+	replication.MARIADB_BINLOG_CHECKPOINT_EVENT: "checkpoint",
+}
+
+// binlogStreamer follows the binlog and counts its events.
+type binlogStreamer struct {
+	mtx           sync.Mutex
+	connected     bool
+	events        map[string]float64
+	bytes         float64
+	transactions  float64
+	lastEvent     float64
+	inTransaction bool
+	// txOpen is set from the first event of a transaction until its
+	// commit, while txBytes and txRowEvents add up its size.
+	txOpen          bool
+	txBytes         float64
+	txRowEvents     float64
+	txSizeBytes     prometheus.Histogram
+	txSizeRowEvents prometheus.Histogram
+	// tables holds the changed rows by operation per table.
+	tables map[binlogTable]map[string]float64
+	// allowlist holds the tables to count rows of, all if empty.
+	allowlist map[binlogTable]bool
+}
+
+// binlogTable is a table of a row event.
+type binlogTable struct {
+	schema, table string
+}
+
+// binlogStreamState is the running binlog stream, nil unless started by
+// StartBinlogStream.
+var binlogStreamState *binlogStreamer
+
+func newBinlogStreamer() *binlogStreamer {
+	s := &binlogStreamer{
+		events:          map[string]float64{},
+		tables:          map[binlogTable]map[string]float64{},
+		allowlist:       map[binlogTable]bool{},
+		txSizeBytes:     prometheus.NewHistogram(binlogStreamTransactionBytesOpts),
+		txSizeRowEvents: prometheus.NewHistogram(binlogStreamTransactionRowEventsOpts),
+	}
+	for _, name := range strings.Split(*binlogStreamTablesAllowlist, ",") {
+		if schema, table, ok := strings.Cut(strings.TrimSpace(name), "."); ok {
+			s.allowlist[binlogTable{schema, table}] = true
+		}
+	}
+	return s
+}
+
+// StartBinlogStream follows the binlog of the server at dsn in the
+// background when the binlog_stream scraper is enabled. It reconnects until
+// ctx is done.
+func StartBinlogStream(ctx context.Context, dsn string, scrapers []Scraper, logger log.Logger) {
+	for _, scraper := range scrapers {
+		if _, ok := scraper.(ScrapeBinlogStream); ok {
+			binlogStreamState = newBinlogStreamer()
+			go binlogStreamState.run(ctx, dsn, log.With(logger, "scraper", scraper.Name()))
+			return
+		}
+	}
+}
+
+func (s *binlogStreamer) run(ctx context.Context, dsn string, logger log.Logger) {
+	for {
+		err := s.follow(ctx, dsn, logger)
+		s.setConnected(false)
+		if ctx.Err() != nil {
+			return
+		}
+		level.Error(logger).Log("msg", "Binlog stream failed, reconnecting", "err", err, "retry_in", binlogStreamRetryInterval)
+		countScrapeError("collect."+binlogStream, err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(binlogStreamRetryInterval):
+		}
+	}
+}
+
+// follow streams the binlog from its current position until an error.
+func (s *binlogStreamer) follow(ctx context.Context, dsn string, logger log.Logger) error {
+	cfg, err := MySQL.ParseDSN(dsn)
+	if err != nil {
+		return err
+	}
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	versionStr, version := getServerVersion(ctx, db, logger)
+	pos, err := binlogStartPosition(withServerVersion(ctx, versionStr, version), db)
+	if err != nil {
+		return err
+	}
+
+	syncerCfg := replication.BinlogSyncerConfig{
+		ServerID:        *binlogStreamServerID,
+		Flavor:          gomysql.MySQLFlavor,
+		User:            cfg.User,
+		Password:        cfg.Passwd,
+		TLSConfig:       cfg.TLS,
+		HeartbeatPeriod: binlogStreamHeartbeat,
+		ReadTimeout:     3 * binlogStreamHeartbeat,
+		Logger:          golog.NewDefault(binlogLogHandler{logger: logger}),
+		// Connect like the scrapes, e.g. through unix sockets or tunnels.
+		Dialer: func(ctx context.Context, network, address string) (net.Conn, error) {
+			return dialContext(ctx, cfg.Net, cfg.Addr)
+		},
+	}
+	if syncerCfg.ServerID == 0 {
+		syncerCfg.ServerID = 1<<30 + uint32(rand.Int31n(1<<30))
+	}
+	if strings.Contains(strings.ToLower(versionStr), "mariadb") {
+		syncerCfg.Flavor = gomysql.MariaDBFlavor
+	}
+	if host, port, err := net.SplitHostPort(cfg.Addr); err == nil {
+		p, _ := strconv.ParseUint(port, 10, 16)
+		syncerCfg.Host, syncerCfg.Port = host, uint16(p)
+	} else {
+		syncerCfg.Host = cfg.Addr
+	}
+
+	syncer := replication.NewBinlogSyncer(syncerCfg)
+	defer syncer.Close()
+	streamer, err := syncer.StartSync(pos)
+	if err != nil {
+		return err
+	}
+	level.Info(logger).Log("msg", "Following binlog", "file", pos.Name, "position", pos.Pos, "server_id", syncerCfg.ServerID)
+	s.setConnected(true)
+	for {
+		ev, err := streamer.GetEvent(ctx)
+		if err != nil {
+			return err
+		}
+		s.handle(ev)
+	}
+}
+
+// binlogStartPosition returns the current position of the binlog, so the
+// stream starts with new events.
+func binlogStartPosition(ctx context.Context, db *sql.DB) (gomysql.Position, error) {
+	rows, err := db.QueryContext(ctx, compatStatement(ctx, masterStatusQuery))
+	if isMySQLError(err, 1064) { // ER_PARSE_ERROR
+		rows, err = db.QueryContext(ctx, binaryLogStatusQuery)
+	}
+	if err != nil {
+		return gomysql.Position{}, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return gomysql.Position{}, err
+	}
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return gomysql.Position{}, err
+		}
+		return gomysql.Position{}, fmt.Errorf("binary log disabled")
+	}
+	scanArgs := make([]interface{}, len(columns))
+	for i := range scanArgs {
+		scanArgs[i] = &sql.RawBytes{}
+	}
+	if err := rows.Scan(scanArgs...); err != nil {
+		return gomysql.Position{}, err
+	}
+	pos, err := strconv.ParseUint(columnValue(scanArgs, columns, "Position"), 10, 32)
+	if err != nil {
+		return gomysql.Position{}, parseError{err}
+	}
+	return gomysql.Position{Name: columnValue(scanArgs, columns, "File"), Pos: uint32(pos)}, nil
+}
+
+func (s *binlogStreamer) setConnected(connected bool) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.connected = connected
+}
+
+// handle counts ev.
+func (s *binlogStreamer) handle(ev *replication.BinlogEvent) {
+	// Heartbeats and the artificial events starting a stream aren't part
+	// of the binlog.
+	if ev.Header.EventType == replication.HEARTBEAT_EVENT || ev.Header.LogPos == 0 {
+		return
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	eventType, ok := binlogEventTypes[ev.Header.EventType]
+	if !ok {
+		eventType = "other"
+	}
+	s.events[eventType]++
+	s.bytes += float64(ev.Header.EventSize)
+	s.lastEvent = float64(ev.Header.Timestamp)
+
+	// Transactions start with their GTID event, or without GTIDs with BEGIN
+	// or the statement itself.
+	switch e := ev.Event.(type) {
+	case *replication.GTIDEvent, *replication.MariadbGTIDEvent:
+		s.startTransaction()
+	case *replication.QueryEvent:
+		if !s.txOpen && string(e.Query) != "COMMIT" {
+			s.startTransaction()
+		}
+	}
+	if s.txOpen {
+		s.txBytes += float64(ev.Header.EventSize)
+		if _, ok := binlogRowOperations[ev.Header.EventType]; ok {
+			s.txRowEvents++
+		}
+	}
+
+	switch e := ev.Event.(type) {
+	case *replication.MariadbGTIDEvent:
+		// MariaDB starts transactions with the GTID event instead of BEGIN.
+		s.inTransaction = !e.IsStandalone()
+	case *replication.XIDEvent:
+		s.commitTransaction()
+	case *replication.QueryEvent:
+		switch string(e.Query) {
+		case "BEGIN":
+			s.inTransaction = true
+		case "COMMIT":
+			s.commitTransaction()
+		default:
+			// Statements outside of BEGIN ... COMMIT, e.g. DDL, are
+			// transactions of their own.
+			if !s.inTransaction {
+				s.commitTransaction()
+			}
+		}
+	case *replication.RowsEvent:
+		s.countRows(ev.Header.EventType, e)
+	}
+}
+
+func (s *binlogStreamer) startTransaction() {
+	s.txOpen = true
+	s.txBytes = 0
+	s.txRowEvents = 0
+}
+
+// commitTransaction counts a transaction and observes its size.
+func (s *binlogStreamer) commitTransaction() {
+	s.transactions++
+	s.inTransaction = false
+	if s.txOpen {
+		s.txSizeBytes.Observe(s.txBytes)
+		s.txSizeRowEvents.Observe(s.txRowEvents)
+	}
+	s.txOpen = false
+}
+
+// countRows counts the rows changed by a row event.
+func (s *binlogStreamer) countRows(eventType replication.EventType, e *replication.RowsEvent) {
+	operation, ok := binlogRowOperations[eventType]
+	if !ok || e.Table == nil {
+		return
+	}
+	table := binlogTable{string(e.Table.Schema), string(e.Table.Table)}
+	if len(s.allowlist) > 0 && !s.allowlist[table] {
+		return
+	}
+	rows := len(e.Rows)
+	if operation == "update" {
+		// Update events hold the before and after image of every row.
+		rows /= 2
+	}
+	if s.tables[table] == nil {
+		s.tables[table] = map[string]float64{}
+	}
+	s.tables[table][operation] += float64(rows)
+}
+
+// topTables returns the tables with the most changed rows, up to limit or
+// all if limit is 0.
+func (s *binlogStreamer) topTables(limit int) []binlogTable {
+	totals := make(map[binlogTable]float64, len(s.tables))
+	tables := make([]binlogTable, 0, len(s.tables))
+	for table, operations := range s.tables {
+		for _, rows := range operations {
+			totals[table] += rows
+		}
+		tables = append(tables, table)
+	}
+	sort.Slice(tables, func(i, j int) bool {
+		if totals[tables[i]] != totals[tables[j]] {
+			return totals[tables[i]] > totals[tables[j]]
+		}
+		if tables[i].schema != tables[j].schema {
+			return tables[i].schema < tables[j].schema
+		}
+		return tables[i].table < tables[j].table
+	})
+	if limit > 0 && len(tables) > limit {
+		tables = tables[:limit]
+	}
+	return tables
+}
+
+// collect sends the metrics of the stream.
+func (s *binlogStreamer) collect(ch chan<- prometheus.Metric) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	connected := 0.0
+	if s.connected {
+		connected = 1
+	}
+	ch <- prometheus.MustNewConstMetric(binlogStreamConnectedDesc, prometheus.GaugeValue, connected)
+	for eventType, count := range s.events {
+		ch <- prometheus.MustNewConstMetric(binlogStreamEventsDesc, prometheus.CounterValue, count, eventType)
+	}
+	ch <- prometheus.MustNewConstMetric(binlogStreamBytesDesc, prometheus.CounterValue, s.bytes)
+	ch <- prometheus.MustNewConstMetric(binlogStreamTransactionsDesc, prometheus.CounterValue, s.transactions)
+	s.txSizeBytes.Collect(ch)
+	s.txSizeRowEvents.Collect(ch)
+	if s.lastEvent > 0 {
+		ch <- prometheus.MustNewConstMetric(binlogStreamLastEventDesc, prometheus.GaugeValue, s.lastEvent)
+	}
+	for _, table := range s.topTables(*binlogStreamTablesLimit) {
+		for operation, rows := range s.tables[table] {
+			ch <- prometheus.MustNewConstMetric(
+				binlogStreamTableRowsDesc, prometheus.CounterValue, rows,
+				table.schema, table.table, operation,
+			)
+		}
+	}
+}
+
+// binlogLogHandler writes the log of the replication library as debug
+// messages.
+type binlogLogHandler struct {
+	logger log.Logger
+}
+
+func (h binlogLogHandler) Write(b []byte) (int, error) {
+	level.Debug(h.logger).Log("msg", strings.TrimSpace(string(b)))
+	return len(b), nil
+}
+
+func (h binlogLogHandler) Close() error {
+	return nil
+}
+
+// ScrapeBinlogStream collects the metrics of the binlog stream started by
+// StartBinlogStream.
+type ScrapeBinlogStream struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeBinlogStream) Name() string {
+	return binlogStream
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeBinlogStream) Help() string {
+	return "Collect binlog event, transaction, byte and per table row counters and transaction sizes from a replication client following the binlog"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeBinlogStream) Version() float64 {
+	return 5.6
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeBinlogStream) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	if binlogStreamState == nil {
+		return nil
+	}
+	binlogStreamState.collect(ch)
+	return nil
+}
+
+// check interface
+var _ Scraper = ScrapeBinlogStream{}
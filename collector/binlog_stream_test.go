@@ -0,0 +1,199 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	gomysql "github.com/go-mysql-org/go-mysql/mysql"
+	"github.com/go-mysql-org/go-mysql/replication"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+// binlogEvent returns an event of eventType and size logged at position 100.
+func binlogEvent(eventType replication.EventType, size uint32, event replication.Event) *replication.BinlogEvent {
+	return &replication.BinlogEvent{
+		Header: &replication.EventHeader{EventType: eventType, EventSize: size, LogPos: 100, Timestamp: 1700000000},
+		Event:  event,
+	}
+}
+
+// binlogStreamCollector exposes the metrics of a binlogStreamer.
+type binlogStreamCollector struct {
+	s *binlogStreamer
+}
+
+func (c binlogStreamCollector) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, ch)
+}
+
+func (c binlogStreamCollector) Collect(ch chan<- prometheus.Metric) {
+	c.s.collect(ch)
+}
+
+func TestBinlogStreamerHandle(t *testing.T) {
+	s := newBinlogStreamer()
+	for _, ev := range []*replication.BinlogEvent{
+		// Artificial rotate event starting the stream.
+		{Header: &replication.EventHeader{EventType: replication.ROTATE_EVENT, EventSize: 40}, Event: &replication.RotateEvent{}},
+		binlogEvent(replication.HEARTBEAT_EVENT, 20, nil),
+		// Transaction.
+		binlogEvent(replication.GTID_EVENT, 65, &replication.GTIDEvent{}),
+		binlogEvent(replication.QUERY_EVENT, 70, &replication.QueryEvent{Query: []byte("BEGIN")}),
+		binlogEvent(replication.TABLE_MAP_EVENT, 50, &replication.TableMapEvent{}),
+		binlogEvent(replication.WRITE_ROWS_EVENTv2, 100, &replication.RowsEvent{}),
+		binlogEvent(replication.UPDATE_ROWS_EVENTv2, 100, &replication.RowsEvent{}),
+		binlogEvent(replication.XID_EVENT, 31, &replication.XIDEvent{}),
+		// DDL.
+		binlogEvent(replication.GTID_EVENT, 65, &replication.GTIDEvent{}),
+		binlogEvent(replication.QUERY_EVENT, 120, &replication.QueryEvent{Query: []byte("ALTER TABLE t ADD COLUMN c INT")}),
+		// MariaDB transaction.
+		binlogEvent(replication.MARIADB_GTID_EVENT, 38, &replication.MariadbGTIDEvent{}),
+		binlogEvent(replication.DELETE_ROWS_EVENTv1, 100, &replication.RowsEvent{}),
+		binlogEvent(replication.QUERY_EVENT, 70, &replication.QueryEvent{Query: []byte("COMMIT")}),
+	} {
+		s.handle(ev)
+	}
+	s.setConnected(true)
+
+	convey.Convey("Binlog events are counted", t, func() {
+		err := testutil.CollectAndCompare(binlogStreamCollector{s}, strings.NewReader(`
+# HELP mysql_binlog_stream_bytes_total Bytes of binlog events received.
+# TYPE mysql_binlog_stream_bytes_total counter
+mysql_binlog_stream_bytes_total 809
+# HELP mysql_binlog_stream_connected Whether the binlog stream is connected to the server.
+# TYPE mysql_binlog_stream_connected gauge
+mysql_binlog_stream_connected 1
+# HELP mysql_binlog_stream_events_total Binlog events received by type.
+# TYPE mysql_binlog_stream_events_total counter
+mysql_binlog_stream_events_total{type="delete_rows"} 1
+mysql_binlog_stream_events_total{type="gtid"} 3
+mysql_binlog_stream_events_total{type="query"} 3
+mysql_binlog_stream_events_total{type="table_map"} 1
+mysql_binlog_stream_events_total{type="update_rows"} 1
+mysql_binlog_stream_events_total{type="write_rows"} 1
+mysql_binlog_stream_events_total{type="xid"} 1
+# HELP mysql_binlog_stream_last_event_timestamp_seconds Timestamp of the last binlog event received.
+# TYPE mysql_binlog_stream_last_event_timestamp_seconds gauge
+mysql_binlog_stream_last_event_timestamp_seconds 1.7e+09
+# HELP mysql_binlog_stream_transactions_total Transactions committed to the binlog.
+# TYPE mysql_binlog_stream_transactions_total counter
+mysql_binlog_stream_transactions_total 3
+`),
+			"mysql_binlog_stream_bytes_total", "mysql_binlog_stream_connected", "mysql_binlog_stream_events_total",
+			"mysql_binlog_stream_last_event_timestamp_seconds", "mysql_binlog_stream_transactions_total",
+		)
+		convey.So(err, convey.ShouldBeNil)
+	})
+
+	convey.Convey("Transaction sizes are observed", t, func() {
+		err := testutil.CollectAndCompare(binlogStreamCollector{s}, strings.NewReader(`
+# HELP mysql_binlog_stream_transaction_row_events Number of row events of the transactions committed to the binlog.
+# TYPE mysql_binlog_stream_transaction_row_events histogram
+mysql_binlog_stream_transaction_row_events_bucket{le="1"} 2
+mysql_binlog_stream_transaction_row_events_bucket{le="4"} 3
+mysql_binlog_stream_transaction_row_events_bucket{le="16"} 3
+mysql_binlog_stream_transaction_row_events_bucket{le="64"} 3
+mysql_binlog_stream_transaction_row_events_bucket{le="256"} 3
+mysql_binlog_stream_transaction_row_events_bucket{le="1024"} 3
+mysql_binlog_stream_transaction_row_events_bucket{le="4096"} 3
+mysql_binlog_stream_transaction_row_events_bucket{le="16384"} 3
+mysql_binlog_stream_transaction_row_events_bucket{le="65536"} 3
+mysql_binlog_stream_transaction_row_events_bucket{le="+Inf"} 3
+mysql_binlog_stream_transaction_row_events_sum 3
+mysql_binlog_stream_transaction_row_events_count 3
+`), "mysql_binlog_stream_transaction_row_events")
+		convey.So(err, convey.ShouldBeNil)
+
+		// The transaction, the DDL and the MariaDB transaction.
+		var m dto.Metric
+		convey.So(s.txSizeBytes.Write(&m), convey.ShouldBeNil)
+		convey.So(m.GetHistogram().GetSampleSum(), convey.ShouldEqual, 416+185+208)
+	})
+}
+
+func TestBinlogStartPosition(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(masterStatusQuery)).WillReturnRows(
+		sqlmock.NewRows([]string{"File", "Position", "Binlog_Do_DB", "Binlog_Ignore_DB", "Executed_Gtid_Set"}).
+			AddRow("binlog.000006", "49066", "", "", ""))
+
+	convey.Convey("The stream starts at the current position", t, func() {
+		pos, err := binlogStartPosition(context.Background(), db)
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(pos, convey.ShouldResemble, gomysql.Position{Name: "binlog.000006", Pos: 49066})
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestBinlogStreamerTableRows(t *testing.T) {
+	defaultLimit, defaultAllowlist := *binlogStreamTablesLimit, *binlogStreamTablesAllowlist
+	*binlogStreamTablesLimit = 2
+	*binlogStreamTablesAllowlist = "app.users, app.orders,app.audit"
+	defer func() { *binlogStreamTablesLimit, *binlogStreamTablesAllowlist = defaultLimit, defaultAllowlist }()
+
+	rowsEvent := func(schema, table string, rows int) *replication.RowsEvent {
+		return &replication.RowsEvent{
+			Table: &replication.TableMapEvent{Schema: []byte(schema), Table: []byte(table)},
+			Rows:  make([][]interface{}, rows),
+		}
+	}
+	s := newBinlogStreamer()
+	for _, ev := range []*replication.BinlogEvent{
+		binlogEvent(replication.WRITE_ROWS_EVENTv2, 100, rowsEvent("app", "users", 3)),
+		// Before and after images of 2 rows.
+		binlogEvent(replication.UPDATE_ROWS_EVENTv2, 100, rowsEvent("app", "users", 4)),
+		binlogEvent(replication.DELETE_ROWS_EVENTv2, 100, rowsEvent("app", "orders", 2)),
+		binlogEvent(replication.WRITE_ROWS_EVENTv2, 100, rowsEvent("app", "audit", 1)),
+		// Not in the allowlist.
+		binlogEvent(replication.WRITE_ROWS_EVENTv2, 100, rowsEvent("app", "sessions", 50)),
+	} {
+		s.handle(ev)
+	}
+
+	convey.Convey("Row changes of the top allowed tables are counted", t, func() {
+		ch := make(chan prometheus.Metric)
+		go func() {
+			s.collect(ch)
+			close(ch)
+		}()
+		rows := map[string]float64{}
+		for m := range ch {
+			if m.Desc() == binlogStreamTableRowsDesc {
+				got := readMetric(m)
+				rows[got.labels["table"]+"/"+got.labels["operation"]] = got.value
+			}
+		}
+		convey.So(rows, convey.ShouldResemble, map[string]float64{
+			"users/insert":  3,
+			"users/update":  2,
+			"orders/delete": 2,
+		})
+	})
+}
@@ -0,0 +1,175 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Write and read back a canary row.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// canary is the Metric subsystem we use.
+	canary = "canary"
+	// canaryRoleQuery tells whether the server takes writes.
+	canaryRoleQuery = `SELECT @@server_id, @@read_only`
+	// canaryWriteQuery stores the current server timestamp in the row of the
+	// server. %s will be replaced by the database and table name.
+	canaryWriteQuery = "REPLACE INTO `%s`.`%s` (server_id, ts) VALUES (@@server_id, UNIX_TIMESTAMP(NOW(6)))"
+	// canaryReadQuery fetches the stored timestamps along with the current
+	// one of the server.
+	canaryReadQuery = "SELECT server_id, ts, UNIX_TIMESTAMP(NOW(6)) FROM `%s`.`%s`"
+)
+
+var (
+	collectCanaryDatabase = kingpin.Flag(
+		"collect.canary.database",
+		"Database of the canary table",
+	).Default("mysqld_exporter").String()
+	collectCanaryTable = kingpin.Flag(
+		"collect.canary.table",
+		"Canary table written on writable servers and read on all servers",
+	).Default("canary").String()
+)
+
+// Metric descriptors.
+var (
+	canaryWriteSuccessDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, canary, "write_success"),
+		"Whether the canary row was written and read back.",
+		nil, nil,
+	)
+	canaryWriteDurationDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, canary, "write_duration_seconds"),
+		"Time taken to write the canary row and read it back.",
+		nil, nil,
+	)
+	canaryPropagationDelayDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, canary, "propagation_delay_seconds"),
+		"Age of the canary row written by the server, as of the current server timestamp.",
+		[]string{"server_id"}, nil,
+	)
+)
+
+// ScrapeCanary writes a canary row on writable servers and reads the canary
+// rows replicated from other servers. It requires the table:
+// CREATE TABLE canary (
+//
+//	server_id             int unsigned NOT NULL PRIMARY KEY,
+//	ts                    decimal(20,6) NOT NULL
+//
+// );
+type ScrapeCanary struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeCanary) Name() string {
+	return "canary"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeCanary) Help() string {
+	return "Write a canary row on writable servers and read the replicated canary rows"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeCanary) Version() float64 {
+	return 5.6
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeCanary) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	var (
+		serverID uint64
+		readOnly bool
+	)
+	if err := db.QueryRowContext(ctx, canaryRoleQuery).Scan(&serverID, &readOnly); err != nil {
+		return err
+	}
+
+	// Scrape connections reject writes with --exporter.read_only.
+	if !readOnly && !*readOnlyMode {
+		start := time.Now()
+		success := 0.0
+		stored, err := writeCanary(ctx, db, serverID)
+		if canaryMissing(err) {
+			level.Debug(logger).Log("msg", "No canary table, skipping canary", "err", err)
+			return nil
+		}
+		if err != nil {
+			level.Error(logger).Log("msg", "Error writing canary row", "err", err)
+		}
+		if stored {
+			success = 1
+		}
+		ch <- prometheus.MustNewConstMetric(canaryWriteSuccessDesc, prometheus.GaugeValue, success)
+		ch <- prometheus.MustNewConstMetric(canaryWriteDurationDesc, prometheus.GaugeValue, time.Since(start).Seconds())
+	}
+
+	rows, err := db.QueryContext(ctx, fmt.Sprintf(canaryReadQuery, *collectCanaryDatabase, *collectCanaryTable))
+	if canaryMissing(err) {
+		level.Debug(logger).Log("msg", "No canary table, skipping canary", "err", err)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var (
+		id      uint64
+		ts, now float64
+	)
+	for rows.Next() {
+		if err := rows.Scan(&id, &ts, &now); err != nil {
+			return err
+		}
+		if id == serverID {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(canaryPropagationDelayDesc, prometheus.GaugeValue, now-ts, strconv.FormatUint(id, 10))
+	}
+	return rows.Err()
+}
+
+// writeCanary writes the canary row of the server and returns whether it
+// reads it back.
+func writeCanary(ctx context.Context, db *sql.DB, serverID uint64) (bool, error) {
+	if _, err := db.ExecContext(ctx, fmt.Sprintf(canaryWriteQuery, *collectCanaryDatabase, *collectCanaryTable)); err != nil {
+		return false, err
+	}
+	rows, err := db.QueryContext(ctx, fmt.Sprintf(canaryReadQuery, *collectCanaryDatabase, *collectCanaryTable)+" WHERE server_id = ?", serverID)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+	return rows.Next(), rows.Err()
+}
+
+// canaryMissing returns whether err is due to a missing canary database or
+// table, servers without a canary configured.
+func canaryMissing(err error) bool {
+	return isMySQLError(err, 1049) || isMySQLError(err, 1146) // ER_BAD_DB_ERROR, ER_NO_SUCH_TABLE
+}
+
+// check interface
+var _ Scraper = ScrapeCanary{}
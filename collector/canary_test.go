@@ -0,0 +1,137 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	MySQL "github.com/go-sql-driver/mysql"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeCanary(t *testing.T) {
+	defaultDatabase, defaultTable := *collectCanaryDatabase, *collectCanaryTable
+	*collectCanaryDatabase, *collectCanaryTable = "mysqld_exporter", "canary"
+	defer func() {
+		*collectCanaryDatabase, *collectCanaryTable = defaultDatabase, defaultTable
+	}()
+
+	readQuery := fmt.Sprintf(canaryReadQuery, "mysqld_exporter", "canary")
+	readColumns := []string{"server_id", "ts", "UNIX_TIMESTAMP(NOW(6))"}
+
+	convey.Convey("The primary writes and reads back its canary row", t, func() {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("error opening a stub database connection: %s", err)
+		}
+		defer db.Close()
+
+		mock.ExpectQuery(sanitizeQuery(canaryRoleQuery)).WillReturnRows(sqlmock.NewRows([]string{"@@server_id", "@@read_only"}).AddRow(1, 0))
+		mock.ExpectExec(sanitizeQuery(fmt.Sprintf(canaryWriteQuery, "mysqld_exporter", "canary"))).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(sanitizeQuery(readQuery + " WHERE server_id = ?")).WithArgs(1).
+			WillReturnRows(sqlmock.NewRows(readColumns).AddRow(1, "1683169200.000000", "1683169200.000000"))
+		mock.ExpectQuery(sanitizeQuery(readQuery)).
+			WillReturnRows(sqlmock.NewRows(readColumns).AddRow(1, "1683169200.000000", "1683169200.000000"))
+
+		ch := make(chan prometheus.Metric)
+		go func() {
+			if err = (ScrapeCanary{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+				t.Errorf("error calling function on test: %s", err)
+			}
+			close(ch)
+		}()
+
+		convey.So(readMetric(<-ch), convey.ShouldResemble, MetricResult{labels: labelMap{}, value: 1, metricType: dto.MetricType_GAUGE})
+		convey.So(readMetric(<-ch).value, convey.ShouldBeGreaterThanOrEqualTo, 0)
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+
+		// Ensure all SQL queries were executed
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled exceptions: %s", err)
+		}
+	})
+
+	convey.Convey("A replica only reads the canary rows", t, func() {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("error opening a stub database connection: %s", err)
+		}
+		defer db.Close()
+
+		mock.ExpectQuery(sanitizeQuery(canaryRoleQuery)).WillReturnRows(sqlmock.NewRows([]string{"@@server_id", "@@read_only"}).AddRow(2, 1))
+		mock.ExpectQuery(sanitizeQuery(readQuery)).
+			WillReturnRows(sqlmock.NewRows(readColumns).
+				AddRow(1, "1683169200.000000", "1683169201.500000").
+				AddRow(2, "1683160000.000000", "1683169201.500000"))
+
+		ch := make(chan prometheus.Metric)
+		go func() {
+			if err = (ScrapeCanary{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+				t.Errorf("error calling function on test: %s", err)
+			}
+			close(ch)
+		}()
+
+		convey.So(readMetric(<-ch), convey.ShouldResemble, MetricResult{labels: labelMap{"server_id": "1"}, value: 1.5, metricType: dto.MetricType_GAUGE})
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+
+		// Ensure all SQL queries were executed
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled exceptions: %s", err)
+		}
+	})
+
+	convey.Convey("Servers without a canary table are skipped", t, func() {
+		for _, readOnly := range []int{0, 1} {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("error opening a stub database connection: %s", err)
+			}
+			defer db.Close()
+
+			mock.ExpectQuery(sanitizeQuery(canaryRoleQuery)).WillReturnRows(sqlmock.NewRows([]string{"@@server_id", "@@read_only"}).AddRow(1, readOnly))
+			if readOnly == 0 {
+				mock.ExpectExec(sanitizeQuery(fmt.Sprintf(canaryWriteQuery, "mysqld_exporter", "canary"))).
+					WillReturnError(&MySQL.MySQLError{Number: 1049, Message: "Unknown database 'mysqld_exporter'"})
+			} else {
+				mock.ExpectQuery(sanitizeQuery(readQuery)).
+					WillReturnError(&MySQL.MySQLError{Number: 1146, Message: "Table 'mysqld_exporter.canary' doesn't exist"})
+			}
+
+			ch := make(chan prometheus.Metric)
+			go func() {
+				if err = (ScrapeCanary{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+					t.Errorf("error calling function on test: %s", err)
+				}
+				close(ch)
+			}()
+
+			_, ok := <-ch
+			convey.So(ok, convey.ShouldBeFalse)
+
+			// Ensure all SQL queries were executed
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled exceptions: %s", err)
+			}
+		}
+	})
+}
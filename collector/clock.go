@@ -0,0 +1,94 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Compare the server clock to the clock of the exporter.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const clockQuery = `
+	SELECT UNIX_TIMESTAMP(NOW(6)),
+	       TIMESTAMPDIFF(SECOND, UTC_TIMESTAMP(), NOW()),
+	       @@global.time_zone,
+	       @@system_time_zone
+	`
+
+// Metric descriptors.
+var (
+	clockSkewDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "clock", "skew_seconds"),
+		"Difference between the clock of the server and the clock of the exporter, positive if the server is ahead.",
+		[]string{}, nil,
+	)
+	timeZoneOffsetDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "time_zone", "offset_seconds"),
+		"Offset of the server's time zone from UTC.",
+		[]string{}, nil,
+	)
+	timeZoneInfoDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "time_zone", "info"),
+		"The time_zone and system_time_zone of the server.",
+		[]string{"time_zone", "system_time_zone"}, nil,
+	)
+)
+
+// ScrapeClock collects the clock skew and time zone of the server.
+type ScrapeClock struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeClock) Name() string {
+	return "clock"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeClock) Help() string {
+	return "Collect the clock skew between the server and the exporter, and the server time zone"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeClock) Version() float64 {
+	return 5.6
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeClock) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	var (
+		serverTime     float64
+		offset         float64
+		timeZone       string
+		systemTimeZone string
+	)
+	before := time.Now()
+	if err := db.QueryRowContext(ctx, clockQuery).Scan(&serverTime, &offset, &timeZone, &systemTimeZone); err != nil {
+		return err
+	}
+	// Compare to the middle of the round trip, when the server most likely
+	// evaluated NOW().
+	local := before.Add(time.Since(before) / 2)
+
+	ch <- prometheus.MustNewConstMetric(clockSkewDesc, prometheus.GaugeValue, serverTime-float64(local.UnixNano())/1e9)
+	ch <- prometheus.MustNewConstMetric(timeZoneOffsetDesc, prometheus.GaugeValue, offset)
+	ch <- prometheus.MustNewConstMetric(timeZoneInfoDesc, prometheus.GaugeValue, 1, timeZone, systemTimeZone)
+	return nil
+}
+
+// check interface
+var _ Scraper = ScrapeClock{}
@@ -0,0 +1,61 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeClock(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	// The server is two minutes ahead.
+	serverTime := fmt.Sprintf("%.6f", float64(time.Now().Add(2*time.Minute).UnixNano())/1e9)
+	columns := []string{"UNIX_TIMESTAMP(NOW(6))", "offset", "@@global.time_zone", "@@system_time_zone"}
+	rows := sqlmock.NewRows(columns).AddRow(serverTime, "7200", "SYSTEM", "CEST")
+	mock.ExpectQuery(sanitizeQuery(clockQuery)).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeClock{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	convey.Convey("Metrics comparison", t, func() {
+		skew := readMetric(<-ch)
+		convey.So(skew.value, convey.ShouldAlmostEqual, 120, 1)
+		convey.So(readMetric(<-ch), convey.ShouldResemble, MetricResult{labels: labelMap{}, value: 7200, metricType: dto.MetricType_GAUGE})
+		convey.So(readMetric(<-ch), convey.ShouldResemble, MetricResult{labels: labelMap{"time_zone": "SYSTEM", "system_time_zone": "CEST"}, value: 1, metricType: dto.MetricType_GAUGE})
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -0,0 +1,192 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape cluster quorum of Galera and Group Replication.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	MySQL "github.com/go-sql-driver/mysql"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// Subsystem.
+	cluster = "cluster"
+	// Queries.
+	galeraQuorumQuery = `
+	SHOW GLOBAL STATUS
+	  WHERE Variable_name IN ('wsrep_cluster_size', 'wsrep_cluster_status')
+	`
+	groupReplicationQuorumQuery = `
+	SELECT
+	    MEMBER_STATE,
+	    COUNT(*)
+	  FROM performance_schema.replication_group_members
+	  GROUP BY MEMBER_STATE
+	`
+)
+
+// Cluster technologies.
+const (
+	clusterGalera           = "galera"
+	clusterGroupReplication = "group_replication"
+)
+
+// Metric descriptors.
+var (
+	clusterHasQuorumDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, cluster, "has_quorum"),
+		"Whether the cluster component this server is part of has quorum.",
+		[]string{"technology"}, nil,
+	)
+	clusterMembersDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, cluster, "members"),
+		"The number of cluster members seen by this server.",
+		[]string{"technology"}, nil,
+	)
+	clusterOnlineMembersDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, cluster, "online_members"),
+		"The number of cluster members able to serve as part of the quorum.",
+		[]string{"technology"}, nil,
+	)
+)
+
+// ScrapeClusterQuorum collects the quorum of Galera and Group Replication clusters.
+type ScrapeClusterQuorum struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeClusterQuorum) Name() string {
+	return "cluster_quorum"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeClusterQuorum) Help() string {
+	return "Collect the quorum of Galera and Group Replication clusters"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeClusterQuorum) Version() float64 {
+	return 5.6
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeClusterQuorum) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	if err := scrapeGaleraQuorum(ctx, db, ch); err != nil {
+		return err
+	}
+	return scrapeGroupReplicationQuorum(ctx, db, ch, logger)
+}
+
+// scrapeGaleraQuorum derives the quorum from wsrep_cluster_status, the
+// component is only Primary while it has quorum.
+func scrapeGaleraQuorum(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	rows, err := db.QueryContext(ctx, galeraQuorumQuery)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var (
+		name, value string
+		status      string
+		size        float64
+	)
+	for rows.Next() {
+		if err := rows.Scan(&name, &value); err != nil {
+			return err
+		}
+		switch name {
+		case "wsrep_cluster_status":
+			status = value
+		case "wsrep_cluster_size":
+			size, _ = strconv.ParseFloat(value, 64)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	// Not a Galera node.
+	if status == "" {
+		return nil
+	}
+
+	quorum, online := 0.0, 0.0
+	if status == "Primary" {
+		quorum, online = 1, size
+	}
+	ch <- prometheus.MustNewConstMetric(clusterHasQuorumDesc, prometheus.GaugeValue, quorum, clusterGalera)
+	ch <- prometheus.MustNewConstMetric(clusterMembersDesc, prometheus.GaugeValue, size, clusterGalera)
+	ch <- prometheus.MustNewConstMetric(clusterOnlineMembersDesc, prometheus.GaugeValue, online, clusterGalera)
+	return nil
+}
+
+// scrapeGroupReplicationQuorum derives the quorum from the majority of
+// members in the group view being ONLINE, unreachable members stay in the
+// view of a partitioned member.
+func scrapeGroupReplicationQuorum(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	rows, err := db.QueryContext(ctx, groupReplicationQuorumQuery)
+	if err != nil {
+		if mysqlErr, ok := err.(*MySQL.MySQLError); ok {
+			// Check for error 1109: Unknown table
+			if mysqlErr.Number == 1109 {
+				level.Debug(logger).Log("msg", "performance_schema.replication_group_members is not available.")
+				return nil
+			}
+		}
+		return err
+	}
+	defer rows.Close()
+
+	var (
+		state                string
+		count, total, online float64
+	)
+	for rows.Next() {
+		if err := rows.Scan(&state, &count); err != nil {
+			return err
+		}
+		// A server with Group Replication stopped lists itself as OFFLINE.
+		if state == "OFFLINE" {
+			continue
+		}
+		total += count
+		if state == "ONLINE" {
+			online += count
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if total == 0 {
+		return nil
+	}
+
+	quorum := 0.0
+	if online > total/2 {
+		quorum = 1
+	}
+	ch <- prometheus.MustNewConstMetric(clusterHasQuorumDesc, prometheus.GaugeValue, quorum, clusterGroupReplication)
+	ch <- prometheus.MustNewConstMetric(clusterMembersDesc, prometheus.GaugeValue, total, clusterGroupReplication)
+	ch <- prometheus.MustNewConstMetric(clusterOnlineMembersDesc, prometheus.GaugeValue, online, clusterGroupReplication)
+	return nil
+}
+
+// check interface
+var _ Scraper = ScrapeClusterQuorum{}
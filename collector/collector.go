@@ -20,6 +20,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -47,11 +48,23 @@ type GlobalTransactionIdentifier struct {
 
 var logRE = regexp.MustCompile(`.+\.(\d+)$`)
 
+// descCache holds descriptors of metrics whose names are only known at
+// scrape time, so they are not rebuilt for every row and every scrape.
+var descCache sync.Map
+
+// cachedDesc returns a descriptor without const labels, creating it only
+// on the first call for the given name, help and labels.
+func cachedDesc(fqName, help string, variableLabels []string) *prometheus.Desc {
+	key := fqName + "\xff" + help + "\xff" + strings.Join(variableLabels, "\xff")
+	if desc, ok := descCache.Load(key); ok {
+		return desc.(*prometheus.Desc)
+	}
+	desc, _ := descCache.LoadOrStore(key, prometheus.NewDesc(fqName, help, variableLabels, nil))
+	return desc.(*prometheus.Desc)
+}
+
 func newDesc(subsystem, name, help string) *prometheus.Desc {
-	return prometheus.NewDesc(
-		prometheus.BuildFQName(namespace, subsystem, name),
-		help, nil, nil,
-	)
+	return cachedDesc(prometheus.BuildFQName(namespace, subsystem, name), help, nil)
 }
 
 func parseStatus(data sql.RawBytes) (float64, bool) {
@@ -84,6 +97,13 @@ func parseStatus(data sql.RawBytes) (float64, bool) {
 	return value, err == nil
 }
 
+// ParseStatus parses a status or variable value the way the scrapers do:
+// booleans like ON/OFF, replication states, timestamps, log file numbers and
+// numbers. It returns false for values which can't be parsed.
+func ParseStatus(data []byte) (float64, bool) {
+	return parseStatus(data)
+}
+
 func parsePrivilege(data sql.RawBytes) (float64, bool) {
 	if bytes.Equal(data, []byte("Y")) {
 		return 1, true
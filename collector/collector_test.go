@@ -14,9 +14,14 @@
 package collector
 
 import (
+	"context"
+	"database/sql/driver"
 	"strings"
+	"sync"
 	"testing"
 
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
 	"github.com/prometheus/client_golang/prometheus"
 	dto "github.com/prometheus/client_model/go"
 	. "github.com/smartystreets/goconvey/convey"
@@ -54,6 +59,8 @@ func sanitizeQuery(q string) string {
 	q = strings.Replace(q, "(", "\\(", -1)
 	q = strings.Replace(q, ")", "\\)", -1)
 	q = strings.Replace(q, "*", "\\*", -1)
+	q = strings.Replace(q, "+", "\\+", -1)
+	q = strings.Replace(q, "?", "\\?", -1)
 	return q
 }
 
@@ -137,3 +144,64 @@ func TestParseGTID(t *testing.T) {
 		}
 	})
 }
+
+func TestCachedDesc(t *testing.T) {
+	Convey("Descriptors are reused", t, func() {
+		a := cachedDesc("mysql_test_cached", "Test help.", []string{"l"})
+		So(cachedDesc("mysql_test_cached", "Test help.", []string{"l"}), ShouldEqual, a)
+		So(cachedDesc("mysql_test_cached", "Other help.", []string{"l"}), ShouldNotEqual, a)
+		So(cachedDesc("mysql_test_cached", "Test help.", nil), ShouldNotEqual, a)
+	})
+}
+
+func BenchmarkNewDesc(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		prometheus.NewDesc("mysql_slave_status_read_master_log_pos", "Generic metric from SHOW SLAVE STATUS.", []string{"master_host", "master_uuid", "channel_name", "connection_name"}, nil)
+	}
+}
+
+func BenchmarkCachedDesc(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		cachedDesc("mysql_slave_status_read_master_log_pos", "Generic metric from SHOW SLAVE STATUS.", slaveStatusLabels)
+	}
+}
+
+// BenchmarkScrapeSlaveStatus scrapes the numeric columns of SHOW SLAVE
+// STATUS. The uncached case empties the descriptor cache before every
+// scrape, building the descriptors like before cachedDesc.
+func BenchmarkScrapeSlaveStatus(b *testing.B) {
+	columns := []string{
+		"Master_Host", "Master_UUID", "Master_Port", "Connect_Retry", "Read_Master_Log_Pos",
+		"Relay_Log_Pos", "Exec_Master_Log_Pos", "Relay_Log_Space", "Seconds_Behind_Master",
+		"Master_Server_Id", "Master_Retry_Count", "Skip_Counter", "Last_Errno",
+		"Last_IO_Errno", "Last_SQL_Errno", "Slave_IO_Running", "Slave_SQL_Running",
+		"Master_SSL_Allowed", "Master_SSL_Verify_Server_Cert", "Auto_Position",
+	}
+	values := []driver.Value{"10.0.0.1", "uuid", "3306", "60", "12345", "678", "12345", "9999", "0",
+		"1", "86400", "0", "0", "0", "0", "Yes", "Yes", "No", "No", "1"}
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		b.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	scrape := func(b *testing.B, uncached bool) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			if uncached {
+				descCache = sync.Map{}
+			}
+			mock.ExpectQuery("SHOW SLAVE STATUS").WillReturnRows(sqlmock.NewRows(columns).AddRow(values...))
+			ch := make(chan prometheus.Metric, 1024)
+			b.StartTimer()
+			if err := (ScrapeSlaveStatus{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+	b.Run("cached", func(b *testing.B) { scrape(b, false) })
+	b.Run("uncached", func(b *testing.B) { scrape(b, true) })
+}
@@ -0,0 +1,125 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Compare `SHOW GLOBAL VARIABLES` to expected values.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"strings"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	configComplianceExpected = kingpin.Flag(
+		"collect.config_compliance.expected",
+		"Expected value of a global variable as <variable>=<value>, e.g. sql_mode=STRICT_TRANS_TABLES,NO_ENGINE_SUBSTITUTION. May be repeated.",
+	).Default("innodb_flush_log_at_trx_commit=1", "sync_binlog=1").StringMap()
+)
+
+// Metric descriptors.
+var (
+	configCompliantDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "config", "compliant"),
+		"Whether the global variable has the expected value set with --collect.config_compliance.expected.",
+		[]string{"variable"}, nil,
+	)
+)
+
+// normalizeVariableValue returns value in a form comparable regardless of
+// case, boolean spelling and the order of lists such as sql_mode.
+func normalizeVariableValue(value string) string {
+	items := strings.Split(strings.ToUpper(strings.TrimSpace(value)), ",")
+	for i, item := range items {
+		item = strings.TrimSpace(item)
+		switch item {
+		case "ON", "TRUE", "YES":
+			item = "1"
+		case "OFF", "FALSE", "NO":
+			item = "0"
+		}
+		items[i] = item
+	}
+	sort.Strings(items)
+	return strings.Join(items, ",")
+}
+
+// ScrapeConfigCompliance compares global variables to expected values.
+type ScrapeConfigCompliance struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeConfigCompliance) Name() string {
+	return "config_compliance"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeConfigCompliance) Help() string {
+	return "Collect whether global variables have the values set with --collect.config_compliance.expected"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeConfigCompliance) Version() float64 {
+	return 5.1
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeConfigCompliance) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	expected := make(map[string]string, len(*configComplianceExpected))
+	for variable, value := range *configComplianceExpected {
+		expected[strings.ToLower(variable)] = normalizeVariableValue(value)
+	}
+
+	rows, err := queryRows(ctx, db, globalVariablesQuery)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var (
+		variable string
+		value    sql.RawBytes
+	)
+	for rows.Next() {
+		if err := rows.Scan(&variable, &value); err != nil {
+			return err
+		}
+		variable = strings.ToLower(variable)
+		want, ok := expected[variable]
+		if !ok {
+			continue
+		}
+		delete(expected, variable)
+		compliant := 0.0
+		if normalizeVariableValue(string(value)) == want {
+			compliant = 1
+		}
+		ch <- prometheus.MustNewConstMetric(configCompliantDesc, prometheus.GaugeValue, compliant, variable)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for variable := range expected {
+		level.Debug(logger).Log("msg", "Expected global variable doesn't exist", "variable", variable)
+	}
+	return nil
+}
+
+// check interface
+var _ Scraper = ScrapeConfigCompliance{}
@@ -0,0 +1,78 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeConfigCompliance(t *testing.T) {
+	defaultExpected := *configComplianceExpected
+	*configComplianceExpected = map[string]string{
+		"sql_mode":                       "STRICT_TRANS_TABLES,no_engine_substitution",
+		"innodb_flush_log_at_trx_commit": "1",
+		"log_bin":                        "ON",
+		"read_only":                      "on",
+		"missing_variable":               "1",
+	}
+	defer func() { *configComplianceExpected = defaultExpected }()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"Variable_name", "Value"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("innodb_flush_log_at_trx_commit", "2").
+		AddRow("log_bin", "ON").
+		AddRow("max_connections", "151").
+		AddRow("read_only", "OFF").
+		AddRow("sql_mode", "NO_ENGINE_SUBSTITUTION,STRICT_TRANS_TABLES")
+	mock.ExpectQuery(sanitizeQuery(globalVariablesQuery)).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeConfigCompliance{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	counterExpected := []MetricResult{
+		{labels: labelMap{"variable": "innodb_flush_log_at_trx_commit"}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"variable": "log_bin"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"variable": "read_only"}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"variable": "sql_mode"}, value: 1, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range counterExpected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -0,0 +1,241 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"net"
+	"sync"
+	"syscall"
+	"time"
+
+	MySQL "github.com/go-sql-driver/mysql"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// TLS record content types, see RFC 8446 section 5.1.
+const (
+	tlsRecordHandshake       = 0x16
+	tlsRecordApplicationData = 0x17
+)
+
+var connectPhaseDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    prometheus.BuildFQName(namespace, exporter, "connect_phase_duration_seconds"),
+	Help:    "Duration of the phases of opening scrape connections: dns, connect, tls and auth, which includes the session settings.",
+	Buckets: pingDurationOpts.Buckets,
+}, []string{"target", "phase"})
+
+func init() {
+	prometheus.MustRegister(connectPhaseDuration)
+}
+
+type connectTimingKey struct{}
+
+// connectTiming records when the phases of opening a connection ended.
+type connectTiming struct {
+	mtx       sync.Mutex
+	dialStart time.Time
+	resolved  time.Time
+	connected time.Time
+	tlsStart  time.Time
+	tlsDone   time.Time
+}
+
+func (t *connectTiming) set(at *time.Time) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	if at.IsZero() {
+		*at = time.Now()
+	}
+}
+
+// observe observes the phases of a connection opened at start.
+func (t *connectTiming) observe(target string, start time.Time) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	end := time.Now()
+	if t.connected.IsZero() {
+		// The dial is not instrumented, e.g. for sockets and tunnels.
+		connectPhaseDuration.WithLabelValues(target, "auth").Observe(end.Sub(start).Seconds())
+		return
+	}
+	resolved := t.resolved
+	if resolved.IsZero() {
+		resolved = t.dialStart
+	}
+	connectPhaseDuration.WithLabelValues(target, "dns").Observe(resolved.Sub(t.dialStart).Seconds())
+	connectPhaseDuration.WithLabelValues(target, "connect").Observe(t.connected.Sub(resolved).Seconds())
+	var tls time.Duration
+	if !t.tlsStart.IsZero() && !t.tlsDone.IsZero() {
+		tls = t.tlsDone.Sub(t.tlsStart)
+		connectPhaseDuration.WithLabelValues(target, "tls").Observe(tls.Seconds())
+	}
+	connectPhaseDuration.WithLabelValues(target, "auth").Observe((end.Sub(t.connected) - tls).Seconds())
+}
+
+// DialTimed dials addr with dialer. For scrape connections it records when
+// DNS resolution, the TCP connect and the TLS handshake ended.
+func DialTimed(ctx context.Context, dialer *net.Dialer, network, addr string) (net.Conn, error) {
+	t, ok := ctx.Value(connectTimingKey{}).(*connectTiming)
+	if !ok {
+		return dialer.DialContext(ctx, network, addr)
+	}
+	d := *dialer
+	control := dialer.Control
+	// Control runs once the address is resolved, before connecting.
+	d.Control = func(network, address string, c syscall.RawConn) error {
+		t.set(&t.resolved)
+		if control != nil {
+			return control(network, address, c)
+		}
+		return nil
+	}
+	t.set(&t.dialStart)
+	conn, err := d.DialContext(ctx, network, addr)
+	if err != nil {
+		return nil, err
+	}
+	t.set(&t.connected)
+	return &timedConn{Conn: conn, timing: t}, nil
+}
+
+// timedConn records when the TLS handshake the driver runs over it starts
+// and ends, from the first handshake record written to the first encrypted
+// record.
+type timedConn struct {
+	net.Conn
+	timing *connectTiming
+	done   bool
+}
+
+func (c *timedConn) Write(b []byte) (int, error) {
+	if !c.done && len(b) > 1 {
+		switch {
+		case b[0] == tlsRecordHandshake && b[1] == 0x03:
+			c.timing.set(&c.timing.tlsStart)
+		case b[0] == tlsRecordApplicationData && b[1] == 0x03:
+			c.timing.set(&c.timing.tlsDone)
+			c.done = true
+		}
+	}
+	return c.Conn.Write(b)
+}
+
+// SyscallConn implements syscall.Conn, which the driver checks connections
+// for liveness with.
+func (c *timedConn) SyscallConn() (syscall.RawConn, error) {
+	sc, ok := c.Conn.(syscall.Conn)
+	if !ok {
+		return nil, fmt.Errorf("%T is not a syscall.Conn", c.Conn)
+	}
+	return sc.SyscallConn()
+}
+
+// timedConnector observes the connect phases of the connections it opens.
+type timedConnector struct {
+	driver.Connector
+	target string
+}
+
+// Connect implements driver.Connector.
+func (c timedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	t := &connectTiming{}
+	start := time.Now()
+	conn, err := c.Connector.Connect(context.WithValue(ctx, connectTimingKey{}, t))
+	if err != nil {
+		return nil, err
+	}
+	t.observe(c.target, start)
+	return conn, nil
+}
+
+// openTimedDB is sql.Open observing the connect phases of the connections
+// to the target of dsn. The connections run the query overrides.
+func openTimedDB(driverName, dsn string) (*sql.DB, error) {
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+	d := db.Driver()
+	db.Close()
+	connector, err := openConnector(d, dsn)
+	if err != nil {
+		return nil, err
+	}
+	if len(queryOverrides) > 0 {
+		connector = overrideConnector{connector}
+	}
+	target := "unknown"
+	if cfg, err := MySQL.ParseDSN(dsn); err == nil {
+		target = cfg.Addr
+	}
+	return sql.OpenDB(timedConnector{Connector: connector, target: target}), nil
+}
+
+// openConnector returns a connector of d for name, which only passes the
+// context of Connect on to the dial if d implements driver.DriverContext.
+func openConnector(d driver.Driver, name string) (driver.Connector, error) {
+	if dc, ok := d.(driver.DriverContext); ok {
+		return dc.OpenConnector(name)
+	}
+	return dsnConnector{driver: d, name: name}, nil
+}
+
+// dsnConnector is the connector of a driver.Driver not implementing
+// driver.DriverContext.
+type dsnConnector struct {
+	driver driver.Driver
+	name   string
+}
+
+// Connect implements driver.Connector.
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.name)
+}
+
+// Driver implements driver.Connector.
+func (c dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// dialers are the dialers registered with RegisterDialContext by DSN
+// network, the driver doesn't expose its own.
+var dialers = struct {
+	sync.RWMutex
+	m map[string]MySQL.DialContextFunc
+}{m: map[string]MySQL.DialContextFunc{}}
+
+// RegisterDialContext registers dial as the dialer of the DSN network with
+// the driver, and for the connections the exporter opens without it, like
+// the one of the binlog stream.
+func RegisterDialContext(network string, dial MySQL.DialContextFunc) {
+	dialers.Lock()
+	dialers.m[network] = dial
+	dialers.Unlock()
+	MySQL.RegisterDialContext(network, dial)
+}
+
+// dialContext dials addr on the DSN network like the driver does.
+func dialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	dialers.RLock()
+	dial, ok := dialers.m[network]
+	dialers.RUnlock()
+	if ok {
+		return dial(ctx, addr)
+	}
+	return (&net.Dialer{}).DialContext(ctx, network, addr)
+}
@@ -0,0 +1,94 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestDialTimed(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		io.Copy(io.Discard, conn)
+	}()
+	defer connectPhaseDuration.Reset()
+
+	convey.Convey("The phases of a dial are recorded", t, func() {
+		timing := &connectTiming{}
+		ctx := context.WithValue(context.Background(), connectTimingKey{}, timing)
+		start := time.Now()
+		conn, err := DialTimed(ctx, &net.Dialer{}, "tcp", listener.Addr().String())
+		convey.So(err, convey.ShouldBeNil)
+		defer conn.Close()
+
+		// A ClientHello, then a record encrypted with the negotiated keys.
+		_, err = conn.Write([]byte{tlsRecordHandshake, 0x03, 0x01, 0x00, 0x00})
+		convey.So(err, convey.ShouldBeNil)
+		_, err = conn.Write([]byte{tlsRecordApplicationData, 0x03, 0x03, 0x00, 0x00})
+		convey.So(err, convey.ShouldBeNil)
+
+		convey.So(timing.resolved.IsZero(), convey.ShouldBeFalse)
+		convey.So(timing.connected.IsZero(), convey.ShouldBeFalse)
+		convey.So(timing.tlsDone.Before(timing.tlsStart), convey.ShouldBeFalse)
+
+		timing.observe("127.0.0.1:3306", start)
+		convey.So(testutil.CollectAndCount(connectPhaseDuration), convey.ShouldEqual, 4)
+	})
+
+	convey.Convey("Dials of other connections are not recorded", t, func() {
+		conn, err := DialTimed(context.Background(), &net.Dialer{}, "tcp", listener.Addr().String())
+		convey.So(err, convey.ShouldBeNil)
+		defer conn.Close()
+		_, ok := conn.(*timedConn)
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+}
+
+func TestDialContextRegistered(t *testing.T) {
+	var dialed string
+	RegisterDialContext("test-net", func(ctx context.Context, addr string) (net.Conn, error) {
+		dialed = addr
+		client, server := net.Pipe()
+		server.Close()
+		return client, nil
+	})
+	defer func() {
+		dialers.Lock()
+		delete(dialers.m, "test-net")
+		dialers.Unlock()
+	}()
+
+	convey.Convey("Registered networks use their dialer", t, func() {
+		conn, err := dialContext(context.Background(), "test-net", "db:3306")
+		convey.So(err, convey.ShouldBeNil)
+		conn.Close()
+		convey.So(dialed, convey.ShouldEqual, "db:3306")
+	})
+}
@@ -0,0 +1,108 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const threadsRunningQuery = `SHOW GLOBAL STATUS LIKE 'Threads_running'`
+
+// Tunable flags.
+var (
+	degradeThreshold = kingpin.Flag(
+		"exporter.degrade_threshold",
+		"Skip low priority collectors while Threads_running, or the value of --exporter.degrade_query, is above this threshold. 0 disables degraded scrapes.",
+	).Default("0").Float64()
+	degradeQuery = kingpin.Flag(
+		"exporter.degrade_query",
+		"Query returning the load compared to --exporter.degrade_threshold as a single number, instead of Threads_running.",
+	).Default("").String()
+	degradeCollectors = kingpin.Flag(
+		"exporter.degrade_collectors",
+		"Comma separated list of the low priority collectors skipped in degraded scrapes, defaults to the heavyweight collectors of --exporter.replica_aware.",
+	).Default("").String()
+)
+
+// Metric descriptors.
+var (
+	degradedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, exporter, "degraded"),
+		"Whether low priority collectors were skipped because the server is under load.",
+		nil, nil,
+	)
+)
+
+// lowPriority returns whether the named scraper is skipped in degraded scrapes.
+func lowPriority(name string) bool {
+	if *degradeCollectors == "" {
+		_, ok := defaultScrapePolicies[name]
+		return ok
+	}
+	for _, collector := range strings.Split(*degradeCollectors, ",") {
+		if strings.TrimSpace(collector) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// filterByLoad drops the low priority scrapers when the load of the server
+// is above --exporter.degrade_threshold and returns whether it did. On error
+// all scrapers are kept.
+func filterByLoad(ctx context.Context, db *sql.DB, scrapers []Scraper, logger log.Logger) ([]Scraper, bool) {
+	if *degradeThreshold <= 0 {
+		return scrapers, false
+	}
+	load, err := serverLoad(ctx, db)
+	if err != nil {
+		level.Error(logger).Log("msg", "Error querying server load, not degrading the scrape", "err", err)
+		return scrapers, false
+	}
+	if load <= *degradeThreshold {
+		return scrapers, false
+	}
+
+	res := make([]Scraper, 0, len(scrapers))
+	for _, scraper := range scrapers {
+		if lowPriority(scraper.Name()) {
+			level.Debug(logger).Log("msg", "Skipping low priority scraper on loaded server", "scraper", scraper.Name(), "load", load)
+			continue
+		}
+		res = append(res, scraper)
+	}
+	return res, true
+}
+
+// serverLoad returns the value of --exporter.degrade_query, or Threads_running.
+func serverLoad(ctx context.Context, db *sql.DB) (float64, error) {
+	var load float64
+	if *degradeQuery != "" {
+		err := db.QueryRowContext(ctx, *degradeQuery).Scan(&load)
+		return load, err
+	}
+	var name, value string
+	if err := db.QueryRowContext(ctx, threadsRunningQuery).Scan(&name, &value); err != nil {
+		return 0, err
+	}
+	return strconv.ParseFloat(value, 64)
+}
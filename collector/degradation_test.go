@@ -0,0 +1,74 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestFilterByLoad(t *testing.T) {
+	oldThreshold, oldQuery, oldCollectors := *degradeThreshold, *degradeQuery, *degradeCollectors
+	defer func() {
+		*degradeThreshold, *degradeQuery, *degradeCollectors = oldThreshold, oldQuery, oldCollectors
+	}()
+	*degradeThreshold = 50
+
+	scrapers := []Scraper{ScrapeGlobalStatus{}, ScrapeTableSchema{}, ScrapeSlaveStatus{}}
+	names := func(scrapers []Scraper) []string {
+		var res []string
+		for _, scraper := range scrapers {
+			res = append(res, scraper.Name())
+		}
+		return res
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"Variable_name", "Value"}
+	mock.ExpectQuery(sanitizeQuery(threadsRunningQuery)).WillReturnRows(sqlmock.NewRows(columns).AddRow("Threads_running", "12"))
+	mock.ExpectQuery(sanitizeQuery(threadsRunningQuery)).WillReturnRows(sqlmock.NewRows(columns).AddRow("Threads_running", "80"))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM information_schema.processlist")).WillReturnRows(
+		sqlmock.NewRows([]string{"COUNT(*)"}).AddRow(60))
+
+	convey.Convey("Scrapers filtered by server load", t, func() {
+		filtered, degraded := filterByLoad(context.Background(), db, scrapers, log.NewNopLogger())
+		convey.So(degraded, convey.ShouldBeFalse)
+		convey.So(names(filtered), convey.ShouldResemble, []string{"global_status", "info_schema.tables", "slave_status"})
+
+		filtered, degraded = filterByLoad(context.Background(), db, scrapers, log.NewNopLogger())
+		convey.So(degraded, convey.ShouldBeTrue)
+		convey.So(names(filtered), convey.ShouldResemble, []string{"global_status", "slave_status"})
+
+		*degradeQuery = "SELECT COUNT(*) FROM information_schema.processlist"
+		*degradeCollectors = "slave_status"
+		filtered, degraded = filterByLoad(context.Background(), db, scrapers, log.NewNopLogger())
+		convey.So(degraded, convey.ShouldBeTrue)
+		convey.So(names(filtered), convey.ShouldResemble, []string{"global_status", "info_schema.tables"})
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
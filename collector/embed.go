@@ -0,0 +1,281 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	MySQL "github.com/go-sql-driver/mysql"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// ExporterOption configures the collector returned by NewExporter.
+type ExporterOption func(*embeddedExporter)
+
+// WithCollectors sets the scrapers run for every target, by default
+// ScrapeGlobalStatus and ScrapeGlobalVariables.
+func WithCollectors(scrapers ...Scraper) ExporterOption {
+	return func(e *embeddedExporter) {
+		e.scrapers = scrapers
+	}
+}
+
+// WithLogger sets the logger, by default nothing is logged.
+func WithLogger(logger log.Logger) ExporterOption {
+	return func(e *embeddedExporter) {
+		e.logger = logger
+	}
+}
+
+// WithContext sets the context of the scrapes, by default
+// context.Background().
+func WithContext(ctx context.Context) ExporterOption {
+	return func(e *embeddedExporter) {
+		e.ctx = ctx
+	}
+}
+
+// WithLocks serializes the scrapes of a target, so concurrent collections
+// don't open one connection each. Combined with WithCache, collections
+// waiting for a running scrape reuse its result.
+func WithLocks(enabled bool) ExporterOption {
+	return func(e *embeddedExporter) {
+		e.locks = enabled
+	}
+}
+
+// WithCache replays the metrics of a target scraped less than ttl ago
+// instead of scraping it again.
+func WithCache(ttl time.Duration) ExporterOption {
+	return func(e *embeddedExporter) {
+		e.cacheTTL = ttl
+	}
+}
+
+// WithDB adds a target scraped through db, for programs controlling the
+// connections themselves. name is the target label of its metrics when
+// there is more than one target. db is not closed.
+func WithDB(name string, db *sql.DB) ExporterOption {
+	return func(e *embeddedExporter) {
+		e.sources = append(e.sources, embeddedSource{name: name, db: db})
+	}
+}
+
+// WithConnector adds a target whose scrape connections are opened with
+// connector, e.g. to wrap the connections of the driver with a proxy or
+// interceptor. name is the target label of its metrics when there is more
+// than one target.
+func WithConnector(name string, connector driver.Connector) ExporterOption {
+	return func(e *embeddedExporter) {
+		e.sources = append(e.sources, embeddedSource{name: name, connector: connector})
+	}
+}
+
+// NewExporter returns a collector scraping the MySQL servers at the targets
+// DSNs and the targets added by WithDB and WithConnector, for programs
+// embedding the exporter rather than running it. With more than one target
+// the metrics get a target label with the address of the server.
+//
+// The scrapers read their settings from the exporter's kingpin flags, call
+// kingpin.Parse or kingpin.CommandLine.Parse(nil) to apply their defaults.
+func NewExporter(targets []string, opts ...ExporterOption) prometheus.Collector {
+	e := &embeddedExporter{
+		ctx:      context.Background(),
+		logger:   log.NewNopLogger(),
+		scrapers: []Scraper{ScrapeGlobalStatus{}, ScrapeGlobalVariables{}},
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	labeled := len(targets)+len(e.sources) > 1
+	for i, dsn := range targets {
+		t := &embeddedTarget{exporter: New(e.ctx, dsn, e.scrapers, e.logger)}
+		if labeled {
+			t.name = targetName(dsn, i)
+		}
+		e.targets = append(e.targets, t)
+	}
+	for _, s := range e.sources {
+		t := &embeddedTarget{}
+		if s.db != nil {
+			t.exporter = NewWithDB(e.ctx, s.db, e.scrapers, e.logger)
+		} else {
+			t.exporter = NewWithConnector(e.ctx, s.connector, e.scrapers, e.logger)
+		}
+		if labeled {
+			t.name = s.name
+		}
+		e.targets = append(e.targets, t)
+	}
+	return e
+}
+
+// embeddedSource is a target added by WithDB or WithConnector.
+type embeddedSource struct {
+	name      string
+	db        *sql.DB
+	connector driver.Connector
+}
+
+// targetName returns the address of dsn, leaving out the credentials, or
+// its index if it can't be parsed.
+func targetName(dsn string, i int) string {
+	if cfg, err := MySQL.ParseDSN(dsn); err == nil && cfg.Addr != "" {
+		return cfg.Addr
+	}
+	return strconv.Itoa(i)
+}
+
+type embeddedExporter struct {
+	ctx      context.Context
+	logger   log.Logger
+	scrapers []Scraper
+	locks    bool
+	cacheTTL time.Duration
+	sources  []embeddedSource
+	targets  []*embeddedTarget
+}
+
+// Describe implements prometheus.Collector. The metrics of scrapers are only
+// known at scrape time, so it is an unchecked collector.
+func (e *embeddedExporter) Describe(ch chan<- *prometheus.Desc) {}
+
+// Collect implements prometheus.Collector.
+func (e *embeddedExporter) Collect(ch chan<- prometheus.Metric) {
+	var wg sync.WaitGroup
+	for _, t := range e.targets {
+		wg.Add(1)
+		go func(t *embeddedTarget) {
+			defer wg.Done()
+			for _, m := range e.collectTarget(t) {
+				ch <- m
+			}
+		}(t)
+	}
+	wg.Wait()
+}
+
+func (e *embeddedExporter) collectTarget(t *embeddedTarget) []prometheus.Metric {
+	if e.locks {
+		t.scrapeMtx.Lock()
+		defer t.scrapeMtx.Unlock()
+	}
+	if metrics, ok := t.cached(e.cacheTTL); ok {
+		return metrics
+	}
+	metrics, err := t.scrape()
+	if err != nil {
+		level.Error(e.logger).Log("msg", "Error collecting target", "target", t.name, "err", err)
+	}
+	if e.cacheTTL > 0 {
+		t.store(metrics)
+	}
+	return metrics
+}
+
+// embeddedTarget is a target of NewExporter.
+type embeddedTarget struct {
+	name     string
+	exporter *Exporter
+
+	scrapeMtx sync.Mutex
+
+	cacheMtx sync.Mutex
+	metrics  []prometheus.Metric
+	scraped  time.Time
+}
+
+func (t *embeddedTarget) cached(ttl time.Duration) ([]prometheus.Metric, bool) {
+	t.cacheMtx.Lock()
+	defer t.cacheMtx.Unlock()
+	if ttl <= 0 || t.metrics == nil || time.Since(t.scraped) >= ttl {
+		return nil, false
+	}
+	return t.metrics, true
+}
+
+func (t *embeddedTarget) store(metrics []prometheus.Metric) {
+	t.cacheMtx.Lock()
+	defer t.cacheMtx.Unlock()
+	t.metrics, t.scraped = metrics, time.Now()
+}
+
+// scrape runs the exporter of the target and returns its metrics with the
+// target label added.
+func (t *embeddedTarget) scrape() ([]prometheus.Metric, error) {
+	registry := prometheus.NewRegistry()
+	var labels prometheus.Labels
+	if t.name != "" {
+		labels = prometheus.Labels{"target": t.name}
+	}
+	if err := prometheus.WrapRegistererWith(labels, registry).Register(t.exporter); err != nil {
+		return nil, err
+	}
+	mfs, err := registry.Gather()
+	return constMetrics(mfs), err
+}
+
+// constMetrics turns gathered metric families back into metrics.
+func constMetrics(mfs []*dto.MetricFamily) []prometheus.Metric {
+	var res []prometheus.Metric
+	for _, mf := range mfs {
+		for _, m := range mf.Metric {
+			names := make([]string, len(m.Label))
+			values := make([]string, len(m.Label))
+			for i, lp := range m.Label {
+				names[i], values[i] = lp.GetName(), lp.GetValue()
+			}
+			desc := cachedDesc(mf.GetName(), mf.GetHelp(), names)
+
+			var (
+				metric prometheus.Metric
+				err    error
+			)
+			switch mf.GetType() {
+			case dto.MetricType_COUNTER:
+				metric, err = prometheus.NewConstMetric(desc, prometheus.CounterValue, m.GetCounter().GetValue(), values...)
+			case dto.MetricType_GAUGE:
+				metric, err = prometheus.NewConstMetric(desc, prometheus.GaugeValue, m.GetGauge().GetValue(), values...)
+			case dto.MetricType_UNTYPED:
+				metric, err = prometheus.NewConstMetric(desc, prometheus.UntypedValue, m.GetUntyped().GetValue(), values...)
+			case dto.MetricType_HISTOGRAM:
+				h := m.GetHistogram()
+				buckets := make(map[float64]uint64, len(h.Bucket))
+				for _, b := range h.Bucket {
+					buckets[b.GetUpperBound()] = b.GetCumulativeCount()
+				}
+				metric, err = prometheus.NewConstHistogram(desc, h.GetSampleCount(), h.GetSampleSum(), buckets, values...)
+			case dto.MetricType_SUMMARY:
+				s := m.GetSummary()
+				quantiles := make(map[float64]float64, len(s.Quantile))
+				for _, q := range s.Quantile {
+					quantiles[q.GetQuantile()] = q.GetValue()
+				}
+				metric, err = prometheus.NewConstSummary(desc, s.GetSampleCount(), s.GetSampleSum(), quantiles, values...)
+			}
+			if err == nil && metric != nil {
+				res = append(res, metric)
+			}
+		}
+	}
+	return res
+}
@@ -0,0 +1,91 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestNewExporter(t *testing.T) {
+	// Nothing listens on port 1, connecting fails right away.
+	exporter := NewExporter(
+		[]string{"root:secret@tcp(127.0.0.1:1)/", "root:secret@tcp(127.0.0.2:1)/"},
+		WithCollectors(ScrapeGlobalStatus{}),
+		WithLocks(true),
+		WithCache(time.Minute),
+	)
+
+	convey.Convey("Targets are labelled with their address", t, func() {
+		err := testutil.CollectAndCompare(exporter, strings.NewReader(`
+# HELP mysql_up Whether the MySQL server is up.
+# TYPE mysql_up gauge
+mysql_up{target="127.0.0.1:1"} 0
+mysql_up{target="127.0.0.2:1"} 0
+`), "mysql_up")
+		convey.So(err, convey.ShouldBeNil)
+	})
+
+	convey.Convey("Cached targets are not scraped again", t, func() {
+		errors := testutil.ToFloat64(scrapeErrorsTotal.WithLabelValues("connection", errorClassConnection, ""))
+		testutil.CollectAndCount(exporter)
+		convey.So(testutil.ToFloat64(scrapeErrorsTotal.WithLabelValues("connection", errorClassConnection, "")), convey.ShouldEqual, errors)
+	})
+}
+
+func TestNewExporterWithDB(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectPing()
+	mock.ExpectQuery(sanitizeQuery(versionQuery)).WillReturnRows(sqlmock.NewRows([]string{"@@version"}).AddRow("8.0.32"))
+	mock.ExpectQuery(sanitizeQuery(uptimeQuery)).WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).AddRow("Uptime", "42"))
+
+	exporter := NewExporter(
+		[]string{"root:secret@tcp(127.0.0.1:1)/"},
+		WithCollectors(ScrapeUptime{}),
+		WithDB("pooled", db),
+	)
+
+	convey.Convey("Targets added with WithDB are labelled with their name", t, func() {
+		err := testutil.CollectAndCompare(exporter, strings.NewReader(`
+# HELP mysql_up Whether the MySQL server is up.
+# TYPE mysql_up gauge
+mysql_up{target="127.0.0.1:1"} 0
+mysql_up{target="pooled"} 1
+`), "mysql_up")
+		convey.So(err, convey.ShouldBeNil)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestTargetName(t *testing.T) {
+	convey.Convey("Target names leave out credentials", t, func() {
+		convey.So(targetName("user:password@tcp(db-1:3306)/", 0), convey.ShouldEqual, "db-1:3306")
+		convey.So(targetName("user:password@unix(/run/mysqld.sock)/", 0), convey.ShouldEqual, "/run/mysqld.sock")
+		convey.So(targetName("not a dsn", 3), convey.ShouldEqual, "3")
+	})
+}
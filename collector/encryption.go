@@ -0,0 +1,200 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape keyring and data-at-rest encryption status.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	MySQL "github.com/go-sql-driver/mysql"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// Subsystem.
+	encryption = "encryption"
+	// Queries.
+	keyringPluginsQuery = `
+	SELECT PLUGIN_NAME
+	  FROM information_schema.plugins
+	  WHERE PLUGIN_NAME LIKE 'keyring%' AND PLUGIN_STATUS = 'ACTIVE'
+	`
+	// Keyring components replace keyring plugins from MySQL 8.0.24.
+	keyringComponentQuery = `
+	SELECT STATUS_KEY, STATUS_VALUE
+	  FROM performance_schema.keyring_component_status
+	  WHERE STATUS_KEY IN ('Component_name', 'Component_status')
+	`
+	encryptedTablespacesQuery = `
+	SELECT ENCRYPTION, COUNT(*)
+	  FROM information_schema.INNODB_TABLESPACES
+	  GROUP BY ENCRYPTION
+	`
+	encryptionVariablesQuery = `
+	SHOW GLOBAL VARIABLES
+	  WHERE Variable_name IN (
+	    'binlog_encryption',
+	    'default_table_encryption',
+	    'innodb_redo_log_encrypt',
+	    'innodb_undo_log_encrypt'
+	  )
+	`
+)
+
+// Metric descriptors.
+var (
+	encryptionKeyringLoadedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, encryption, "keyring_loaded"),
+		"Whether a keyring plugin or component is loaded.",
+		nil, nil,
+	)
+	encryptionKeyringInfoDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, encryption, "keyring_info"),
+		"The active keyring plugins and components.",
+		[]string{"type", "name"}, nil,
+	)
+	encryptionTablespacesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, encryption, "tablespaces"),
+		"The number of InnoDB tablespaces by encryption.",
+		[]string{"encrypted"}, nil,
+	)
+	encryptionSettingDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, encryption, "setting_enabled"),
+		"Whether an encryption setting such as binlog_encryption is enabled.",
+		[]string{"variable"}, nil,
+	)
+)
+
+// ScrapeEncryption collects keyring and data-at-rest encryption status.
+type ScrapeEncryption struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeEncryption) Name() string {
+	return encryption
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeEncryption) Help() string {
+	return "Collect keyring status, encrypted tablespaces and encryption settings"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeEncryption) Version() float64 {
+	return 8.0
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeEncryption) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	pluginRows, err := db.QueryContext(ctx, keyringPluginsQuery)
+	if err != nil {
+		return err
+	}
+	defer pluginRows.Close()
+
+	loaded := 0.0
+	var name string
+	for pluginRows.Next() {
+		if err := pluginRows.Scan(&name); err != nil {
+			return err
+		}
+		loaded = 1
+		ch <- prometheus.MustNewConstMetric(encryptionKeyringInfoDesc, prometheus.GaugeValue, 1, "plugin", name)
+	}
+	if err := pluginRows.Err(); err != nil {
+		return err
+	}
+
+	componentRows, err := db.QueryContext(ctx, keyringComponentQuery)
+	if err != nil {
+		mysqlErr, ok := err.(*MySQL.MySQLError)
+		// Check for error 1146: Table doesn't exist
+		if !ok || mysqlErr.Number != 1146 {
+			return err
+		}
+		level.Debug(logger).Log("msg", "performance_schema.keyring_component_status is not available.")
+	} else {
+		defer componentRows.Close()
+		var key, value, componentName, componentStatus string
+		for componentRows.Next() {
+			if err := componentRows.Scan(&key, &value); err != nil {
+				return err
+			}
+			switch key {
+			case "Component_name":
+				componentName = value
+			case "Component_status":
+				componentStatus = value
+			}
+		}
+		if err := componentRows.Err(); err != nil {
+			return err
+		}
+		if componentStatus == "Active" {
+			loaded = 1
+			ch <- prometheus.MustNewConstMetric(encryptionKeyringInfoDesc, prometheus.GaugeValue, 1, "component", componentName)
+		}
+	}
+	ch <- prometheus.MustNewConstMetric(encryptionKeyringLoadedDesc, prometheus.GaugeValue, loaded)
+
+	tablespaceRows, err := db.QueryContext(ctx, encryptedTablespacesQuery)
+	if err != nil {
+		return err
+	}
+	defer tablespaceRows.Close()
+
+	var (
+		encrypted string
+		count     float64
+	)
+	for tablespaceRows.Next() {
+		if err := tablespaceRows.Scan(&encrypted, &count); err != nil {
+			return err
+		}
+		label := "no"
+		if encrypted == "Y" {
+			label = "yes"
+		}
+		ch <- prometheus.MustNewConstMetric(encryptionTablespacesDesc, prometheus.GaugeValue, count, label)
+	}
+	if err := tablespaceRows.Err(); err != nil {
+		return err
+	}
+
+	variableRows, err := db.QueryContext(ctx, encryptionVariablesQuery)
+	if err != nil {
+		return err
+	}
+	defer variableRows.Close()
+
+	var value string
+	for variableRows.Next() {
+		if err := variableRows.Scan(&name, &value); err != nil {
+			return err
+		}
+		enabled := 0.0
+		if strings.EqualFold(value, "ON") {
+			enabled = 1
+		}
+		ch <- prometheus.MustNewConstMetric(encryptionSettingDesc, prometheus.GaugeValue, enabled, name)
+	}
+	return variableRows.Err()
+}
+
+// check interface
+var _ Scraper = ScrapeEncryption{}
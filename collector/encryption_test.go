@@ -0,0 +1,76 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeEncryption(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(keyringPluginsQuery)).WillReturnRows(
+		sqlmock.NewRows([]string{"PLUGIN_NAME"}))
+	mock.ExpectQuery(sanitizeQuery(keyringComponentQuery)).WillReturnRows(
+		sqlmock.NewRows([]string{"STATUS_KEY", "STATUS_VALUE"}).
+			AddRow("Component_name", "component_keyring_file").
+			AddRow("Component_status", "Active"))
+	mock.ExpectQuery(sanitizeQuery(encryptedTablespacesQuery)).WillReturnRows(
+		sqlmock.NewRows([]string{"ENCRYPTION", "COUNT(*)"}).
+			AddRow("N", 10).
+			AddRow("Y", 3))
+	mock.ExpectQuery(sanitizeQuery(encryptionVariablesQuery)).WillReturnRows(
+		sqlmock.NewRows([]string{"Variable_name", "Value"}).
+			AddRow("binlog_encryption", "ON").
+			AddRow("default_table_encryption", "OFF"))
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeEncryption{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{"type": "component", "name": "component_keyring_file"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"encrypted": "no"}, value: 10, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"encrypted": "yes"}, value: 3, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"variable": "binlog_encryption"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"variable": "default_table_encryption"}, value: 0, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -21,6 +21,8 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/go-kit/log"
 	"github.com/prometheus/client_golang/prometheus"
@@ -33,6 +35,33 @@ const (
 	engineInnodbStatusQuery = `SHOW ENGINE INNODB STATUS`
 )
 
+// Sections of `SHOW ENGINE INNODB STATUS`.
+const (
+	innodbStatusForeignKeyErrorSection = "LATEST FOREIGN KEY ERROR"
+	innodbStatusDeadlockSection        = "LATEST DETECTED DEADLOCK"
+)
+
+// innodbStatusTimeRE matches the timestamp an event section starts with,
+// "2006-01-02 15:04:05" or "060102 15:04:05" before MySQL 5.6.
+var innodbStatusTimeRE = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2}[ T]\d{2}:\d{2}:\d{2}|\d{6} +\d{1,2}:\d{2}:\d{2})`)
+
+// Metric descriptors.
+var (
+	engineInnodbForeignKeyErrorTimeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, innodb, "latest_foreign_key_error_timestamp_seconds"),
+		"Time of the latest foreign key error from SHOW ENGINE INNODB STATUS.",
+		nil, nil,
+	)
+	engineInnodbForeignKeyErrorsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, innodb, "foreign_key_errors_total"),
+		"Number of foreign key errors seen in SHOW ENGINE INNODB STATUS since the exporter started.",
+		nil, nil,
+	)
+)
+
+// innodbForeignKeyErrors counts the foreign key errors seen across scrapes.
+var innodbForeignKeyErrors innodbStatusEvents
+
 // ScrapeEngineInnodbStatus scrapes from `SHOW ENGINE INNODB STATUS`.
 type ScrapeEngineInnodbStatus struct{}
 
@@ -53,19 +82,11 @@ func (ScrapeEngineInnodbStatus) Version() float64 {
 
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
 func (ScrapeEngineInnodbStatus) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
-	rows, err := db.QueryContext(ctx, engineInnodbStatusQuery)
+	statusCol, err := innodbStatus(ctx, db)
 	if err != nil {
 		return err
 	}
-	defer rows.Close()
-
-	var typeCol, nameCol, statusCol string
-	// First row should contain the necessary info. If many rows returned then it's unknown case.
-	if rows.Next() {
-		if err := rows.Scan(&typeCol, &nameCol, &statusCol); err != nil {
-			return err
-		}
-	}
+	recordRawOutput(ctx, ScrapeEngineInnodbStatus{}.Name(), statusCol)
 
 	// 0 queries inside InnoDB, 0 queries in queue
 	// 0 read views open inside InnoDB
@@ -96,8 +117,104 @@ func (ScrapeEngineInnodbStatus) Scrape(ctx context.Context, db *sql.DB, ch chan<
 		}
 	}
 
+	fkErrorTime, ok := innodbStatusSectionTime(statusCol, innodbStatusForeignKeyErrorSection)
+	if ok {
+		ch <- prometheus.MustNewConstMetric(
+			engineInnodbForeignKeyErrorTimeDesc, prometheus.GaugeValue, float64(fkErrorTime.Unix()),
+		)
+	}
+	ch <- prometheus.MustNewConstMetric(
+		engineInnodbForeignKeyErrorsDesc, prometheus.CounterValue, innodbForeignKeyErrors.observe(fkErrorTime),
+	)
+
 	return nil
 }
 
+// innodbStatus returns the output of SHOW ENGINE INNODB STATUS.
+func innodbStatus(ctx context.Context, db *sql.DB) (string, error) {
+	rows, err := db.QueryContext(ctx, engineInnodbStatusQuery)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	var typeCol, nameCol, statusCol string
+	// First row should contain the necessary info. If many rows returned then it's unknown case.
+	if rows.Next() {
+		if err := rows.Scan(&typeCol, &nameCol, &statusCol); err != nil {
+			return "", err
+		}
+	}
+	return statusCol, rows.Err()
+}
+
+// innodbStatusSection returns the lines of a section of SHOW ENGINE INNODB
+// STATUS, nil if the section is missing.
+func innodbStatusSection(status, section string) []string {
+	lines := strings.Split(status, "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) != section {
+			continue
+		}
+		var res []string
+		for _, line := range lines[i+1:] {
+			line = strings.TrimSpace(line)
+			// Sections are delimited by lines of dashes.
+			if strings.Trim(line, "-") == "" && line != "" {
+				if len(res) > 0 {
+					break
+				}
+				continue
+			}
+			if line == "" && len(res) == 0 {
+				continue
+			}
+			res = append(res, line)
+		}
+		return res
+	}
+	return nil
+}
+
+// innodbStatusSectionTime returns the time of the event shown in a section
+// of SHOW ENGINE INNODB STATUS.
+func innodbStatusSectionTime(status, section string) (time.Time, bool) {
+	lines := innodbStatusSection(status, section)
+	if len(lines) == 0 {
+		return time.Time{}, false
+	}
+	match := innodbStatusTimeRE.FindString(lines[0])
+	if match == "" {
+		return time.Time{}, false
+	}
+	match = strings.Join(strings.Fields(strings.Replace(match, "T", " ", 1)), " ")
+	layout := "2006-01-02 15:04:05"
+	if !strings.Contains(match, "-") {
+		layout = "060102 15:04:05"
+	}
+	ts, err := time.ParseInLocation(layout, match, time.Local)
+	return ts, err == nil
+}
+
+// innodbStatusEvents counts the events of a SHOW ENGINE INNODB STATUS
+// section, which only ever shows the latest event.
+type innodbStatusEvents struct {
+	mu    sync.Mutex
+	last  time.Time
+	count float64
+}
+
+// observe records the time of the latest event and returns the number of
+// events seen so far.
+func (e *innodbStatusEvents) observe(ts time.Time) float64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if ts.After(e.last) {
+		e.last = ts
+		e.count++
+	}
+	return e.count
+}
+
 // check interface
 var _ Scraper = ScrapeEngineInnodbStatus{}
@@ -15,7 +15,9 @@ package collector
 
 import (
 	"context"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/go-kit/log"
@@ -25,6 +27,8 @@ import (
 )
 
 func TestScrapeEngineInnodbStatus(t *testing.T) {
+	innodbForeignKeyErrors = innodbStatusEvents{}
+
 	db, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("error opening a stub database connection: %s", err)
@@ -165,6 +169,7 @@ END OF INNODB MONITOR OUTPUT
 		{labels: labelMap{}, value: 661, metricType: dto.MetricType_GAUGE},
 		{labels: labelMap{}, value: 10, metricType: dto.MetricType_GAUGE},
 		{labels: labelMap{}, value: 15, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 0, metricType: dto.MetricType_COUNTER},
 	}
 	convey.Convey("Metrics comparison", t, func() {
 		for _, expect := range metricsExpected {
@@ -178,3 +183,91 @@ END OF INNODB MONITOR OUTPUT
 		t.Errorf("there were unfulfilled exceptions: %s", err)
 	}
 }
+
+func TestScrapeEngineInnodbStatusForeignKeyError(t *testing.T) {
+	innodbForeignKeyErrors = innodbStatusEvents{}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	sample := `
+=====================================
+2016-09-14 19:04:38 0x7fed21462700 INNODB MONITOR OUTPUT
+=====================================
+------------------------
+LATEST FOREIGN KEY ERROR
+------------------------
+%s 0x7fed21462700 Transaction:
+TRANSACTION 67840, ACTIVE 0 sec inserting
+Foreign key constraint fails for table ` + "`shop`.`orders`" + `:
+------------
+TRANSACTIONS
+------------
+Trx id counter 67843
+----------------------------
+END OF INNODB MONITOR OUTPUT
+============================
+	`
+	columns := []string{"Type", "Name", "Status"}
+	for _, ts := range []string{"2016-09-14 19:01:02", "2016-09-14 19:01:02", "2016-09-14 19:03:04"} {
+		rows := sqlmock.NewRows(columns).AddRow("InnoDB", "", fmt.Sprintf(sample, ts))
+		mock.ExpectQuery(sanitizeQuery(engineInnodbStatusQuery)).WillReturnRows(rows)
+	}
+
+	first, _ := time.ParseInLocation("2006-01-02 15:04:05", "2016-09-14 19:01:02", time.Local)
+	second, _ := time.ParseInLocation("2006-01-02 15:04:05", "2016-09-14 19:03:04", time.Local)
+	metricsExpected := [][]MetricResult{
+		{
+			{labels: labelMap{}, value: float64(first.Unix()), metricType: dto.MetricType_GAUGE},
+			{labels: labelMap{}, value: 1, metricType: dto.MetricType_COUNTER},
+		},
+		{
+			{labels: labelMap{}, value: float64(first.Unix()), metricType: dto.MetricType_GAUGE},
+			{labels: labelMap{}, value: 1, metricType: dto.MetricType_COUNTER},
+		},
+		{
+			{labels: labelMap{}, value: float64(second.Unix()), metricType: dto.MetricType_GAUGE},
+			{labels: labelMap{}, value: 2, metricType: dto.MetricType_COUNTER},
+		},
+	}
+	convey.Convey("Foreign key errors are counted once", t, func() {
+		for _, expected := range metricsExpected {
+			ch := make(chan prometheus.Metric)
+			go func() {
+				if err = (ScrapeEngineInnodbStatus{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+					t.Errorf("error calling function on test: %s", err)
+				}
+				close(ch)
+			}()
+			var got []MetricResult
+			for m := range ch {
+				got = append(got, readMetric(m))
+			}
+			convey.So(got, convey.ShouldResemble, expected)
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestInnodbStatusSectionTime(t *testing.T) {
+	convey.Convey("Section timestamps", t, func() {
+		for status, want := range map[string]string{
+			"LATEST FOREIGN KEY ERROR\n----\n2023-05-10 12:34:56 0x7f Transaction:": "2023-05-10 12:34:56",
+			"LATEST FOREIGN KEY ERROR\n----\n2023-05-10T12:34:56.123 Transaction:":  "2023-05-10 12:34:56",
+			"LATEST FOREIGN KEY ERROR\n----\n230510  9:34:56 Transaction:":          "2023-05-10 09:34:56",
+		} {
+			ts, ok := innodbStatusSectionTime(status, innodbStatusForeignKeyErrorSection)
+			convey.So(ok, convey.ShouldBeTrue)
+			convey.So(ts.Format("2006-01-02 15:04:05"), convey.ShouldEqual, want)
+		}
+		_, ok := innodbStatusSectionTime("TRANSACTIONS\n----\n", innodbStatusForeignKeyErrorSection)
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+}
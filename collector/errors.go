@@ -0,0 +1,112 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+
+	MySQL "github.com/go-sql-driver/mysql"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Classes of scrape errors.
+const (
+	errorClassPermission   = "permission"
+	errorClassTimeout      = "timeout"
+	errorClassMissingTable = "missing_table"
+	errorClassParse        = "parse"
+	errorClassConnection   = "connection"
+	errorClassOther        = "other"
+)
+
+// mysqlErrorClasses maps MySQL error numbers to error classes.
+var mysqlErrorClasses = map[uint16]string{
+	1044: errorClassPermission,   // ER_DBACCESS_DENIED_ERROR
+	1045: errorClassPermission,   // ER_ACCESS_DENIED_ERROR
+	1142: errorClassPermission,   // ER_TABLEACCESS_DENIED_ERROR
+	1143: errorClassPermission,   // ER_COLUMNACCESS_DENIED_ERROR
+	1227: errorClassPermission,   // ER_SPECIFIC_ACCESS_DENIED_ERROR
+	1370: errorClassPermission,   // ER_PROCACCESS_DENIED_ERROR
+	3118: errorClassPermission,   // ER_ACCOUNT_HAS_BEEN_LOCKED
+	1049: errorClassMissingTable, // ER_BAD_DB_ERROR
+	1054: errorClassMissingTable, // ER_BAD_FIELD_ERROR
+	1109: errorClassMissingTable, // ER_UNKNOWN_TABLE
+	1146: errorClassMissingTable, // ER_NO_SUCH_TABLE
+	1193: errorClassMissingTable, // ER_UNKNOWN_SYSTEM_VARIABLE
+	1205: errorClassTimeout,      // ER_LOCK_WAIT_TIMEOUT
+	1317: errorClassTimeout,      // ER_QUERY_INTERRUPTED
+	3024: errorClassTimeout,      // ER_QUERY_TIMEOUT
+	1040: errorClassConnection,   // ER_CON_COUNT_ERROR
+	1203: errorClassConnection,   // ER_TOO_MANY_USER_CONNECTIONS
+}
+
+var scrapeErrorsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: prometheus.BuildFQName(namespace, exporter, "scrape_errors_total"),
+	Help: "Errors while scraping MySQL, by collector, error class and MySQL error number.",
+}, []string{"collector", "error_class", "mysql_errno"})
+
+func init() {
+	prometheus.MustRegister(scrapeErrorsTotal)
+}
+
+// parseError is a value a scraper failed to parse.
+type parseError struct {
+	err error
+}
+
+func (e parseError) Error() string { return e.err.Error() }
+
+func (e parseError) Unwrap() error { return e.err }
+
+// classifyError returns the error class and, for errors returned by the
+// server, the MySQL error number of err.
+func classifyError(err error) (string, string) {
+	var mysqlErr *MySQL.MySQLError
+	if errors.As(err, &mysqlErr) {
+		errno := strconv.Itoa(int(mysqlErr.Number))
+		if class, ok := mysqlErrorClasses[mysqlErr.Number]; ok {
+			return class, errno
+		}
+		return errorClassOther, errno
+	}
+
+	var (
+		netErr   net.Error
+		numErr   *strconv.NumError
+		parseErr parseError
+	)
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		return errorClassTimeout, ""
+	case errors.Is(err, driver.ErrBadConn), errors.Is(err, MySQL.ErrInvalidConn), errors.As(err, &netErr):
+		return errorClassConnection, ""
+	case errors.As(err, &parseErr), errors.As(err, &numErr):
+		return errorClassParse, ""
+	case strings.HasPrefix(err.Error(), "sql: Scan error"):
+		// database/sql doesn't wrap conversion errors.
+		return errorClassParse, ""
+	}
+	return errorClassOther, ""
+}
+
+// countScrapeError increments mysql_exporter_scrape_errors_total for err.
+func countScrapeError(collector string, err error) {
+	class, errno := classifyError(err)
+	scrapeErrorsTotal.WithLabelValues(collector, class, errno).Inc()
+}
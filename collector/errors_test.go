@@ -0,0 +1,59 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+
+	MySQL "github.com/go-sql-driver/mysql"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestClassifyError(t *testing.T) {
+	_, numErr := strconv.ParseFloat("x", 64)
+	tests := []struct {
+		err   error
+		class string
+		errno string
+	}{
+		{&MySQL.MySQLError{Number: 1227, Message: "Access denied"}, errorClassPermission, "1227"},
+		{fmt.Errorf("querying: %w", &MySQL.MySQLError{Number: 1146}), errorClassMissingTable, "1146"},
+		{&MySQL.MySQLError{Number: 3024}, errorClassTimeout, "3024"},
+		{&MySQL.MySQLError{Number: 1064}, errorClassOther, "1064"},
+		{context.DeadlineExceeded, errorClassTimeout, ""},
+		{driver.ErrBadConn, errorClassConnection, ""},
+		{numErr, errorClassParse, ""},
+		{errors.New(`sql: Scan error on column index 1, name "Value": converting driver.Value type []uint8 ("x") to a float64: invalid syntax`), errorClassParse, ""},
+		{errors.New("unexpected"), errorClassOther, ""},
+	}
+
+	convey.Convey("Error classes", t, func() {
+		for _, tt := range tests {
+			class, errno := classifyError(tt.err)
+			convey.So(class, convey.ShouldEqual, tt.class)
+			convey.So(errno, convey.ShouldEqual, tt.errno)
+		}
+	})
+
+	convey.Convey("Errors are counted", t, func() {
+		countScrapeError("collect.test", &MySQL.MySQLError{Number: 1142})
+		convey.So(testutil.ToFloat64(scrapeErrorsTotal.WithLabelValues("collect.test", errorClassPermission, "1142")), convey.ShouldEqual, 1)
+	})
+}
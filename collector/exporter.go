@@ -16,18 +16,22 @@ package collector
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
 	"regexp"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/alecthomas/kingpin/v2"
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
+	MySQL "github.com/go-sql-driver/mysql"
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // Metric name parts.
@@ -38,7 +42,9 @@ const (
 
 // SQL queries and parameters.
 const (
-	versionQuery = `SELECT @@version`
+	versionQuery      = `SELECT @@version`
+	connectionIDQuery = `SELECT CONNECTION_ID()`
+	killQueryQuery    = `KILL QUERY %d`
 
 	// System variable params formatting.
 	// See: https://github.com/go-sql-driver/mysql#system-variables
@@ -60,6 +66,14 @@ var (
 		"exporter.log_slow_filter",
 		"Add a log_slow_filter to avoid slow query logging of scrapes. NOTE: Not supported by Oracle MySQL.",
 	).Default("false").Bool()
+	killQueryOnTimeout = kingpin.Flag(
+		"exporter.kill_query_on_timeout",
+		"Issue KILL QUERY on a separate connection for a query still running when the scrape is cancelled.",
+	).Default("true").Bool()
+	killQueryTimeout = kingpin.Flag(
+		"exporter.kill_query_timeout",
+		"Timeout for connecting and issuing KILL QUERY for a cancelled scrape.",
+	).Default("2s").Duration()
 )
 
 // metric definition
@@ -88,14 +102,29 @@ var _ prometheus.Collector = (*Exporter)(nil)
 
 // Exporter collects MySQL metrics. It implements prometheus.Collector.
 type Exporter struct {
-	ctx      context.Context
-	logger   log.Logger
-	dsn      string
-	scrapers []Scraper
+	ctx       context.Context
+	logger    log.Logger
+	dsn       string
+	scrapers  []Scraper
+	pingQuery string
+	// db or connector replace dsn for exporters returned by NewWithDB and
+	// NewWithConnector.
+	db        *sql.DB
+	connector driver.Connector
+	// target identifies the target in the state kept across scrapes.
+	target string
+	// followPrimary sends the scrapes to the writable primary of
+	// --exporter.primary_candidates, see FollowPrimary.
+	followPrimary bool
 }
 
+// connectorSeq numbers the exporters returned by NewWithConnector.
+var connectorSeq uint64
+
 // New returns a new MySQL exporter for the provided DSN.
 func New(ctx context.Context, dsn string, scrapers []Scraper, logger log.Logger) *Exporter {
+	followPrimary := followPrimaryDSN != "" && dsn == followPrimaryDSN
+
 	// Setup extra params for the DSN, default to having a lock timeout.
 	dsnParams := []string{fmt.Sprintf(timeoutParam, *exporterLockTimeout)}
 
@@ -103,6 +132,10 @@ func New(ctx context.Context, dsn string, scrapers []Scraper, logger log.Logger)
 		dsnParams = append(dsnParams, sessionSettingsParam)
 	}
 
+	if *batchShowStatements {
+		dsnParams = append(dsnParams, multiStatementsParam)
+	}
+
 	if strings.Contains(dsn, "?") {
 		dsn = dsn + "&"
 	} else {
@@ -111,10 +144,44 @@ func New(ctx context.Context, dsn string, scrapers []Scraper, logger log.Logger)
 	dsn += strings.Join(dsnParams, "&")
 
 	return &Exporter{
-		ctx:      ctx,
-		logger:   logger,
-		dsn:      dsn,
-		scrapers: scrapers,
+		ctx:           ctx,
+		logger:        logger,
+		dsn:           dsn,
+		scrapers:      scrapers,
+		pingQuery:     *pingQuery,
+		target:        dsn,
+		followPrimary: followPrimary,
+	}
+}
+
+// NewWithDB returns a new MySQL exporter scraping db, for programs managing
+// their own connections. db is not closed. As the scrapes don't run on a
+// connection of their own, queries still running when a scrape is
+// cancelled are not killed, and the session settings of the exporter, like
+// lock_wait_timeout, are up to db.
+func NewWithDB(ctx context.Context, db *sql.DB, scrapers []Scraper, logger log.Logger) *Exporter {
+	return &Exporter{
+		ctx:       ctx,
+		logger:    logger,
+		scrapers:  scrapers,
+		pingQuery: *pingQuery,
+		db:        db,
+		target:    fmt.Sprintf("db-%p", db),
+	}
+}
+
+// NewWithConnector returns a new MySQL exporter opening the connection of
+// every scrape with connector, e.g. to wrap the connections of the driver
+// with a proxy or interceptor. The session settings of the exporter, like
+// lock_wait_timeout, are up to connector.
+func NewWithConnector(ctx context.Context, connector driver.Connector, scrapers []Scraper, logger log.Logger) *Exporter {
+	return &Exporter{
+		ctx:       ctx,
+		logger:    logger,
+		scrapers:  scrapers,
+		pingQuery: *pingQuery,
+		connector: connector,
+		target:    fmt.Sprintf("connector-%d", atomic.AddUint64(&connectorSeq, 1)),
 	}
 }
 
@@ -123,26 +190,73 @@ func (e *Exporter) Describe(ch chan<- *prometheus.Desc) {
 	ch <- mysqlUp
 	ch <- mysqlScrapeDurationSeconds
 	ch <- mysqlScrapeCollectorSuccess
+	ch <- followedPrimaryDesc
+	ch <- degradedDesc
+	if e.pingQuery != "" {
+		ch <- pingDuration(e.target).Desc()
+	}
 }
 
 // Collect implements prometheus.Collector.
 func (e *Exporter) Collect(ch chan<- prometheus.Metric) {
+	scrapeTime := time.Now()
 	up := e.scrape(e.ctx, ch)
+	if tracingEnabled {
+		observeDuration(e.ctx, scrapeDurationHistogram, time.Since(scrapeTime).Seconds())
+	}
 	ch <- prometheus.MustNewConstMetric(mysqlUp, prometheus.GaugeValue, up)
+	if e.pingQuery != "" {
+		pingDuration(e.target).Collect(ch)
+	}
 }
 
-// scrape collects metrics from the target, returns an up metric value.
-func (e *Exporter) scrape(ctx context.Context, ch chan<- prometheus.Metric) float64 {
-	var err error
-	scrapeTime := time.Now()
-	level.Info(e.logger).Log("dsn", e.dsn)
-	db, err := sql.Open("mysql", e.dsn)
+// open opens and pings the connection to the target, or to the writable
+// primary of --exporter.primary_candidates. It returns the DSN the
+// connection was opened with, empty for NewWithDB and NewWithConnector.
+func (e *Exporter) open(ctx context.Context) (*sql.DB, string, error) {
+	switch {
+	case e.db != nil:
+		if err := e.db.PingContext(ctx); err != nil {
+			level.Error(e.logger).Log("msg", "Error pinging mysqld", "err", err)
+			return nil, "", err
+		}
+		return e.db, "", nil
+	case e.connector != nil:
+		db, err := e.ping(ctx, sql.OpenDB(e.connector))
+		return db, "", err
+	case e.followPrimary && len(followedPrimary.candidates()) > 0:
+		return followedPrimary.open(ctx, e.dsn, e.openDSN, e.logger)
+	}
+	db, err := e.openDSN(ctx, e.dsn)
+	return db, e.dsn, err
+}
+
+// openDSN opens and pings the connection to dsn.
+func (e *Exporter) openDSN(ctx context.Context, dsn string) (*sql.DB, error) {
+	level.Info(e.logger).Log("dsn", redactDSN(dsn))
+	db, err := openTimedDB(driverName(), dsn)
 	if err != nil {
 		level.Error(e.logger).Log("msg", "Error opening connection to database", "err", err)
-		return 0.0
+		return nil, err
 	}
-	defer db.Close()
+	return e.ping(ctx, db)
+}
 
+// redactDSN returns dsn with its password masked, for logging.
+func redactDSN(dsn string) string {
+	cfg, err := MySQL.ParseDSN(dsn)
+	if err != nil {
+		return "<unparsable>"
+	}
+	if cfg.Passwd != "" {
+		cfg.Passwd = "xxxxx"
+	}
+	return cfg.FormatDSN()
+}
+
+// ping sets up db to be used by a single scrape and pings it. db is closed
+// on error.
+func (e *Exporter) ping(ctx context.Context, db *sql.DB) (*sql.DB, error) {
 	// By design exporter should use maximum one connection per request.
 	db.SetMaxOpenConns(1)
 	db.SetMaxIdleConns(1)
@@ -151,47 +265,264 @@ func (e *Exporter) scrape(ctx context.Context, ch chan<- prometheus.Metric) floa
 
 	if err := db.PingContext(ctx); err != nil {
 		level.Error(e.logger).Log("msg", "Error pinging mysqld", "err", err)
-		return 0.0
+		db.Close()
+		return nil, err
 	}
+	return db, nil
+}
 
-	ch <- prometheus.MustNewConstMetric(mysqlScrapeDurationSeconds, prometheus.GaugeValue, time.Since(scrapeTime).Seconds(), "connection")
+// scrape collects metrics from the target, returns an up metric value.
+func (e *Exporter) scrape(ctx context.Context, ch chan<- prometheus.Metric) float64 {
+	run, err := e.begin(ctx)
+	if err != nil {
+		return 0.0
+	}
+	defer run.close()
+	for _, metric := range run.metrics {
+		ch <- metric
+	}
 
-	version := getMySQLVersion(db, e.logger)
 	var wg sync.WaitGroup
 	defer wg.Wait()
-	for _, scraper := range e.scrapers {
-		if version < scraper.Version() {
+	for _, scraper := range run.scrapers {
+		if skipped, ok := run.skip(scraper); !ok {
+			if skipped != nil {
+				ch <- skipped
+			}
 			continue
 		}
-
 		wg.Add(1)
 		go func(scraper Scraper) {
 			defer wg.Done()
-			label := "collect." + scraper.Name()
-			scrapeTime := time.Now()
-			collectorSuccess := 1.0
-			if err := scraper.Scrape(ctx, db, ch, log.With(e.logger, "scraper", scraper.Name())); err != nil {
-				level.Error(e.logger).Log("msg", "Error from scraper", "scraper", scraper.Name(), "err", err)
-				collectorSuccess = 0.0
+			for _, metric := range e.runScraper(run, scraper, ch) {
+				ch <- metric
 			}
-			ch <- prometheus.MustNewConstMetric(mysqlScrapeCollectorSuccess, prometheus.GaugeValue, collectorSuccess, label)
-			ch <- prometheus.MustNewConstMetric(mysqlScrapeDurationSeconds, prometheus.GaugeValue, time.Since(scrapeTime).Seconds(), label)
 		}(scraper)
 	}
 	return 1.0
 }
 
-func getMySQLVersion(db *sql.DB, logger log.Logger) float64 {
-	var versionStr string
-	var versionNum float64
-	if err := db.QueryRow(versionQuery).Scan(&versionStr); err == nil {
-		versionNum, _ = strconv.ParseFloat(versionRE.FindString(versionStr), 64)
+// scrapeRun is the connection and the scrapers of a scrape.
+type scrapeRun struct {
+	e        *Exporter
+	ctx      context.Context
+	db       *sql.DB
+	dsn      string
+	connID   uint64
+	scrapeID uint64
+	version  float64
+	scrapers []Scraper
+	// metrics of the exporter about the connection and the scrape.
+	metrics []prometheus.Metric
+}
+
+// targetKey is the context key of the target of a scrape.
+type targetKey struct{}
+
+// scrapeTarget returns the target of the scrape of ctx, to key the state
+// scrapers keep across scrapes by target.
+func scrapeTarget(ctx context.Context) string {
+	target, _ := ctx.Value(targetKey{}).(string)
+	return target
+}
+
+// begin opens the connection of a scrape and selects the scrapers to run.
+// The scrape must be finished with close.
+func (e *Exporter) begin(ctx context.Context) (*scrapeRun, error) {
+	scrapeTime := time.Now()
+	openCtx, span := startSpan(ctx, "connect")
+	db, dsn, err := e.open(openCtx)
+	endSpan(span, err)
+	if err != nil {
+		countScrapeError("connection", err)
+		return nil, err
+	}
+	run := &scrapeRun{e: e, db: db, dsn: dsn}
+
+	if e.pingQuery != "" {
+		pingStart := time.Now()
+		if err := runPingQuery(ctx, db, e.pingQuery); err != nil {
+			level.Error(e.logger).Log("msg", "Error running ping query", "err", err)
+			countScrapeError("ping", err)
+		} else {
+			pingDuration(e.target).Observe(time.Since(pingStart).Seconds())
+		}
+	}
+
+	// A connection ID of the pool of NewWithDB doesn't identify the
+	// connection of the scrape.
+	if e.db == nil {
+		run.connID = getConnectionID(ctx, db, e.logger)
+	}
+	run.scrapeID = inFlight.start(run.connID, run.dsn)
+
+	run.metrics = append(run.metrics, prometheus.MustNewConstMetric(mysqlScrapeDurationSeconds, prometheus.GaugeValue, time.Since(scrapeTime).Seconds(), "connection"))
+	if address := followedPrimary.current(); e.followPrimary && address != "" {
+		run.metrics = append(run.metrics, prometheus.MustNewConstMetric(followedPrimaryDesc, prometheus.GaugeValue, 1, address))
+	}
+
+	versionStr, version := getServerVersion(ctx, db, e.logger)
+	ctx = withServerVersion(context.WithValue(ctx, targetKey{}, e.target), versionStr, version)
+	scrapers, degraded := filterByLoad(ctx, db, filterByPolicy(ctx, db, e.scrapers, e.logger), e.logger)
+	if *batchShowStatements {
+		ctx = withScrapeSession(ctx, newScrapeSession(ctx, db, scrapers, e.logger))
+	}
+	if *degradeThreshold > 0 {
+		value := 0.0
+		if degraded {
+			value = 1
+		}
+		run.metrics = append(run.metrics, prometheus.MustNewConstMetric(degradedDesc, prometheus.GaugeValue, value))
+	}
+	run.ctx, run.version, run.scrapers = ctx, version, scrapers
+	return run, nil
+}
+
+// close finishes the scrape, killing the query still running on the
+// connection if the scrape was cancelled. A db of NewWithDB is left open.
+func (r *scrapeRun) close() {
+	inFlight.finish(r.scrapeID)
+	r.e.killRunawayQuery(r.ctx, r.dsn, r.connID)
+	if r.e.db == nil {
+		r.db.Close()
+	}
+}
+
+// skip returns whether scraper should run. Scrapers not supported by the
+// server are skipped silently, the ones left once the scrape deadline has
+// passed with a failed collector success metric.
+func (r *scrapeRun) skip(scraper Scraper) (prometheus.Metric, bool) {
+	if r.version < scraper.Version() {
+		return nil, false
+	}
+	// Don't start scrapers once the scrape deadline has passed.
+	if err := r.ctx.Err(); err != nil {
+		label := "collect." + scraper.Name()
+		level.Error(r.e.logger).Log("msg", "Skipping scraper", "scraper", scraper.Name(), "err", err)
+		countScrapeError(label, err)
+		return prometheus.MustNewConstMetric(mysqlScrapeCollectorSuccess, prometheus.GaugeValue, 0.0, label), false
+	}
+	return nil, true
+}
+
+// runScraper runs scraper, or replays its metrics of the last scrape within
+// its minimum interval, and sends its metrics to ch. It returns the collector
+// success and duration metrics of the exporter.
+func (e *Exporter) runScraper(run *scrapeRun, scraper Scraper, ch chan<- prometheus.Metric) []prometheus.Metric {
+	label := "collect." + scraper.Name()
+	inFlight.scraperStarted(run.scrapeID, scraper.Name())
+	defer inFlight.scraperDone(run.scrapeID, scraper.Name())
+	scrapeTime := time.Now()
+	collectorSuccess := 1.0
+	interval := minIntervals[scraper.Name()]
+	if metrics, ok := scraperCache.get(e.target, scraper.Name(), interval, scrapeTime); ok {
+		for _, metric := range metrics {
+			ch <- metric
+		}
+		return []prometheus.Metric{
+			prometheus.MustNewConstMetric(mysqlScrapeCollectorSuccess, prometheus.GaugeValue, collectorSuccess, label),
+			prometheus.MustNewConstMetric(mysqlScrapeDurationSeconds, prometheus.GaugeValue, time.Since(scrapeTime).Seconds(), label),
+		}
+	}
+	scraperCh, record := ch, func() []prometheus.Metric { return nil }
+	if interval > 0 {
+		scraperCh, record = recordMetrics(ch)
+	}
+	done := func() {}
+	if filter := schemaFilters[scraper.Name()]; filter != nil {
+		scraperCh, done = filter.filter(scraperCh)
+	}
+	scraperCtx, span := startSpan(run.ctx, "scraper "+scraper.Name(), attribute.String("scraper", scraper.Name()))
+	err := scraper.Scrape(scraperCtx, run.db, scraperCh, log.With(e.logger, "scraper", scraper.Name()))
+	done()
+	if metrics := record(); err == nil && interval > 0 {
+		scraperCache.set(e.target, scraper.Name(), metrics, scrapeTime)
+	}
+	endSpan(span, err)
+	if tracingEnabled {
+		observeDuration(scraperCtx, collectorDurationHistogram.WithLabelValues(label), time.Since(scrapeTime).Seconds())
+	}
+	if err != nil {
+		level.Error(e.logger).Log("msg", "Error from scraper", "scraper", scraper.Name(), "err", err)
+		countScrapeError(label, err)
+		collectorSuccess = 0.0
+	}
+	return []prometheus.Metric{
+		prometheus.MustNewConstMetric(mysqlScrapeCollectorSuccess, prometheus.GaugeValue, collectorSuccess, label),
+		prometheus.MustNewConstMetric(mysqlScrapeDurationSeconds, prometheus.GaugeValue, time.Since(scrapeTime).Seconds(), label),
+	}
+}
+
+// killRunawayQuery kills the query running on the scrape connection, opened
+// with dsn, when the scrape has been cancelled. Cancelling the context only
+// closes the client side of the connection, some MySQL versions keep
+// running the query.
+func (e *Exporter) killRunawayQuery(ctx context.Context, dsn string, connID uint64) {
+	if !*killQueryOnTimeout || connID == 0 || ctx.Err() == nil {
+		return
+	}
+	var db *sql.DB
+	if e.connector != nil {
+		db = sql.OpenDB(e.connector)
 	} else {
+		var err error
+		if db, err = sql.Open("mysql", dsn); err != nil {
+			level.Error(e.logger).Log("msg", "Error opening control connection to database", "err", err)
+			return
+		}
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	killCtx, cancel := context.WithTimeout(context.Background(), *killQueryTimeout)
+	defer cancel()
+	if err := killQuery(killCtx, db, connID); err != nil {
+		level.Error(e.logger).Log("msg", "Error killing cancelled scrape query", "connection_id", connID, "err", err)
+		return
+	}
+	level.Debug(e.logger).Log("msg", "Killed cancelled scrape query", "connection_id", connID, "reason", ctx.Err())
+}
+
+func killQuery(ctx context.Context, db *sql.DB, connID uint64) error {
+	_, err := db.ExecContext(ctx, fmt.Sprintf(killQueryQuery, connID))
+	return err
+}
+
+// getConnectionID returns the id of the scrape connection, 0 if unknown.
+func getConnectionID(ctx context.Context, db *sql.DB, logger log.Logger) uint64 {
+	var connID uint64
+	if err := db.QueryRowContext(ctx, connectionIDQuery).Scan(&connID); err != nil {
+		level.Debug(logger).Log("msg", "Error querying connection id", "err", err)
+		return 0
+	}
+	return connID
+}
+
+func getMySQLVersion(ctx context.Context, db *sql.DB, logger log.Logger) float64 {
+	_, versionNum := getServerVersion(ctx, db, logger)
+	return versionNum
+}
+
+// getServerVersion returns the version string of the server and its
+// major.minor version number.
+func getServerVersion(ctx context.Context, db *sql.DB, logger log.Logger) (string, float64) {
+	var versionStr string
+	if err := db.QueryRowContext(ctx, versionQuery).Scan(&versionStr); err != nil {
 		level.Debug(logger).Log("msg", "Error querying version", "err", err)
 	}
+	versionNum := parseServerVersion(versionStr)
+	if versionNum == 999 {
+		level.Debug(logger).Log("msg", "Error parsing version string", "version", versionStr)
+	}
+	return versionStr, versionNum
+}
+
+// parseServerVersion returns the major.minor version number of a server
+// version string.
+func parseServerVersion(versionStr string) float64 {
+	versionNum, _ := strconv.ParseFloat(versionRE.FindString(versionStr), 64)
 	// If we can't match/parse the version, set it some big value that matches all versions.
 	if versionNum == 0 {
-		level.Debug(logger).Log("msg", "Error parsing version string", "version", versionStr)
 		versionNum = 999
 	}
 	return versionNum
@@ -19,6 +19,7 @@ import (
 	"os"
 	"testing"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
 	"github.com/prometheus/client_golang/prometheus"
@@ -82,6 +83,75 @@ func TestGetMySQLVersion(t *testing.T) {
 		convey.So(err, convey.ShouldBeNil)
 		defer db.Close()
 
-		convey.So(getMySQLVersion(db, logger), convey.ShouldBeBetweenOrEqual, 5.6, 11.0)
+		convey.So(getMySQLVersion(context.Background(), db, logger), convey.ShouldBeBetweenOrEqual, 5.6, 11.0)
 	})
 }
+
+func TestRedactDSN(t *testing.T) {
+	convey.Convey("Passwords are masked", t, func() {
+		convey.So(redactDSN("exporter:s3cret@tcp(db:3306)/"), convey.ShouldEqual, "exporter:xxxxx@tcp(db:3306)/")
+		convey.So(redactDSN("exporter@tcp(db:3306)/"), convey.ShouldEqual, "exporter@tcp(db:3306)/")
+		convey.So(redactDSN("exporter:s3cret@tcp(db:3306"), convey.ShouldEqual, "<unparsable>")
+	})
+}
+
+func TestKillQuery(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(connectionIDQuery)).WillReturnRows(sqlmock.NewRows([]string{"CONNECTION_ID()"}).AddRow("42"))
+	mock.ExpectExec(sanitizeQuery("KILL QUERY 42")).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	convey.Convey("Kill query by connection id", t, func() {
+		connID := getConnectionID(context.Background(), db, log.NewNopLogger())
+		convey.So(connID, convey.ShouldEqual, 42)
+		convey.So(killQuery(context.Background(), db, connID), convey.ShouldBeNil)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestNewWithDB(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectPing()
+	mock.ExpectQuery(sanitizeQuery(versionQuery)).WillReturnRows(sqlmock.NewRows([]string{"@@version"}).AddRow("8.0.32"))
+	mock.ExpectQuery(sanitizeQuery(uptimeQuery)).WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).AddRow("Uptime", "42"))
+	mock.ExpectPing()
+
+	exporter := NewWithDB(context.Background(), db, []Scraper{ScrapeUptime{}}, log.NewNopLogger())
+
+	convey.Convey("Metrics collection", t, func() {
+		ch := make(chan prometheus.Metric)
+		go func() {
+			exporter.Collect(ch)
+			close(ch)
+		}()
+
+		for m := range ch {
+			got := readMetric(m)
+			if got.labels[model.MetricNameLabel] == "mysql_up" {
+				convey.So(got.value, convey.ShouldEqual, 1)
+			}
+		}
+	})
+
+	convey.Convey("The database stays open", t, func() {
+		convey.So(db.Ping(), convey.ShouldBeNil)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
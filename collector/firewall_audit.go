@@ -0,0 +1,177 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape MySQL Enterprise Firewall and audit log plugin statistics.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// Subsystems.
+	firewall = "firewall"
+	auditLog = "audit_log"
+	// Queries.
+	firewallAuditStatusQuery = `SHOW GLOBAL STATUS WHERE Variable_name LIKE 'Firewall\_%' OR Variable_name LIKE 'Audit\_log\_%'`
+	firewallUsersPluginQuery = `
+	SELECT COUNT(*)
+	  FROM information_schema.plugins
+	  WHERE PLUGIN_NAME = 'MYSQL_FIREWALL_USERS' AND PLUGIN_STATUS = 'ACTIVE'
+	`
+	firewallProfilesQuery = `
+	SELECT MODE, COUNT(*)
+	  FROM information_schema.mysql_firewall_users
+	  GROUP BY MODE
+	`
+)
+
+// firewallAuditStatus maps the Firewall_* status variables of MySQL
+// Enterprise Firewall and the Audit_log_* status variables of the MySQL
+// Enterprise and Percona audit log plugins to metrics.
+var firewallAuditStatus = map[string]struct {
+	vtype prometheus.ValueType
+	desc  *prometheus.Desc
+	label string
+}{
+	"Firewall_access_granted":    {prometheus.CounterValue, firewallStatementsDesc, "granted"},
+	"Firewall_access_denied":     {prometheus.CounterValue, firewallStatementsDesc, "denied"},
+	"Firewall_access_suspicious": {prometheus.CounterValue, firewallStatementsDesc, "suspicious"},
+	"Firewall_cached_entries": {prometheus.GaugeValue,
+		prometheus.NewDesc(prometheus.BuildFQName(namespace, firewall, "cached_entries"),
+			"The number of statements recorded in the firewall cache.", nil, nil), ""},
+	"Audit_log_events": {prometheus.CounterValue,
+		prometheus.NewDesc(prometheus.BuildFQName(namespace, auditLog, "events_total"),
+			"The number of events handled by the audit log plugin.", nil, nil), ""},
+	"Audit_log_events_filtered": {prometheus.CounterValue,
+		prometheus.NewDesc(prometheus.BuildFQName(namespace, auditLog, "events_filtered_total"),
+			"The number of events filtered out by the audit log plugin.", nil, nil), ""},
+	"Audit_log_events_lost": {prometheus.CounterValue,
+		prometheus.NewDesc(prometheus.BuildFQName(namespace, auditLog, "events_lost_total"),
+			"The number of events lost because they were larger than the audit log buffer.", nil, nil), ""},
+	"Audit_log_events_written": {prometheus.CounterValue,
+		prometheus.NewDesc(prometheus.BuildFQName(namespace, auditLog, "events_written_total"),
+			"The number of events written to the audit log.", nil, nil), ""},
+	"Audit_log_write_waits": {prometheus.CounterValue,
+		prometheus.NewDesc(prometheus.BuildFQName(namespace, auditLog, "write_waits_total"),
+			"The number of events which had to wait for space in the audit log buffer.", nil, nil), ""},
+	"Audit_log_buffer_size_overflow": {prometheus.CounterValue,
+		prometheus.NewDesc(prometheus.BuildFQName(namespace, auditLog, "buffer_size_overflows_total"),
+			"The number of events dropped or written directly because they were larger than the audit log buffer (Percona).", nil, nil), ""},
+	"Audit_log_total_size": {prometheus.CounterValue,
+		prometheus.NewDesc(prometheus.BuildFQName(namespace, auditLog, "written_bytes_total"),
+			"The size of the events written to the audit log files.", nil, nil), ""},
+	"Audit_log_current_size": {prometheus.GaugeValue,
+		prometheus.NewDesc(prometheus.BuildFQName(namespace, auditLog, "current_size_bytes"),
+			"The size of the current audit log file.", nil, nil), ""},
+}
+
+// Metric descriptors.
+var (
+	firewallStatementsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, firewall, "statements_total"),
+		"The number of statements checked by the firewall by result.",
+		[]string{"result"}, nil,
+	)
+	firewallProfilesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, firewall, "profiles"),
+		"The number of firewall account profiles by operational mode.",
+		[]string{"mode"}, nil,
+	)
+)
+
+// ScrapeFirewallAudit collects MySQL Enterprise Firewall and audit log
+// plugin statistics.
+type ScrapeFirewallAudit struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeFirewallAudit) Name() string {
+	return "firewall_audit"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeFirewallAudit) Help() string {
+	return "Collect MySQL Enterprise Firewall and audit log plugin statistics"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeFirewallAudit) Version() float64 {
+	return 5.6
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeFirewallAudit) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	statusRows, err := queryRows(ctx, db, firewallAuditStatusQuery)
+	if err != nil {
+		return err
+	}
+	defer statusRows.Close()
+
+	var name, value string
+	for statusRows.Next() {
+		if err := statusRows.Scan(&name, &value); err != nil {
+			return err
+		}
+		metric, ok := firewallAuditStatus[name]
+		if !ok {
+			continue
+		}
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			continue
+		}
+		if metric.label != "" {
+			ch <- prometheus.MustNewConstMetric(metric.desc, metric.vtype, v, metric.label)
+		} else {
+			ch <- prometheus.MustNewConstMetric(metric.desc, metric.vtype, v)
+		}
+	}
+	if err := statusRows.Err(); err != nil {
+		return err
+	}
+
+	// The profiles are only listed with the MYSQL_FIREWALL_USERS plugin.
+	var loaded int
+	if err := db.QueryRowContext(ctx, firewallUsersPluginQuery).Scan(&loaded); err != nil {
+		return err
+	}
+	if loaded == 0 {
+		return nil
+	}
+	profileRows, err := db.QueryContext(ctx, firewallProfilesQuery)
+	if err != nil {
+		return err
+	}
+	defer profileRows.Close()
+
+	var (
+		mode  string
+		count float64
+	)
+	for profileRows.Next() {
+		if err := profileRows.Scan(&mode, &count); err != nil {
+			return err
+		}
+		ch <- prometheus.MustNewConstMetric(firewallProfilesDesc, prometheus.GaugeValue, count, mode)
+	}
+	return profileRows.Err()
+}
+
+// check interface
+var _ Scraper = ScrapeFirewallAudit{}
@@ -0,0 +1,84 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeFirewallAudit(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"Variable_name", "Value"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("Audit_log_current_size", "4096").
+		AddRow("Audit_log_events", "120").
+		AddRow("Audit_log_events_lost", "2").
+		AddRow("Audit_log_write_waits", "1").
+		AddRow("Firewall_access_denied", "7").
+		AddRow("Firewall_access_granted", "900").
+		AddRow("Firewall_access_suspicious", "3").
+		AddRow("Firewall_cached_entries", "42")
+	mock.ExpectQuery(regexp.QuoteMeta(firewallAuditStatusQuery)).WillReturnRows(rows)
+	mock.ExpectQuery(sanitizeQuery(firewallUsersPluginQuery)).WillReturnRows(
+		sqlmock.NewRows([]string{"COUNT(*)"}).AddRow(1))
+	mock.ExpectQuery(sanitizeQuery(firewallProfilesQuery)).WillReturnRows(
+		sqlmock.NewRows([]string{"MODE", "COUNT(*)"}).
+			AddRow("PROTECTING", 5).
+			AddRow("RECORDING", 1))
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeFirewallAudit{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{}, value: 4096, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 120, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{}, value: 2, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{}, value: 1, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"result": "denied"}, value: 7, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"result": "granted"}, value: 900, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"result": "suspicious"}, value: 3, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{}, value: 42, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"mode": "PROTECTING"}, value: 5, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"mode": "RECORDING"}, value: 1, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
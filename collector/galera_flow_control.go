@@ -0,0 +1,183 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape Galera flow control and replication queues, derived from the wsrep
+// status variables.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// Subsystem.
+	galera = "galera"
+	// Query.
+	galeraFlowControlQuery = `
+	SHOW GLOBAL STATUS
+	  WHERE Variable_name IN (
+	    'wsrep_flow_control_paused_ns', 'wsrep_flow_control_sent', 'wsrep_flow_control_recv',
+	    'wsrep_cert_deps_distance', 'wsrep_local_send_queue_avg', 'wsrep_local_recv_queue_avg'
+	  )
+	`
+)
+
+// Metric descriptors.
+var (
+	galeraFlowControlPausedRatioDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, galera, "flow_control_paused_ratio"),
+		"The fraction of time replication was paused by flow control since the previous scrape.",
+		nil, nil,
+	)
+	galeraFlowControlPausedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, galera, "flow_control_paused_seconds_total"),
+		"The time replication was paused by flow control.",
+		nil, nil,
+	)
+	galeraFlowControlMessagesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, galera, "flow_control_messages_total"),
+		"The number of flow control pause messages sent by this node and received from the cluster.",
+		[]string{"direction"}, nil,
+	)
+	galeraCertDepsDistanceDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, galera, "cert_deps_distance"),
+		"The average distance between the lowest and highest sequence numbers the node can apply in parallel.",
+		nil, nil,
+	)
+	galeraQueueAvgDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, galera, "queue_avg"),
+		"The average length of the replication queue since the previous query of the wsrep status variables.",
+		[]string{"queue"}, nil,
+	)
+)
+
+// galeraFlowControlPrevious holds the previous sample of
+// wsrep_flow_control_paused_ns, by target.
+var galeraFlowControlPrevious galeraPausedSamples
+
+// galeraPausedSamples holds a sample of wsrep_flow_control_paused_ns per
+// target.
+type galeraPausedSamples struct {
+	mu      sync.Mutex
+	samples map[string]*galeraPausedSample
+}
+
+// get returns the sample of target.
+func (s *galeraPausedSamples) get(target string) *galeraPausedSample {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.samples == nil {
+		s.samples = map[string]*galeraPausedSample{}
+	}
+	sample, ok := s.samples[target]
+	if !ok {
+		sample = &galeraPausedSample{}
+		s.samples[target] = sample
+	}
+	return sample
+}
+
+type galeraPausedSample struct {
+	mu       sync.Mutex
+	time     time.Time
+	pausedNs float64
+}
+
+// pausedRatio stores the sample taken at ts and returns the fraction of the
+// time since the previous sample replication was paused. It returns false
+// on the first sample and after the counter went backwards, e.g. after a
+// restart.
+func (s *galeraPausedSample) pausedRatio(ts time.Time, pausedNs float64) (float64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	elapsed := ts.Sub(s.time)
+	prev, first := s.pausedNs, s.time.IsZero()
+	s.time, s.pausedNs = ts, pausedNs
+	if first || elapsed <= 0 || pausedNs < prev {
+		return 0, false
+	}
+	return (pausedNs - prev) / float64(elapsed.Nanoseconds()), true
+}
+
+// ScrapeGaleraFlowControl collects Galera flow control and replication
+// queues.
+type ScrapeGaleraFlowControl struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeGaleraFlowControl) Name() string {
+	return "galera.flow_control"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeGaleraFlowControl) Help() string {
+	return "Collect Galera flow control pause fraction, certification dependency distance and replication queues from the wsrep status variables"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeGaleraFlowControl) Version() float64 {
+	return 5.1
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeGaleraFlowControl) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	rows, err := db.QueryContext(ctx, galeraFlowControlQuery)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var (
+		name, value string
+		values      = map[string]float64{}
+	)
+	for rows.Next() {
+		if err := rows.Scan(&name, &value); err != nil {
+			return err
+		}
+		if v, err := strconv.ParseFloat(value, 64); err == nil {
+			values[name] = v
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	// Not a Galera node.
+	pausedNs, ok := values["wsrep_flow_control_paused_ns"]
+	if !ok {
+		return nil
+	}
+	ch <- prometheus.MustNewConstMetric(galeraFlowControlPausedDesc, prometheus.CounterValue, pausedNs/1e9)
+	if !catalogRun(ctx) {
+		if ratio, ok := galeraFlowControlPrevious.get(scrapeTarget(ctx)).pausedRatio(time.Now(), pausedNs); ok {
+			ch <- prometheus.MustNewConstMetric(galeraFlowControlPausedRatioDesc, prometheus.GaugeValue, ratio)
+		}
+	}
+	ch <- prometheus.MustNewConstMetric(galeraFlowControlMessagesDesc, prometheus.CounterValue, values["wsrep_flow_control_sent"], "sent")
+	ch <- prometheus.MustNewConstMetric(galeraFlowControlMessagesDesc, prometheus.CounterValue, values["wsrep_flow_control_recv"], "received")
+	ch <- prometheus.MustNewConstMetric(galeraCertDepsDistanceDesc, prometheus.GaugeValue, values["wsrep_cert_deps_distance"])
+	ch <- prometheus.MustNewConstMetric(galeraQueueAvgDesc, prometheus.GaugeValue, values["wsrep_local_send_queue_avg"], "send")
+	ch <- prometheus.MustNewConstMetric(galeraQueueAvgDesc, prometheus.GaugeValue, values["wsrep_local_recv_queue_avg"], "receive")
+	return nil
+}
+
+// check interface
+var _ Scraper = ScrapeGaleraFlowControl{}
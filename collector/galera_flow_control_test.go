@@ -0,0 +1,100 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeGaleraFlowControl(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	// The previous scrape of the target ran a minute ago, another target
+	// was scraped since.
+	ctx := context.WithValue(context.Background(), targetKey{}, "db1")
+	galeraFlowControlPrevious = galeraPausedSamples{samples: map[string]*galeraPausedSample{
+		"db1": {time: time.Now().Add(-time.Minute), pausedNs: 4e9},
+		"db2": {time: time.Now().Add(-time.Second), pausedNs: 9e9},
+	}}
+	defer func() { galeraFlowControlPrevious = galeraPausedSamples{} }()
+
+	mock.ExpectQuery(sanitizeQuery(galeraFlowControlQuery)).WillReturnRows(
+		sqlmock.NewRows([]string{"Variable_name", "Value"}).
+			AddRow("wsrep_cert_deps_distance", "23.5").
+			AddRow("wsrep_flow_control_paused_ns", "10000000000").
+			AddRow("wsrep_flow_control_recv", "40").
+			AddRow("wsrep_flow_control_sent", "12").
+			AddRow("wsrep_local_recv_queue_avg", "3.2").
+			AddRow("wsrep_local_send_queue_avg", "0.5"))
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeGaleraFlowControl{}).Scrape(ctx, db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	convey.Convey("Metrics comparison", t, func() {
+		convey.So(readMetric(<-ch), convey.ShouldResemble, MetricResult{labels: labelMap{}, value: 10, metricType: dto.MetricType_COUNTER})
+		// 6s paused in about a minute.
+		ratio := readMetric(<-ch)
+		convey.So(ratio.value, convey.ShouldAlmostEqual, 0.1, 0.001)
+		expected := []MetricResult{
+			{labels: labelMap{"direction": "sent"}, value: 12, metricType: dto.MetricType_COUNTER},
+			{labels: labelMap{"direction": "received"}, value: 40, metricType: dto.MetricType_COUNTER},
+			{labels: labelMap{}, value: 23.5, metricType: dto.MetricType_GAUGE},
+			{labels: labelMap{"queue": "send"}, value: 0.5, metricType: dto.MetricType_GAUGE},
+			{labels: labelMap{"queue": "receive"}, value: 3.2, metricType: dto.MetricType_GAUGE},
+		}
+		for _, expect := range expected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestGaleraPausedRatio(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	var s galeraPausedSample
+
+	convey.Convey("The paused fraction needs two samples", t, func() {
+		_, ok := s.pausedRatio(start, 1e9)
+		convey.So(ok, convey.ShouldBeFalse)
+		ratio, ok := s.pausedRatio(start.Add(10*time.Second), 6e9)
+		convey.So(ok, convey.ShouldBeTrue)
+		convey.So(ratio, convey.ShouldEqual, 0.5)
+	})
+	convey.Convey("A restart resets the paused fraction", t, func() {
+		_, ok := s.pausedRatio(start.Add(20*time.Second), 0)
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+}
@@ -19,9 +19,13 @@ import (
 	"context"
 	"database/sql"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/alecthomas/kingpin/v2"
 	"github.com/go-kit/log"
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -33,9 +37,101 @@ const (
 	globalStatus = "global_status"
 )
 
+// Tunable flags.
+var (
+	globalStatusRates = kingpin.Flag(
+		"collect.global_status.rates",
+		"Expose per-second rates of counters between consecutive scrapes, for consumers that cannot compute rates",
+	).Default("false").Bool()
+	globalStatusRateCounters = kingpin.Flag(
+		"collect.global_status.rates.counters",
+		"Comma separated list of status variables to expose rates for, % matches any characters",
+	).Default("Questions,Com_%,Innodb_rows_%").String()
+	globalStatusCommandsMode = kingpin.Flag(
+		"collect.global_status.commands_mode",
+		"How to expose Com_* counters: detailed for one series per command, grouped for reads/writes/ddl/admin/other buckets",
+	).Default(commandsModeDetailed).Enum(commandsModeDetailed, commandsModeGrouped)
+	globalStatusNamingVersion = kingpin.Flag(
+		"collect.global_status.naming_version",
+		"Naming of generic status variables: 1 for untyped metrics, 2 for counters with a _total suffix for the monotonically increasing ones",
+	).Default(globalStatusNamingV1).Enum(globalStatusNamingV1, globalStatusNamingV2)
+	globalStatusCommandsAllowlist = kingpin.Flag(
+		"collect.global_status.commands_allowlist",
+		"Comma separated list of commands still exposed individually with --collect.global_status.commands_mode=grouped",
+	).Default("").String()
+)
+
+// Naming versions of generic status variables.
+const (
+	globalStatusNamingV1 = "1"
+	globalStatusNamingV2 = "2"
+)
+
+// Modes of exposing Com_* counters.
+const (
+	commandsModeDetailed = "detailed"
+	commandsModeGrouped  = "grouped"
+)
+
+// commandGroups are the buckets of Com_* counters in grouped mode.
+var commandGroups = []string{"reads", "writes", "ddl", "admin", "other"}
+
+// commandGroup returns the bucket of a Com_* counter, without the com_ prefix.
+func commandGroup(command string) string {
+	switch command {
+	case "select":
+		return "reads"
+	case "insert", "insert_select", "update", "update_multi", "delete", "delete_multi", "replace", "replace_select", "load":
+		return "writes"
+	case "create_user", "drop_user", "alter_user", "rename_user", "create_role", "drop_role",
+		"grant", "grant_roles", "revoke", "revoke_all", "revoke_roles", "kill", "shutdown",
+		"analyze", "check", "checksum", "optimize", "repair",
+		"install_plugin", "uninstall_plugin", "install_component", "uninstall_component":
+		return "admin"
+	}
+	for _, prefix := range []string{"flush", "reset", "purge", "change_", "start_", "stop_", "slave_", "replica_"} {
+		if strings.HasPrefix(command, prefix) {
+			return "admin"
+		}
+	}
+	for _, prefix := range []string{"create_", "alter_", "drop_", "rename_", "truncate"} {
+		if strings.HasPrefix(command, prefix) {
+			return "ddl"
+		}
+	}
+	return "other"
+}
+
+// bufferPoolProgressRE matches the progress of a buffer pool dump or load,
+// e.g. "Dumping buffer pool 1/2, page 512/1024" or "Loaded 512/1024 pages".
+var bufferPoolProgressRE = regexp.MustCompile(`(\d+)/(\d+)`)
+
+// Buffer pool dump and load states.
+const (
+	bufferPoolStateNotStarted = "not_started"
+	bufferPoolStateInProgress = "in_progress"
+	bufferPoolStateCompleted  = "completed"
+	bufferPoolStateAborted    = "aborted"
+	bufferPoolStateFailed     = "failed"
+)
+
 // Regexp to match various groups of status vars.
 var globalStatusRE = regexp.MustCompile(`^(com|handler|connection_errors|innodb_buffer_pool_pages|innodb_rows|performance_schema)_(.*)$`)
 
+// globalStatusCounterRE matches the generic status variables which only
+// increase until mysqld restarts or FLUSH STATUS.
+var globalStatusCounterRE = regexp.MustCompile(`^(` +
+	`aborted_(clients|connects)|bytes_(received|sent)|connections|queries|questions|` +
+	`binlog_(stmt_)?cache_(disk_)?use|created_tmp_(disk_tables|files|tables)|flush_commands|` +
+	`innodb_buffer_pool_(read_ahead|read_ahead_evicted|read_ahead_rnd|read_requests|reads|wait_free|write_requests)|` +
+	`innodb_data_(fsyncs|read|reads|writes|written)|innodb_dblwr_(pages_written|writes)|` +
+	`innodb_log_(waits|write_requests|writes)|innodb_os_log_(fsyncs|written)|innodb_pages_(created|read|written)|` +
+	`innodb_row_lock_(time|waits)|key_(read_requests|reads|write_requests|writes)|` +
+	`opened_(files|table_definitions|tables)|select_(full_join|full_range_join|range|range_check|scan)|` +
+	`slow_launch_threads|slow_queries|sort_(merge_passes|range|rows|scan)|` +
+	`table_locks_(immediate|waited)|table_open_cache_(hits|misses|overflows)|threads_created` +
+	`)$`)
+
 // Metric descriptors.
 var (
 	globalCommandsDesc = prometheus.NewDesc(
@@ -43,6 +139,11 @@ var (
 		"Total number of executed MySQL commands.",
 		[]string{"command"}, nil,
 	)
+	globalCommandGroupsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, globalStatus, "command_groups_total"),
+		"Total number of executed MySQL commands by group.",
+		[]string{"group"}, nil,
+	)
 	globalHandlerDesc = prometheus.NewDesc(
 		prometheus.BuildFQName(namespace, globalStatus, "handlers_total"),
 		"Total number of executed MySQL handlers.",
@@ -78,8 +179,137 @@ var (
 		"Total number of MySQL instrumentations that could not be loaded or created due to memory constraints.",
 		[]string{"instrumentation"}, nil,
 	)
+	globalBufferPoolDumpStateDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, globalStatus, "buffer_pool_dump_state"),
+		"Innodb buffer pool dump state parsed from Innodb_buffer_pool_dump_status.",
+		[]string{"state"}, nil,
+	)
+	globalBufferPoolDumpProgressDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, globalStatus, "buffer_pool_dump_progress_percent"),
+		"Innodb buffer pool dump percentage complete parsed from Innodb_buffer_pool_dump_status.",
+		[]string{}, nil,
+	)
+	globalBufferPoolLoadStateDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, globalStatus, "buffer_pool_load_state"),
+		"Innodb buffer pool load state parsed from Innodb_buffer_pool_load_status.",
+		[]string{"state"}, nil,
+	)
+	globalBufferPoolLoadProgressDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, globalStatus, "buffer_pool_load_progress_percent"),
+		"Innodb buffer pool load percentage complete parsed from Innodb_buffer_pool_load_status.",
+		[]string{}, nil,
+	)
+	globalStatusRateDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, globalStatus, "rate_per_second"),
+		"Per-second rate of a status variable between the two latest scrapes.",
+		[]string{"variable"}, nil,
+	)
 )
 
+// globalStatusPrevious holds the previous sample of the counters rates are
+// exposed for, by target.
+var globalStatusPrevious globalStatusSamples
+
+// globalStatusSamples holds a sample of status counters per target.
+type globalStatusSamples struct {
+	mu      sync.Mutex
+	samples map[string]*globalStatusSample
+}
+
+// get returns the sample of target.
+func (s *globalStatusSamples) get(target string) *globalStatusSample {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.samples == nil {
+		s.samples = map[string]*globalStatusSample{}
+	}
+	sample, ok := s.samples[target]
+	if !ok {
+		sample = &globalStatusSample{}
+		s.samples[target] = sample
+	}
+	return sample
+}
+
+// globalStatusSample holds a sample of status counters.
+type globalStatusSample struct {
+	mu     sync.Mutex
+	time   time.Time
+	values map[string]float64
+}
+
+// rates stores the sample taken at ts and returns the per-second rates
+// since the previous sample. Counters which went backwards, e.g. after a
+// restart or FLUSH STATUS, are skipped.
+func (s *globalStatusSample) rates(ts time.Time, values map[string]float64) map[string]float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rates := map[string]float64{}
+	if elapsed := ts.Sub(s.time).Seconds(); s.values != nil && elapsed > 0 {
+		for name, value := range values {
+			if prev, ok := s.values[name]; ok && value >= prev {
+				rates[name] = (value - prev) / elapsed
+			}
+		}
+	}
+	s.time = ts
+	s.values = values
+	return rates
+}
+
+// globalStatusRateRE builds a case insensitive regexp from a comma separated
+// list of status variable patterns.
+func globalStatusRateRE(patterns string) *regexp.Regexp {
+	var alternatives []string
+	for _, pattern := range strings.Split(patterns, ",") {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		alternatives = append(alternatives, strings.ReplaceAll(regexp.QuoteMeta(pattern), "%", ".*"))
+	}
+	return regexp.MustCompile(`(?i)^(` + strings.Join(alternatives, "|") + `)$`)
+}
+
+// parseBufferPoolStatus parses Innodb_buffer_pool_dump_status or
+// Innodb_buffer_pool_load_status into a state and percentage complete.
+// A dump goes through the buffer pool instances in turn, so its progress is
+// the share of instances done plus the page progress of the current one.
+func parseBufferPoolStatus(status string) (string, float64, bool) {
+	lower := strings.ToLower(status)
+	switch {
+	case strings.Contains(lower, "not started"):
+		return bufferPoolStateNotStarted, 0, true
+	case strings.Contains(lower, "completed"):
+		return bufferPoolStateCompleted, 100, true
+	case strings.Contains(lower, "aborted"):
+		return bufferPoolStateAborted, 0, true
+	case strings.Contains(lower, "error"), strings.Contains(lower, "cannot"), strings.Contains(lower, "failed"):
+		return bufferPoolStateFailed, 0, true
+	}
+	matches := bufferPoolProgressRE.FindAllStringSubmatch(status, -1)
+	if len(matches) == 0 {
+		return "", 0, false
+	}
+	ratio := func(match []string) float64 {
+		done, _ := strconv.ParseFloat(match[1], 64)
+		total, _ := strconv.ParseFloat(match[2], 64)
+		if total == 0 {
+			return 0
+		}
+		return done / total
+	}
+	progress := ratio(matches[len(matches)-1])
+	if len(matches) > 1 {
+		instance, _ := strconv.ParseFloat(matches[0][1], 64)
+		instances, _ := strconv.ParseFloat(matches[0][2], 64)
+		if instances > 0 && instance > 0 {
+			progress = (instance - 1 + progress) / instances
+		}
+	}
+	return bufferPoolStateInProgress, progress * 100, true
+}
+
 // ScrapeGlobalStatus collects from `SHOW GLOBAL STATUS`.
 type ScrapeGlobalStatus struct{}
 
@@ -100,7 +330,7 @@ func (ScrapeGlobalStatus) Version() float64 {
 
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
 func (ScrapeGlobalStatus) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
-	globalStatusRows, err := db.QueryContext(ctx, globalStatusQuery)
+	globalStatusRows, err := queryRows(ctx, db, globalStatusQuery)
 	if err != nil {
 		return err
 	}
@@ -108,11 +338,27 @@ func (ScrapeGlobalStatus) Scrape(ctx context.Context, db *sql.DB, ch chan<- prom
 
 	var key string
 	var val sql.RawBytes
+	var rateRE *regexp.Regexp
+	rateValues := map[string]float64{}
+	if *globalStatusRates {
+		rateRE = globalStatusRateRE(*globalStatusRateCounters)
+	}
+	grouped := *globalStatusCommandsMode == commandsModeGrouped
+	commandGroupTotals := map[string]float64{}
+	commandsAllowlist := map[string]bool{}
+	for _, command := range strings.Split(*globalStatusCommandsAllowlist, ",") {
+		if command = strings.TrimSpace(command); command != "" {
+			commandsAllowlist[validPrometheusName(command)] = true
+		}
+	}
 	var textItems = map[string]string{
 		"wsrep_local_state_uuid":   "",
 		"wsrep_cluster_state_uuid": "",
 		"wsrep_provider_version":   "",
 		"wsrep_evs_repl_latency":   "",
+
+		"Innodb_buffer_pool_dump_status": "",
+		"Innodb_buffer_pool_load_status": "",
 	}
 
 	for globalStatusRows.Next() {
@@ -120,9 +366,20 @@ func (ScrapeGlobalStatus) Scrape(ctx context.Context, db *sql.DB, ch chan<- prom
 			return err
 		}
 		if floatVal, ok := parseStatus(val); ok { // Unparsable values are silently skipped.
+			if rateRE != nil && rateRE.MatchString(key) {
+				rateValues[key] = floatVal
+			}
 			key = validPrometheusName(key)
 			match := globalStatusRE.FindStringSubmatch(key)
 			if match == nil {
+				if *globalStatusNamingVersion == globalStatusNamingV2 && globalStatusCounterRE.MatchString(key) {
+					ch <- prometheus.MustNewConstMetric(
+						newDesc(globalStatus, key+"_total", "Generic counter from SHOW GLOBAL STATUS."),
+						prometheus.CounterValue,
+						floatVal,
+					)
+					continue
+				}
 				ch <- prometheus.MustNewConstMetric(
 					newDesc(globalStatus, key, "Generic metric from SHOW GLOBAL STATUS."),
 					prometheus.UntypedValue,
@@ -132,6 +389,12 @@ func (ScrapeGlobalStatus) Scrape(ctx context.Context, db *sql.DB, ch chan<- prom
 			}
 			switch match[1] {
 			case "com":
+				if grouped {
+					commandGroupTotals[commandGroup(match[2])] += floatVal
+					if !commandsAllowlist[match[2]] {
+						continue
+					}
+				}
 				ch <- prometheus.MustNewConstMetric(
 					globalCommandsDesc, prometheus.CounterValue, floatVal, match[2],
 				)
@@ -174,11 +437,45 @@ func (ScrapeGlobalStatus) Scrape(ctx context.Context, db *sql.DB, ch chan<- prom
 		}
 	}
 
+	if grouped {
+		for _, group := range commandGroups {
+			ch <- prometheus.MustNewConstMetric(
+				globalCommandGroupsDesc, prometheus.CounterValue, commandGroupTotals[group], group,
+			)
+		}
+	}
+
+	if rateRE != nil && !catalogRun(ctx) {
+		rates := globalStatusPrevious.get(scrapeTarget(ctx)).rates(time.Now(), rateValues)
+		names := make([]string, 0, len(rates))
+		for name := range rates {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			ch <- prometheus.MustNewConstMetric(globalStatusRateDesc, prometheus.GaugeValue, rates[name], name)
+		}
+	}
+
+	bufferPoolStatuses := []struct {
+		status                  string
+		stateDesc, progressDesc *prometheus.Desc
+	}{
+		{textItems["Innodb_buffer_pool_dump_status"], globalBufferPoolDumpStateDesc, globalBufferPoolDumpProgressDesc},
+		{textItems["Innodb_buffer_pool_load_status"], globalBufferPoolLoadStateDesc, globalBufferPoolLoadProgressDesc},
+	}
+	for _, bp := range bufferPoolStatuses {
+		if state, progress, ok := parseBufferPoolStatus(bp.status); ok {
+			ch <- prometheus.MustNewConstMetric(bp.stateDesc, prometheus.GaugeValue, 1, state)
+			ch <- prometheus.MustNewConstMetric(bp.progressDesc, prometheus.GaugeValue, progress)
+		}
+	}
+
 	// mysql_galera_variables_info metric.
 	if textItems["wsrep_local_state_uuid"] != "" {
 		ch <- prometheus.MustNewConstMetric(
-			prometheus.NewDesc(prometheus.BuildFQName(namespace, "galera", "status_info"), "PXC/Galera status information.",
-				[]string{"wsrep_local_state_uuid", "wsrep_cluster_state_uuid", "wsrep_provider_version"}, nil),
+			cachedDesc(prometheus.BuildFQName(namespace, "galera", "status_info"), "PXC/Galera status information.",
+				[]string{"wsrep_local_state_uuid", "wsrep_cluster_state_uuid", "wsrep_provider_version"}),
 			prometheus.GaugeValue, 1, textItems["wsrep_local_state_uuid"], textItems["wsrep_cluster_state_uuid"], textItems["wsrep_provider_version"],
 		)
 	}
@@ -215,7 +512,7 @@ func (ScrapeGlobalStatus) Scrape(ctx context.Context, db *sql.DB, ch chan<- prom
 			if evsParsingSuccess {
 				for _, v := range evsMap {
 					key := prometheus.BuildFQName(namespace, "galera_evs_repl_latency", v.name)
-					desc := prometheus.NewDesc(key, v.help, []string{}, nil)
+					desc := cachedDesc(key, v.help, nil)
 					ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, v.value)
 				}
 			}
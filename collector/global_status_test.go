@@ -16,6 +16,7 @@ package collector
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/go-kit/log"
@@ -48,6 +49,8 @@ func TestScrapeGlobalStatus(t *testing.T) {
 		AddRow("Innodb_buffer_pool_pages_lru_flushed", "13").
 		AddRow("Innodb_buffer_pool_pages_made_not_young", "14").
 		AddRow("Innodb_buffer_pool_pages_made_young", "15").
+		AddRow("Innodb_buffer_pool_dump_status", "Dumping of buffer pool not started").
+		AddRow("Innodb_buffer_pool_load_status", "Loaded 512/2048 pages").
 		AddRow("Innodb_rows_read", "8").
 		AddRow("Performance_schema_users_lost", "9").
 		AddRow("Slave_running", "OFF").
@@ -91,6 +94,10 @@ func TestScrapeGlobalStatus(t *testing.T) {
 		{labels: labelMap{}, value: 10, metricType: dto.MetricType_UNTYPED},
 		{labels: labelMap{}, value: 11, metricType: dto.MetricType_UNTYPED},
 		{labels: labelMap{}, value: 1, metricType: dto.MetricType_UNTYPED},
+		{labels: labelMap{"state": "not_started"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"state": "in_progress"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 25, metricType: dto.MetricType_GAUGE},
 		{labels: labelMap{"wsrep_local_state_uuid": "6c06e583-686f-11e6-b9e3-8336ad58138c", "wsrep_cluster_state_uuid": "6c06e583-686f-11e6-b9e3-8336ad58138c", "wsrep_provider_version": "3.16(r5c765eb)"}, value: 1, metricType: dto.MetricType_GAUGE},
 		{labels: labelMap{}, value: 0.000227664, metricType: dto.MetricType_GAUGE},
 		{labels: labelMap{}, value: 0.00034135, metricType: dto.MetricType_GAUGE},
@@ -110,3 +117,173 @@ func TestScrapeGlobalStatus(t *testing.T) {
 		t.Errorf("there were unfulfilled exceptions: %s", err)
 	}
 }
+
+func TestParseBufferPoolStatus(t *testing.T) {
+	testcases := []struct {
+		status   string
+		state    string
+		progress float64
+		ok       bool
+	}{
+		{"Dumping of buffer pool not started", "not_started", 0, true},
+		{"Loading of buffer pool not started", "not_started", 0, true},
+		{"Dumping buffer pool 2/4, page 256/1024", "in_progress", 31.25, true},
+		{"Loaded 512/2048 pages", "in_progress", 25, true},
+		{"Buffer pool(s) dump completed at 230510 12:34:56", "completed", 100, true},
+		{"Buffer pool(s) load completed at 230510 12:34:56 (2048/2048 pages)", "completed", 100, true},
+		{"Buffer pool(s) load aborted on request", "aborted", 0, true},
+		{"Error parsing '/var/lib/mysql/ib_buffer_pool', unable to read", "failed", 0, true},
+		{"", "", 0, false},
+	}
+
+	convey.Convey("Buffer pool dump and load status", t, func() {
+		for _, tc := range testcases {
+			state, progress, ok := parseBufferPoolStatus(tc.status)
+			convey.So(state, convey.ShouldEqual, tc.state)
+			convey.So(progress, convey.ShouldEqual, tc.progress)
+			convey.So(ok, convey.ShouldEqual, tc.ok)
+		}
+	})
+}
+
+func TestGlobalStatusSampleRates(t *testing.T) {
+	var sample globalStatusSample
+	start := time.Unix(1000, 0)
+
+	convey.Convey("Rates between samples", t, func() {
+		convey.So(sample.rates(start, map[string]float64{"Questions": 100, "Com_select": 50}), convey.ShouldBeEmpty)
+		convey.So(sample.rates(start.Add(10*time.Second), map[string]float64{"Questions": 200, "Com_select": 40}), convey.ShouldResemble,
+			map[string]float64{"Questions": 10})
+		convey.So(sample.rates(start.Add(10*time.Second), map[string]float64{"Questions": 300}), convey.ShouldBeEmpty)
+	})
+
+	convey.Convey("Samples are kept per target", t, func() {
+		var samples globalStatusSamples
+		samples.get("db1").rates(start, map[string]float64{"Questions": 100})
+		samples.get("db2").rates(start.Add(5*time.Second), map[string]float64{"Questions": 5000})
+		convey.So(samples.get("db1").rates(start.Add(10*time.Second), map[string]float64{"Questions": 200}), convey.ShouldResemble,
+			map[string]float64{"Questions": 10})
+	})
+}
+
+func TestGlobalStatusRateRE(t *testing.T) {
+	re := globalStatusRateRE("Questions, Com_%,Innodb_rows_%")
+
+	convey.Convey("Rate counter patterns", t, func() {
+		convey.So(re.MatchString("Questions"), convey.ShouldBeTrue)
+		convey.So(re.MatchString("Com_select"), convey.ShouldBeTrue)
+		convey.So(re.MatchString("innodb_rows_read"), convey.ShouldBeTrue)
+		convey.So(re.MatchString("Queries"), convey.ShouldBeFalse)
+		convey.So(re.MatchString("Compression"), convey.ShouldBeFalse)
+	})
+}
+
+func TestScrapeGlobalStatusGroupedCommands(t *testing.T) {
+	defaultMode, defaultAllowlist := *globalStatusCommandsMode, *globalStatusCommandsAllowlist
+	*globalStatusCommandsMode, *globalStatusCommandsAllowlist = commandsModeGrouped, "select"
+	defer func() {
+		*globalStatusCommandsMode, *globalStatusCommandsAllowlist = defaultMode, defaultAllowlist
+	}()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"Variable_name", "Value"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("Com_alter_table", "1").
+		AddRow("Com_create_user", "2").
+		AddRow("Com_delete_multi", "3").
+		AddRow("Com_insert", "4").
+		AddRow("Com_flush", "5").
+		AddRow("Com_select", "6").
+		AddRow("Com_show_status", "7")
+	mock.ExpectQuery(sanitizeQuery(globalStatusQuery)).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeGlobalStatus{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	counterExpected := []MetricResult{
+		{labels: labelMap{"command": "select"}, value: 6, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"group": "reads"}, value: 6, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"group": "writes"}, value: 7, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"group": "ddl"}, value: 1, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"group": "admin"}, value: 7, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"group": "other"}, value: 7, metricType: dto.MetricType_COUNTER},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range counterExpected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeGlobalStatusNamingV2(t *testing.T) {
+	defaultVersion := *globalStatusNamingVersion
+	*globalStatusNamingVersion = globalStatusNamingV2
+	defer func() { *globalStatusNamingVersion = defaultVersion }()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"Variable_name", "Value"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("Bytes_received", "100").
+		AddRow("Innodb_buffer_pool_read_requests", "200").
+		AddRow("Questions", "300").
+		AddRow("Threads_running", "4").
+		AddRow("Uptime", "10")
+	mock.ExpectQuery(sanitizeQuery(globalStatusQuery)).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeGlobalStatus{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	expected := []struct {
+		name       string
+		value      float64
+		metricType dto.MetricType
+	}{
+		{"mysql_global_status_bytes_received_total", 100, dto.MetricType_COUNTER},
+		{"mysql_global_status_innodb_buffer_pool_read_requests_total", 200, dto.MetricType_COUNTER},
+		{"mysql_global_status_questions_total", 300, dto.MetricType_COUNTER},
+		{"mysql_global_status_threads_running", 4, dto.MetricType_UNTYPED},
+		{"mysql_global_status_uptime", 10, dto.MetricType_UNTYPED},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			m := <-ch
+			convey.So(m.Desc().String(), convey.ShouldContainSubstring, `fqName: "`+expect.name+`"`)
+			got := readMetric(m)
+			convey.So(got.value, convey.ShouldEqual, expect.value)
+			convey.So(got.metricType, convey.ShouldEqual, expect.metricType)
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -139,7 +139,7 @@ func (ScrapeGlobalVariables) Version() float64 {
 
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
 func (ScrapeGlobalVariables) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
-	globalVariablesRows, err := db.QueryContext(ctx, globalVariablesQuery)
+	globalVariablesRows, err := queryRows(ctx, db, globalVariablesQuery)
 	if err != nil {
 		return err
 	}
@@ -159,6 +159,11 @@ func (ScrapeGlobalVariables) Scrape(ctx context.Context, db *sql.DB, ch chan<- p
 		"server_id":               "",
 		"version_compile_os":      "",
 		"version_compile_machine": "",
+		"hostname":                "",
+		"port":                    "",
+		"datadir":                 "",
+		"binlog_format":           "",
+		"gtid_mode":               "",
 	}
 
 	for globalVariablesRows.Next() {
@@ -186,24 +191,33 @@ func (ScrapeGlobalVariables) Scrape(ctx context.Context, db *sql.DB, ch chan<- p
 
 	// mysql_version_info metric.
 	ch <- prometheus.MustNewConstMetric(
-		prometheus.NewDesc(prometheus.BuildFQName(namespace, "version", "info"), "MySQL version and distribution.",
-			[]string{"innodb_version", "version", "version_comment"}, nil),
+		cachedDesc(prometheus.BuildFQName(namespace, "version", "info"), "MySQL version and distribution.",
+			[]string{"innodb_version", "version", "version_comment"}),
 		prometheus.GaugeValue, 1, textItems["innodb_version"], textItems["version"], textItems["version_comment"],
 	)
 
 	// mysql_server_info metric.
 	ch <- prometheus.MustNewConstMetric(
-		prometheus.NewDesc(prometheus.BuildFQName(namespace, "server", "info"), "MySQL version and distribution.",
-			[]string{"uuid", "id", "version", "os", "arch"}, nil),
+		cachedDesc(prometheus.BuildFQName(namespace, "server", "info"), "MySQL version and distribution.",
+			[]string{"uuid", "id", "version", "os", "arch"}),
 		prometheus.GaugeValue, 1,
 		textItems["server_uuid"], textItems["server_id"], textItems["version"], textItems["version_compile_os"], textItems["version_compile_machine"],
 	)
 
+	// mysql_instance_info metric.
+	ch <- prometheus.MustNewConstMetric(
+		cachedDesc(prometheus.BuildFQName(namespace, "instance", "info"), "MySQL instance metadata for inventory.",
+			[]string{"version", "version_comment", "server_uuid", "server_id", "hostname", "port", "datadir", "binlog_format", "gtid_mode"}),
+		prometheus.GaugeValue, 1,
+		textItems["version"], textItems["version_comment"], textItems["server_uuid"], textItems["server_id"],
+		textItems["hostname"], textItems["port"], textItems["datadir"], textItems["binlog_format"], textItems["gtid_mode"],
+	)
+
 	// mysql_galera_variables_info metric.
 	if textItems["wsrep_cluster_name"] != "" {
 		ch <- prometheus.MustNewConstMetric(
-			prometheus.NewDesc(prometheus.BuildFQName(namespace, "galera", "variables_info"), "PXC/Galera variables information.",
-				[]string{"wsrep_cluster_name"}, nil),
+			cachedDesc(prometheus.BuildFQName(namespace, "galera", "variables_info"), "PXC/Galera variables information.",
+				[]string{"wsrep_cluster_name"}),
 			prometheus.GaugeValue, 1, textItems["wsrep_cluster_name"],
 		)
 	}
@@ -224,8 +238,8 @@ func (ScrapeGlobalVariables) Scrape(ctx context.Context, db *sql.DB, ch chan<- p
 			level = textItems["tx_isolation"]
 		}
 		ch <- prometheus.MustNewConstMetric(
-			prometheus.NewDesc(prometheus.BuildFQName(namespace, "transaction", "isolation"), "MySQL transaction isolation.",
-				[]string{"level"}, nil),
+			cachedDesc(prometheus.BuildFQName(namespace, "transaction", "isolation"), "MySQL transaction isolation.",
+				[]string{"level"}),
 			prometheus.GaugeValue,
 			1, level,
 		)
@@ -0,0 +1,96 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alecthomas/kingpin/v2"
+)
+
+// Tunable flags.
+var (
+	historyCollectors = kingpin.Flag(
+		"exporter.history_collectors",
+		"Comma separated list of collectors to keep the raw output of the last --exporter.history_size scrapes of, one of info_schema.processlist and engine_innodb_status.",
+	).Default("").String()
+	historySize = kingpin.Flag(
+		"exporter.history_size",
+		"Number of scrapes to keep the raw output of per collector.",
+	).Default("10").Int()
+)
+
+// RawOutput is the raw output of a collector in a scrape.
+type RawOutput struct {
+	Time   time.Time
+	Output string
+}
+
+// rawOutputHistory keeps the raw output of the last scrapes per collector.
+type rawOutputHistory struct {
+	mtx     sync.Mutex
+	outputs map[string][]RawOutput
+}
+
+var rawHistory = &rawOutputHistory{outputs: map[string][]RawOutput{}}
+
+// HistoryCollectors returns the collectors whose raw output is kept.
+func HistoryCollectors() []string {
+	var res []string
+	for _, name := range strings.Split(*historyCollectors, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			res = append(res, name)
+		}
+	}
+	return res
+}
+
+// historyEnabled returns whether the raw output of the collector is kept.
+func historyEnabled(name string) bool {
+	if *historySize <= 0 {
+		return false
+	}
+	for _, c := range HistoryCollectors() {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
+
+// recordRawOutput keeps output as the latest raw output of the collector,
+// dropping the oldest beyond --exporter.history_size.
+func recordRawOutput(ctx context.Context, name, output string) {
+	if !historyEnabled(name) || catalogRun(ctx) {
+		return
+	}
+	rawHistory.mtx.Lock()
+	defer rawHistory.mtx.Unlock()
+	outputs := append(rawHistory.outputs[name], RawOutput{Time: time.Now(), Output: output})
+	if len(outputs) > *historySize {
+		outputs = append([]RawOutput{}, outputs[len(outputs)-*historySize:]...)
+	}
+	rawHistory.outputs[name] = outputs
+}
+
+// RawOutputHistory returns the kept raw outputs of the collector, oldest
+// first.
+func RawOutputHistory(name string) []RawOutput {
+	rawHistory.mtx.Lock()
+	defer rawHistory.mtx.Unlock()
+	return append([]RawOutput{}, rawHistory.outputs[name]...)
+}
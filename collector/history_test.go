@@ -0,0 +1,47 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestRecordRawOutput(t *testing.T) {
+	defaultCollectors, defaultSize := *historyCollectors, *historySize
+	defer func() {
+		*historyCollectors, *historySize = defaultCollectors, defaultSize
+		rawHistory.outputs = map[string][]RawOutput{}
+	}()
+	*historyCollectors = "engine_innodb_status, info_schema.processlist"
+	*historySize = 2
+
+	convey.Convey("The last scrapes of the history collectors are kept", t, func() {
+		convey.So(HistoryCollectors(), convey.ShouldResemble, []string{"engine_innodb_status", "info_schema.processlist"})
+
+		recordRawOutput(context.Background(), "engine_innodb_status", "first")
+		recordRawOutput(context.Background(), "engine_innodb_status", "second")
+		recordRawOutput(context.Background(), "engine_innodb_status", "third")
+		recordRawOutput(context.Background(), "global_status", "not kept")
+
+		var outputs []string
+		for _, o := range RawOutputHistory("engine_innodb_status") {
+			outputs = append(outputs, o.Output)
+		}
+		convey.So(outputs, convey.ShouldResemble, []string{"second", "third"})
+		convey.So(RawOutputHistory("global_status"), convey.ShouldBeEmpty)
+	})
+}
@@ -0,0 +1,178 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape the identity the server reports about itself.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+const identityQuery = `SELECT @@hostname, @@server_id, IFNULL(@@report_host, '')`
+
+var (
+	identityLabels = kingpin.Flag(
+		"collect.identity.labels",
+		"Add the labels of mysql_identity_info to all metrics read from MySQL",
+	).Default("false").Bool()
+	identityCustomQuery = kingpin.Flag(
+		"collect.identity.query",
+		"Query returning the identity labels as columns of a single row, instead of hostname, server_id and report_host",
+	).Default("").String()
+)
+
+var identityName = prometheus.BuildFQName(namespace, "identity", "info")
+
+// Metric descriptors.
+var (
+	identityDesc = prometheus.NewDesc(
+		identityName,
+		"Identity the MySQL server reports about itself.",
+		[]string{"hostname", "server_id", "report_host"}, nil,
+	)
+)
+
+// ScrapeIdentity collects @@hostname, @@server_id and @@report_host, or the
+// result of --collect.identity.query.
+type ScrapeIdentity struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeIdentity) Name() string {
+	return "identity"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeIdentity) Help() string {
+	return "Collect the hostname, server_id and report_host the server reports about itself"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeIdentity) Version() float64 {
+	return 5.1
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeIdentity) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	if *identityCustomQuery != "" {
+		return scrapeCustomIdentity(ctx, db, *identityCustomQuery, ch)
+	}
+	var hostname, serverID, reportHost string
+	if err := db.QueryRowContext(ctx, identityQuery).Scan(&hostname, &serverID, &reportHost); err != nil {
+		return err
+	}
+	ch <- prometheus.MustNewConstMetric(identityDesc, prometheus.GaugeValue, 1, hostname, serverID, reportHost)
+	return nil
+}
+
+// scrapeCustomIdentity exposes the columns of the first row returned by
+// query as labels of mysql_identity_info. Proxies like ProxySQL route it to
+// the backend serving the scrape, so metrics can be attributed to it rather
+// than to the proxy.
+func scrapeCustomIdentity(ctx context.Context, db *sql.DB, query string, ch chan<- prometheus.Metric) error {
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return fmt.Errorf("identity query returned no rows")
+	}
+	values := make([]sql.NullString, len(columns))
+	scanArgs := make([]interface{}, len(columns))
+	for i := range values {
+		scanArgs[i] = &values[i]
+	}
+	if err := rows.Scan(scanArgs...); err != nil {
+		return err
+	}
+
+	labels := make([]string, len(columns))
+	labelValues := make([]string, len(columns))
+	for i, column := range columns {
+		labels[i] = validPrometheusName(column)
+		labelValues[i] = values[i].String
+	}
+	desc := cachedDesc(identityName, "Identity the MySQL server reports about itself.", labels)
+	metric, err := prometheus.NewConstMetric(desc, prometheus.GaugeValue, 1, labelValues...)
+	if err != nil {
+		return err
+	}
+	ch <- metric
+	return nil
+}
+
+// check interface
+var _ Scraper = ScrapeIdentity{}
+
+// AddIdentityLabels adds the labels of mysql_identity_info to the metrics
+// read from MySQL when --collect.identity.labels is set. Labels a metric
+// already has are kept.
+func AddIdentityLabels(mfs []*dto.MetricFamily) []*dto.MetricFamily {
+	if !*identityLabels {
+		return mfs
+	}
+	var identity []*dto.LabelPair
+	for _, mf := range mfs {
+		if mf.GetName() == identityName && len(mf.Metric) == 1 {
+			identity = mf.Metric[0].Label
+		}
+	}
+	if len(identity) == 0 {
+		return mfs
+	}
+
+	for _, mf := range mfs {
+		name := mf.GetName()
+		// Keep the exporter's own series stable while MySQL is down.
+		if name == identityName || name == prometheus.BuildFQName(namespace, "", "up") || strings.HasPrefix(name, namespace+"_"+exporter+"_") {
+			continue
+		}
+		for _, m := range mf.Metric {
+			m.Label = mergeLabels(m.Label, identity)
+		}
+	}
+	return mfs
+}
+
+// mergeLabels adds the extra labels not in labels and sorts them by name.
+func mergeLabels(labels, extra []*dto.LabelPair) []*dto.LabelPair {
+	names := make(map[string]bool, len(labels))
+	for _, lp := range labels {
+		names[lp.GetName()] = true
+	}
+	for _, lp := range extra {
+		if !names[lp.GetName()] {
+			labels = append(labels, lp)
+		}
+	}
+	sort.Slice(labels, func(i, j int) bool { return labels[i].GetName() < labels[j].GetName() })
+	return labels
+}
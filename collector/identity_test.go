@@ -0,0 +1,130 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeIdentity(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(identityQuery)).WillReturnRows(
+		sqlmock.NewRows([]string{"@@hostname", "@@server_id", "IFNULL(@@report_host, '')"}).AddRow("db-1", "42", "db-1.example.com"))
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeIdentity{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	convey.Convey("Metrics comparison", t, func() {
+		got := readMetric(<-ch)
+		convey.So(got, convey.ShouldResemble, MetricResult{
+			labels:     labelMap{"hostname": "db-1", "server_id": "42", "report_host": "db-1.example.com"},
+			value:      1,
+			metricType: dto.MetricType_GAUGE,
+		})
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestAddIdentityLabels(t *testing.T) {
+	if _, err := kingpin.CommandLine.Parse([]string{"--collect.identity.labels"}); err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(metricsCollector{
+		prometheus.MustNewConstMetric(identityDesc, prometheus.GaugeValue, 1, "db-1", "42", ""),
+		prometheus.MustNewConstMetric(mysqlUp, prometheus.GaugeValue, 1),
+		prometheus.MustNewConstMetric(newDesc("global_status", "threads_running", "Threads running."), prometheus.UntypedValue, 3),
+		prometheus.MustNewConstMetric(
+			prometheus.NewDesc("mysql_perf_schema_hosts", "Hosts.", []string{"hostname"}, nil),
+			prometheus.GaugeValue, 1, "app-1"),
+	})
+	mfs, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	convey.Convey("Identity labels are added to MySQL metrics", t, func() {
+		labels := map[string]map[string]string{}
+		for _, mf := range AddIdentityLabels(mfs) {
+			labels[mf.GetName()] = map[string]string{}
+			for _, lp := range mf.Metric[0].Label {
+				labels[mf.GetName()][lp.GetName()] = lp.GetValue()
+			}
+		}
+		convey.So(labels, convey.ShouldResemble, map[string]map[string]string{
+			"mysql_identity_info":                 {"hostname": "db-1", "server_id": "42", "report_host": ""},
+			"mysql_up":                            {},
+			"mysql_global_status_threads_running": {"hostname": "db-1", "server_id": "42", "report_host": ""},
+			"mysql_perf_schema_hosts":             {"hostname": "app-1", "server_id": "42", "report_host": ""},
+		})
+	})
+}
+
+func TestScrapeCustomIdentity(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	query := "SELECT @@hostname AS backend_host, @@port AS backend_port"
+	mock.ExpectQuery(sanitizeQuery(query)).WillReturnRows(
+		sqlmock.NewRows([]string{"backend_host", "backend_port"}).AddRow("db-2", "3307"))
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = scrapeCustomIdentity(context.Background(), db, query, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	convey.Convey("Metrics comparison", t, func() {
+		got := readMetric(<-ch)
+		convey.So(got, convey.ShouldResemble, MetricResult{
+			labels:     labelMap{"backend_host": "db-2", "backend_port": "3307"},
+			value:      1,
+			metricType: dto.MetricType_GAUGE,
+		})
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// InFlightScrape is a snapshot of a scrape which has not finished yet.
+type InFlightScrape struct {
+	ConnectionID uint64
+	// DSN is the DSN the scrape connection was opened with, empty for the
+	// exporters of NewWithDB and NewWithConnector.
+	DSN   string
+	Start time.Time
+	// Scrapers maps the scrapers still running to their start time.
+	Scrapers map[string]time.Time
+}
+
+// inFlight tracks the running scrapes for debugging hangs.
+var inFlight inFlightScrapes
+
+// inFlightScrapes holds the running scrapes.
+type inFlightScrapes struct {
+	mu      sync.Mutex
+	nextID  uint64
+	scrapes map[uint64]*InFlightScrape
+}
+
+// start records a new scrape on connection connID, opened with dsn, and
+// returns its id.
+func (f *inFlightScrapes) start(connID uint64, dsn string) uint64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.scrapes == nil {
+		f.scrapes = map[uint64]*InFlightScrape{}
+	}
+	f.nextID++
+	f.scrapes[f.nextID] = &InFlightScrape{
+		ConnectionID: connID,
+		DSN:          dsn,
+		Start:        time.Now(),
+		Scrapers:     map[string]time.Time{},
+	}
+	return f.nextID
+}
+
+// finish removes the scrape id.
+func (f *inFlightScrapes) finish(id uint64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.scrapes, id)
+}
+
+// scraperStarted records that scraper started running in scrape id.
+func (f *inFlightScrapes) scraperStarted(id uint64, scraper string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if s, ok := f.scrapes[id]; ok {
+		s.Scrapers[scraper] = time.Now()
+	}
+}
+
+// scraperDone records that scraper finished in scrape id.
+func (f *inFlightScrapes) scraperDone(id uint64, scraper string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if s, ok := f.scrapes[id]; ok {
+		delete(s.Scrapers, scraper)
+	}
+}
+
+// InFlightScrapes returns the running scrapes, oldest first.
+func InFlightScrapes() []InFlightScrape {
+	inFlight.mu.Lock()
+	defer inFlight.mu.Unlock()
+	res := make([]InFlightScrape, 0, len(inFlight.scrapes))
+	for _, s := range inFlight.scrapes {
+		scrapers := make(map[string]time.Time, len(s.Scrapers))
+		for name, start := range s.Scrapers {
+			scrapers[name] = start
+		}
+		res = append(res, InFlightScrape{ConnectionID: s.ConnectionID, DSN: s.DSN, Start: s.Start, Scrapers: scrapers})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Start.Before(res[j].Start) })
+	return res
+}
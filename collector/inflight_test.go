@@ -0,0 +1,43 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"testing"
+
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestInFlightScrapes(t *testing.T) {
+	defer func() { inFlight = inFlightScrapes{} }()
+
+	convey.Convey("Tracking in-flight scrapes", t, func() {
+		first := inFlight.start(10, "user:pass@tcp(db1:3306)/")
+		second := inFlight.start(11, "")
+		inFlight.scraperStarted(first, "global_status")
+		inFlight.scraperStarted(first, "slave_status")
+		inFlight.scraperDone(first, "global_status")
+
+		scrapes := InFlightScrapes()
+		convey.So(scrapes, convey.ShouldHaveLength, 2)
+		convey.So(scrapes[0].ConnectionID, convey.ShouldEqual, 10)
+		convey.So(scrapes[0].DSN, convey.ShouldEqual, "user:pass@tcp(db1:3306)/")
+		convey.So(scrapes[0].Scrapers, convey.ShouldContainKey, "slave_status")
+		convey.So(scrapes[0].Scrapers, convey.ShouldNotContainKey, "global_status")
+
+		inFlight.finish(first)
+		inFlight.finish(second)
+		convey.So(InFlightScrapes(), convey.ShouldBeEmpty)
+	})
+}
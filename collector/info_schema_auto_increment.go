@@ -71,19 +71,12 @@ func (ScrapeAutoIncrementColumns) Version() float64 {
 
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
 func (ScrapeAutoIncrementColumns) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
-	autoIncrementRows, err := db.QueryContext(ctx, infoSchemaAutoIncrementQuery)
-	if err != nil {
-		return err
-	}
-	defer autoIncrementRows.Close()
-
 	var (
 		schema, table, column string
 		value, max            float64
 	)
-
-	for autoIncrementRows.Next() {
-		if err := autoIncrementRows.Scan(
+	return newRowScanner(ScrapeAutoIncrementColumns{}.Name(), logger).scan(ctx, db, func(rows *sql.Rows) error {
+		if err := rows.Scan(
 			&schema, &table, &column, &value, &max,
 		); err != nil {
 			return err
@@ -96,8 +89,8 @@ func (ScrapeAutoIncrementColumns) Scrape(ctx context.Context, db *sql.DB, ch cha
 			globalInfoSchemaAutoIncrementMaxDesc, prometheus.GaugeValue, max,
 			schema, table, column,
 		)
-	}
-	return nil
+		return nil
+	}, infoSchemaAutoIncrementQuery)
 }
 
 // check interface
@@ -0,0 +1,151 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape tables and columns not using the default collation from `information_schema`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	collationServerQuery       = `SELECT @@collation_server`
+	charsetMismatchTablesQuery = `
+	SELECT
+	    TABLE_SCHEMA,
+	    TABLE_COLLATION,
+	    COUNT(*)
+	  FROM information_schema.tables
+	  WHERE TABLE_TYPE = 'BASE TABLE'
+	    AND TABLE_SCHEMA NOT IN ('mysql', 'performance_schema', 'information_schema', 'sys')
+	    AND TABLE_COLLATION <> ?
+	  GROUP BY TABLE_SCHEMA, TABLE_COLLATION
+	  ORDER BY TABLE_SCHEMA, TABLE_COLLATION
+	`
+	charsetMismatchColumnsQuery = `
+	SELECT
+	    c.TABLE_SCHEMA,
+	    c.CHARACTER_SET_NAME,
+	    c.COLLATION_NAME,
+	    COUNT(*)
+	  FROM information_schema.columns c
+	  JOIN information_schema.tables t
+	    ON t.TABLE_SCHEMA = c.TABLE_SCHEMA AND t.TABLE_NAME = c.TABLE_NAME
+	  WHERE t.TABLE_TYPE = 'BASE TABLE'
+	    AND c.TABLE_SCHEMA NOT IN ('mysql', 'performance_schema', 'information_schema', 'sys')
+	    AND c.COLLATION_NAME IS NOT NULL
+	    AND c.COLLATION_NAME <> ?
+	  GROUP BY c.TABLE_SCHEMA, c.CHARACTER_SET_NAME, c.COLLATION_NAME
+	  ORDER BY c.TABLE_SCHEMA, c.CHARACTER_SET_NAME, c.COLLATION_NAME
+	`
+)
+
+// Tunable flags.
+var (
+	charsetMismatchCollation = kingpin.Flag(
+		"collect.info_schema.charset_mismatch.collation",
+		"The expected collation of tables and columns, collation_server if empty",
+	).Default("").String()
+)
+
+// Metric descriptors.
+var (
+	infoSchemaCharsetMismatchTablesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "charset_mismatch_tables"),
+		"The number of tables not using the expected collation.",
+		[]string{"schema", "collation"}, nil,
+	)
+	infoSchemaCharsetMismatchColumnsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "charset_mismatch_columns"),
+		"The number of columns not using the expected collation.",
+		[]string{"schema", "character_set", "collation"}, nil,
+	)
+)
+
+// ScrapeCharsetMismatch collects tables and columns not using the expected collation.
+type ScrapeCharsetMismatch struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeCharsetMismatch) Name() string {
+	return informationSchema + ".charset_mismatch"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeCharsetMismatch) Help() string {
+	return "Collect the number of tables and columns not using the expected collation from information_schema"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeCharsetMismatch) Version() float64 {
+	return 5.1
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeCharsetMismatch) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	collation := *charsetMismatchCollation
+	if collation == "" {
+		if err := db.QueryRowContext(ctx, collationServerQuery).Scan(&collation); err != nil {
+			return err
+		}
+	}
+
+	tableRows, err := db.QueryContext(ctx, charsetMismatchTablesQuery, collation)
+	if err != nil {
+		return err
+	}
+	defer tableRows.Close()
+
+	var (
+		schema, tableCollation string
+		count                  float64
+	)
+	for tableRows.Next() {
+		if err := tableRows.Scan(&schema, &tableCollation, &count); err != nil {
+			return err
+		}
+		ch <- prometheus.MustNewConstMetric(
+			infoSchemaCharsetMismatchTablesDesc, prometheus.GaugeValue, count,
+			schema, tableCollation,
+		)
+	}
+	if err := tableRows.Err(); err != nil {
+		return err
+	}
+
+	columnRows, err := db.QueryContext(ctx, charsetMismatchColumnsQuery, collation)
+	if err != nil {
+		return err
+	}
+	defer columnRows.Close()
+
+	var charset, columnCollation string
+	for columnRows.Next() {
+		if err := columnRows.Scan(&schema, &charset, &columnCollation, &count); err != nil {
+			return err
+		}
+		ch <- prometheus.MustNewConstMetric(
+			infoSchemaCharsetMismatchColumnsDesc, prometheus.GaugeValue, count,
+			schema, charset, columnCollation,
+		)
+	}
+	return columnRows.Err()
+}
+
+// check interface
+var _ Scraper = ScrapeCharsetMismatch{}
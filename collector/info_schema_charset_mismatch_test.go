@@ -0,0 +1,69 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeCharsetMismatch(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(collationServerQuery)).WillReturnRows(
+		sqlmock.NewRows([]string{"@@collation_server"}).AddRow("utf8mb4_0900_ai_ci"))
+	mock.ExpectQuery(sanitizeQuery(charsetMismatchTablesQuery)).WithArgs("utf8mb4_0900_ai_ci").WillReturnRows(
+		sqlmock.NewRows([]string{"TABLE_SCHEMA", "TABLE_COLLATION", "COUNT(*)"}).
+			AddRow("legacy", "latin1_swedish_ci", 4))
+	mock.ExpectQuery(sanitizeQuery(charsetMismatchColumnsQuery)).WithArgs("utf8mb4_0900_ai_ci").WillReturnRows(
+		sqlmock.NewRows([]string{"TABLE_SCHEMA", "CHARACTER_SET_NAME", "COLLATION_NAME", "COUNT(*)"}).
+			AddRow("legacy", "latin1", "latin1_swedish_ci", 12).
+			AddRow("shop", "utf8mb4", "utf8mb4_bin", 1))
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeCharsetMismatch{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{"schema": "legacy", "collation": "latin1_swedish_ci"}, value: 4, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"schema": "legacy", "character_set": "latin1", "collation": "latin1_swedish_ci"}, value: 12, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"schema": "shop", "character_set": "utf8mb4", "collation": "utf8mb4_bin"}, value: 1, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape `information_schema.column_statistics`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// The last-updated attribute of a histogram is in UTC.
+const columnStatisticsQuery = `
+	SELECT
+	    SCHEMA_NAME,
+	    COUNT(*),
+	    TIMESTAMPDIFF(SECOND, MIN(CAST(JSON_UNQUOTE(JSON_EXTRACT(HISTOGRAM, '$."last-updated"')) AS DATETIME)), UTC_TIMESTAMP())
+	  FROM information_schema.column_statistics
+	  GROUP BY SCHEMA_NAME
+	`
+
+// Metric descriptors.
+var (
+	infoSchemaColumnHistogramsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "column_histograms"),
+		"The number of columns with an optimizer histogram.",
+		[]string{"schema"}, nil,
+	)
+	infoSchemaColumnHistogramMaxAgeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "column_histogram_max_age_seconds"),
+		"The time since the oldest optimizer histogram was updated.",
+		[]string{"schema"}, nil,
+	)
+)
+
+// ScrapeColumnStatistics collects from `information_schema.column_statistics`.
+type ScrapeColumnStatistics struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeColumnStatistics) Name() string {
+	return informationSchema + ".column_statistics"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeColumnStatistics) Help() string {
+	return "Collect the number of columns with optimizer histograms and the oldest histogram age per schema from information_schema.column_statistics"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeColumnStatistics) Version() float64 {
+	return 8.0
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeColumnStatistics) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	rows, err := db.QueryContext(ctx, columnStatisticsQuery)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var (
+		schema          string
+		histograms, age float64
+	)
+	for rows.Next() {
+		if err := rows.Scan(&schema, &histograms, &age); err != nil {
+			return err
+		}
+		ch <- prometheus.MustNewConstMetric(infoSchemaColumnHistogramsDesc, prometheus.GaugeValue, histograms, schema)
+		ch <- prometheus.MustNewConstMetric(infoSchemaColumnHistogramMaxAgeDesc, prometheus.GaugeValue, age, schema)
+	}
+	return rows.Err()
+}
+
+// check interface
+var _ Scraper = ScrapeColumnStatistics{}
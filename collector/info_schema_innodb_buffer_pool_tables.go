@@ -0,0 +1,165 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape the buffer pool pages of tables from `information_schema.innodb_cached_indexes`
+// or `information_schema.innodb_buffer_page`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	MySQL "github.com/go-sql-driver/mysql"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// Table names are schema/table, with #p# and the partition name
+	// appended for partitions.
+	innodbCachedTablesQuery = `
+	SELECT
+	    SUBSTRING_INDEX(SUBSTRING_INDEX(t.NAME, '#', 1), '/', 1) AS TABLE_SCHEMA,
+	    SUBSTRING_INDEX(SUBSTRING_INDEX(t.NAME, '#', 1), '/', -1) AS TABLE_NAME,
+	    SUM(c.N_CACHED_PAGES) AS PAGES
+	  FROM information_schema.innodb_cached_indexes c
+	  JOIN information_schema.innodb_indexes i ON i.INDEX_ID = c.INDEX_ID
+	  JOIN information_schema.innodb_tables t ON t.TABLE_ID = i.TABLE_ID
+	  GROUP BY TABLE_SCHEMA, TABLE_NAME
+	  ORDER BY PAGES DESC
+	  LIMIT %d
+	`
+	// Table names are quoted as `schema`.`table`, followed by
+	// /* Partition `name` */ for partitions.
+	innodbBufferPageTablesQuery = `
+	SELECT
+	    SUBSTRING_INDEX(TABLE_NAME, ' /*', 1) AS NAME,
+	    COUNT(*) AS PAGES
+	  FROM information_schema.innodb_buffer_page
+	  WHERE TABLE_NAME IS NOT NULL
+	  GROUP BY NAME
+	  ORDER BY PAGES DESC
+	  LIMIT %d
+	`
+)
+
+// Tunable flags.
+var (
+	innodbBufferPoolTablesLimit = kingpin.Flag(
+		"collect.info_schema.innodb_buffer_pool_tables.limit",
+		"Limit the number of tables by number of pages in the buffer pool",
+	).Default("20").Int()
+	innodbBufferPoolTablesScan = kingpin.Flag(
+		"collect.info_schema.innodb_buffer_pool_tables.scan",
+		"Scan information_schema.innodb_buffer_page when information_schema.innodb_cached_indexes is not available. The scan can stall servers with large buffer pools.",
+	).Default("false").Bool()
+)
+
+// Metric descriptors.
+var (
+	infoSchemaInnodbBufferPoolTablePagesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "innodb_buffer_pool_table_pages"),
+		"The number of pages of a table in the InnoDB buffer pool.",
+		[]string{"schema", "table"}, nil,
+	)
+)
+
+// ScrapeInnodbBufferPoolTables collects the buffer pool pages of the tables using most of it.
+type ScrapeInnodbBufferPoolTables struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeInnodbBufferPoolTables) Name() string {
+	return informationSchema + ".innodb_buffer_pool_tables"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeInnodbBufferPoolTables) Help() string {
+	return "Collect the buffer pool pages of the tables using most of it from information_schema.innodb_cached_indexes or information_schema.innodb_buffer_page"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeInnodbBufferPoolTables) Version() float64 {
+	return 5.6
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeInnodbBufferPoolTables) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	rows, err := db.QueryContext(ctx, fmt.Sprintf(innodbCachedTablesQuery, *innodbBufferPoolTablesLimit))
+	if err != nil {
+		if mysqlErr, ok := err.(*MySQL.MySQLError); !ok || mysqlErr.Number != 1109 {
+			return err
+		}
+		// Check for error 1109: Unknown table, before MySQL 8.0.
+		if !*innodbBufferPoolTablesScan {
+			level.Debug(logger).Log("msg", "information_schema.innodb_cached_indexes is not available.")
+			return nil
+		}
+		return scrapeInnodbBufferPageTables(ctx, db, ch)
+	}
+	defer rows.Close()
+
+	var (
+		schema, table string
+		pages         float64
+	)
+	for rows.Next() {
+		if err := rows.Scan(&schema, &table, &pages); err != nil {
+			return err
+		}
+		ch <- prometheus.MustNewConstMetric(infoSchemaInnodbBufferPoolTablePagesDesc, prometheus.GaugeValue, pages, schema, table)
+	}
+	return rows.Err()
+}
+
+// scrapeInnodbBufferPageTables collects the buffer pool pages of tables from
+// information_schema.innodb_buffer_page, which is expensive.
+func scrapeInnodbBufferPageTables(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	rows, err := db.QueryContext(ctx, fmt.Sprintf(innodbBufferPageTablesQuery, *innodbBufferPoolTablesLimit))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var (
+		name  string
+		pages float64
+	)
+	for rows.Next() {
+		if err := rows.Scan(&name, &pages); err != nil {
+			return err
+		}
+		schema, table, ok := parseQuotedTableName(name)
+		if !ok {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(infoSchemaInnodbBufferPoolTablePagesDesc, prometheus.GaugeValue, pages, schema, table)
+	}
+	return rows.Err()
+}
+
+// parseQuotedTableName splits `schema`.`table` into its schema and table.
+func parseQuotedTableName(name string) (string, string, bool) {
+	parts := strings.SplitN(strings.TrimSpace(name), "`.`", 2)
+	if len(parts) != 2 {
+		return "", "", false
+	}
+	return strings.TrimPrefix(parts[0], "`"), strings.TrimSuffix(parts[1], "`"), true
+}
+
+// check interface
+var _ Scraper = ScrapeInnodbBufferPoolTables{}
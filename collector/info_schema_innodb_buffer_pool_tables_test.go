@@ -0,0 +1,120 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	MySQL "github.com/go-sql-driver/mysql"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeInnodbBufferPoolTables(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.info_schema.innodb_buffer_pool_tables.limit=2",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(fmt.Sprintf(innodbCachedTablesQuery, 2))).WillReturnRows(
+		sqlmock.NewRows([]string{"TABLE_SCHEMA", "TABLE_NAME", "PAGES"}).
+			AddRow("shop", "orders", 5000).
+			AddRow("shop", "users", 120))
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeInnodbBufferPoolTables{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{"schema": "shop", "table": "orders"}, value: 5000, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"schema": "shop", "table": "users"}, value: 120, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeInnodbBufferPoolTablesScan(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.info_schema.innodb_buffer_pool_tables.limit=2",
+		"--collect.info_schema.innodb_buffer_pool_tables.scan",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(fmt.Sprintf(innodbCachedTablesQuery, 2))).WillReturnError(
+		&MySQL.MySQLError{Number: 1109, Message: "Unknown table 'INNODB_CACHED_INDEXES' in information_schema"})
+	mock.ExpectQuery(sanitizeQuery(fmt.Sprintf(innodbBufferPageTablesQuery, 2))).WillReturnRows(
+		sqlmock.NewRows([]string{"NAME", "PAGES"}).
+			AddRow("`shop`.`orders`", 3000).
+			AddRow("SYS_TABLES", 10))
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeInnodbBufferPoolTables{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{"schema": "shop", "table": "orders"}, value: 3000, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -175,9 +175,9 @@ func (ScrapeInnodbMetrics) Scrape(ctx context.Context, db *sql.DB, ch chan<- pro
 		// MySQL returns counters named two different ways. "counter" and "status_counter"
 		// value >= 0 is necessary due to upstream bugs: http://bugs.mysql.com/bug.php?id=75966
 		if (metricType == "counter" || metricType == "status_counter") && value >= 0 {
-			description := prometheus.NewDesc(
+			description := cachedDesc(
 				prometheus.BuildFQName(namespace, informationSchema, metricName+"_total"),
-				comment, nil, nil,
+				comment, nil,
 			)
 			ch <- prometheus.MustNewConstMetric(
 				description,
@@ -185,9 +185,9 @@ func (ScrapeInnodbMetrics) Scrape(ctx context.Context, db *sql.DB, ch chan<- pro
 				value,
 			)
 		} else {
-			description := prometheus.NewDesc(
+			description := cachedDesc(
 				prometheus.BuildFQName(namespace, informationSchema, metricName),
-				comment, nil, nil,
+				comment, nil,
 			)
 			ch <- prometheus.MustNewConstMetric(
 				description,
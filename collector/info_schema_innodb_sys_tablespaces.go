@@ -21,7 +21,9 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/alecthomas/kingpin/v2"
 	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -44,8 +46,33 @@ const innodbTablespacesQuery = `
 	    ifnull(ROW_FORMAT, 'NONE') as ROW_FORMAT,
 	    ifnull(SPACE_TYPE, 'NONE') as SPACE_TYPE,
 	    FILE_SIZE,
-	    ALLOCATED_SIZE
-	  FROM information_schema.` + "`%s`"
+	    ALLOCATED_SIZE,
+	    PAGE_SIZE
+	  FROM information_schema.` + "`%s`" + `
+	  ORDER BY FILE_SIZE DESC
+	`
+const innodbTablespacesFreeExtentsQuery = `
+	SELECT
+	    FILE_ID,
+	    FREE_EXTENTS
+	  FROM information_schema.FILES
+	  WHERE ENGINE = 'InnoDB'
+	`
+
+// innodbMaxTablespacePages is the maximum number of pages of an InnoDB tablespace.
+const innodbMaxTablespacePages = 1 << 32
+
+// Tunable flags.
+var (
+	innodbTablespacesLimit = kingpin.Flag(
+		"collect.info_schema.innodb_tablespaces.limit",
+		"Limit the number of file-per-table tablespaces by file size, 0 for no limit",
+	).Default("0").Int()
+	innodbTablespacesPageLimitRatio = kingpin.Flag(
+		"collect.info_schema.innodb_tablespaces.page_limit_ratio",
+		"Ratio of the InnoDB tablespace page limit from which a tablespace counts as approaching the limit",
+	).Default("0.8").Float64()
+)
 
 // Metric descriptors.
 var (
@@ -64,6 +91,16 @@ var (
 		"The actual size of the file, which is the amount of space allocated on disk.",
 		[]string{"tablespace_name"}, nil,
 	)
+	infoSchemaInnodbTablesspaceFreeExtentsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "innodb_tablespace_free_extents"),
+		"The number of fully free extents in the tablespace.",
+		[]string{"tablespace_name"}, nil,
+	)
+	infoSchemaInnodbTablespacesNearPageLimitDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "innodb_tablespaces_near_page_limit"),
+		"The number of tablespaces approaching the maximum number of pages of a tablespace.",
+		nil, nil,
+	)
 )
 
 // ScrapeInfoSchemaInnodbTablespaces collects from `information_schema.innodb_sys_tablespaces`.
@@ -100,6 +137,11 @@ func (ScrapeInfoSchemaInnodbTablespaces) Scrape(ctx context.Context, db *sql.DB,
 		return errors.New("Couldn't find INNODB_SYS_TABLESPACES or INNODB_TABLESPACES in information_schema.")
 	}
 
+	freeExtents, err := innodbTablespacesFreeExtents(ctx, db)
+	if err != nil {
+		level.Debug(logger).Log("msg", "Error querying free extents from information_schema.FILES", "err", err)
+	}
+
 	tablespacesRows, err := db.QueryContext(ctx, query)
 	if err != nil {
 		return err
@@ -114,8 +156,11 @@ func (ScrapeInfoSchemaInnodbTablespaces) Scrape(ctx context.Context, db *sql.DB,
 		spaceType     string
 		fileSize      uint64
 		allocatedSize uint64
+		pageSize      uint64
 	)
 
+	singleTablespaces := 0
+	nearPageLimit := 0
 	for tablespacesRows.Next() {
 		err = tablespacesRows.Scan(
 			&tableSpace,
@@ -125,10 +170,21 @@ func (ScrapeInfoSchemaInnodbTablespaces) Scrape(ctx context.Context, db *sql.DB,
 			&spaceType,
 			&fileSize,
 			&allocatedSize,
+			&pageSize,
 		)
 		if err != nil {
 			return err
 		}
+		if pageSize > 0 && float64(fileSize/pageSize) >= *innodbTablespacesPageLimitRatio*innodbMaxTablespacePages {
+			nearPageLimit++
+		}
+		// File-per-table tablespaces are capped, rows are ordered by size.
+		if spaceType == "Single" {
+			singleTablespaces++
+			if *innodbTablespacesLimit > 0 && singleTablespaces > *innodbTablespacesLimit {
+				continue
+			}
+		}
 		ch <- prometheus.MustNewConstMetric(
 			infoSchemaInnodbTablesspaceInfoDesc, prometheus.GaugeValue, float64(tableSpace),
 			tableName, fileFormat, rowFormat, spaceType,
@@ -141,10 +197,46 @@ func (ScrapeInfoSchemaInnodbTablespaces) Scrape(ctx context.Context, db *sql.DB,
 			infoSchemaInnodbTablesspaceAllocatedSizeDesc, prometheus.GaugeValue, float64(allocatedSize),
 			tableName,
 		)
+		if free, ok := freeExtents[tableSpace]; ok {
+			ch <- prometheus.MustNewConstMetric(
+				infoSchemaInnodbTablesspaceFreeExtentsDesc, prometheus.GaugeValue, float64(free),
+				tableName,
+			)
+		}
+	}
+	if err := tablespacesRows.Err(); err != nil {
+		return err
 	}
+	ch <- prometheus.MustNewConstMetric(
+		infoSchemaInnodbTablespacesNearPageLimitDesc, prometheus.GaugeValue, float64(nearPageLimit),
+	)
 
 	return nil
 }
 
+// innodbTablespacesFreeExtents returns the free extents by tablespace id.
+func innodbTablespacesFreeExtents(ctx context.Context, db *sql.DB) (map[uint32]uint64, error) {
+	rows, err := db.QueryContext(ctx, innodbTablespacesFreeExtentsQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	freeExtents := map[uint32]uint64{}
+	var (
+		fileID uint32
+		free   sql.NullInt64
+	)
+	for rows.Next() {
+		if err := rows.Scan(&fileID, &free); err != nil {
+			return nil, err
+		}
+		if free.Valid {
+			freeExtents[fileID] = uint64(free.Int64)
+		}
+	}
+	return freeExtents, rows.Err()
+}
+
 // check interface
 var _ Scraper = ScrapeInfoSchemaInnodbTablespaces{}
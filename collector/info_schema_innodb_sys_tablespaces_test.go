@@ -19,6 +19,7 @@ import (
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alecthomas/kingpin/v2"
 	"github.com/go-kit/log"
 	"github.com/prometheus/client_golang/prometheus"
 	dto "github.com/prometheus/client_model/go"
@@ -32,16 +33,30 @@ func TestScrapeInfoSchemaInnodbTablespaces(t *testing.T) {
 	}
 	defer db.Close()
 
+	_, err = kingpin.CommandLine.Parse([]string{
+		"--collect.info_schema.innodb_tablespaces.limit=2",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
 	columns := []string{"TABLE_NAME"}
 	rows := sqlmock.NewRows(columns).
 		AddRow("INNODB_SYS_TABLESPACES")
 	mock.ExpectQuery(sanitizeQuery(innodbTablespacesTablenameQuery)).WillReturnRows(rows)
 
+	columns = []string{"FILE_ID", "FREE_EXTENTS"}
+	rows = sqlmock.NewRows(columns).
+		AddRow(3, 5).
+		AddRow(2, nil)
+	mock.ExpectQuery(sanitizeQuery(innodbTablespacesFreeExtentsQuery)).WillReturnRows(rows)
+
 	tablespacesTablename := "INNODB_SYS_TABLESPACES"
-	columns = []string{"SPACE", "NAME", "FILE_FORMAT", "ROW_FORMAT", "SPACE_TYPE", "FILE_SIZE", "ALLOCATED_SIZE"}
+	columns = []string{"SPACE", "NAME", "FILE_FORMAT", "ROW_FORMAT", "SPACE_TYPE", "FILE_SIZE", "ALLOCATED_SIZE", "PAGE_SIZE"}
 	rows = sqlmock.NewRows(columns).
-		AddRow(1, "sys/sys_config", "Barracuda", "Dynamic", "Single", 100, 100).
-		AddRow(2, "db/compressed", "Barracuda", "Compressed", "Single", 300, 200)
+		AddRow(3, "db/huge", "Barracuda", "Dynamic", "Single", uint64(60000000000000), uint64(60000000000000), 16384).
+		AddRow(2, "db/compressed", "Barracuda", "Compressed", "Single", 300, 200, 16384).
+		AddRow(1, "sys/sys_config", "Barracuda", "Dynamic", "Single", 100, 100, 16384)
 	query := fmt.Sprintf(innodbTablespacesQuery, tablespacesTablename, tablespacesTablename)
 	mock.ExpectQuery(sanitizeQuery(query)).WillReturnRows(rows)
 
@@ -54,18 +69,22 @@ func TestScrapeInfoSchemaInnodbTablespaces(t *testing.T) {
 	}()
 
 	expected := []MetricResult{
-		{labels: labelMap{"tablespace_name": "sys/sys_config", "file_format": "Barracuda", "row_format": "Dynamic", "space_type": "Single"}, value: 1, metricType: dto.MetricType_GAUGE},
-		{labels: labelMap{"tablespace_name": "sys/sys_config"}, value: 100, metricType: dto.MetricType_GAUGE},
-		{labels: labelMap{"tablespace_name": "sys/sys_config"}, value: 100, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"tablespace_name": "db/huge", "file_format": "Barracuda", "row_format": "Dynamic", "space_type": "Single"}, value: 3, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"tablespace_name": "db/huge"}, value: 60000000000000, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"tablespace_name": "db/huge"}, value: 60000000000000, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"tablespace_name": "db/huge"}, value: 5, metricType: dto.MetricType_GAUGE},
 		{labels: labelMap{"tablespace_name": "db/compressed", "file_format": "Barracuda", "row_format": "Compressed", "space_type": "Single"}, value: 2, metricType: dto.MetricType_GAUGE},
 		{labels: labelMap{"tablespace_name": "db/compressed"}, value: 300, metricType: dto.MetricType_GAUGE},
 		{labels: labelMap{"tablespace_name": "db/compressed"}, value: 200, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 1, metricType: dto.MetricType_GAUGE},
 	}
 	convey.Convey("Metrics comparison", t, func() {
 		for _, expect := range expected {
 			got := readMetric(<-ch)
 			convey.So(expect, convey.ShouldResemble, got)
 		}
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
 	})
 
 	// Ensure all SQL queries were executed
@@ -0,0 +1,145 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape `information_schema.plugins` and `mysql.component`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	MySQL "github.com/go-sql-driver/mysql"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	pluginsQuery = `
+	SELECT
+	    PLUGIN_NAME,
+	    PLUGIN_VERSION,
+	    PLUGIN_STATUS,
+	    PLUGIN_TYPE,
+	    IFNULL(PLUGIN_LIBRARY, '')
+	  FROM information_schema.plugins
+	  ORDER BY PLUGIN_TYPE, PLUGIN_NAME
+	`
+	// Components are available from MySQL 8.0.
+	componentsQuery = `SELECT component_urn FROM mysql.component ORDER BY component_urn`
+)
+
+// Metric descriptors.
+var (
+	infoSchemaPluginsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "plugins"),
+		"The number of plugins by type and status.",
+		[]string{"type", "status"}, nil,
+	)
+	infoSchemaPluginInfoDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "plugin_info"),
+		"Plugins loaded from a library, such as audit, semisync, clone or validate_password.",
+		[]string{"name", "version", "status", "type", "library"}, nil,
+	)
+	componentInfoDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "component", "info"),
+		"Components installed with INSTALL COMPONENT.",
+		[]string{"urn"}, nil,
+	)
+)
+
+// ScrapePlugins collects from `information_schema.plugins` and `mysql.component`.
+type ScrapePlugins struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapePlugins) Name() string {
+	return informationSchema + ".plugins"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapePlugins) Help() string {
+	return "Collect plugin counts and loaded plugins from information_schema.plugins and components from mysql.component"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapePlugins) Version() float64 {
+	return 5.1
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapePlugins) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	pluginRows, err := db.QueryContext(ctx, pluginsQuery)
+	if err != nil {
+		return err
+	}
+	defer pluginRows.Close()
+
+	type pluginKey struct{ pluginType, status string }
+	var (
+		name, version, status, pluginType, library string
+		keys                                       []pluginKey
+		counts                                     = map[pluginKey]float64{}
+	)
+	for pluginRows.Next() {
+		if err := pluginRows.Scan(&name, &version, &status, &pluginType, &library); err != nil {
+			return err
+		}
+		key := pluginKey{pluginType, status}
+		if _, ok := counts[key]; !ok {
+			keys = append(keys, key)
+		}
+		counts[key]++
+		// Built-in plugins have no library.
+		if library != "" {
+			ch <- prometheus.MustNewConstMetric(
+				infoSchemaPluginInfoDesc, prometheus.GaugeValue, 1,
+				name, version, status, pluginType, library,
+			)
+		}
+	}
+	if err := pluginRows.Err(); err != nil {
+		return err
+	}
+	for _, key := range keys {
+		ch <- prometheus.MustNewConstMetric(
+			infoSchemaPluginsDesc, prometheus.GaugeValue, counts[key],
+			key.pluginType, key.status,
+		)
+	}
+
+	componentRows, err := db.QueryContext(ctx, componentsQuery)
+	if err != nil {
+		if mysqlErr, ok := err.(*MySQL.MySQLError); ok {
+			// Check for error 1146: Table doesn't exist
+			if mysqlErr.Number == 1146 {
+				level.Debug(logger).Log("msg", "mysql.component is not available.")
+				return nil
+			}
+		}
+		return err
+	}
+	defer componentRows.Close()
+
+	var urn string
+	for componentRows.Next() {
+		if err := componentRows.Scan(&urn); err != nil {
+			return err
+		}
+		ch <- prometheus.MustNewConstMetric(componentInfoDesc, prometheus.GaugeValue, 1, urn)
+	}
+	return componentRows.Err()
+}
+
+// check interface
+var _ Scraper = ScrapePlugins{}
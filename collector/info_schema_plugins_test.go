@@ -0,0 +1,70 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapePlugins(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"PLUGIN_NAME", "PLUGIN_VERSION", "PLUGIN_STATUS", "PLUGIN_TYPE", "PLUGIN_LIBRARY"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("clone", "1.0", "ACTIVE", "CLONE", "mysql_clone.so").
+		AddRow("InnoDB", "8.0", "ACTIVE", "STORAGE ENGINE", "").
+		AddRow("MyISAM", "1.0", "ACTIVE", "STORAGE ENGINE", "").
+		AddRow("FEDERATED", "1.0", "DISABLED", "STORAGE ENGINE", "")
+	mock.ExpectQuery(sanitizeQuery(pluginsQuery)).WillReturnRows(rows)
+	mock.ExpectQuery(sanitizeQuery(componentsQuery)).WillReturnRows(
+		sqlmock.NewRows([]string{"component_urn"}).AddRow("file://component_validate_password"))
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapePlugins{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{"name": "clone", "version": "1.0", "status": "ACTIVE", "type": "CLONE", "library": "mysql_clone.so"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"type": "CLONE", "status": "ACTIVE"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"type": "STORAGE ENGINE", "status": "ACTIVE"}, value: 2, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"type": "STORAGE ENGINE", "status": "DISABLED"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"urn": "file://component_validate_password"}, value: 1, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -142,11 +142,20 @@ func (ScrapeProcesslist) Scrape(ctx context.Context, db *sql.DB, ch chan<- prome
 	stateHostUserCommandCount := make(map[string]map[string]map[string]map[string]uint32)
 	stateHostUserCommandTime := make(map[string]map[string]map[string]map[string]uint32)
 
+	var raw *strings.Builder
+	if historyEnabled(ScrapeProcesslist{}.Name()) {
+		raw = &strings.Builder{}
+		raw.WriteString("user\thost\tcommand\tstate\tprocesses\tseconds\n")
+	}
+
 	for processlistRows.Next() {
 		err = processlistRows.Scan(&user, &host, &command, &state, &count, &time)
 		if err != nil {
 			return err
 		}
+		if raw != nil {
+			fmt.Fprintf(raw, "%s\t%s\t%s\t%s\t%d\t%d\n", user, host, command, state, count, time)
+		}
 		command = sanitizeState(command)
 		state = sanitizeState(state)
 		if host == "" {
@@ -203,6 +212,9 @@ func (ScrapeProcesslist) Scrape(ctx context.Context, db *sql.DB, ch chan<- prome
 		stateHostUserCommandCount[user][host][command][state] += count
 		stateHostUserCommandTime[user][host][command][state] += time
 	}
+	if raw != nil {
+		recordRawOutput(ctx, ScrapeProcesslist{}.Name(), raw.String())
+	}
 
 	for _, command := range sortedMapKeys(stateCounts) {
 		for _, state := range sortedMapKeys(stateCounts[command]) {
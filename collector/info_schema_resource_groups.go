@@ -0,0 +1,162 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape `information_schema.resource_groups` and the resource groups of
+// `performance_schema.threads`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	resourceGroupsQuery = `
+	SELECT
+	    RESOURCE_GROUP_NAME,
+	    RESOURCE_GROUP_TYPE,
+	    RESOURCE_GROUP_ENABLED,
+	    IFNULL(VCPU_IDS, ''),
+	    THREAD_PRIORITY
+	  FROM information_schema.resource_groups
+	`
+	resourceGroupThreadsQuery = `
+	SELECT RESOURCE_GROUP, TYPE, COUNT(*)
+	  FROM performance_schema.threads
+	  WHERE RESOURCE_GROUP IS NOT NULL
+	  GROUP BY RESOURCE_GROUP, TYPE
+	`
+)
+
+// Metric descriptors.
+var (
+	infoSchemaResourceGroupEnabledDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "resource_group_enabled"),
+		"Whether the resource group is enabled.",
+		[]string{"resource_group", "type"}, nil,
+	)
+	infoSchemaResourceGroupVCPUsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "resource_group_vcpus"),
+		"The number of virtual CPUs the threads of the resource group can run on.",
+		[]string{"resource_group", "type"}, nil,
+	)
+	infoSchemaResourceGroupThreadPriorityDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "resource_group_thread_priority"),
+		"The priority of the threads of the resource group, from -20 (highest) to 19 (lowest).",
+		[]string{"resource_group", "type"}, nil,
+	)
+	infoSchemaResourceGroupThreadsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "resource_group_threads"),
+		"The number of threads assigned to the resource group by thread type.",
+		[]string{"resource_group", "thread_type"}, nil,
+	)
+)
+
+// ScrapeResourceGroups collects from `information_schema.resource_groups`
+// and `performance_schema.threads`.
+type ScrapeResourceGroups struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeResourceGroups) Name() string {
+	return "info_schema.resource_groups"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeResourceGroups) Help() string {
+	return "Collect resource groups from information_schema.resource_groups and their threads from performance_schema.threads"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeResourceGroups) Version() float64 {
+	return 8.0
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeResourceGroups) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	groupRows, err := db.QueryContext(ctx, resourceGroupsQuery)
+	if err != nil {
+		return err
+	}
+	defer groupRows.Close()
+
+	var (
+		name, groupType, vcpuIDs string
+		enabled, priority        float64
+	)
+	for groupRows.Next() {
+		if err := groupRows.Scan(&name, &groupType, &enabled, &vcpuIDs, &priority); err != nil {
+			return err
+		}
+		ch <- prometheus.MustNewConstMetric(infoSchemaResourceGroupEnabledDesc, prometheus.GaugeValue, enabled, name, groupType)
+		if vcpus, ok := countVCPUs(vcpuIDs); ok {
+			ch <- prometheus.MustNewConstMetric(infoSchemaResourceGroupVCPUsDesc, prometheus.GaugeValue, vcpus, name, groupType)
+		}
+		ch <- prometheus.MustNewConstMetric(infoSchemaResourceGroupThreadPriorityDesc, prometheus.GaugeValue, priority, name, groupType)
+	}
+	if err := groupRows.Err(); err != nil {
+		return err
+	}
+
+	threadRows, err := db.QueryContext(ctx, resourceGroupThreadsQuery)
+	if err != nil {
+		return err
+	}
+	defer threadRows.Close()
+
+	var (
+		threadType string
+		count      float64
+	)
+	for threadRows.Next() {
+		if err := threadRows.Scan(&name, &threadType, &count); err != nil {
+			return err
+		}
+		ch <- prometheus.MustNewConstMetric(infoSchemaResourceGroupThreadsDesc, prometheus.GaugeValue, count, name, threadType)
+	}
+	return threadRows.Err()
+}
+
+// countVCPUs returns the number of CPUs of a VCPU_IDS list of CPU numbers
+// and ranges, such as "0-3,6". An empty list means all CPUs, which are not
+// counted.
+func countVCPUs(ids string) (float64, bool) {
+	var count float64
+	for _, part := range strings.Split(ids, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		first, last, isRange := strings.Cut(part, "-")
+		from, err := strconv.Atoi(strings.TrimSpace(first))
+		if err != nil {
+			return 0, false
+		}
+		to := from
+		if isRange {
+			if to, err = strconv.Atoi(strings.TrimSpace(last)); err != nil || to < from {
+				return 0, false
+			}
+		}
+		count += float64(to - from + 1)
+	}
+	return count, count > 0
+}
+
+// check interface
+var _ Scraper = ScrapeResourceGroups{}
@@ -0,0 +1,85 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeResourceGroups(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(resourceGroupsQuery)).WillReturnRows(
+		sqlmock.NewRows([]string{"RESOURCE_GROUP_NAME", "RESOURCE_GROUP_TYPE", "RESOURCE_GROUP_ENABLED", "VCPU_IDS", "THREAD_PRIORITY"}).
+			AddRow("USR_default", "USER", 1, "", 0).
+			AddRow("batch", "USER", 1, "0-3,6", 10))
+	mock.ExpectQuery(sanitizeQuery(resourceGroupThreadsQuery)).WillReturnRows(
+		sqlmock.NewRows([]string{"RESOURCE_GROUP", "TYPE", "COUNT(*)"}).
+			AddRow("SYS_default", "BACKGROUND", 40).
+			AddRow("USR_default", "FOREGROUND", 12).
+			AddRow("batch", "FOREGROUND", 3))
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeResourceGroups{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{"resource_group": "USR_default", "type": "USER"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"resource_group": "USR_default", "type": "USER"}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"resource_group": "batch", "type": "USER"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"resource_group": "batch", "type": "USER"}, value: 5, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"resource_group": "batch", "type": "USER"}, value: 10, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"resource_group": "SYS_default", "thread_type": "BACKGROUND"}, value: 40, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"resource_group": "USR_default", "thread_type": "FOREGROUND"}, value: 12, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"resource_group": "batch", "thread_type": "FOREGROUND"}, value: 3, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestCountVCPUs(t *testing.T) {
+	convey.Convey("VCPU_IDS lists are counted", t, func() {
+		count, ok := countVCPUs("0-3,6")
+		convey.So(ok, convey.ShouldBeTrue)
+		convey.So(count, convey.ShouldEqual, 5)
+		_, ok = countVCPUs("")
+		convey.So(ok, convey.ShouldBeFalse)
+		_, ok = countVCPUs("3-1")
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+}
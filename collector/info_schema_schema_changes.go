@@ -0,0 +1,186 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Detect schema changes from checksums of `information_schema` definitions.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// schemaDefinitionsQuery counts the tables, columns and indexes of every
+// schema along with an order independent checksum of their definitions.
+const schemaDefinitionsQuery = `
+	SELECT TABLE_SCHEMA, 'tables', COUNT(*),
+	       SUM(CRC32(CONCAT_WS(':', TABLE_NAME, TABLE_TYPE, IFNULL(ENGINE, ''))))
+	  FROM information_schema.tables
+	  WHERE TABLE_SCHEMA NOT IN ('mysql', 'performance_schema', 'information_schema', 'sys')
+	  GROUP BY TABLE_SCHEMA
+	UNION ALL
+	SELECT TABLE_SCHEMA, 'columns', COUNT(*),
+	       SUM(CRC32(CONCAT_WS(':', TABLE_NAME, COLUMN_NAME, ORDINAL_POSITION, COLUMN_TYPE, IS_NULLABLE, IFNULL(COLUMN_DEFAULT, 'NULL'), EXTRA)))
+	  FROM information_schema.columns
+	  WHERE TABLE_SCHEMA NOT IN ('mysql', 'performance_schema', 'information_schema', 'sys')
+	  GROUP BY TABLE_SCHEMA
+	UNION ALL
+	SELECT TABLE_SCHEMA, 'indexes', COUNT(DISTINCT TABLE_NAME, INDEX_NAME),
+	       SUM(CRC32(CONCAT_WS(':', TABLE_NAME, INDEX_NAME, SEQ_IN_INDEX, COLUMN_NAME, NON_UNIQUE, INDEX_TYPE)))
+	  FROM information_schema.statistics
+	  WHERE TABLE_SCHEMA NOT IN ('mysql', 'performance_schema', 'information_schema', 'sys')
+	  GROUP BY TABLE_SCHEMA
+	`
+
+// Metric descriptors.
+var (
+	schemaChangeDetectedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "schema", "change_detected_total"),
+		"Number of scrapes which found the tables, columns or indexes of the schema changed since the previous scrape.",
+		[]string{"schema"}, nil,
+	)
+	schemaDefinitionObjectsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "schema", "definition_objects"),
+		"The number of tables, columns and indexes in the schema.",
+		[]string{"schema", "type"}, nil,
+	)
+)
+
+// schemaDefinition is the count and checksum of objects of a type.
+type schemaDefinition struct {
+	count    uint64
+	checksum string
+}
+
+// schemaChangeTracker holds the definitions of the previous scrape and the
+// number of changes by schema.
+type schemaChangeTracker struct {
+	mtx         sync.Mutex
+	definitions map[string]map[string]schemaDefinition
+	changes     map[string]float64
+}
+
+var schemaChanges = &schemaChangeTracker{changes: map[string]float64{}}
+
+// update compares definitions to the previous scrape and returns the
+// number of changes by schema. The first scrape only records a baseline.
+// Created and dropped schemas count as a change.
+func (t *schemaChangeTracker) update(definitions map[string]map[string]schemaDefinition, logger log.Logger) map[string]float64 {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	if t.definitions != nil {
+		for schema, defs := range definitions {
+			if !schemaDefinitionsEqual(t.definitions[schema], defs) {
+				level.Info(logger).Log("msg", "Schema change detected", "schema", schema)
+				t.changes[schema]++
+			}
+		}
+		for schema := range t.definitions {
+			if _, ok := definitions[schema]; !ok {
+				level.Info(logger).Log("msg", "Schema dropped", "schema", schema)
+				t.changes[schema]++
+			}
+		}
+	}
+	for schema := range definitions {
+		if _, ok := t.changes[schema]; !ok {
+			t.changes[schema] = 0
+		}
+	}
+	t.definitions = definitions
+
+	res := make(map[string]float64, len(t.changes))
+	for schema, changes := range t.changes {
+		res[schema] = changes
+	}
+	return res
+}
+
+func schemaDefinitionsEqual(a, b map[string]schemaDefinition) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for objectType, def := range a {
+		if b[objectType] != def {
+			return false
+		}
+	}
+	return true
+}
+
+// ScrapeSchemaChanges detects schema changes between scrapes.
+type ScrapeSchemaChanges struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeSchemaChanges) Name() string {
+	return informationSchema + ".schema_changes"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeSchemaChanges) Help() string {
+	return "Detect changes of the tables, columns and indexes per schema between scrapes"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeSchemaChanges) Version() float64 {
+	return 5.1
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeSchemaChanges) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	rows, err := db.QueryContext(ctx, schemaDefinitionsQuery)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var (
+		schema     string
+		objectType string
+		def        schemaDefinition
+	)
+	definitions := map[string]map[string]schemaDefinition{}
+	for rows.Next() {
+		if err := rows.Scan(&schema, &objectType, &def.count, &def.checksum); err != nil {
+			return err
+		}
+		if definitions[schema] == nil {
+			definitions[schema] = map[string]schemaDefinition{}
+		}
+		definitions[schema][objectType] = def
+		ch <- prometheus.MustNewConstMetric(
+			schemaDefinitionObjectsDesc, prometheus.GaugeValue, float64(def.count),
+			schema, objectType,
+		)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if catalogRun(ctx) {
+		return nil
+	}
+
+	for schema, changes := range schemaChanges.update(definitions, logger) {
+		ch <- prometheus.MustNewConstMetric(schemaChangeDetectedDesc, prometheus.CounterValue, changes, schema)
+	}
+	return nil
+}
+
+// check interface
+var _ Scraper = ScrapeSchemaChanges{}
@@ -0,0 +1,73 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeSchemaChanges(t *testing.T) {
+	schemaChanges = &schemaChangeTracker{changes: map[string]float64{}}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"TABLE_SCHEMA", "tables", "COUNT(*)", "checksum"}
+	mock.ExpectQuery(sanitizeQuery(schemaDefinitionsQuery)).WillReturnRows(sqlmock.NewRows(columns).
+		AddRow("app", "tables", 2, "1111").
+		AddRow("app", "columns", 10, "2222").
+		AddRow("old", "tables", 1, "3333"))
+	// A column was added to app and the old schema dropped.
+	mock.ExpectQuery(sanitizeQuery(schemaDefinitionsQuery)).WillReturnRows(sqlmock.NewRows(columns).
+		AddRow("app", "tables", 2, "1111").
+		AddRow("app", "columns", 11, "2345"))
+
+	scrape := func() map[string]float64 {
+		ch := make(chan prometheus.Metric)
+		go func() {
+			if err := (ScrapeSchemaChanges{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+				t.Errorf("error calling function on test: %s", err)
+			}
+			close(ch)
+		}()
+		changes := map[string]float64{}
+		for m := range ch {
+			got := readMetric(m)
+			if got.metricType == dto.MetricType_COUNTER {
+				changes[got.labels["schema"]] = got.value
+			}
+		}
+		return changes
+	}
+
+	convey.Convey("Schema changes are counted from the second scrape", t, func() {
+		convey.So(scrape(), convey.ShouldResemble, map[string]float64{"app": 0, "old": 0})
+		convey.So(scrape(), convey.ShouldResemble, map[string]float64{"app": 1, "old": 1})
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
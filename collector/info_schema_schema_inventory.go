@@ -0,0 +1,139 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape schema object counts from `information_schema`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	schemaInventoryObjectsQuery = `
+	SELECT TABLE_SCHEMA, CASE TABLE_TYPE WHEN 'VIEW' THEN 'view' ELSE 'table' END AS TYPE, COUNT(*)
+	  FROM information_schema.tables
+	  WHERE TABLE_SCHEMA NOT IN ('mysql', 'performance_schema', 'information_schema', 'sys')
+	  GROUP BY TABLE_SCHEMA, TYPE
+	UNION ALL
+	SELECT TRIGGER_SCHEMA, 'trigger', COUNT(*)
+	  FROM information_schema.triggers
+	  WHERE TRIGGER_SCHEMA NOT IN ('mysql', 'performance_schema', 'information_schema', 'sys')
+	  GROUP BY TRIGGER_SCHEMA
+	UNION ALL
+	SELECT ROUTINE_SCHEMA, LOWER(ROUTINE_TYPE), COUNT(*)
+	  FROM information_schema.routines
+	  WHERE ROUTINE_SCHEMA NOT IN ('mysql', 'performance_schema', 'information_schema', 'sys')
+	  GROUP BY ROUTINE_SCHEMA, ROUTINE_TYPE
+	UNION ALL
+	SELECT CONSTRAINT_SCHEMA, 'foreign_key', COUNT(*)
+	  FROM information_schema.referential_constraints
+	  WHERE CONSTRAINT_SCHEMA NOT IN ('mysql', 'performance_schema', 'information_schema', 'sys')
+	  GROUP BY CONSTRAINT_SCHEMA
+	`
+	schemaInventoryNoPrimaryKeyQuery = `
+	SELECT t.TABLE_SCHEMA, COUNT(*)
+	  FROM information_schema.tables t
+	  LEFT JOIN information_schema.table_constraints c
+	    ON c.TABLE_SCHEMA = t.TABLE_SCHEMA
+	    AND c.TABLE_NAME = t.TABLE_NAME
+	    AND c.CONSTRAINT_TYPE = 'PRIMARY KEY'
+	  WHERE t.TABLE_TYPE = 'BASE TABLE'
+	    AND t.TABLE_SCHEMA NOT IN ('mysql', 'performance_schema', 'information_schema', 'sys')
+	    AND c.CONSTRAINT_NAME IS NULL
+	  GROUP BY t.TABLE_SCHEMA
+	`
+)
+
+// Metric descriptors.
+var (
+	infoSchemaSchemaObjectsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "schema_objects"),
+		"The number of objects in the schema by type: table, view, trigger, procedure, function and foreign_key.",
+		[]string{"schema", "type"}, nil,
+	)
+	infoSchemaSchemaTablesWithoutPrimaryKeyDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "schema_tables_without_primary_key"),
+		"The number of base tables in the schema without a primary key.",
+		[]string{"schema"}, nil,
+	)
+)
+
+// ScrapeSchemaInventory collects schema object counts from `information_schema`.
+type ScrapeSchemaInventory struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeSchemaInventory) Name() string {
+	return informationSchema + ".schema_inventory"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeSchemaInventory) Help() string {
+	return "Collect counts of tables, views, triggers, routines, foreign keys and tables without primary key per schema"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeSchemaInventory) Version() float64 {
+	return 5.1
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeSchemaInventory) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	objectRows, err := db.QueryContext(ctx, schemaInventoryObjectsQuery)
+	if err != nil {
+		return err
+	}
+	defer objectRows.Close()
+
+	var (
+		schema     string
+		objectType string
+		count      uint64
+	)
+	for objectRows.Next() {
+		if err := objectRows.Scan(&schema, &objectType, &count); err != nil {
+			return err
+		}
+		ch <- prometheus.MustNewConstMetric(
+			infoSchemaSchemaObjectsDesc, prometheus.GaugeValue, float64(count),
+			schema, objectType,
+		)
+	}
+	if err := objectRows.Err(); err != nil {
+		return err
+	}
+
+	noPrimaryKeyRows, err := db.QueryContext(ctx, schemaInventoryNoPrimaryKeyQuery)
+	if err != nil {
+		return err
+	}
+	defer noPrimaryKeyRows.Close()
+
+	for noPrimaryKeyRows.Next() {
+		if err := noPrimaryKeyRows.Scan(&schema, &count); err != nil {
+			return err
+		}
+		ch <- prometheus.MustNewConstMetric(
+			infoSchemaSchemaTablesWithoutPrimaryKeyDesc, prometheus.GaugeValue, float64(count),
+			schema,
+		)
+	}
+	return noPrimaryKeyRows.Err()
+}
+
+// check interface
+var _ Scraper = ScrapeSchemaInventory{}
@@ -0,0 +1,75 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeSchemaInventory(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"TABLE_SCHEMA", "TYPE", "COUNT(*)"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("shop", "table", 12).
+		AddRow("shop", "view", 2).
+		AddRow("shop", "trigger", 1).
+		AddRow("shop", "procedure", 3).
+		AddRow("shop", "foreign_key", 4)
+	mock.ExpectQuery(sanitizeQuery(schemaInventoryObjectsQuery)).WillReturnRows(rows)
+
+	columns = []string{"TABLE_SCHEMA", "COUNT(*)"}
+	rows = sqlmock.NewRows(columns).
+		AddRow("shop", 1)
+	mock.ExpectQuery(sanitizeQuery(schemaInventoryNoPrimaryKeyQuery)).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeSchemaInventory{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{"schema": "shop", "type": "table"}, value: 12, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"schema": "shop", "type": "view"}, value: 2, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"schema": "shop", "type": "trigger"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"schema": "shop", "type": "procedure"}, value: 3, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"schema": "shop", "type": "foreign_key"}, value: 4, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"schema": "shop"}, value: 1, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
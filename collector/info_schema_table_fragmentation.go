@@ -0,0 +1,118 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape table fragmentation estimates from `information_schema.tables`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const tableFragmentationQuery = `
+	SELECT
+	    TABLE_SCHEMA,
+	    TABLE_NAME,
+	    DATA_FREE,
+	    DATA_LENGTH + INDEX_LENGTH AS SIZE
+	  FROM information_schema.tables
+	  WHERE TABLE_TYPE = 'BASE TABLE'
+	    AND TABLE_SCHEMA NOT IN ('mysql', 'performance_schema', 'information_schema', 'sys')
+	    AND DATA_LENGTH + INDEX_LENGTH >= %d
+	    AND DATA_LENGTH + INDEX_LENGTH > 0
+	    AND DATA_FREE / (DATA_LENGTH + INDEX_LENGTH) >= %g
+	  ORDER BY DATA_FREE / (DATA_LENGTH + INDEX_LENGTH) DESC
+	  LIMIT %d
+	`
+
+// Tunable flags.
+var (
+	tableFragmentationMinSize = kingpin.Flag(
+		"collect.info_schema.table_fragmentation.min_size",
+		"Minimum size of data and indexes in bytes of the tables to estimate fragmentation for",
+	).Default("104857600").Int64()
+	tableFragmentationMinRatio = kingpin.Flag(
+		"collect.info_schema.table_fragmentation.min_ratio",
+		"Minimum ratio of free space to table size of the tables to report",
+	).Default("0.1").Float64()
+	tableFragmentationLimit = kingpin.Flag(
+		"collect.info_schema.table_fragmentation.limit",
+		"Limit the number of tables by fragmentation ratio",
+	).Default("100").Int()
+)
+
+// Metric descriptors.
+var (
+	infoSchemaTableFragmentationRatioDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "table_fragmentation_ratio"),
+		"Estimated fragmentation of the table as data_free / (data_length + index_length), candidates for OPTIMIZE TABLE.",
+		[]string{"schema", "table"}, nil,
+	)
+)
+
+// ScrapeTableFragmentation collects table fragmentation estimates from `information_schema.tables`.
+type ScrapeTableFragmentation struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeTableFragmentation) Name() string {
+	return informationSchema + ".table_fragmentation"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeTableFragmentation) Help() string {
+	return "Collect table fragmentation estimates from information_schema.tables"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeTableFragmentation) Version() float64 {
+	return 5.1
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeTableFragmentation) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	query := fmt.Sprintf(tableFragmentationQuery, *tableFragmentationMinSize, *tableFragmentationMinRatio, *tableFragmentationLimit)
+	fragmentationRows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer fragmentationRows.Close()
+
+	var (
+		tableSchema string
+		tableName   string
+		dataFree    uint64
+		size        uint64
+	)
+	for fragmentationRows.Next() {
+		if err := fragmentationRows.Scan(&tableSchema, &tableName, &dataFree, &size); err != nil {
+			return err
+		}
+		if size == 0 {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(
+			infoSchemaTableFragmentationRatioDesc, prometheus.GaugeValue, float64(dataFree)/float64(size),
+			tableSchema, tableName,
+		)
+	}
+	return fragmentationRows.Err()
+}
+
+// check interface
+var _ Scraper = ScrapeTableFragmentation{}
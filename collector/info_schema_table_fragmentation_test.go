@@ -0,0 +1,74 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeTableFragmentation(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.info_schema.table_fragmentation.min_size=1024",
+		"--collect.info_schema.table_fragmentation.min_ratio=0.2",
+		"--collect.info_schema.table_fragmentation.limit=10",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"TABLE_SCHEMA", "TABLE_NAME", "DATA_FREE", "SIZE"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("db", "orders", 6000, 10000).
+		AddRow("db", "events", 2500, 10000)
+	mock.ExpectQuery(sanitizeQuery(fmt.Sprintf(tableFragmentationQuery, 1024, 0.2, 10))).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeTableFragmentation{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{"schema": "db", "table": "orders"}, value: 0.6, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"schema": "db", "table": "events"}, value: 0.25, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -120,29 +120,23 @@ func (ScrapeTableSchema) Scrape(ctx context.Context, db *sql.DB, ch chan<- prome
 		dbList = strings.Split(*tableSchemaDatabases, ",")
 	}
 
+	var (
+		tableSchema   string
+		tableName     string
+		tableType     string
+		engine        string
+		version       uint64
+		rowFormat     string
+		tableRows     uint64
+		dataLength    uint64
+		indexLength   uint64
+		dataFree      uint64
+		createOptions string
+	)
+	scanner := newRowScanner(ScrapeTableSchema{}.Name(), logger)
 	for _, database := range dbList {
-		tableSchemaRows, err := db.QueryContext(ctx, fmt.Sprintf(tableSchemaQuery, database))
-		if err != nil {
-			return err
-		}
-		defer tableSchemaRows.Close()
-
-		var (
-			tableSchema   string
-			tableName     string
-			tableType     string
-			engine        string
-			version       uint64
-			rowFormat     string
-			tableRows     uint64
-			dataLength    uint64
-			indexLength   uint64
-			dataFree      uint64
-			createOptions string
-		)
-
-		for tableSchemaRows.Next() {
-			err = tableSchemaRows.Scan(
+		err := scanner.scan(ctx, db, func(rows *sql.Rows) error {
+			if err := rows.Scan(
 				&tableSchema,
 				&tableName,
 				&tableType,
@@ -154,8 +148,7 @@ func (ScrapeTableSchema) Scrape(ctx context.Context, db *sql.DB, ch chan<- prome
 				&indexLength,
 				&dataFree,
 				&createOptions,
-			)
-			if err != nil {
+			); err != nil {
 				return err
 			}
 			ch <- prometheus.MustNewConstMetric(
@@ -178,6 +171,10 @@ func (ScrapeTableSchema) Scrape(ctx context.Context, db *sql.DB, ch chan<- prome
 				infoSchemaTablesSizeDesc, prometheus.GaugeValue, float64(dataFree),
 				tableSchema, tableName, "data_free",
 			)
+			return nil
+		}, fmt.Sprintf(tableSchemaQuery, database))
+		if err != nil {
+			return err
 		}
 	}
 
@@ -0,0 +1,122 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape InnoDB tables without primary key from `information_schema`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// tablesWithoutPKQuery lists InnoDB tables without a primary key or a
+// unique index on only NOT NULL columns.
+const tablesWithoutPKQuery = `
+	SELECT
+	    t.TABLE_SCHEMA,
+	    t.TABLE_NAME
+	  FROM information_schema.tables t
+	  WHERE t.ENGINE = 'InnoDB'
+	    AND t.TABLE_TYPE = 'BASE TABLE'
+	    AND t.TABLE_SCHEMA NOT IN ('mysql', 'performance_schema', 'information_schema', 'sys')
+	    AND NOT EXISTS (
+	      SELECT 1
+	        FROM information_schema.statistics s
+	        WHERE s.TABLE_SCHEMA = t.TABLE_SCHEMA
+	          AND s.TABLE_NAME = t.TABLE_NAME
+	          AND s.NON_UNIQUE = 0
+	        GROUP BY s.INDEX_NAME
+	        HAVING SUM(s.NULLABLE = 'YES') = 0
+	    )
+	  ORDER BY t.TABLE_SCHEMA, t.TABLE_NAME
+	`
+
+// Tunable flags.
+var (
+	tablesWithoutPKInfo = kingpin.Flag(
+		"collect.info_schema.tables_without_pk.info",
+		"Expose an info metric for every table without primary key",
+	).Default("false").Bool()
+)
+
+// Metric descriptors.
+var (
+	infoSchemaTablesWithoutPKDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "tables_without_pk"),
+		"The number of InnoDB tables without a primary key or a unique NOT NULL key.",
+		nil, nil,
+	)
+	infoSchemaTableWithoutPKInfoDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "table_without_pk_info"),
+		"An InnoDB table without a primary key or a unique NOT NULL key.",
+		[]string{"schema", "table"}, nil,
+	)
+)
+
+// ScrapeTablesWithoutPK collects InnoDB tables without primary key from `information_schema`.
+type ScrapeTablesWithoutPK struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeTablesWithoutPK) Name() string {
+	return informationSchema + ".tables_without_pk"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeTablesWithoutPK) Help() string {
+	return "Collect InnoDB tables without a primary key or a unique NOT NULL key from information_schema"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeTablesWithoutPK) Version() float64 {
+	return 5.1
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeTablesWithoutPK) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	tableRows, err := db.QueryContext(ctx, tablesWithoutPKQuery)
+	if err != nil {
+		return err
+	}
+	defer tableRows.Close()
+
+	var (
+		tableSchema string
+		tableName   string
+		count       int
+	)
+	for tableRows.Next() {
+		if err := tableRows.Scan(&tableSchema, &tableName); err != nil {
+			return err
+		}
+		count++
+		if *tablesWithoutPKInfo {
+			ch <- prometheus.MustNewConstMetric(
+				infoSchemaTableWithoutPKInfoDesc, prometheus.GaugeValue, 1,
+				tableSchema, tableName,
+			)
+		}
+	}
+	if err := tableRows.Err(); err != nil {
+		return err
+	}
+	ch <- prometheus.MustNewConstMetric(infoSchemaTablesWithoutPKDesc, prometheus.GaugeValue, float64(count))
+	return nil
+}
+
+// check interface
+var _ Scraper = ScrapeTablesWithoutPK{}
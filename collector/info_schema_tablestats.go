@@ -84,12 +84,6 @@ func (ScrapeTableStat) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometh
 		return nil
 	}
 
-	informationSchemaTableStatisticsRows, err := db.QueryContext(ctx, tableStatQuery)
-	if err != nil {
-		return err
-	}
-	defer informationSchemaTableStatisticsRows.Close()
-
 	var (
 		tableSchema         string
 		tableName           string
@@ -97,16 +91,14 @@ func (ScrapeTableStat) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometh
 		rowsChanged         uint64
 		rowsChangedXIndexes uint64
 	)
-
-	for informationSchemaTableStatisticsRows.Next() {
-		err = informationSchemaTableStatisticsRows.Scan(
+	return newRowScanner(ScrapeTableStat{}.Name(), logger).scan(ctx, db, func(rows *sql.Rows) error {
+		if err := rows.Scan(
 			&tableSchema,
 			&tableName,
 			&rowsRead,
 			&rowsChanged,
 			&rowsChangedXIndexes,
-		)
-		if err != nil {
+		); err != nil {
 			return err
 		}
 		ch <- prometheus.MustNewConstMetric(
@@ -121,8 +113,8 @@ func (ScrapeTableStat) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometh
 			infoSchemaTableStatsRowsChangedXIndexesDesc, prometheus.CounterValue, float64(rowsChangedXIndexes),
 			tableSchema, tableName,
 		)
-	}
-	return nil
+		return nil
+	}, tableStatQuery)
 }
 
 // check interface
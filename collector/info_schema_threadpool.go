@@ -0,0 +1,137 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape thread pool status and `information_schema.THREADPOOL_QUEUES`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	MySQL "github.com/go-sql-driver/mysql"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	threadPoolStatusQuery = `SHOW GLOBAL STATUS WHERE Variable_name IN ('Threadpool_threads', 'Threadpool_idle_threads')`
+	// THREADPOOL_QUEUES only exists on MariaDB >= 10.5.
+	threadPoolQueuesQuery = `
+		SELECT GROUP_ID, PRIORITY, COUNT(*), MAX(QUEUEING_TIME_MICROSECONDS)
+		  FROM information_schema.THREADPOOL_QUEUES
+		  GROUP BY GROUP_ID, PRIORITY
+		`
+)
+
+// Metric descriptors.
+var (
+	infoSchemaThreadPoolThreadsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "threadpool_threads"),
+		"Number of threads in the thread pool.",
+		[]string{}, nil,
+	)
+	infoSchemaThreadPoolIdleThreadsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "threadpool_idle_threads"),
+		"Number of inactive threads in the thread pool.",
+		[]string{}, nil,
+	)
+	infoSchemaThreadPoolQueuedRequestsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "threadpool_queued_requests"),
+		"Number of requests waiting in the thread pool queues.",
+		[]string{"group_id", "priority"}, nil,
+	)
+	infoSchemaThreadPoolQueueMaxWaitDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "threadpool_queue_max_wait_seconds"),
+		"Longest time in seconds a request has been waiting in the thread pool queues.",
+		[]string{"group_id", "priority"}, nil,
+	)
+)
+
+// ScrapeThreadPool collects thread pool metrics.
+type ScrapeThreadPool struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeThreadPool) Name() string {
+	return informationSchema + ".threadpool"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeThreadPool) Help() string {
+	return "Collect thread pool metrics from SHOW GLOBAL STATUS and information_schema.THREADPOOL_QUEUES"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeThreadPool) Version() float64 {
+	return 5.5
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeThreadPool) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	statusRows, err := db.QueryContext(ctx, threadPoolStatusQuery)
+	if err != nil {
+		return err
+	}
+	defer statusRows.Close()
+
+	var (
+		key string
+		val float64
+	)
+	for statusRows.Next() {
+		if err := statusRows.Scan(&key, &val); err != nil {
+			return err
+		}
+		switch strings.ToLower(key) {
+		case "threadpool_threads":
+			ch <- prometheus.MustNewConstMetric(infoSchemaThreadPoolThreadsDesc, prometheus.GaugeValue, val)
+		case "threadpool_idle_threads":
+			ch <- prometheus.MustNewConstMetric(infoSchemaThreadPoolIdleThreadsDesc, prometheus.GaugeValue, val)
+		}
+	}
+	if err := statusRows.Err(); err != nil {
+		return err
+	}
+
+	queueRows, err := db.QueryContext(ctx, threadPoolQueuesQuery)
+	if err != nil {
+		if mysqlErr, ok := err.(*MySQL.MySQLError); ok {
+			// Check for error 1109: Unknown table
+			if mysqlErr.Number == 1109 {
+				level.Debug(logger).Log("msg", "information_schema.THREADPOOL_QUEUES is not available.")
+				return nil
+			}
+		}
+		return err
+	}
+	defer queueRows.Close()
+
+	var (
+		groupID, priority string
+		queued, maxWait   float64
+	)
+	for queueRows.Next() {
+		if err := queueRows.Scan(&groupID, &priority, &queued, &maxWait); err != nil {
+			return err
+		}
+		priority = strings.ToLower(priority)
+		ch <- prometheus.MustNewConstMetric(infoSchemaThreadPoolQueuedRequestsDesc, prometheus.GaugeValue, queued, groupID, priority)
+		ch <- prometheus.MustNewConstMetric(infoSchemaThreadPoolQueueMaxWaitDesc, prometheus.GaugeValue, maxWait/1e6, groupID, priority)
+	}
+	return queueRows.Err()
+}
+
+// check interface
+var _ Scraper = ScrapeThreadPool{}
@@ -0,0 +1,105 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	MySQL "github.com/go-sql-driver/mysql"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeThreadPool(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	statusRows := sqlmock.NewRows([]string{"Variable_name", "Value"}).
+		AddRow("Threadpool_idle_threads", "3").
+		AddRow("Threadpool_threads", "8")
+	mock.ExpectQuery(sanitizeQuery(threadPoolStatusQuery)).WillReturnRows(statusRows)
+
+	queueRows := sqlmock.NewRows([]string{"GROUP_ID", "PRIORITY", "COUNT(*)", "MAX(QUEUEING_TIME_MICROSECONDS)"}).
+		AddRow("0", "HIGH", "2", "1500000").
+		AddRow("1", "LOW", "5", "250000")
+	mock.ExpectQuery(sanitizeQuery(threadPoolQueuesQuery)).WillReturnRows(queueRows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeThreadPool{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{}, value: 3, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 8, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"group_id": "0", "priority": "high"}, value: 2, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"group_id": "0", "priority": "high"}, value: 1.5, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"group_id": "1", "priority": "low"}, value: 5, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"group_id": "1", "priority": "low"}, value: 0.25, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeThreadPoolNoQueues(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	statusRows := sqlmock.NewRows([]string{"Variable_name", "Value"}).
+		AddRow("Threadpool_threads", "4")
+	mock.ExpectQuery(sanitizeQuery(threadPoolStatusQuery)).WillReturnRows(statusRows)
+	mock.ExpectQuery(sanitizeQuery(threadPoolQueuesQuery)).WillReturnError(&MySQL.MySQLError{Number: 1109})
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeThreadPool{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	convey.Convey("Metrics comparison", t, func() {
+		got := readMetric(<-ch)
+		convey.So(got, convey.ShouldResemble, MetricResult{labels: labelMap{}, value: 4, metricType: dto.MetricType_GAUGE})
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -0,0 +1,202 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape the InnoDB Cluster topology from the `mysql_innodb_cluster_metadata` schema.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	MySQL "github.com/go-sql-driver/mysql"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// Subsystem.
+	innodbCluster = "innodb_cluster"
+	// Queries.
+	innodbClusterClustersQuery = `
+	SELECT
+	    cluster_id,
+	    cluster_name,
+	    IFNULL(cluster_type, ''),
+	    IFNULL(primary_mode, '')
+	  FROM mysql_innodb_cluster_metadata.v2_clusters
+	`
+	// Roles and states are only known for members of the group of this server.
+	innodbClusterInstancesQuery = `
+	SELECT
+	    c.cluster_name,
+	    i.address,
+	    IFNULL(i.mysql_server_uuid, ''),
+	    IFNULL(m.MEMBER_ROLE, ''),
+	    IFNULL(m.MEMBER_STATE, '')
+	  FROM mysql_innodb_cluster_metadata.v2_instances i
+	  JOIN mysql_innodb_cluster_metadata.v2_clusters c USING (cluster_id)
+	  LEFT JOIN performance_schema.replication_group_members m
+	    ON m.MEMBER_ID = i.mysql_server_uuid
+	`
+	innodbClusterRoutersQuery = `
+	SELECT
+	    IFNULL(c.cluster_name, ''),
+	    r.router_name,
+	    r.address,
+	    IFNULL(r.version, ''),
+	    IFNULL(UNIX_TIMESTAMP(r.last_check_in), 0)
+	  FROM mysql_innodb_cluster_metadata.v2_routers r
+	  LEFT JOIN mysql_innodb_cluster_metadata.v2_clusters c USING (cluster_id)
+	`
+)
+
+// Metric descriptors.
+var (
+	innodbClusterInfoDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, innodbCluster, "info"),
+		"Clusters registered in the InnoDB Cluster metadata.",
+		[]string{"cluster_id", "cluster_name", "cluster_type", "primary_mode"}, nil,
+	)
+	innodbClusterInstancesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, innodbCluster, "instances"),
+		"The number of instances registered for a cluster in the InnoDB Cluster metadata.",
+		[]string{"cluster_name"}, nil,
+	)
+	innodbClusterInstanceInfoDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, innodbCluster, "instance_info"),
+		"Instances registered in the InnoDB Cluster metadata with their group role and state.",
+		[]string{"cluster_name", "address", "server_uuid", "role", "state"}, nil,
+	)
+	innodbClusterRouterInfoDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, innodbCluster, "router_info"),
+		"MySQL Router instances registered in the InnoDB Cluster metadata.",
+		[]string{"cluster_name", "router_name", "address", "version"}, nil,
+	)
+	innodbClusterRouterLastCheckInDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, innodbCluster, "router_last_check_in_timestamp_seconds"),
+		"The last time a MySQL Router checked in to the InnoDB Cluster metadata.",
+		[]string{"cluster_name", "router_name", "address"}, nil,
+	)
+)
+
+// ScrapeInnodbClusterMetadata collects from the `mysql_innodb_cluster_metadata` schema.
+type ScrapeInnodbClusterMetadata struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeInnodbClusterMetadata) Name() string {
+	return "innodb_cluster_metadata"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeInnodbClusterMetadata) Help() string {
+	return "Collect InnoDB Cluster topology and MySQL Router registrations from mysql_innodb_cluster_metadata"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeInnodbClusterMetadata) Version() float64 {
+	return 8.0
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeInnodbClusterMetadata) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	clusterRows, err := db.QueryContext(ctx, innodbClusterClustersQuery)
+	if err != nil {
+		if mysqlErr, ok := err.(*MySQL.MySQLError); ok {
+			// Check for error 1146: Table doesn't exist
+			if mysqlErr.Number == 1146 {
+				level.Debug(logger).Log("msg", "mysql_innodb_cluster_metadata is not available.")
+				return nil
+			}
+		}
+		return err
+	}
+	defer clusterRows.Close()
+
+	var clusterID, clusterName, clusterType, primaryMode string
+	for clusterRows.Next() {
+		if err := clusterRows.Scan(&clusterID, &clusterName, &clusterType, &primaryMode); err != nil {
+			return err
+		}
+		ch <- prometheus.MustNewConstMetric(
+			innodbClusterInfoDesc, prometheus.GaugeValue, 1,
+			clusterID, clusterName, clusterType, primaryMode,
+		)
+	}
+	if err := clusterRows.Err(); err != nil {
+		return err
+	}
+
+	instanceRows, err := db.QueryContext(ctx, innodbClusterInstancesQuery)
+	if err != nil {
+		return err
+	}
+	defer instanceRows.Close()
+
+	var (
+		address, serverUUID, role, state string
+		clusterNames                     []string
+		instances                        = map[string]float64{}
+	)
+	for instanceRows.Next() {
+		if err := instanceRows.Scan(&clusterName, &address, &serverUUID, &role, &state); err != nil {
+			return err
+		}
+		if _, ok := instances[clusterName]; !ok {
+			clusterNames = append(clusterNames, clusterName)
+		}
+		instances[clusterName]++
+		ch <- prometheus.MustNewConstMetric(
+			innodbClusterInstanceInfoDesc, prometheus.GaugeValue, 1,
+			clusterName, address, serverUUID, role, state,
+		)
+	}
+	if err := instanceRows.Err(); err != nil {
+		return err
+	}
+	for _, name := range clusterNames {
+		ch <- prometheus.MustNewConstMetric(innodbClusterInstancesDesc, prometheus.GaugeValue, instances[name], name)
+	}
+
+	routerRows, err := db.QueryContext(ctx, innodbClusterRoutersQuery)
+	if err != nil {
+		return err
+	}
+	defer routerRows.Close()
+
+	var (
+		routerName, version string
+		lastCheckIn         float64
+	)
+	for routerRows.Next() {
+		if err := routerRows.Scan(&clusterName, &routerName, &address, &version, &lastCheckIn); err != nil {
+			return err
+		}
+		ch <- prometheus.MustNewConstMetric(
+			innodbClusterRouterInfoDesc, prometheus.GaugeValue, 1,
+			clusterName, routerName, address, version,
+		)
+		// Routers which never checked in have no last_check_in.
+		if lastCheckIn > 0 {
+			ch <- prometheus.MustNewConstMetric(
+				innodbClusterRouterLastCheckInDesc, prometheus.GaugeValue, lastCheckIn,
+				clusterName, routerName, address,
+			)
+		}
+	}
+	return routerRows.Err()
+}
+
+// check interface
+var _ Scraper = ScrapeInnodbClusterMetadata{}
@@ -0,0 +1,103 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	MySQL "github.com/go-sql-driver/mysql"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeInnodbClusterMetadata(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(innodbClusterClustersQuery)).WillReturnRows(
+		sqlmock.NewRows([]string{"cluster_id", "cluster_name", "cluster_type", "primary_mode"}).
+			AddRow("c1", "prod", "gr", "pm"))
+	mock.ExpectQuery(sanitizeQuery(innodbClusterInstancesQuery)).WillReturnRows(
+		sqlmock.NewRows([]string{"cluster_name", "address", "mysql_server_uuid", "MEMBER_ROLE", "MEMBER_STATE"}).
+			AddRow("prod", "db1:3306", "uuid1", "PRIMARY", "ONLINE").
+			AddRow("prod", "db2:3306", "uuid2", "SECONDARY", "ONLINE"))
+	mock.ExpectQuery(sanitizeQuery(innodbClusterRoutersQuery)).WillReturnRows(
+		sqlmock.NewRows([]string{"cluster_name", "router_name", "address", "version", "last_check_in"}).
+			AddRow("prod", "", "app1", "8.0.33", 1683722096).
+			AddRow("prod", "", "app2", "8.0.33", 0))
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeInnodbClusterMetadata{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{"cluster_id": "c1", "cluster_name": "prod", "cluster_type": "gr", "primary_mode": "pm"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"cluster_name": "prod", "address": "db1:3306", "server_uuid": "uuid1", "role": "PRIMARY", "state": "ONLINE"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"cluster_name": "prod", "address": "db2:3306", "server_uuid": "uuid2", "role": "SECONDARY", "state": "ONLINE"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"cluster_name": "prod"}, value: 2, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"cluster_name": "prod", "router_name": "", "address": "app1", "version": "8.0.33"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"cluster_name": "prod", "router_name": "", "address": "app1"}, value: 1683722096, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"cluster_name": "prod", "router_name": "", "address": "app2", "version": "8.0.33"}, value: 1, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeInnodbClusterMetadataNoSchema(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(innodbClusterClustersQuery)).WillReturnError(&MySQL.MySQLError{Number: 1146})
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeInnodbClusterMetadata{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	convey.Convey("No metrics without metadata schema", t, func() {
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
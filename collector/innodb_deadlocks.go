@@ -0,0 +1,191 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape InnoDB deadlocks from `SHOW ENGINE INNODB STATUS` and
+// `information_schema.innodb_metrics`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"strings"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	innodbDeadlocksQuery = `
+	SELECT count
+	  FROM information_schema.innodb_metrics
+	  WHERE name = 'lock_deadlocks'
+	`
+	statementDigestQuery = `SELECT STATEMENT_DIGEST(?), STATEMENT_DIGEST_TEXT(?)`
+)
+
+// Tunable flags.
+var (
+	innodbDeadlockStatements = kingpin.Flag(
+		"collect.innodb.deadlocks.statements",
+		"Expose the statement digests of the transactions in the latest deadlock, requires MySQL 8.0",
+	).Default("false").Bool()
+)
+
+var (
+	// *** (1) TRANSACTION:
+	innodbDeadlockTransactionRE = regexp.MustCompile(`^\*\*\* \((\d+)\) TRANSACTION:`)
+	// *** WE ROLL BACK TRANSACTION (1)
+	innodbDeadlockRollbackRE = regexp.MustCompile(`^\*\*\* WE ROLL BACK TRANSACTION \((\d+)\)`)
+)
+
+// Metric descriptors.
+var (
+	innodbLastDeadlockTimeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "innodb", "last_deadlock_timestamp_seconds"),
+		"Time of the latest deadlock from SHOW ENGINE INNODB STATUS.",
+		nil, nil,
+	)
+	innodbDeadlocksDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "innodb", "deadlocks_total"),
+		"Number of deadlocks from information_schema.innodb_metrics lock_deadlocks.",
+		nil, nil,
+	)
+	innodbLastDeadlockStatementDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "innodb", "last_deadlock_statement_info"),
+		"Statement digests of the transactions in the latest deadlock.",
+		[]string{"transaction", "digest", "digest_text", "rolled_back"}, nil,
+	)
+)
+
+// innodbDeadlockTransaction is a transaction of a deadlock.
+type innodbDeadlockTransaction struct {
+	id         string
+	statement  string
+	rolledBack bool
+}
+
+// ScrapeInnodbDeadlocks collects InnoDB deadlocks.
+type ScrapeInnodbDeadlocks struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeInnodbDeadlocks) Name() string {
+	return "innodb.deadlocks"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeInnodbDeadlocks) Help() string {
+	return "Collect InnoDB deadlocks from SHOW ENGINE INNODB STATUS and information_schema.innodb_metrics"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeInnodbDeadlocks) Version() float64 {
+	return 5.6
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeInnodbDeadlocks) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	status, err := innodbStatus(ctx, db)
+	if err != nil {
+		return err
+	}
+	if ts, ok := innodbStatusSectionTime(status, innodbStatusDeadlockSection); ok {
+		ch <- prometheus.MustNewConstMetric(innodbLastDeadlockTimeDesc, prometheus.GaugeValue, float64(ts.Unix()))
+	}
+
+	var deadlocks float64
+	err = db.QueryRowContext(ctx, innodbDeadlocksQuery).Scan(&deadlocks)
+	switch {
+	case err == sql.ErrNoRows:
+		level.Debug(logger).Log("msg", "lock_deadlocks not found in information_schema.innodb_metrics")
+	case err != nil:
+		return err
+	default:
+		ch <- prometheus.MustNewConstMetric(innodbDeadlocksDesc, prometheus.CounterValue, deadlocks)
+	}
+
+	if !*innodbDeadlockStatements {
+		return nil
+	}
+	for _, trx := range parseInnodbDeadlock(innodbStatusSection(status, innodbStatusDeadlockSection)) {
+		var digest, digestText string
+		if err := db.QueryRowContext(ctx, statementDigestQuery, trx.statement, trx.statement).Scan(&digest, &digestText); err != nil {
+			level.Debug(logger).Log("msg", "Error computing statement digest", "err", err)
+			return nil
+		}
+		rolledBack := "false"
+		if trx.rolledBack {
+			rolledBack = "true"
+		}
+		ch <- prometheus.MustNewConstMetric(
+			innodbLastDeadlockStatementDesc, prometheus.GaugeValue, 1,
+			trx.id, digest, digestText, rolledBack,
+		)
+	}
+	return nil
+}
+
+// parseInnodbDeadlock returns the transactions of the LATEST DETECTED
+// DEADLOCK section. The statement of a transaction follows its
+// "MySQL thread id" line.
+func parseInnodbDeadlock(lines []string) []innodbDeadlockTransaction {
+	var (
+		trxs        []innodbDeadlockTransaction
+		current     *innodbDeadlockTransaction
+		inStatement bool
+	)
+	for _, line := range lines {
+		if match := innodbDeadlockTransactionRE.FindStringSubmatch(line); match != nil {
+			trxs = append(trxs, innodbDeadlockTransaction{id: match[1]})
+			current = &trxs[len(trxs)-1]
+			inStatement = false
+			continue
+		}
+		if match := innodbDeadlockRollbackRE.FindStringSubmatch(line); match != nil {
+			for i := range trxs {
+				if trxs[i].id == match[1] {
+					trxs[i].rolledBack = true
+				}
+			}
+			continue
+		}
+		if strings.HasPrefix(line, "***") {
+			inStatement = false
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		if strings.HasPrefix(line, "MySQL thread id") {
+			inStatement = true
+			continue
+		}
+		if inStatement {
+			current.statement = strings.TrimSpace(current.statement + " " + line)
+		}
+	}
+
+	res := trxs[:0]
+	for _, trx := range trxs {
+		if trx.statement != "" {
+			res = append(res, trx)
+		}
+	}
+	return res
+}
+
+// check interface
+var _ Scraper = ScrapeInnodbDeadlocks{}
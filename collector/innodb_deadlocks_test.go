@@ -0,0 +1,115 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+const innodbDeadlockSample = `
+=====================================
+2023-05-10 12:40:00 0x7fed21462700 INNODB MONITOR OUTPUT
+=====================================
+------------------------
+LATEST DETECTED DEADLOCK
+------------------------
+2023-05-10 12:34:56 0x7fed21462700
+*** (1) TRANSACTION:
+TRANSACTION 67840, ACTIVE 3 sec starting index read
+mysql tables in use 1, locked 1
+LOCK WAIT 3 lock struct(s), heap size 1136, 2 row lock(s)
+MySQL thread id 8, OS thread handle 140656308950784, query id 100 localhost root updating
+UPDATE accounts SET balance = balance - 10 WHERE id = 2
+*** (1) HOLDS THE LOCK(S):
+RECORD LOCKS space id 2 page no 4 n bits 72 index PRIMARY of table ` + "`shop`.`accounts`" + ` trx id 67840 lock_mode X locks rec but not gap
+*** (2) TRANSACTION:
+TRANSACTION 67841, ACTIVE 2 sec starting index read
+mysql tables in use 1, locked 1
+MySQL thread id 9, OS thread handle 140656308950785, query id 101 localhost root updating
+UPDATE accounts
+  SET balance = balance + 10 WHERE id = 1
+*** (2) HOLDS THE LOCK(S):
+RECORD LOCKS space id 2 page no 4 n bits 72 index PRIMARY of table ` + "`shop`.`accounts`" + ` trx id 67841 lock_mode X locks rec but not gap
+*** WE ROLL BACK TRANSACTION (2)
+------------
+TRANSACTIONS
+------------
+Trx id counter 67843
+----------------------------
+END OF INNODB MONITOR OUTPUT
+============================
+`
+
+func TestScrapeInnodbDeadlocks(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.innodb.deadlocks.statements",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(engineInnodbStatusQuery)).WillReturnRows(
+		sqlmock.NewRows([]string{"Type", "Name", "Status"}).AddRow("InnoDB", "", innodbDeadlockSample))
+	mock.ExpectQuery(sanitizeQuery(innodbDeadlocksQuery)).WillReturnRows(
+		sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mock.ExpectQuery(regexp.QuoteMeta(statementDigestQuery)).
+		WithArgs("UPDATE accounts SET balance = balance - 10 WHERE id = 2", "UPDATE accounts SET balance = balance - 10 WHERE id = 2").
+		WillReturnRows(sqlmock.NewRows([]string{"digest", "digest_text"}).AddRow("d1", "UPDATE `accounts` SET `balance` = `balance` - ? WHERE `id` = ?"))
+	mock.ExpectQuery(regexp.QuoteMeta(statementDigestQuery)).
+		WithArgs("UPDATE accounts SET balance = balance + 10 WHERE id = 1", "UPDATE accounts SET balance = balance + 10 WHERE id = 1").
+		WillReturnRows(sqlmock.NewRows([]string{"digest", "digest_text"}).AddRow("d2", "UPDATE `accounts` SET `balance` = `balance` + ? WHERE `id` = ?"))
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeInnodbDeadlocks{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	deadlockTime, _ := time.ParseInLocation("2006-01-02 15:04:05", "2023-05-10 12:34:56", time.Local)
+	expected := []MetricResult{
+		{labels: labelMap{}, value: float64(deadlockTime.Unix()), metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 3, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"transaction": "1", "digest": "d1", "digest_text": "UPDATE `accounts` SET `balance` = `balance` - ? WHERE `id` = ?", "rolled_back": "false"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"transaction": "2", "digest": "d2", "digest_text": "UPDATE `accounts` SET `balance` = `balance` + ? WHERE `id` = ?", "rolled_back": "true"}, value: 1, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
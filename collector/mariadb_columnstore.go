@@ -0,0 +1,214 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape MariaDB ColumnStore and S3 engine status.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// Subsystems.
+	columnstore = "columnstore"
+	s3Engine    = "s3"
+	// Queries.
+	analyticsEnginesQuery = `
+	SELECT PLUGIN_NAME
+	  FROM information_schema.plugins
+	  WHERE PLUGIN_NAME IN ('Columnstore', 'S3') AND PLUGIN_STATUS = 'ACTIVE'
+	`
+	columnstoreTablesQuery = `SELECT COUNT(*) FROM information_schema.COLUMNSTORE_TABLES`
+	columnstoreFilesQuery  = `
+	SELECT
+	    COUNT(*),
+	    IFNULL(SUM(FILE_SIZE), 0),
+	    IFNULL(SUM(COMPRESSED_DATA_SIZE), 0)
+	  FROM information_schema.COLUMNSTORE_FILES
+	`
+	columnstoreExtentsQuery = `
+	SELECT STATE, COUNT(*)
+	  FROM information_schema.COLUMNSTORE_EXTENTS
+	  GROUP BY STATE
+	`
+	s3StatusQuery = `SHOW GLOBAL STATUS LIKE 'S3\_%'`
+)
+
+// Metric descriptors.
+var (
+	columnstoreEnabledDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, columnstore, "enabled"),
+		"Whether the ColumnStore engine is loaded.",
+		nil, nil,
+	)
+	columnstoreTablesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, columnstore, "tables"),
+		"The number of ColumnStore tables.",
+		nil, nil,
+	)
+	columnstoreFilesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, columnstore, "files"),
+		"The number of ColumnStore segment files.",
+		nil, nil,
+	)
+	columnstoreFileSizeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, columnstore, "file_size_bytes"),
+		"The size of ColumnStore segment files on disk.",
+		nil, nil,
+	)
+	columnstoreCompressedSizeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, columnstore, "compressed_data_size_bytes"),
+		"The size of compressed data in ColumnStore segment files.",
+		nil, nil,
+	)
+	columnstoreExtentsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, columnstore, "extents"),
+		"The number of ColumnStore extents by state.",
+		[]string{"state"}, nil,
+	)
+	s3EnabledDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, s3Engine, "enabled"),
+		"Whether the S3 engine is loaded.",
+		nil, nil,
+	)
+)
+
+// ScrapeColumnstore collects MariaDB ColumnStore and S3 engine status.
+type ScrapeColumnstore struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeColumnstore) Name() string {
+	return "mariadb.columnstore"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeColumnstore) Help() string {
+	return "Collect MariaDB ColumnStore and S3 engine status"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeColumnstore) Version() float64 {
+	return 10.5
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeColumnstore) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	engineRows, err := db.QueryContext(ctx, analyticsEnginesQuery)
+	if err != nil {
+		return err
+	}
+	defer engineRows.Close()
+
+	var (
+		name               string
+		columnstoreEnabled float64
+		s3Enabled          float64
+	)
+	for engineRows.Next() {
+		if err := engineRows.Scan(&name); err != nil {
+			return err
+		}
+		switch name {
+		case "Columnstore":
+			columnstoreEnabled = 1
+		case "S3":
+			s3Enabled = 1
+		}
+	}
+	if err := engineRows.Err(); err != nil {
+		return err
+	}
+
+	ch <- prometheus.MustNewConstMetric(columnstoreEnabledDesc, prometheus.GaugeValue, columnstoreEnabled)
+	if columnstoreEnabled == 1 {
+		if err := scrapeColumnstoreUsage(ctx, db, ch); err != nil {
+			return err
+		}
+	}
+
+	ch <- prometheus.MustNewConstMetric(s3EnabledDesc, prometheus.GaugeValue, s3Enabled)
+	if s3Enabled == 1 {
+		return scrapeS3Status(ctx, db, ch)
+	}
+	return nil
+}
+
+// scrapeColumnstoreUsage collects tables, files and extents of ColumnStore.
+func scrapeColumnstoreUsage(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	var tables float64
+	if err := db.QueryRowContext(ctx, columnstoreTablesQuery).Scan(&tables); err != nil {
+		return err
+	}
+	ch <- prometheus.MustNewConstMetric(columnstoreTablesDesc, prometheus.GaugeValue, tables)
+
+	var files, fileSize, compressedSize float64
+	if err := db.QueryRowContext(ctx, columnstoreFilesQuery).Scan(&files, &fileSize, &compressedSize); err != nil {
+		return err
+	}
+	ch <- prometheus.MustNewConstMetric(columnstoreFilesDesc, prometheus.GaugeValue, files)
+	ch <- prometheus.MustNewConstMetric(columnstoreFileSizeDesc, prometheus.GaugeValue, fileSize)
+	ch <- prometheus.MustNewConstMetric(columnstoreCompressedSizeDesc, prometheus.GaugeValue, compressedSize)
+
+	extentRows, err := db.QueryContext(ctx, columnstoreExtentsQuery)
+	if err != nil {
+		return err
+	}
+	defer extentRows.Close()
+
+	var (
+		state   string
+		extents float64
+	)
+	for extentRows.Next() {
+		if err := extentRows.Scan(&state, &extents); err != nil {
+			return err
+		}
+		ch <- prometheus.MustNewConstMetric(columnstoreExtentsDesc, prometheus.GaugeValue, extents, strings.ToLower(state))
+	}
+	return extentRows.Err()
+}
+
+// scrapeS3Status collects the S3_* status variables of the S3 engine.
+func scrapeS3Status(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	statusRows, err := db.QueryContext(ctx, s3StatusQuery)
+	if err != nil {
+		return err
+	}
+	defer statusRows.Close()
+
+	var (
+		key string
+		val sql.RawBytes
+	)
+	for statusRows.Next() {
+		if err := statusRows.Scan(&key, &val); err != nil {
+			return err
+		}
+		if floatVal, ok := parseStatus(val); ok {
+			ch <- prometheus.MustNewConstMetric(
+				newDesc(s3Engine, strings.TrimPrefix(validPrometheusName(key), "s3_"), "Generic metric from the S3_* status variables."),
+				prometheus.UntypedValue, floatVal,
+			)
+		}
+	}
+	return statusRows.Err()
+}
+
+// check interface
+var _ Scraper = ScrapeColumnstore{}
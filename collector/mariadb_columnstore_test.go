@@ -0,0 +1,78 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeColumnstore(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(analyticsEnginesQuery)).WillReturnRows(
+		sqlmock.NewRows([]string{"PLUGIN_NAME"}).AddRow("Columnstore").AddRow("S3"))
+	mock.ExpectQuery(sanitizeQuery(columnstoreTablesQuery)).WillReturnRows(
+		sqlmock.NewRows([]string{"COUNT(*)"}).AddRow(3))
+	mock.ExpectQuery(sanitizeQuery(columnstoreFilesQuery)).WillReturnRows(
+		sqlmock.NewRows([]string{"COUNT(*)", "FILE_SIZE", "COMPRESSED_DATA_SIZE"}).AddRow(12, 100663296, 4194304))
+	mock.ExpectQuery(sanitizeQuery(columnstoreExtentsQuery)).WillReturnRows(
+		sqlmock.NewRows([]string{"STATE", "COUNT(*)"}).AddRow("Available", 12))
+	mock.ExpectQuery(regexp.QuoteMeta(s3StatusQuery)).WillReturnRows(
+		sqlmock.NewRows([]string{"Variable_name", "Value"}).
+			AddRow("S3_pagecache_reads", "5").
+			AddRow("S3_pagecache_read_requests", "50"))
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeColumnstore{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 3, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 12, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 100663296, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 4194304, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"state": "available"}, value: 12, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 5, metricType: dto.MetricType_UNTYPED},
+		{labels: labelMap{}, value: 50, metricType: dto.MetricType_UNTYPED},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape MariaDB Spider engine link status.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// Subsystem.
+	spider = "spider"
+	// Queries.
+	spiderPluginQuery = `
+	SELECT COUNT(*)
+	  FROM information_schema.plugins
+	  WHERE PLUGIN_NAME = 'SPIDER' AND PLUGIN_STATUS = 'ACTIVE'
+	`
+	spiderStatusQuery     = `SHOW GLOBAL STATUS LIKE 'Spider\_%'`
+	spiderLinkStatusQuery = `
+	SELECT link_status, COUNT(*)
+	  FROM mysql.spider_tables
+	  GROUP BY link_status
+	`
+	spiderLinkFailuresQuery = `
+	SELECT
+	    host,
+	    port,
+	    COUNT(*),
+	    UNIX_TIMESTAMP(MAX(failed_time))
+	  FROM mysql.spider_link_failed_log
+	  GROUP BY host, port
+	`
+)
+
+// spiderLinkStatus names the link_status values of mysql.spider_tables.
+var spiderLinkStatus = map[string]string{
+	"0": "ok",
+	"1": "ok",
+	"2": "recovery",
+	"3": "disabled",
+}
+
+// Metric descriptors.
+var (
+	spiderTableLinksDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, spider, "table_links"),
+		"The number of Spider table links to remote data nodes by link status.",
+		[]string{"link_status"}, nil,
+	)
+	spiderLinkFailuresDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, spider, "link_failures_total"),
+		"The number of failed links to a remote data node logged in mysql.spider_link_failed_log.",
+		[]string{"host", "port"}, nil,
+	)
+	spiderLastLinkFailureDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, spider, "last_link_failure_timestamp_seconds"),
+		"The time of the latest failed link to a remote data node.",
+		[]string{"host", "port"}, nil,
+	)
+)
+
+// ScrapeSpider collects MariaDB Spider engine link status.
+type ScrapeSpider struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeSpider) Name() string {
+	return "mariadb.spider"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeSpider) Help() string {
+	return "Collect MariaDB Spider engine status and links to remote data nodes"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeSpider) Version() float64 {
+	return 10.0
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeSpider) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	var loaded int
+	if err := db.QueryRowContext(ctx, spiderPluginQuery).Scan(&loaded); err != nil {
+		return err
+	}
+	if loaded == 0 {
+		return nil
+	}
+
+	statusRows, err := db.QueryContext(ctx, spiderStatusQuery)
+	if err != nil {
+		return err
+	}
+	defer statusRows.Close()
+
+	var (
+		key string
+		val sql.RawBytes
+	)
+	for statusRows.Next() {
+		if err := statusRows.Scan(&key, &val); err != nil {
+			return err
+		}
+		if floatVal, ok := parseStatus(val); ok {
+			ch <- prometheus.MustNewConstMetric(
+				newDesc(spider, strings.TrimPrefix(validPrometheusName(key), "spider_"), "Generic metric from the Spider_* status variables."),
+				prometheus.UntypedValue, floatVal,
+			)
+		}
+	}
+	if err := statusRows.Err(); err != nil {
+		return err
+	}
+
+	linkRows, err := db.QueryContext(ctx, spiderLinkStatusQuery)
+	if err != nil {
+		return err
+	}
+	defer linkRows.Close()
+
+	var (
+		linkStatus string
+		count      float64
+		links      = map[string]float64{}
+	)
+	for linkRows.Next() {
+		if err := linkRows.Scan(&linkStatus, &count); err != nil {
+			return err
+		}
+		name, ok := spiderLinkStatus[linkStatus]
+		if !ok {
+			name = linkStatus
+		}
+		links[name] += count
+	}
+	if err := linkRows.Err(); err != nil {
+		return err
+	}
+	for _, name := range []string{"ok", "recovery", "disabled"} {
+		ch <- prometheus.MustNewConstMetric(spiderTableLinksDesc, prometheus.GaugeValue, links[name], name)
+	}
+
+	failureRows, err := db.QueryContext(ctx, spiderLinkFailuresQuery)
+	if err != nil {
+		return err
+	}
+	defer failureRows.Close()
+
+	var (
+		host, port  string
+		lastFailure float64
+	)
+	for failureRows.Next() {
+		if err := failureRows.Scan(&host, &port, &count, &lastFailure); err != nil {
+			return err
+		}
+		ch <- prometheus.MustNewConstMetric(spiderLinkFailuresDesc, prometheus.CounterValue, count, host, port)
+		ch <- prometheus.MustNewConstMetric(spiderLastLinkFailureDesc, prometheus.GaugeValue, lastFailure, host, port)
+	}
+	return failureRows.Err()
+}
+
+// check interface
+var _ Scraper = ScrapeSpider{}
@@ -0,0 +1,75 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeSpider(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(spiderPluginQuery)).WillReturnRows(
+		sqlmock.NewRows([]string{"COUNT(*)"}).AddRow(1))
+	mock.ExpectQuery(regexp.QuoteMeta(spiderStatusQuery)).WillReturnRows(
+		sqlmock.NewRows([]string{"Variable_name", "Value"}).AddRow("Spider_direct_update", "7"))
+	mock.ExpectQuery(sanitizeQuery(spiderLinkStatusQuery)).WillReturnRows(
+		sqlmock.NewRows([]string{"link_status", "COUNT(*)"}).
+			AddRow("0", 2).
+			AddRow("1", 4).
+			AddRow("3", 1))
+	mock.ExpectQuery(sanitizeQuery(spiderLinkFailuresQuery)).WillReturnRows(
+		sqlmock.NewRows([]string{"host", "port", "COUNT(*)", "failed_time"}).
+			AddRow("10.0.0.5", "3306", 3, 1683722096))
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeSpider{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{}, value: 7, metricType: dto.MetricType_UNTYPED},
+		{labels: labelMap{"link_status": "ok"}, value: 6, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"link_status": "recovery"}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"link_status": "disabled"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"host": "10.0.0.5", "port": "3306"}, value: 3, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"host": "10.0.0.5", "port": "3306"}, value: 1683722096, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
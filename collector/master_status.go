@@ -18,18 +18,24 @@ package collector
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
-	"github.com/go-kit/log"
-	"github.com/prometheus/client_golang/prometheus"
 	"strconv"
 	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	MySQL "github.com/go-sql-driver/mysql"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 const (
 	// Subsystem
 	master = "master_status"
 	// Queries.
-	masterStatusQueries = `SHOW MASTER STATUS`
+	masterStatusQuery = `SHOW MASTER STATUS`
+	// MySQL 8.2 renamed SHOW MASTER STATUS, 8.4 removed the old name.
+	binaryLogStatusQuery = `SHOW BINARY LOG STATUS`
 )
 
 // Metric descriptors.
@@ -54,6 +60,11 @@ var (
 		"Number of now use binlog files.",
 		[]string{"executed_server_id", "partition"}, nil,
 	)
+	binlogEnabledDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, binlog, "enabled"),
+		"Whether the binary log is enabled.",
+		[]string{}, nil,
+	)
 )
 
 // ScrapeMasterStatus collects from `SHOW MASTER STATUS`.
@@ -74,48 +85,58 @@ func (ScrapeMasterStatus) Version() float64 {
 	return 5.1
 }
 
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
 func (s ScrapeMasterStatus) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
-	masterStatusRow, err := db.QueryContext(ctx, masterStatusQueries)
+	masterStatusRows, err := db.QueryContext(ctx, compatStatement(ctx, masterStatusQuery))
+	if isMySQLError(err, 1064) { // ER_PARSE_ERROR
+		masterStatusRows, err = db.QueryContext(ctx, binaryLogStatusQuery)
+	}
+	if isMySQLError(err, 1227) { // ER_SPECIFIC_ACCESS_DENIED_ERROR
+		level.Warn(logger).Log("msg", "No privilege to read the binary log status, REPLICATION CLIENT is required", "err", err)
+		return scrapeBinlogEnabled(ctx, db, ch)
+	}
 	if err != nil {
 		return err
 	}
-	defer masterStatusRow.Close()
-
-	var (
-		filename        string
-		position        int64
-		binlogDoDb      string
-		binlogIgnoreDb  string
-		executedGTidSet string
-	)
+	defer masterStatusRows.Close()
 
-	columns, err := masterStatusRow.Columns()
+	columns, err := masterStatusRows.Columns()
 	if err != nil {
 		return err
 	}
-	columnCount := len(columns)
-
-	for masterStatusRow.Next() {
-		switch columnCount {
-		case 4:
-			if err := masterStatusRow.Scan(&filename, &position, &binlogDoDb, &binlogIgnoreDb); err != nil {
-				return err
-			}
-		case 5:
-			if err := masterStatusRow.Scan(&filename, &position, &binlogDoDb, &binlogIgnoreDb, &executedGTidSet); err != nil {
-				return err
-			}
-		default:
-			return fmt.Errorf("invalid number of columns: %q", columnCount)
+	// Only the first row describes the binary log of this server.
+	if !masterStatusRows.Next() {
+		if err := masterStatusRows.Err(); err != nil {
+			return err
 		}
+		// No rows with the binary log disabled.
+		ch <- prometheus.MustNewConstMetric(binlogEnabledDesc, prometheus.GaugeValue, 0)
+		return nil
+	}
+	scanArgs := make([]interface{}, len(columns))
+	for i := range scanArgs {
+		scanArgs[i] = &sql.RawBytes{}
+	}
+	if err := masterStatusRows.Scan(scanArgs...); err != nil {
+		return err
 	}
+	filename := columnValue(scanArgs, columns, "File")
+	position := columnValue(scanArgs, columns, "Position")
+	executedGTIDSet := columnValue(scanArgs, columns, "Executed_Gtid_Set")
+
+	ch <- prometheus.MustNewConstMetric(binlogEnabledDesc, prometheus.GaugeValue, 1)
+
 	// only have file need report
 	if filename != "" {
 		ss := strings.Split(filename, ".")
 		if len(ss) < 2 {
-			return fmt.Errorf("split %s by `.` item not enough", filename)
+			return parseError{fmt.Errorf("split %s by `.` item not enough", filename)}
 		}
-		value, err := strconv.ParseFloat(ss[1], 64)
+		value, err := strconv.ParseFloat(ss[len(ss)-1], 64)
+		if err != nil {
+			return err
+		}
+		pos, err := strconv.ParseFloat(position, 64)
 		if err != nil {
 			return err
 		}
@@ -123,12 +144,12 @@ func (s ScrapeMasterStatus) Scrape(ctx context.Context, db *sql.DB, ch chan<- pr
 			masterBinlogFileNum, prometheus.GaugeValue, value,
 		)
 		ch <- prometheus.MustNewConstMetric(
-			masterBinlogPos, prometheus.GaugeValue, float64(position),
+			masterBinlogPos, prometheus.GaugeValue, pos,
 		)
 	}
 
-	if executedGTidSet != "" {
-		GTIDs, err := ParseGTID(executedGTidSet)
+	if executedGTIDSet != "" {
+		GTIDs, err := ParseGTID(executedGTIDSet)
 		if err != nil {
 			return err
 		}
@@ -146,5 +167,22 @@ func (s ScrapeMasterStatus) Scrape(ctx context.Context, db *sql.DB, ch chan<- pr
 	return nil
 }
 
+// scrapeBinlogEnabled exposes whether the binary log is enabled from
+// @@log_bin, which needs no privileges.
+func scrapeBinlogEnabled(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	var logBin uint8
+	if err := db.QueryRowContext(ctx, logbinQuery).Scan(&logBin); err != nil {
+		return err
+	}
+	ch <- prometheus.MustNewConstMetric(binlogEnabledDesc, prometheus.GaugeValue, float64(logBin))
+	return nil
+}
+
+// isMySQLError reports whether err is the MySQL error number.
+func isMySQLError(err error, number uint16) bool {
+	var mysqlErr *MySQL.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == number
+}
+
 // check interface
 var _ Scraper = ScrapeMasterStatus{}
@@ -15,8 +15,10 @@ package collector
 
 import (
 	"context"
+
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/go-kit/log"
+	MySQL "github.com/go-sql-driver/mysql"
 	"github.com/prometheus/client_golang/prometheus"
 	dto "github.com/prometheus/client_model/go"
 	. "github.com/smartystreets/goconvey/convey"
@@ -43,6 +45,7 @@ func TestScrapeMasterStatus(t *testing.T) {
 	}()
 
 	counterExpected := []MetricResult{
+		{labels: labelMap{}, value: 1, metricType: dto.MetricType_GAUGE},
 		{labels: labelMap{}, value: 6, metricType: dto.MetricType_GAUGE},
 		{labels: labelMap{}, value: 49066, metricType: dto.MetricType_GAUGE},
 		{labels: labelMap{"executed_server_id": "215d19f8-7eca-11ed-9d98-00163e000147", "partition": ""}, value: 1, metricType: dto.MetricType_GAUGE},
@@ -61,3 +64,76 @@ func TestScrapeMasterStatus(t *testing.T) {
 		t.Errorf("there were unfulfilled exceptions: %s", err)
 	}
 }
+
+func TestScrapeMasterStatusFallbacks(t *testing.T) {
+	tests := []struct {
+		name     string
+		mock     func(mock sqlmock.Sqlmock)
+		expected []MetricResult
+	}{
+		{
+			name: "SHOW BINARY LOG STATUS",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(sanitizeQuery(masterStatusQuery)).WillReturnError(&MySQL.MySQLError{Number: 1064})
+				mock.ExpectQuery(sanitizeQuery(binaryLogStatusQuery)).WillReturnRows(
+					sqlmock.NewRows([]string{"File", "Position", "Binlog_Do_DB", "Binlog_Ignore_DB", "Executed_Gtid_Set"}).
+						AddRow("db-1-bin.000012", "157", "", "", ""))
+			},
+			expected: []MetricResult{
+				{labels: labelMap{}, value: 1, metricType: dto.MetricType_GAUGE},
+				{labels: labelMap{}, value: 12, metricType: dto.MetricType_GAUGE},
+				{labels: labelMap{}, value: 157, metricType: dto.MetricType_GAUGE},
+			},
+		},
+		{
+			name: "Binary log disabled",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(sanitizeQuery(masterStatusQuery)).WillReturnRows(
+					sqlmock.NewRows([]string{"File", "Position", "Binlog_Do_DB", "Binlog_Ignore_DB"}))
+			},
+			expected: []MetricResult{
+				{labels: labelMap{}, value: 0, metricType: dto.MetricType_GAUGE},
+			},
+		},
+		{
+			name: "Missing privilege",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(sanitizeQuery(masterStatusQuery)).WillReturnError(&MySQL.MySQLError{Number: 1227})
+				mock.ExpectQuery(sanitizeQuery(logbinQuery)).WillReturnRows(sqlmock.NewRows([]string{"@@log_bin"}).AddRow(1))
+			},
+			expected: []MetricResult{
+				{labels: labelMap{}, value: 1, metricType: dto.MetricType_GAUGE},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("error opening a stub database connection: %s", err)
+		}
+		tt.mock(mock)
+
+		ch := make(chan prometheus.Metric)
+		go func() {
+			if err := (ScrapeMasterStatus{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+				t.Errorf("error calling function on test: %s", err)
+			}
+			close(ch)
+		}()
+
+		Convey(tt.name, t, func() {
+			got := []MetricResult{}
+			for m := range ch {
+				got = append(got, readMetric(m))
+			}
+			So(got, ShouldResemble, tt.expected)
+		})
+
+		// Ensure all SQL queries were executed
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled exceptions: %s", err)
+		}
+		db.Close()
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// minIntervals are the minimum intervals between scrapes of the scrapers
+// with one, set by SetMinIntervals at startup.
+var minIntervals = map[string]time.Duration{}
+
+// SetMinIntervals sets the minimum intervals between scrapes of scrapers by
+// name. Scrapes within the interval return the metrics of the last scrape.
+func SetMinIntervals(intervals map[string]time.Duration) {
+	minIntervals = intervals
+}
+
+type scrapeCacheKey struct {
+	dsn, scraper string
+}
+
+// cachedScrape holds the metrics of a successful scrape.
+type cachedScrape struct {
+	time    time.Time
+	metrics []prometheus.Metric
+}
+
+// scrapeCache holds the last successful scrape of the scrapers with a
+// minimum interval, by target.
+type scrapeCache struct {
+	mtx     sync.Mutex
+	scrapes map[scrapeCacheKey]cachedScrape
+}
+
+var scraperCache = &scrapeCache{scrapes: map[scrapeCacheKey]cachedScrape{}}
+
+// get returns the metrics of the last scrape of the scraper if it is less
+// than interval old.
+func (c *scrapeCache) get(dsn, scraper string, interval time.Duration, now time.Time) ([]prometheus.Metric, bool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	s, ok := c.scrapes[scrapeCacheKey{dsn, scraper}]
+	if !ok || now.Sub(s.time) >= interval {
+		return nil, false
+	}
+	return s.metrics, true
+}
+
+func (c *scrapeCache) set(dsn, scraper string, metrics []prometheus.Metric, now time.Time) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.scrapes[scrapeCacheKey{dsn, scraper}] = cachedScrape{time: now, metrics: metrics}
+}
+
+// recordMetrics returns a channel sending the metrics to ch, and a function
+// to call once done sending which returns the metrics sent.
+func recordMetrics(ch chan<- prometheus.Metric) (chan<- prometheus.Metric, func() []prometheus.Metric) {
+	recorded := make(chan prometheus.Metric)
+	done := make(chan struct{})
+	var metrics []prometheus.Metric
+	go func() {
+		defer close(done)
+		for metric := range recorded {
+			metrics = append(metrics, metric)
+			ch <- metric
+		}
+	}()
+	return recorded, func() []prometheus.Metric {
+		close(recorded)
+		<-done
+		return metrics
+	}
+}
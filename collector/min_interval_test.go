@@ -0,0 +1,52 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeCache(t *testing.T) {
+	convey.Convey("Scrapes are cached for the minimum interval", t, func() {
+		cache := &scrapeCache{scrapes: map[scrapeCacheKey]cachedScrape{}}
+		start := time.Now()
+		metric := prometheus.MustNewConstMetric(globalInfoSchemaAutoIncrementDesc, prometheus.GaugeValue, 1, "db", "t", "id")
+
+		_, ok := cache.get("dsn", "info_schema.tables", 10*time.Minute, start)
+		convey.So(ok, convey.ShouldBeFalse)
+
+		cache.set("dsn", "info_schema.tables", []prometheus.Metric{metric}, start)
+		metrics, ok := cache.get("dsn", "info_schema.tables", 10*time.Minute, start.Add(9*time.Minute))
+		convey.So(ok, convey.ShouldBeTrue)
+		convey.So(metrics, convey.ShouldResemble, []prometheus.Metric{metric})
+
+		_, ok = cache.get("dsn", "info_schema.tables", 10*time.Minute, start.Add(10*time.Minute))
+		convey.So(ok, convey.ShouldBeFalse)
+		_, ok = cache.get("other", "info_schema.tables", 10*time.Minute, start)
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+
+	convey.Convey("Recorded metrics are passed on", t, func() {
+		ch := make(chan prometheus.Metric, 2)
+		recordCh, record := recordMetrics(ch)
+		metric := prometheus.MustNewConstMetric(globalInfoSchemaAutoIncrementDesc, prometheus.GaugeValue, 1, "db", "t", "id")
+		recordCh <- metric
+		convey.So(record(), convey.ShouldResemble, []prometheus.Metric{metric})
+		convey.So(<-ch, convey.ShouldEqual, metric)
+	})
+}
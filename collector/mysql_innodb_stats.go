@@ -0,0 +1,120 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape `mysql.innodb_table_stats` and `mysql.innodb_index_stats`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	mysqlInnodbTableStatsQuery = `
+	SELECT
+	    database_name,
+	    COUNT(*),
+	    UNIX_TIMESTAMP() - UNIX_TIMESTAMP(MIN(last_update))
+	  FROM mysql.innodb_table_stats
+	  WHERE database_name NOT IN ('mysql', 'performance_schema', 'information_schema', 'sys')
+	  GROUP BY database_name
+	`
+	mysqlInnodbIndexStatsQuery = `
+	SELECT
+	    database_name,
+	    COUNT(DISTINCT table_name, index_name),
+	    UNIX_TIMESTAMP() - UNIX_TIMESTAMP(MIN(last_update))
+	  FROM mysql.innodb_index_stats
+	  WHERE database_name NOT IN ('mysql', 'performance_schema', 'information_schema', 'sys')
+	  GROUP BY database_name
+	`
+)
+
+// Metric descriptors.
+var (
+	mysqlInnodbTableStatsTablesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, mysql, "innodb_table_stats_tables"),
+		"The number of tables with persistent statistics in mysql.innodb_table_stats.",
+		[]string{"schema"}, nil,
+	)
+	mysqlInnodbTableStatsStalenessDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, mysql, "innodb_table_stats_max_staleness_seconds"),
+		"The time since the least recently updated table statistics in mysql.innodb_table_stats.",
+		[]string{"schema"}, nil,
+	)
+	mysqlInnodbIndexStatsIndexesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, mysql, "innodb_index_stats_indexes"),
+		"The number of indexes with persistent statistics in mysql.innodb_index_stats.",
+		[]string{"schema"}, nil,
+	)
+	mysqlInnodbIndexStatsStalenessDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, mysql, "innodb_index_stats_max_staleness_seconds"),
+		"The time since the least recently updated index statistics in mysql.innodb_index_stats.",
+		[]string{"schema"}, nil,
+	)
+)
+
+// ScrapeInnodbStats collects persistent statistics staleness from `mysql.innodb_table_stats` and `mysql.innodb_index_stats`.
+type ScrapeInnodbStats struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeInnodbStats) Name() string {
+	return mysql + ".innodb_stats"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeInnodbStats) Help() string {
+	return "Collect persistent optimizer statistics staleness per schema from mysql.innodb_table_stats and mysql.innodb_index_stats"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeInnodbStats) Version() float64 {
+	return 5.6
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeInnodbStats) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	if err := scrapeInnodbStats(ctx, db, ch, mysqlInnodbTableStatsQuery, mysqlInnodbTableStatsTablesDesc, mysqlInnodbTableStatsStalenessDesc); err != nil {
+		return err
+	}
+	return scrapeInnodbStats(ctx, db, ch, mysqlInnodbIndexStatsQuery, mysqlInnodbIndexStatsIndexesDesc, mysqlInnodbIndexStatsStalenessDesc)
+}
+
+// scrapeInnodbStats emits the count and staleness per schema returned by query.
+func scrapeInnodbStats(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, query string, countDesc, stalenessDesc *prometheus.Desc) error {
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var (
+		schema           string
+		count, staleness float64
+	)
+	for rows.Next() {
+		if err := rows.Scan(&schema, &count, &staleness); err != nil {
+			return err
+		}
+		ch <- prometheus.MustNewConstMetric(countDesc, prometheus.GaugeValue, count, schema)
+		ch <- prometheus.MustNewConstMetric(stalenessDesc, prometheus.GaugeValue, staleness, schema)
+	}
+	return rows.Err()
+}
+
+// check interface
+var _ Scraper = ScrapeInnodbStats{}
@@ -0,0 +1,70 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeInnodbStats(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"database_name", "COUNT(*)", "staleness"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("shop", 12, 3600).
+		AddRow("billing", 3, 864000)
+	mock.ExpectQuery(sanitizeQuery(mysqlInnodbTableStatsQuery)).WillReturnRows(rows)
+	rows = sqlmock.NewRows(columns).
+		AddRow("shop", 30, 7200)
+	mock.ExpectQuery(sanitizeQuery(mysqlInnodbIndexStatsQuery)).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeInnodbStats{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{"schema": "shop"}, value: 12, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"schema": "shop"}, value: 3600, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"schema": "billing"}, value: 3, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"schema": "billing"}, value: 864000, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"schema": "shop"}, value: 30, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"schema": "shop"}, value: 7200, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape roles and password policy from `mysql.user`, `mysql.role_edges` and
+// the validate_password and password lifetime variables.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	mysqlRolesQuery = `
+	SELECT
+	    COUNT(DISTINCT FROM_USER, FROM_HOST)
+	  FROM mysql.role_edges
+	`
+	// Roles are accounts too, they are left out of the user counts as
+	// CREATE ROLE leaves their password expired.
+	mysqlUserPasswordsQuery = `
+	SELECT
+	    IFNULL(SUM(password_expired = 'Y'), 0),
+	    IFNULL(SUM(password_last_changed < NOW() - INTERVAL ? DAY), 0)
+	  FROM mysql.user u
+	  WHERE NOT EXISTS (
+	    SELECT 1 FROM mysql.role_edges r
+	      WHERE r.FROM_USER = u.user AND r.FROM_HOST = u.host
+	  )
+	`
+	passwordPolicyVariablesQuery = `
+	SHOW GLOBAL VARIABLES
+	  WHERE Variable_name LIKE 'validate\_password%' OR Variable_name IN ('default_password_lifetime', 'password_history', 'password_reuse_interval')
+	`
+)
+
+// Tunable flags.
+var (
+	passwordPolicyMaxAgeDays = kingpin.Flag(
+		"collect.mysql.password_policy.max_age_days",
+		"Number of days after which a password counts as too old",
+	).Default("90").Int()
+)
+
+// validate_password policy levels.
+var validatePasswordPolicies = map[string]float64{
+	"low":    0,
+	"medium": 1,
+	"strong": 2,
+}
+
+// Metric descriptors.
+var (
+	mysqlRolesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, mysql, "roles"),
+		"The number of roles granted to at least one account.",
+		nil, nil,
+	)
+	mysqlUsersPasswordExpiredDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, mysql, "users_password_expired"),
+		"The number of users with an expired password.",
+		nil, nil,
+	)
+	mysqlUsersPasswordTooOldDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, mysql, "users_password_too_old"),
+		"The number of users whose password is older than --collect.mysql.password_policy.max_age_days.",
+		nil, nil,
+	)
+	mysqlPasswordPolicySettingDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, mysql, "password_policy_setting"),
+		"Password policy settings, the validate_password policy is exposed as 0 for LOW, 1 for MEDIUM and 2 for STRONG.",
+		[]string{"variable"}, nil,
+	)
+)
+
+// ScrapePasswordPolicy collects roles and password policy.
+type ScrapePasswordPolicy struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapePasswordPolicy) Name() string {
+	return mysql + ".password_policy"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapePasswordPolicy) Help() string {
+	return "Collect the number of roles, users with expired or too old passwords and password policy settings"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapePasswordPolicy) Version() float64 {
+	return 8.0
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapePasswordPolicy) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	var roles float64
+	if err := db.QueryRowContext(ctx, mysqlRolesQuery).Scan(&roles); err != nil {
+		return err
+	}
+	ch <- prometheus.MustNewConstMetric(mysqlRolesDesc, prometheus.GaugeValue, roles)
+
+	var expired, tooOld float64
+	if err := db.QueryRowContext(ctx, mysqlUserPasswordsQuery, *passwordPolicyMaxAgeDays).Scan(&expired, &tooOld); err != nil {
+		return err
+	}
+	ch <- prometheus.MustNewConstMetric(mysqlUsersPasswordExpiredDesc, prometheus.GaugeValue, expired)
+	ch <- prometheus.MustNewConstMetric(mysqlUsersPasswordTooOldDesc, prometheus.GaugeValue, tooOld)
+
+	variableRows, err := db.QueryContext(ctx, passwordPolicyVariablesQuery)
+	if err != nil {
+		return err
+	}
+	defer variableRows.Close()
+
+	var name string
+	var value sql.RawBytes
+	for variableRows.Next() {
+		if err := variableRows.Scan(&name, &value); err != nil {
+			return err
+		}
+		floatVal, ok := parseStatus(value)
+		if !ok {
+			floatVal, ok = validatePasswordPolicies[strings.ToLower(string(value))]
+		}
+		if !ok { // Unparsable values such as the dictionary file are silently skipped.
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(mysqlPasswordPolicySettingDesc, prometheus.GaugeValue, floatVal, name)
+	}
+	return variableRows.Err()
+}
+
+// check interface
+var _ Scraper = ScrapePasswordPolicy{}
@@ -0,0 +1,86 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapePasswordPolicy(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.mysql.password_policy.max_age_days=30",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(mysqlRolesQuery)).WillReturnRows(sqlmock.NewRows([]string{"roles"}).AddRow(3))
+	mock.ExpectQuery(sanitizeQuery(mysqlUserPasswordsQuery)).WithArgs(30).
+		WillReturnRows(sqlmock.NewRows([]string{"expired", "too_old"}).AddRow(1, 4))
+	columns := []string{"Variable_name", "Value"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("default_password_lifetime", "0").
+		AddRow("password_history", "5").
+		AddRow("password_reuse_interval", "365").
+		AddRow("validate_password.check_user_name", "ON").
+		AddRow("validate_password.dictionary_file", "").
+		AddRow("validate_password.length", "8").
+		AddRow("validate_password.policy", "MEDIUM")
+	mock.ExpectQuery("SHOW GLOBAL VARIABLES WHERE Variable_name LIKE").WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapePasswordPolicy{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{}, value: 3, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 4, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"variable": "default_password_lifetime"}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"variable": "password_history"}, value: 5, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"variable": "password_reuse_interval"}, value: 365, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"variable": "validate_password.check_user_name"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"variable": "validate_password.length"}, value: 8, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"variable": "validate_password.policy"}, value: 1, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
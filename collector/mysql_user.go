@@ -228,11 +228,10 @@ func (ScrapeUser) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.M
 			for i, col := range userCols {
 				if value, ok := parsePrivilege(*scanArgs[i].(*sql.RawBytes)); ok { // Silently skip unparsable values.
 					ch <- prometheus.MustNewConstMetric(
-						prometheus.NewDesc(
+						cachedDesc(
 							prometheus.BuildFQName(namespace, mysql, strings.ToLower(col)),
 							col+" by user.",
 							labelNames,
-							nil,
 						),
 						prometheus.GaugeValue,
 						value,
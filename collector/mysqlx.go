@@ -0,0 +1,181 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape `SHOW GLOBAL STATUS LIKE 'Mysqlx\_%'`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// Subsystem.
+	mysqlx = "mysqlx"
+	// Query.
+	mysqlxStatusQuery = `SHOW GLOBAL STATUS LIKE 'Mysqlx\_%'`
+)
+
+// Regexp to match the groups of status vars counting sessions, connections,
+// notices and X protocol messages by kind.
+var mysqlxStatusRE = regexp.MustCompile(`^(sessions|connections|notice|stmt|crud|expect|cursor|prep)_(.+)$`)
+
+// mysqlxGauges are the status variables which are not counters, by name
+// without the Mysqlx_ prefix.
+var mysqlxGauges = map[string]*prometheus.Desc{
+	"sessions": prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, mysqlx, "sessions"),
+		"The number of open X protocol sessions.",
+		nil, nil,
+	),
+	"connections_active": prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, mysqlx, "connections_active"),
+		"The number of active X protocol connections.",
+		nil, nil,
+	),
+	"worker_threads": prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, mysqlx, "worker_threads"),
+		"The number of X plugin worker threads.",
+		nil, nil,
+	),
+	"worker_threads_active": prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, mysqlx, "worker_threads_active"),
+		"The number of X plugin worker threads in use.",
+		nil, nil,
+	),
+}
+
+// Metric descriptors.
+var (
+	mysqlxSessionsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, mysqlx, "sessions_total"),
+		"The number of X protocol sessions by outcome.",
+		[]string{"state"}, nil,
+	)
+	mysqlxConnectionsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, mysqlx, "connections_total"),
+		"The number of X protocol connections by outcome.",
+		[]string{"state"}, nil,
+	)
+	mysqlxBytesSentDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, mysqlx, "bytes_sent_total"),
+		"The number of bytes sent to X protocol clients.",
+		nil, nil,
+	)
+	mysqlxBytesReceivedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, mysqlx, "bytes_received_total"),
+		"The number of bytes received from X protocol clients.",
+		nil, nil,
+	)
+	mysqlxErrorsSentDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, mysqlx, "errors_sent_total"),
+		"The number of errors sent to X protocol clients.",
+		nil, nil,
+	)
+	mysqlxNoticesSentDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, mysqlx, "notices_sent_total"),
+		"The number of notices sent to X protocol clients by type.",
+		[]string{"type"}, nil,
+	)
+	mysqlxMessagesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, mysqlx, "messages_total"),
+		"The number of X protocol messages received by kind, such as statements, CRUD operations and cursors.",
+		[]string{"kind", "message"}, nil,
+	)
+)
+
+// ScrapeMysqlx collects from the Mysqlx_* status variables of the X plugin.
+type ScrapeMysqlx struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeMysqlx) Name() string {
+	return "mysqlx"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeMysqlx) Help() string {
+	return "Collect X protocol sessions, worker threads, traffic, errors and notices from the Mysqlx_* status variables"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeMysqlx) Version() float64 {
+	return 5.7
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeMysqlx) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	rows, err := db.QueryContext(ctx, mysqlxStatusQuery)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var (
+		key string
+		val sql.RawBytes
+	)
+	for rows.Next() {
+		if err := rows.Scan(&key, &val); err != nil {
+			return err
+		}
+		// Values like Mysqlx_address and Mysqlx_ssl_cipher are skipped.
+		value, ok := parseStatus(val)
+		if !ok {
+			continue
+		}
+		name := strings.TrimPrefix(strings.ToLower(key), "mysqlx_")
+		if desc, ok := mysqlxGauges[name]; ok {
+			ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value)
+			continue
+		}
+		switch name {
+		case "bytes_sent":
+			ch <- prometheus.MustNewConstMetric(mysqlxBytesSentDesc, prometheus.CounterValue, value)
+			continue
+		case "bytes_received":
+			ch <- prometheus.MustNewConstMetric(mysqlxBytesReceivedDesc, prometheus.CounterValue, value)
+			continue
+		case "errors_sent":
+			ch <- prometheus.MustNewConstMetric(mysqlxErrorsSentDesc, prometheus.CounterValue, value)
+			continue
+		}
+		match := mysqlxStatusRE.FindStringSubmatch(name)
+		if match == nil {
+			ch <- prometheus.MustNewConstMetric(
+				newDesc(mysqlx, validPrometheusName(name), "Generic metric from the Mysqlx_* status variables."),
+				prometheus.UntypedValue, value,
+			)
+			continue
+		}
+		switch match[1] {
+		case "sessions":
+			ch <- prometheus.MustNewConstMetric(mysqlxSessionsDesc, prometheus.CounterValue, value, match[2])
+		case "connections":
+			ch <- prometheus.MustNewConstMetric(mysqlxConnectionsDesc, prometheus.CounterValue, value, match[2])
+		case "notice":
+			ch <- prometheus.MustNewConstMetric(mysqlxNoticesSentDesc, prometheus.CounterValue, value, strings.TrimSuffix(match[2], "_sent"))
+		default:
+			ch <- prometheus.MustNewConstMetric(mysqlxMessagesDesc, prometheus.CounterValue, value, match[1], match[2])
+		}
+	}
+	return rows.Err()
+}
+
+// check interface
+var _ Scraper = ScrapeMysqlx{}
@@ -0,0 +1,85 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeMysqlx(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"Variable_name", "Value"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("Mysqlx_address", "::").
+		AddRow("Mysqlx_bytes_received", "1024").
+		AddRow("Mysqlx_bytes_sent", "4096").
+		AddRow("Mysqlx_connections_accepted", "12").
+		AddRow("Mysqlx_connections_active", "2").
+		AddRow("Mysqlx_crud_find", "30").
+		AddRow("Mysqlx_errors_sent", "3").
+		AddRow("Mysqlx_errors_unknown_message_type", "1").
+		AddRow("Mysqlx_notice_warning_sent", "5").
+		AddRow("Mysqlx_sessions", "2").
+		AddRow("Mysqlx_sessions_killed", "1").
+		AddRow("Mysqlx_stmt_execute_sql", "40").
+		AddRow("Mysqlx_worker_threads", "2")
+	mock.ExpectQuery(regexp.QuoteMeta(mysqlxStatusQuery)).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeMysqlx{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{}, value: 1024, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{}, value: 4096, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"state": "accepted"}, value: 12, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{}, value: 2, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"kind": "crud", "message": "find"}, value: 30, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{}, value: 3, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{}, value: 1, metricType: dto.MetricType_UNTYPED},
+		{labels: labelMap{"type": "warning"}, value: 5, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{}, value: 2, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"state": "killed"}, value: 1, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"kind": "stmt", "message": "execute_sql"}, value: 40, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{}, value: 2, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
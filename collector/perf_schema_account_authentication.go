@@ -0,0 +1,141 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape failed authentications from
+// `performance_schema.events_errors_summary_by_account_by_error` and locked
+// accounts from `mysql.user`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	MySQL "github.com/go-sql-driver/mysql"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// 1045 is ER_ACCESS_DENIED_ERROR, 3955 is raised while an account is
+	// temporarily locked by FAILED_LOGIN_ATTEMPTS.
+	perfAccountAuthErrorsQuery = `
+	SELECT
+	    ifnull(USER, '') AS USER,
+	    ifnull(HOST, '') AS HOST,
+	    ERROR_NAME,
+	    SUM_ERROR_RAISED
+	  FROM performance_schema.events_errors_summary_by_account_by_error
+	  WHERE ERROR_NUMBER IN (1045, 3955) AND SUM_ERROR_RAISED > 0
+	`
+	mysqlAccountsLockedQuery = `
+	SELECT
+	    COUNT(*)
+	  FROM mysql.user
+	  WHERE account_locked = 'Y'
+	`
+	// MySQL does not expose whether an account is temporarily locked right
+	// now, only which accounts have failed login tracking enabled.
+	mysqlAccountsFailedLoginTrackingQuery = `
+	SELECT
+	    COUNT(*)
+	  FROM mysql.user
+	  WHERE JSON_EXTRACT(User_attributes, '$.Password_locking.failed_login_attempts') > 0
+	`
+)
+
+// Metric descriptors.
+var (
+	performanceSchemaAccountAuthErrorsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "account_authentication_errors_total"),
+		"The total number of failed authentications by account and error.",
+		[]string{"user", "host", "error"}, nil,
+	)
+	mysqlAccountsLockedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, mysql, "accounts_locked"),
+		"The number of accounts locked with ACCOUNT LOCK.",
+		nil, nil,
+	)
+	mysqlAccountsFailedLoginTrackingDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, mysql, "accounts_failed_login_tracking"),
+		"The number of accounts with FAILED_LOGIN_ATTEMPTS tracking enabled.",
+		nil, nil,
+	)
+)
+
+// ScrapePerfAccountAuthentication collects failed authentications and locked accounts.
+type ScrapePerfAccountAuthentication struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapePerfAccountAuthentication) Name() string {
+	return performanceSchema + ".account_authentication"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapePerfAccountAuthentication) Help() string {
+	return "Collect failed authentications by account from performance_schema.events_errors_summary_by_account_by_error and locked accounts from mysql.user"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapePerfAccountAuthentication) Version() float64 {
+	return 8.0
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapePerfAccountAuthentication) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	errorRows, err := db.QueryContext(ctx, perfAccountAuthErrorsQuery)
+	if err != nil {
+		return err
+	}
+	defer errorRows.Close()
+
+	var (
+		user, host, errorName string
+		raised                float64
+	)
+	for errorRows.Next() {
+		if err := errorRows.Scan(&user, &host, &errorName, &raised); err != nil {
+			return err
+		}
+		ch <- prometheus.MustNewConstMetric(
+			performanceSchemaAccountAuthErrorsDesc, prometheus.CounterValue, raised,
+			user, host, errorName,
+		)
+	}
+	if err := errorRows.Err(); err != nil {
+		return err
+	}
+
+	var locked float64
+	if err := db.QueryRowContext(ctx, mysqlAccountsLockedQuery).Scan(&locked); err != nil {
+		return err
+	}
+	ch <- prometheus.MustNewConstMetric(mysqlAccountsLockedDesc, prometheus.GaugeValue, locked)
+
+	var tracking float64
+	if err := db.QueryRowContext(ctx, mysqlAccountsFailedLoginTrackingQuery).Scan(&tracking); err != nil {
+		mysqlErr, ok := err.(*MySQL.MySQLError)
+		// Check for error 1054: Unknown column, User_attributes was added in MySQL 8.0.19
+		if !ok || mysqlErr.Number != 1054 {
+			return err
+		}
+		level.Debug(logger).Log("msg", "mysql.user.User_attributes is not available.")
+		return nil
+	}
+	ch <- prometheus.MustNewConstMetric(mysqlAccountsFailedLoginTrackingDesc, prometheus.GaugeValue, tracking)
+	return nil
+}
+
+// check interface
+var _ Scraper = ScrapePerfAccountAuthentication{}
@@ -0,0 +1,93 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	MySQL "github.com/go-sql-driver/mysql"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapePerfAccountAuthentication(t *testing.T) {
+	convey.Convey("Scrape account authentication", t, func() {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("error opening a stub database connection: %s", err)
+		}
+		defer db.Close()
+
+		columns := []string{"USER", "HOST", "ERROR_NAME", "SUM_ERROR_RAISED"}
+		rows := sqlmock.NewRows(columns).
+			AddRow("app", "%", "ER_ACCESS_DENIED_ERROR", 12).
+			AddRow("app", "%", "ER_USER_ACCESS_DENIED_FOR_USER_ACCOUNT_BLOCKED_BY_PASSWORD_LOCK", 3).
+			AddRow("", "", "ER_ACCESS_DENIED_ERROR", 40)
+		mock.ExpectQuery(sanitizeQuery(perfAccountAuthErrorsQuery)).WillReturnRows(rows)
+		mock.ExpectQuery(sanitizeQuery(mysqlAccountsLockedQuery)).WillReturnRows(sqlmock.NewRows([]string{"COUNT(*)"}).AddRow(2))
+
+		convey.Convey("With User_attributes", func() {
+			mock.ExpectQuery("FROM mysql.user WHERE JSON_EXTRACT").WillReturnRows(sqlmock.NewRows([]string{"COUNT(*)"}).AddRow(1))
+
+			ch := make(chan prometheus.Metric)
+			go func() {
+				if err = (ScrapePerfAccountAuthentication{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+					t.Errorf("error calling function on test: %s", err)
+				}
+				close(ch)
+			}()
+
+			expected := []MetricResult{
+				{labels: labelMap{"user": "app", "host": "%", "error": "ER_ACCESS_DENIED_ERROR"}, value: 12, metricType: dto.MetricType_COUNTER},
+				{labels: labelMap{"user": "app", "host": "%", "error": "ER_USER_ACCESS_DENIED_FOR_USER_ACCOUNT_BLOCKED_BY_PASSWORD_LOCK"}, value: 3, metricType: dto.MetricType_COUNTER},
+				{labels: labelMap{"user": "", "host": "", "error": "ER_ACCESS_DENIED_ERROR"}, value: 40, metricType: dto.MetricType_COUNTER},
+				{labels: labelMap{}, value: 2, metricType: dto.MetricType_GAUGE},
+				{labels: labelMap{}, value: 1, metricType: dto.MetricType_GAUGE},
+			}
+			for _, expect := range expected {
+				got := readMetric(<-ch)
+				convey.So(got, convey.ShouldResemble, expect)
+			}
+			_, more := <-ch
+			convey.So(more, convey.ShouldBeFalse)
+		})
+
+		convey.Convey("Without User_attributes", func() {
+			mock.ExpectQuery("FROM mysql.user WHERE JSON_EXTRACT").WillReturnError(&MySQL.MySQLError{Number: 1054})
+
+			ch := make(chan prometheus.Metric)
+			go func() {
+				if err = (ScrapePerfAccountAuthentication{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+					t.Errorf("error calling function on test: %s", err)
+				}
+				close(ch)
+			}()
+
+			count := 0
+			for range ch {
+				count++
+			}
+			convey.So(count, convey.ShouldEqual, 4)
+		})
+
+		// Ensure all SQL queries were executed
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled exceptions: %s", err)
+		}
+	})
+}
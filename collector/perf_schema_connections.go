@@ -0,0 +1,226 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape connection churn from the aborted connection status variables,
+// `performance_schema.accounts` and `performance_schema.threads`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// Subsystem.
+	connections = "connections"
+	// Queries.
+	connectionsStatusQuery = `
+	SHOW GLOBAL STATUS
+	  WHERE Variable_name IN ('Aborted_clients', 'Aborted_connects', 'Max_used_connections')
+	`
+	perfAccountConnectionsQuery = `
+	SELECT
+	    USER,
+	    HOST,
+	    CURRENT_CONNECTIONS,
+	    TOTAL_CONNECTIONS
+	  FROM performance_schema.accounts
+	  WHERE USER IS NOT NULL AND HOST IS NOT NULL
+	`
+	// The connection of the scrape is left out, it only lives for a scrape.
+	perfConnectionIDsQuery = `
+	SELECT PROCESSLIST_ID
+	  FROM performance_schema.threads
+	  WHERE TYPE = 'FOREGROUND' AND PROCESSLIST_ID IS NOT NULL AND PROCESSLIST_ID <> CONNECTION_ID()
+	`
+)
+
+// connectionsStatus maps the status variables to metrics.
+var connectionsStatus = map[string]struct {
+	vtype prometheus.ValueType
+	desc  *prometheus.Desc
+}{
+	"Aborted_clients": {prometheus.CounterValue,
+		prometheus.NewDesc(prometheus.BuildFQName(namespace, connections, "aborted_clients_total"),
+			"The number of connections aborted because the client died without closing the connection properly.", nil, nil)},
+	"Aborted_connects": {prometheus.CounterValue,
+		prometheus.NewDesc(prometheus.BuildFQName(namespace, connections, "aborted_connects_total"),
+			"The number of failed attempts to connect to the server.", nil, nil)},
+	"Max_used_connections": {prometheus.GaugeValue,
+		prometheus.NewDesc(prometheus.BuildFQName(namespace, connections, "max_used"),
+			"The maximum number of connections in use simultaneously since the server started.", nil, nil)},
+}
+
+// Metric descriptors.
+var (
+	connectionsAccountCurrentDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, connections, "account_current"),
+		"The number of current connections of the account.",
+		[]string{"user", "host"}, nil,
+	)
+	connectionsAccountTotalDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, connections, "account_total"),
+		"The number of connections of the account since the server started.",
+		[]string{"user", "host"}, nil,
+	)
+)
+
+var connectionLifetimeOpts = prometheus.HistogramOpts{
+	Namespace: namespace,
+	Subsystem: connections,
+	Name:      "lifetime_seconds",
+	Help:      "Lifetime of the closed connections, from the first to the last scrape they were seen in.",
+	Buckets:   prometheus.ExponentialBuckets(1, 4, 10),
+}
+
+// connectionLifetimes tracks the connections seen by the scrapes.
+var connectionLifetimes = newConnectionTracker()
+
+// connectionTracker observes the lifetime of connections from when they
+// are first and last seen.
+type connectionTracker struct {
+	mu       sync.Mutex
+	seen     map[uint64][2]time.Time
+	lifetime prometheus.Histogram
+}
+
+func newConnectionTracker() *connectionTracker {
+	return &connectionTracker{lifetime: prometheus.NewHistogram(connectionLifetimeOpts)}
+}
+
+// update records the connections open at now and observes the lifetime of
+// the ones which closed since the previous scrape. Connections open on the
+// first scrape are not observed, their start is unknown.
+func (t *connectionTracker) update(ids []uint64, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	first := t.seen == nil
+	seen := make(map[uint64][2]time.Time, len(ids))
+	for _, id := range ids {
+		switch prev, ok := t.seen[id]; {
+		case ok:
+			seen[id] = [2]time.Time{prev[0], now}
+		case first:
+			seen[id] = [2]time.Time{}
+		default:
+			seen[id] = [2]time.Time{now, now}
+		}
+	}
+	for id, times := range t.seen {
+		if _, ok := seen[id]; !ok && !times[0].IsZero() {
+			t.lifetime.Observe(times[1].Sub(times[0]).Seconds())
+		}
+	}
+	t.seen = seen
+}
+
+// ScrapePerfConnections collects aborted connections, the connections of
+// each account and the lifetime of connections.
+type ScrapePerfConnections struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapePerfConnections) Name() string {
+	return "perf_schema.connections"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapePerfConnections) Help() string {
+	return "Collect aborted connections, connections by account from performance_schema.accounts and the lifetime of connections from performance_schema.threads"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapePerfConnections) Version() float64 {
+	return 5.6
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapePerfConnections) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	statusRows, err := queryRows(ctx, db, connectionsStatusQuery)
+	if err != nil {
+		return err
+	}
+	defer statusRows.Close()
+
+	var name, value string
+	for statusRows.Next() {
+		if err := statusRows.Scan(&name, &value); err != nil {
+			return err
+		}
+		metric, ok := connectionsStatus[name]
+		if !ok {
+			continue
+		}
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(metric.desc, metric.vtype, v)
+	}
+	if err := statusRows.Err(); err != nil {
+		return err
+	}
+
+	accountRows, err := db.QueryContext(ctx, perfAccountConnectionsQuery)
+	if err != nil {
+		return err
+	}
+	defer accountRows.Close()
+
+	var (
+		user, host     string
+		current, total float64
+	)
+	for accountRows.Next() {
+		if err := accountRows.Scan(&user, &host, &current, &total); err != nil {
+			return err
+		}
+		ch <- prometheus.MustNewConstMetric(connectionsAccountCurrentDesc, prometheus.GaugeValue, current, user, host)
+		ch <- prometheus.MustNewConstMetric(connectionsAccountTotalDesc, prometheus.CounterValue, total, user, host)
+	}
+	if err := accountRows.Err(); err != nil {
+		return err
+	}
+
+	idRows, err := db.QueryContext(ctx, perfConnectionIDsQuery)
+	if err != nil {
+		return err
+	}
+	defer idRows.Close()
+
+	var ids []uint64
+	for idRows.Next() {
+		var id uint64
+		if err := idRows.Scan(&id); err != nil {
+			return err
+		}
+		ids = append(ids, id)
+	}
+	if err := idRows.Err(); err != nil {
+		return err
+	}
+	if !catalogRun(ctx) {
+		connectionLifetimes.update(ids, time.Now())
+	}
+	connectionLifetimes.lifetime.Collect(ch)
+	return nil
+}
+
+// check interface
+var _ Scraper = ScrapePerfConnections{}
@@ -0,0 +1,94 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapePerfConnections(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	defer func(t *connectionTracker) { connectionLifetimes = t }(connectionLifetimes)
+	connectionLifetimes = newConnectionTracker()
+
+	mock.ExpectQuery(sanitizeQuery(connectionsStatusQuery)).WillReturnRows(
+		sqlmock.NewRows([]string{"Variable_name", "Value"}).
+			AddRow("Aborted_clients", "12").
+			AddRow("Aborted_connects", "3").
+			AddRow("Max_used_connections", "151"))
+	mock.ExpectQuery(sanitizeQuery(perfAccountConnectionsQuery)).WillReturnRows(
+		sqlmock.NewRows([]string{"USER", "HOST", "CURRENT_CONNECTIONS", "TOTAL_CONNECTIONS"}).
+			AddRow("app", "10.0.0.1", 20, 51234))
+	mock.ExpectQuery(sanitizeQuery(perfConnectionIDsQuery)).WillReturnRows(
+		sqlmock.NewRows([]string{"PROCESSLIST_ID"}).AddRow(7).AddRow(8))
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapePerfConnections{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{}, value: 12, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{}, value: 3, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{}, value: 151, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"user": "app", "host": "10.0.0.1"}, value: 20, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"user": "app", "host": "10.0.0.1"}, value: 51234, metricType: dto.MetricType_COUNTER},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+		m := &dto.Metric{}
+		convey.So((<-ch).Write(m), convey.ShouldBeNil)
+		convey.So(m.GetHistogram().GetSampleCount(), convey.ShouldEqual, 0)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestConnectionTracker(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	tracker := newConnectionTracker()
+	tracker.update([]uint64{1, 2}, start)
+	tracker.update([]uint64{2, 3}, start.Add(time.Minute))
+	tracker.update([]uint64{2, 3}, start.Add(2*time.Minute))
+	tracker.update([]uint64{4}, start.Add(3*time.Minute))
+
+	convey.Convey("Only connections opened after the first scrape are observed", t, func() {
+		m := &dto.Metric{}
+		convey.So(tracker.lifetime.Write(m), convey.ShouldBeNil)
+		convey.So(m.GetHistogram().GetSampleCount(), convey.ShouldEqual, 1)
+		convey.So(m.GetHistogram().GetSampleSum(), convey.ShouldEqual, 60)
+	})
+}
@@ -0,0 +1,110 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape stage progress from `performance_schema.events_stages_current`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Only stages instrumented for progress report WORK_ESTIMATED, such as
+// ALTER TABLE and the InnoDB buffer pool load.
+const perfEventsStagesCurrentQuery = `
+	SELECT
+	    THREAD_ID,
+	    EVENT_NAME,
+	    WORK_COMPLETED,
+	    WORK_ESTIMATED,
+	    ifnull(TIMER_WAIT, 0) AS TIMER_WAIT
+	  FROM performance_schema.events_stages_current
+	  WHERE WORK_ESTIMATED > 0
+	`
+
+// Metric descriptors.
+var (
+	performanceSchemaStageWorkCompletedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "stage_work_completed"),
+		"The number of work units completed by the current stage.",
+		[]string{"thread_id", "event_name"}, nil,
+	)
+	performanceSchemaStageWorkEstimatedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "stage_work_estimated"),
+		"The number of work units the current stage is expected to perform.",
+		[]string{"thread_id", "event_name"}, nil,
+	)
+	performanceSchemaStageProgressDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "stage_progress_ratio"),
+		"The ratio of completed to estimated work units of the current stage.",
+		[]string{"thread_id", "event_name"}, nil,
+	)
+	performanceSchemaStageElapsedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "stage_elapsed_seconds"),
+		"The time spent in the current stage so far, 0 if the stage is not timed.",
+		[]string{"thread_id", "event_name"}, nil,
+	)
+)
+
+// ScrapePerfEventsStagesCurrent collects stage progress from `performance_schema.events_stages_current`.
+type ScrapePerfEventsStagesCurrent struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapePerfEventsStagesCurrent) Name() string {
+	return performanceSchema + ".events_stages_current"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapePerfEventsStagesCurrent) Help() string {
+	return "Collect progress of long running stages from performance_schema.events_stages_current"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapePerfEventsStagesCurrent) Version() float64 {
+	return 5.7
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapePerfEventsStagesCurrent) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	// Timers here are returned in picoseconds.
+	rows, err := db.QueryContext(ctx, perfEventsStagesCurrentQuery)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var (
+		threadID                      uint64
+		eventName                     string
+		completed, estimated, elapsed float64
+	)
+	for rows.Next() {
+		if err := rows.Scan(&threadID, &eventName, &completed, &estimated, &elapsed); err != nil {
+			return err
+		}
+		thread := strconv.FormatUint(threadID, 10)
+		ch <- prometheus.MustNewConstMetric(performanceSchemaStageWorkCompletedDesc, prometheus.GaugeValue, completed, thread, eventName)
+		ch <- prometheus.MustNewConstMetric(performanceSchemaStageWorkEstimatedDesc, prometheus.GaugeValue, estimated, thread, eventName)
+		ch <- prometheus.MustNewConstMetric(performanceSchemaStageProgressDesc, prometheus.GaugeValue, completed/estimated, thread, eventName)
+		ch <- prometheus.MustNewConstMetric(performanceSchemaStageElapsedDesc, prometheus.GaugeValue, elapsed/picoSeconds, thread, eventName)
+	}
+	return rows.Err()
+}
+
+// check interface
+var _ Scraper = ScrapePerfEventsStagesCurrent{}
@@ -0,0 +1,71 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapePerfEventsStagesCurrent(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"THREAD_ID", "EVENT_NAME", "WORK_COMPLETED", "WORK_ESTIMATED", "TIMER_WAIT"}
+	rows := sqlmock.NewRows(columns).
+		AddRow(48, "stage/innodb/alter table (read PK and internal sort)", 250, 1000, 90000000000000).
+		AddRow(5, "stage/innodb/buffer pool load", 30, 120, 0)
+	mock.ExpectQuery(sanitizeQuery(perfEventsStagesCurrentQuery)).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapePerfEventsStagesCurrent{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	alter := labelMap{"thread_id": "48", "event_name": "stage/innodb/alter table (read PK and internal sort)"}
+	load := labelMap{"thread_id": "5", "event_name": "stage/innodb/buffer pool load"}
+	expected := []MetricResult{
+		{labels: alter, value: 250, metricType: dto.MetricType_GAUGE},
+		{labels: alter, value: 1000, metricType: dto.MetricType_GAUGE},
+		{labels: alter, value: 0.25, metricType: dto.MetricType_GAUGE},
+		{labels: alter, value: 90, metricType: dto.MetricType_GAUGE},
+		{labels: load, value: 30, metricType: dto.MetricType_GAUGE},
+		{labels: load, value: 120, metricType: dto.MetricType_GAUGE},
+		{labels: load, value: 0.25, metricType: dto.MetricType_GAUGE},
+		{labels: load, value: 0, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape `performance_schema.events_statements_summary_by_digest` rolled up by schema.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// MySQL has no statement summary by schema, digests are rolled up instead.
+// Totals drop when digests are evicted from a full digest table.
+const perfEventsStatementsBySchemaQuery = `
+	SELECT
+	    ifnull(SCHEMA_NAME, 'NONE') as SCHEMA_NAME,
+	    SUM(COUNT_STAR),
+	    SUM(SUM_TIMER_WAIT),
+	    SUM(SUM_ERRORS),
+	    SUM(SUM_ROWS_EXAMINED),
+	    SUM(SUM_ROWS_SENT)
+	  FROM performance_schema.events_statements_summary_by_digest
+	  WHERE SCHEMA_NAME IS NULL OR SCHEMA_NAME NOT IN ('mysql', 'performance_schema', 'information_schema')
+	  GROUP BY SCHEMA_NAME
+	  ORDER BY SUM(COUNT_STAR) DESC
+	  %s
+	`
+
+// Tunable flags.
+var (
+	perfEventsStatementsBySchemaLimit = kingpin.Flag(
+		"collect.perf_schema.eventsstatementsbyschema.limit",
+		"Limit the number of schemas by number of statements, 0 for no limit",
+	).Default("0").Int()
+)
+
+// Metric descriptors.
+var (
+	performanceSchemaSchemaStatementsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "schema_statements_total"),
+		"The total number of statements run in a schema.",
+		[]string{"schema"}, nil,
+	)
+	performanceSchemaSchemaStatementsSecondsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "schema_statements_seconds_total"),
+		"The total time of statements run in a schema.",
+		[]string{"schema"}, nil,
+	)
+	performanceSchemaSchemaStatementsErrorsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "schema_statements_errors_total"),
+		"The total number of statements run in a schema which failed.",
+		[]string{"schema"}, nil,
+	)
+	performanceSchemaSchemaRowsExaminedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "schema_statements_rows_examined_total"),
+		"The total number of rows examined by statements run in a schema.",
+		[]string{"schema"}, nil,
+	)
+	performanceSchemaSchemaRowsSentDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "schema_statements_rows_sent_total"),
+		"The total number of rows sent by statements run in a schema.",
+		[]string{"schema"}, nil,
+	)
+)
+
+// ScrapePerfEventsStatementsBySchema collects statements per schema from `performance_schema.events_statements_summary_by_digest`.
+type ScrapePerfEventsStatementsBySchema struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapePerfEventsStatementsBySchema) Name() string {
+	return "perf_schema.eventsstatementsbyschema"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapePerfEventsStatementsBySchema) Help() string {
+	return "Collect statements and latency per schema from performance_schema.events_statements_summary_by_digest"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapePerfEventsStatementsBySchema) Version() float64 {
+	return 5.6
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapePerfEventsStatementsBySchema) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	limit := ""
+	if *perfEventsStatementsBySchemaLimit > 0 {
+		limit = fmt.Sprintf("LIMIT %d", *perfEventsStatementsBySchemaLimit)
+	}
+	// Timers here are returned in picoseconds.
+	rows, err := db.QueryContext(ctx, fmt.Sprintf(perfEventsStatementsBySchemaQuery, limit))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var (
+		schemaName               string
+		count, timerWait, errors float64
+		rowsExamined, rowsSent   float64
+	)
+	for rows.Next() {
+		if err := rows.Scan(&schemaName, &count, &timerWait, &errors, &rowsExamined, &rowsSent); err != nil {
+			return err
+		}
+		ch <- prometheus.MustNewConstMetric(performanceSchemaSchemaStatementsDesc, prometheus.CounterValue, count, schemaName)
+		ch <- prometheus.MustNewConstMetric(performanceSchemaSchemaStatementsSecondsDesc, prometheus.CounterValue, timerWait/picoSeconds, schemaName)
+		ch <- prometheus.MustNewConstMetric(performanceSchemaSchemaStatementsErrorsDesc, prometheus.CounterValue, errors, schemaName)
+		ch <- prometheus.MustNewConstMetric(performanceSchemaSchemaRowsExaminedDesc, prometheus.CounterValue, rowsExamined, schemaName)
+		ch <- prometheus.MustNewConstMetric(performanceSchemaSchemaRowsSentDesc, prometheus.CounterValue, rowsSent, schemaName)
+	}
+	return rows.Err()
+}
+
+// check interface
+var _ Scraper = ScrapePerfEventsStatementsBySchema{}
@@ -0,0 +1,80 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapePerfEventsStatementsBySchema(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.perf_schema.eventsstatementsbyschema.limit=2",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"SCHEMA_NAME", "SUM(COUNT_STAR)", "SUM(SUM_TIMER_WAIT)", "SUM(SUM_ERRORS)", "SUM(SUM_ROWS_EXAMINED)", "SUM(SUM_ROWS_SENT)"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("tenant1", 1000, 2000000000000, 3, 50000, 900).
+		AddRow("NONE", 10, 500000000000, 0, 0, 10)
+	mock.ExpectQuery(sanitizeQuery(fmt.Sprintf(perfEventsStatementsBySchemaQuery, "LIMIT 2"))).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapePerfEventsStatementsBySchema{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{"schema": "tenant1"}, value: 1000, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "tenant1"}, value: 2, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "tenant1"}, value: 3, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "tenant1"}, value: 50000, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "tenant1"}, value: 900, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "NONE"}, value: 10, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "NONE"}, value: 0.5, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "NONE"}, value: 0, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "NONE"}, value: 0, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "NONE"}, value: 10, metricType: dto.MetricType_COUNTER},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape running statements from `performance_schema.events_statements_current`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// TIMER_WAIT of a statement which has not ended is the time elapsed so far.
+// The statements of the scraping connection are skipped.
+const perfEventsStatementsCurrentQuery = `
+	SELECT
+	    ifnull(CURRENT_SCHEMA, 'NONE') as SCHEMA_NAME,
+	    DIGEST,
+	    LEFT(DIGEST_TEXT, %d) as DIGEST_TEXT,
+	    COUNT(*),
+	    MAX(TIMER_WAIT)
+	  FROM performance_schema.events_statements_current
+	  WHERE END_EVENT_ID IS NULL
+	    AND DIGEST IS NOT NULL
+	    AND THREAD_ID NOT IN (
+	      SELECT THREAD_ID FROM performance_schema.threads WHERE PROCESSLIST_ID = CONNECTION_ID()
+	    )
+	  GROUP BY SCHEMA_NAME, DIGEST, DIGEST_TEXT
+	  ORDER BY MAX(TIMER_WAIT) DESC
+	  LIMIT %d
+	`
+
+// Tunable flags.
+var (
+	perfEventsStatementsCurrentLimit = kingpin.Flag(
+		"collect.perf_schema.eventsstatementscurrent.limit",
+		"Limit the number of running statement digests by age of their longest-running statement",
+	).Default("50").Int()
+)
+
+// Metric descriptors.
+var (
+	performanceSchemaCurrentStatementsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "current_statements"),
+		"The number of running statements by digest.",
+		[]string{"schema", "digest", "digest_text"}, nil,
+	)
+	performanceSchemaCurrentStatementsMaxAgeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "current_statements_max_age_seconds"),
+		"The time the longest-running statement of a digest has been running.",
+		[]string{"schema", "digest", "digest_text"}, nil,
+	)
+)
+
+// ScrapePerfEventsStatementsCurrent samples running statements from `performance_schema.events_statements_current`.
+type ScrapePerfEventsStatementsCurrent struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapePerfEventsStatementsCurrent) Name() string {
+	return "perf_schema.eventsstatementscurrent"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapePerfEventsStatementsCurrent) Help() string {
+	return "Collect running statements by digest from performance_schema.events_statements_current"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapePerfEventsStatementsCurrent) Version() float64 {
+	return 5.7
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapePerfEventsStatementsCurrent) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	query := fmt.Sprintf(perfEventsStatementsCurrentQuery, *perfEventsStatementsDigestTextLimit, *perfEventsStatementsCurrentLimit)
+	// Timers here are returned in picoseconds.
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var (
+		schemaName, digest, digestText string
+		count, maxTimerWait            float64
+	)
+	for rows.Next() {
+		if err := rows.Scan(&schemaName, &digest, &digestText, &count, &maxTimerWait); err != nil {
+			return err
+		}
+		ch <- prometheus.MustNewConstMetric(
+			performanceSchemaCurrentStatementsDesc, prometheus.GaugeValue, count,
+			schemaName, digest, digestText,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			performanceSchemaCurrentStatementsMaxAgeDesc, prometheus.GaugeValue, maxTimerWait/picoSeconds,
+			schemaName, digest, digestText,
+		)
+	}
+	return rows.Err()
+}
+
+// check interface
+var _ Scraper = ScrapePerfEventsStatementsCurrent{}
@@ -0,0 +1,75 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapePerfEventsStatementsCurrent(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.perf_schema.eventsstatementscurrent.limit=10",
+		"--collect.perf_schema.eventsstatements.digest_text_limit=20",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"SCHEMA_NAME", "DIGEST", "DIGEST_TEXT", "COUNT(*)", "MAX(TIMER_WAIT)"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("shop", "d1", "SELECT SLEEP ( ? )", 3, 90000000000000).
+		AddRow("NONE", "d2", "SELECT ?", 1, 500000000000)
+	mock.ExpectQuery(sanitizeQuery(fmt.Sprintf(perfEventsStatementsCurrentQuery, 20, 10))).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapePerfEventsStatementsCurrent{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{"schema": "shop", "digest": "d1", "digest_text": "SELECT SLEEP ( ? )"}, value: 3, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"schema": "shop", "digest": "d1", "digest_text": "SELECT SLEEP ( ? )"}, value: 90, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"schema": "NONE", "digest": "d2", "digest_text": "SELECT ?"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"schema": "NONE", "digest": "d2", "digest_text": "SELECT ?"}, value: 0.5, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
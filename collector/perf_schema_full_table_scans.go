@@ -0,0 +1,147 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape full table scans from `performance_schema.events_statements_summary_by_digest`,
+// like `sys.statements_with_full_table_scans`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	perfFullTableScansTotalQuery = `
+	SELECT
+	    ifnull(SUM(SUM_NO_INDEX_USED), 0),
+	    ifnull(SUM(SUM_NO_GOOD_INDEX_USED), 0)
+	  FROM performance_schema.events_statements_summary_by_digest
+	`
+	perfFullTableScansDigestQuery = `
+	SELECT
+	    ifnull(SCHEMA_NAME, 'NONE') as SCHEMA_NAME,
+	    ifnull(DIGEST, 'NONE') as DIGEST,
+	    LEFT(DIGEST_TEXT, %d) as DIGEST_TEXT,
+	    SUM_NO_INDEX_USED,
+	    SUM_NO_GOOD_INDEX_USED,
+	    SUM_ROWS_EXAMINED
+	  FROM performance_schema.events_statements_summary_by_digest
+	  WHERE SUM_NO_INDEX_USED > 0 OR SUM_NO_GOOD_INDEX_USED > 0
+	  ORDER BY SUM_NO_INDEX_USED + SUM_NO_GOOD_INDEX_USED DESC
+	  LIMIT %d
+	`
+)
+
+// Tunable flags.
+var (
+	perfFullTableScansLimit = kingpin.Flag(
+		"collect.perf_schema.fulltablescans.limit",
+		"Limit the number of digests by number of full table scans",
+	).Default("20").Int()
+)
+
+// Metric descriptors.
+var (
+	performanceSchemaFullTableScansDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "full_table_scan_statements_total"),
+		"The total number of statements which did a table scan without using an index.",
+		nil, nil,
+	)
+	performanceSchemaNoGoodIndexDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "no_good_index_statements_total"),
+		"The total number of statements for which no good index was found.",
+		nil, nil,
+	)
+	performanceSchemaDigestFullTableScansDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "digest_full_table_scan_statements_total"),
+		"The number of statements of a digest which did a table scan without using an index.",
+		[]string{"schema", "digest", "digest_text"}, nil,
+	)
+	performanceSchemaDigestNoGoodIndexDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "digest_no_good_index_statements_total"),
+		"The number of statements of a digest for which no good index was found.",
+		[]string{"schema", "digest", "digest_text"}, nil,
+	)
+	performanceSchemaDigestScanRowsExaminedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "digest_full_table_scan_rows_examined_total"),
+		"The number of rows examined by the statements of a digest doing full table scans.",
+		[]string{"schema", "digest", "digest_text"}, nil,
+	)
+)
+
+// ScrapePerfFullTableScans collects full table scans from `performance_schema.events_statements_summary_by_digest`.
+type ScrapePerfFullTableScans struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapePerfFullTableScans) Name() string {
+	return "perf_schema.fulltablescans"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapePerfFullTableScans) Help() string {
+	return "Collect full table scans and the digests doing most of them from performance_schema.events_statements_summary_by_digest"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapePerfFullTableScans) Version() float64 {
+	return 5.6
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapePerfFullTableScans) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	var noIndexUsed, noGoodIndexUsed float64
+	if err := db.QueryRowContext(ctx, perfFullTableScansTotalQuery).Scan(&noIndexUsed, &noGoodIndexUsed); err != nil {
+		return err
+	}
+	ch <- prometheus.MustNewConstMetric(performanceSchemaFullTableScansDesc, prometheus.CounterValue, noIndexUsed)
+	ch <- prometheus.MustNewConstMetric(performanceSchemaNoGoodIndexDesc, prometheus.CounterValue, noGoodIndexUsed)
+
+	query := fmt.Sprintf(perfFullTableScansDigestQuery, *perfEventsStatementsDigestTextLimit, *perfFullTableScansLimit)
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var (
+		schemaName, digest, digestText string
+		rowsExamined                   float64
+	)
+	for rows.Next() {
+		if err := rows.Scan(&schemaName, &digest, &digestText, &noIndexUsed, &noGoodIndexUsed, &rowsExamined); err != nil {
+			return err
+		}
+		ch <- prometheus.MustNewConstMetric(
+			performanceSchemaDigestFullTableScansDesc, prometheus.CounterValue, noIndexUsed,
+			schemaName, digest, digestText,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			performanceSchemaDigestNoGoodIndexDesc, prometheus.CounterValue, noGoodIndexUsed,
+			schemaName, digest, digestText,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			performanceSchemaDigestScanRowsExaminedDesc, prometheus.CounterValue, rowsExamined,
+			schemaName, digest, digestText,
+		)
+	}
+	return rows.Err()
+}
+
+// check interface
+var _ Scraper = ScrapePerfFullTableScans{}
@@ -0,0 +1,77 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapePerfFullTableScans(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.perf_schema.fulltablescans.limit=5",
+		"--collect.perf_schema.eventsstatements.digest_text_limit=30",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(perfFullTableScansTotalQuery)).WillReturnRows(
+		sqlmock.NewRows([]string{"SUM(SUM_NO_INDEX_USED)", "SUM(SUM_NO_GOOD_INDEX_USED)"}).AddRow(120, 4))
+	columns := []string{"SCHEMA_NAME", "DIGEST", "DIGEST_TEXT", "SUM_NO_INDEX_USED", "SUM_NO_GOOD_INDEX_USED", "SUM_ROWS_EXAMINED"}
+	mock.ExpectQuery(sanitizeQuery(fmt.Sprintf(perfFullTableScansDigestQuery, 30, 5))).WillReturnRows(
+		sqlmock.NewRows(columns).AddRow("shop", "d1", "SELECT * FROM `orders`", 100, 0, 500000))
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapePerfFullTableScans{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	digestLabels := labelMap{"schema": "shop", "digest": "d1", "digest_text": "SELECT * FROM `orders`"}
+	expected := []MetricResult{
+		{labels: labelMap{}, value: 120, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{}, value: 4, metricType: dto.MetricType_COUNTER},
+		{labels: digestLabels, value: 100, metricType: dto.MetricType_COUNTER},
+		{labels: digestLabels, value: 0, metricType: dto.MetricType_COUNTER},
+		{labels: digestLabels, value: 500000, metricType: dto.MetricType_COUNTER},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
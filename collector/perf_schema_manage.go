@@ -0,0 +1,181 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+)
+
+const (
+	perfSchemaEnableConsumersQuery = `
+	UPDATE performance_schema.setup_consumers
+	  SET ENABLED = 'YES'
+	  WHERE NAME IN (%s) AND ENABLED <> 'YES'
+	`
+	perfSchemaEnableInstrumentsQuery = `
+	UPDATE performance_schema.setup_instruments
+	  SET ENABLED = 'YES', TIMED = 'YES'
+	  WHERE NAME LIKE ? AND (ENABLED <> 'YES' OR TIMED <> 'YES')
+	`
+)
+
+// Tunable flags.
+var (
+	managePerfSchema = kingpin.Flag(
+		"exporter.manage_perf_schema",
+		"Enable the performance_schema consumers and instruments required by the enabled collectors at startup, requires UPDATE on performance_schema.",
+	).Default("false").Bool()
+)
+
+// perfSchemaRequirement lists the consumers and instruments (as LIKE patterns)
+// a scraper needs to return data.
+type perfSchemaRequirement struct {
+	consumers   []string
+	instruments []string
+}
+
+// Consumers are hierarchical, a consumer only collects when the consumers
+// above it are enabled too.
+var perfSchemaRequirements = map[string]perfSchemaRequirement{
+	ScrapePerfEventsStatements{}.Name(): {
+		consumers:   []string{"global_instrumentation", "thread_instrumentation", "statements_digest"},
+		instruments: []string{"statement/%"},
+	},
+	ScrapePerfEventsStatementsSum{}.Name(): {
+		consumers:   []string{"global_instrumentation", "thread_instrumentation", "statements_digest"},
+		instruments: []string{"statement/%"},
+	},
+	ScrapePerfEventsStatementsBySchema{}.Name(): {
+		consumers:   []string{"global_instrumentation", "thread_instrumentation", "statements_digest"},
+		instruments: []string{"statement/%"},
+	},
+	ScrapePerfFullTableScans{}.Name(): {
+		consumers:   []string{"global_instrumentation", "thread_instrumentation", "statements_digest"},
+		instruments: []string{"statement/%"},
+	},
+	ScrapePerfEventsStatementsCurrent{}.Name(): {
+		consumers:   []string{"global_instrumentation", "thread_instrumentation", "events_statements_current", "statements_digest"},
+		instruments: []string{"statement/%"},
+	},
+	ScrapePerfEventsStagesCurrent{}.Name(): {
+		consumers:   []string{"global_instrumentation", "thread_instrumentation", "events_stages_current"},
+		instruments: []string{"stage/innodb/alter%", "stage/innodb/buffer pool load", "stage/sql/copy to tmp table"},
+	},
+	ScrapePerfEventsWaits{}.Name(): {
+		consumers:   []string{"global_instrumentation"},
+		instruments: []string{"wait/%"},
+	},
+	ScrapePerfFileEvents{}.Name(): {
+		consumers:   []string{"global_instrumentation"},
+		instruments: []string{"wait/io/file/%"},
+	},
+	ScrapePerfFileInstances{}.Name(): {
+		consumers:   []string{"global_instrumentation"},
+		instruments: []string{"wait/io/file/%"},
+	},
+	ScrapePerfIndexIOWaits{}.Name(): {
+		consumers:   []string{"global_instrumentation"},
+		instruments: []string{"wait/io/table/sql/handler"},
+	},
+	ScrapePerfTableIOWaits{}.Name(): {
+		consumers:   []string{"global_instrumentation"},
+		instruments: []string{"wait/io/table/sql/handler"},
+	},
+	ScrapePerfTableLockWaits{}.Name(): {
+		consumers:   []string{"global_instrumentation"},
+		instruments: []string{"wait/lock/table/sql/handler"},
+	},
+	ScrapePerfMemoryEvents{}.Name(): {
+		instruments: []string{"memory/%"},
+	},
+	ScrapePerfPreparedStatements{}.Name(): {
+		consumers:   []string{"global_instrumentation"},
+		instruments: []string{"statement/%"},
+	},
+}
+
+// ManagePerfSchema enables the performance_schema consumers and instruments
+// needed by the given scrapers when --exporter.manage_perf_schema is set.
+// Changes made at runtime are lost when mysqld restarts.
+func ManagePerfSchema(ctx context.Context, dsn string, scrapers []Scraper, logger log.Logger) error {
+	if !*managePerfSchema {
+		return nil
+	}
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return enablePerfSchema(ctx, db, scrapers, logger)
+}
+
+// enablePerfSchema enables the consumers and instruments required by scrapers.
+func enablePerfSchema(ctx context.Context, db *sql.DB, scrapers []Scraper, logger log.Logger) error {
+	consumerSet := map[string]bool{}
+	instrumentSet := map[string]bool{}
+	for _, scraper := range scrapers {
+		req := perfSchemaRequirements[scraper.Name()]
+		for _, consumer := range req.consumers {
+			consumerSet[consumer] = true
+		}
+		for _, instrument := range req.instruments {
+			instrumentSet[instrument] = true
+		}
+	}
+	consumers := sortedKeys(consumerSet)
+	instruments := sortedKeys(instrumentSet)
+
+	if len(consumers) > 0 {
+		args := make([]interface{}, len(consumers))
+		for i, consumer := range consumers {
+			args[i] = consumer
+		}
+		placeholders := strings.TrimSuffix(strings.Repeat("?,", len(consumers)), ",")
+		res, err := db.ExecContext(ctx, fmt.Sprintf(perfSchemaEnableConsumersQuery, placeholders), args...)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err == nil && n > 0 {
+			level.Info(logger).Log("msg", "Enabled performance_schema consumers", "consumers", strings.Join(consumers, ","), "changed", n)
+		}
+	}
+	for _, instrument := range instruments {
+		res, err := db.ExecContext(ctx, perfSchemaEnableInstrumentsQuery, instrument)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err == nil && n > 0 {
+			level.Info(logger).Log("msg", "Enabled performance_schema instruments", "instruments", instrument, "changed", n)
+		}
+	}
+	return nil
+}
+
+// sortedKeys returns the keys of set in sorted order.
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
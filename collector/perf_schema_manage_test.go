@@ -0,0 +1,57 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+)
+
+func TestEnablePerfSchema(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec(regexp.QuoteMeta(fmt.Sprintf(perfSchemaEnableConsumersQuery, "?,?,?"))).
+		WithArgs("global_instrumentation", "statements_digest", "thread_instrumentation").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(perfSchemaEnableInstrumentsQuery)).
+		WithArgs("memory/%").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta(perfSchemaEnableInstrumentsQuery)).
+		WithArgs("statement/%").
+		WillReturnResult(sqlmock.NewResult(0, 12))
+
+	scrapers := []Scraper{
+		ScrapePerfEventsStatements{},
+		ScrapePerfEventsStatementsSum{},
+		ScrapePerfMemoryEvents{},
+		ScrapeGlobalStatus{},
+	}
+	if err := enablePerfSchema(context.Background(), db, scrapers, log.NewNopLogger()); err != nil {
+		t.Fatalf("error calling function on test: %s", err)
+	}
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
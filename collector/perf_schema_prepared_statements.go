@@ -0,0 +1,148 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape `performance_schema.prepared_statements_instances`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	preparedStmtCountQuery    = `SHOW GLOBAL STATUS LIKE 'Prepared_stmt_count'`
+	maxPreparedStmtCountQuery = `SELECT @@global.max_prepared_stmt_count`
+
+	// The digests are the ones of events_statements_summary_by_digest.
+	perfPreparedStatementsQuery = `
+	SELECT
+	    STATEMENT_DIGEST(SQL_TEXT) AS DIGEST,
+	    ANY_VALUE(LEFT(STATEMENT_DIGEST_TEXT(SQL_TEXT), %d)) AS DIGEST_TEXT,
+	    COUNT(*) AS INSTANCES,
+	    SUM(COUNT_EXECUTE),
+	    SUM(SUM_TIMER_EXECUTE)
+	  FROM performance_schema.prepared_statements_instances
+	  GROUP BY DIGEST
+	  ORDER BY INSTANCES DESC
+	  LIMIT %d
+	`
+)
+
+// Tunable flags.
+var (
+	perfPreparedStatementsLimit = kingpin.Flag(
+		"collect.perf_schema.prepared_statements.limit",
+		"Limit the number of prepared statement digests by number of open instances",
+	).Default("50").Int()
+	perfPreparedStatementsTextLimit = kingpin.Flag(
+		"collect.perf_schema.prepared_statements.sql_text_limit",
+		"Maximum length of the prepared statement digest text",
+	).Default("120").Int()
+)
+
+// Metric descriptors.
+var (
+	performanceSchemaPreparedStatementsUtilizationDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "prepared_statements_utilization_ratio"),
+		"Ratio of Prepared_stmt_count to max_prepared_stmt_count.",
+		[]string{}, nil,
+	)
+	performanceSchemaPreparedStatementsInstancesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "prepared_statements_instances"),
+		"Number of open prepared statement instances by statement digest.",
+		[]string{"digest", "digest_text"}, nil,
+	)
+	performanceSchemaPreparedStatementsExecutionsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "prepared_statements_executions"),
+		"Number of executions of the open prepared statement instances by statement digest.",
+		[]string{"digest", "digest_text"}, nil,
+	)
+	performanceSchemaPreparedStatementsExecutionSecondsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "prepared_statements_execution_seconds"),
+		"Time spent executing the open prepared statement instances by statement digest.",
+		[]string{"digest", "digest_text"}, nil,
+	)
+)
+
+// ScrapePerfPreparedStatements collects from `performance_schema.prepared_statements_instances`.
+type ScrapePerfPreparedStatements struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapePerfPreparedStatements) Name() string {
+	return "perf_schema.prepared_statements"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapePerfPreparedStatements) Help() string {
+	return "Collect metrics from performance_schema.prepared_statements_instances"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapePerfPreparedStatements) Version() float64 {
+	return 8.0
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapePerfPreparedStatements) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	var (
+		name                string
+		count, maxStmtCount float64
+	)
+	if err := db.QueryRowContext(ctx, preparedStmtCountQuery).Scan(&name, &count); err != nil {
+		return err
+	}
+	if err := db.QueryRowContext(ctx, maxPreparedStmtCountQuery).Scan(&maxStmtCount); err != nil {
+		return err
+	}
+	if maxStmtCount > 0 {
+		ch <- prometheus.MustNewConstMetric(
+			performanceSchemaPreparedStatementsUtilizationDesc, prometheus.GaugeValue, count/maxStmtCount,
+		)
+	}
+
+	query := fmt.Sprintf(perfPreparedStatementsQuery, *perfPreparedStatementsTextLimit, *perfPreparedStatementsLimit)
+	preparedStatementsRows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer preparedStatementsRows.Close()
+
+	var (
+		digest, digestText              string
+		instances, executions, execTime uint64
+	)
+	for preparedStatementsRows.Next() {
+		if err := preparedStatementsRows.Scan(&digest, &digestText, &instances, &executions, &execTime); err != nil {
+			return err
+		}
+		ch <- prometheus.MustNewConstMetric(
+			performanceSchemaPreparedStatementsInstancesDesc, prometheus.GaugeValue, float64(instances), digest, digestText,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			performanceSchemaPreparedStatementsExecutionsDesc, prometheus.GaugeValue, float64(executions), digest, digestText,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			performanceSchemaPreparedStatementsExecutionSecondsDesc, prometheus.GaugeValue, float64(execTime)/picoSeconds, digest, digestText,
+		)
+	}
+	return preparedStatementsRows.Err()
+}
+
+// check interface
+var _ Scraper = ScrapePerfPreparedStatements{}
@@ -0,0 +1,81 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapePerfPreparedStatements(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(preparedStmtCountQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).AddRow("Prepared_stmt_count", "4000"))
+	mock.ExpectQuery(sanitizeQuery(maxPreparedStmtCountQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"@@global.max_prepared_stmt_count"}).AddRow("16000"))
+
+	columns := []string{"DIGEST", "DIGEST_TEXT", "INSTANCES", "SUM(COUNT_EXECUTE)", "SUM(SUM_TIMER_EXECUTE)"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("3b1d", "SELECT * FROM `t` WHERE `id` = ?", "3990", "12", "2000000000000").
+		AddRow("9f0e", "UPDATE `t` SET `a` = ?", "10", "100", "500000000000")
+	query := fmt.Sprintf(perfPreparedStatementsQuery, 120, 50)
+	mock.ExpectQuery(sanitizeQuery(query)).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapePerfPreparedStatements{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	metricExpected := []MetricResult{
+		{labels: labelMap{}, value: 0.25, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"digest": "3b1d", "digest_text": "SELECT * FROM `t` WHERE `id` = ?"}, value: 3990, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"digest": "3b1d", "digest_text": "SELECT * FROM `t` WHERE `id` = ?"}, value: 12, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"digest": "3b1d", "digest_text": "SELECT * FROM `t` WHERE `id` = ?"}, value: 2, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"digest": "9f0e", "digest_text": "UPDATE `t` SET `a` = ?"}, value: 10, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"digest": "9f0e", "digest_text": "UPDATE `t` SET `a` = ?"}, value: 100, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"digest": "9f0e", "digest_text": "UPDATE `t` SET `a` = ?"}, value: 0.5, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range metricExpected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape parallel replication worker saturation from
+// `performance_schema.replication_applier_status_by_worker`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// replica_parallel_workers replaces slave_parallel_workers from MySQL 8.0.26.
+	replicationParallelWorkersQuery = `
+	SHOW GLOBAL VARIABLES
+	  WHERE Variable_name IN ('replica_parallel_workers', 'slave_parallel_workers')
+	`
+	perfReplicationApplierBusyWorkersQuery = `
+	SELECT
+	    CHANNEL_NAME,
+	    SUM(APPLYING_TRANSACTION <> '') AS BUSY_WORKERS
+	  FROM performance_schema.replication_applier_status_by_worker
+	  GROUP BY CHANNEL_NAME
+	`
+)
+
+// Metric descriptors.
+var (
+	performanceSchemaReplicationApplierWorkersDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "replication_applier_workers"),
+		"The number of parallel replication workers configured for each channel by replica_parallel_workers.",
+		nil, nil,
+	)
+	performanceSchemaReplicationApplierBusyWorkersDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "replication_applier_busy_workers"),
+		"The number of parallel replication workers applying a transaction.",
+		[]string{"channel_name"}, nil,
+	)
+	performanceSchemaReplicationApplierSaturationDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "replication_applier_workers_saturation_ratio"),
+		"The ratio of busy to configured parallel replication workers.",
+		[]string{"channel_name"}, nil,
+	)
+)
+
+// ScrapePerfReplicationApplierWorkers collects parallel replication worker saturation.
+type ScrapePerfReplicationApplierWorkers struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapePerfReplicationApplierWorkers) Name() string {
+	return performanceSchema + ".replication_applier_workers"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapePerfReplicationApplierWorkers) Help() string {
+	return "Collect parallel replication worker saturation from performance_schema.replication_applier_status_by_worker"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapePerfReplicationApplierWorkers) Version() float64 {
+	return 8.0
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapePerfReplicationApplierWorkers) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	workerRows, err := db.QueryContext(ctx, replicationParallelWorkersQuery)
+	if err != nil {
+		return err
+	}
+	defer workerRows.Close()
+
+	var (
+		name, value string
+		workers     float64
+	)
+	for workerRows.Next() {
+		if err := workerRows.Scan(&name, &value); err != nil {
+			return err
+		}
+		if v, err := strconv.ParseFloat(value, 64); err == nil {
+			workers = v
+		}
+	}
+	if err := workerRows.Err(); err != nil {
+		return err
+	}
+	ch <- prometheus.MustNewConstMetric(performanceSchemaReplicationApplierWorkersDesc, prometheus.GaugeValue, workers)
+
+	busyRows, err := db.QueryContext(ctx, perfReplicationApplierBusyWorkersQuery)
+	if err != nil {
+		return err
+	}
+	defer busyRows.Close()
+
+	var (
+		channelName string
+		busy        float64
+	)
+	for busyRows.Next() {
+		if err := busyRows.Scan(&channelName, &busy); err != nil {
+			return err
+		}
+		ch <- prometheus.MustNewConstMetric(
+			performanceSchemaReplicationApplierBusyWorkersDesc, prometheus.GaugeValue, busy,
+			channelName,
+		)
+		// Without parallel workers the SQL thread applies transactions itself.
+		if workers > 0 {
+			ch <- prometheus.MustNewConstMetric(
+				performanceSchemaReplicationApplierSaturationDesc, prometheus.GaugeValue, busy/workers,
+				channelName,
+			)
+		}
+	}
+	return busyRows.Err()
+}
+
+// check interface
+var _ Scraper = ScrapePerfReplicationApplierWorkers{}
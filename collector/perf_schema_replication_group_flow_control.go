@@ -0,0 +1,197 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape Group Replication flow control from the group_replication_flow_control_*
+// variables and `performance_schema.replication_group_member_stats`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	groupReplicationFlowControlVariablesQuery = `
+	SHOW GLOBAL VARIABLES
+	  WHERE Variable_name IN (
+	    'group_replication_flow_control_mode',
+	    'group_replication_flow_control_certifier_threshold',
+	    'group_replication_flow_control_applier_threshold',
+	    'group_replication_flow_control_min_quota',
+	    'group_replication_flow_control_max_quota'
+	  )
+	`
+	perfReplicationGroupMemberQueuesQuery = `
+	SELECT
+	    s.MEMBER_ID,
+	    IFNULL(m.MEMBER_HOST, ''),
+	    s.COUNT_TRANSACTIONS_IN_QUEUE,
+	    s.COUNT_TRANSACTIONS_REMOTE_IN_APPLIER_QUEUE
+	  FROM performance_schema.replication_group_member_stats s
+	  LEFT JOIN performance_schema.replication_group_members m USING (MEMBER_ID)
+	`
+)
+
+// Metric descriptors.
+var (
+	performanceSchemaFlowControlModeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "replication_group_flow_control_mode_info"),
+		"The flow control mode of the group (group_replication_flow_control_mode).",
+		[]string{"mode"}, nil,
+	)
+	performanceSchemaFlowControlCertifierThresholdDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "replication_group_flow_control_certifier_threshold"),
+		"The certifier queue size which triggers flow control (group_replication_flow_control_certifier_threshold).",
+		nil, nil,
+	)
+	performanceSchemaFlowControlApplierThresholdDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "replication_group_flow_control_applier_threshold"),
+		"The applier queue size which triggers flow control (group_replication_flow_control_applier_threshold).",
+		nil, nil,
+	)
+	performanceSchemaFlowControlMinQuotaDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "replication_group_flow_control_min_quota"),
+		"The minimum flow control quota assigned to a member, 0 for no minimum (group_replication_flow_control_min_quota).",
+		nil, nil,
+	)
+	performanceSchemaFlowControlMaxQuotaDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "replication_group_flow_control_max_quota"),
+		"The maximum flow control quota of the group, 0 for no maximum (group_replication_flow_control_max_quota).",
+		nil, nil,
+	)
+	performanceSchemaMemberCertifierQueueDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "replication_group_member_certifier_queue"),
+		"The number of transactions of a member waiting for certification.",
+		[]string{"member_id", "member_host"}, nil,
+	)
+	performanceSchemaMemberApplierQueueDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "replication_group_member_applier_queue"),
+		"The number of transactions of a member waiting to be applied.",
+		[]string{"member_id", "member_host"}, nil,
+	)
+	performanceSchemaMemberThrottlingDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "replication_group_member_flow_control_throttling"),
+		"Whether the queues of a member exceed the flow control thresholds, throttling the writers of the group.",
+		[]string{"member_id", "member_host"}, nil,
+	)
+)
+
+// ScrapePerfReplicationGroupFlowControl collects Group Replication flow control metrics.
+type ScrapePerfReplicationGroupFlowControl struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapePerfReplicationGroupFlowControl) Name() string {
+	return performanceSchema + ".replication_group_flow_control"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapePerfReplicationGroupFlowControl) Help() string {
+	return "Collect Group Replication flow control metrics from performance_schema.replication_group_member_stats"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapePerfReplicationGroupFlowControl) Version() float64 {
+	return 8.0
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapePerfReplicationGroupFlowControl) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	variableRows, err := db.QueryContext(ctx, groupReplicationFlowControlVariablesQuery)
+	if err != nil {
+		return err
+	}
+	defer variableRows.Close()
+
+	var (
+		name, value string
+		mode        string
+		thresholds  = map[string]float64{}
+	)
+	for variableRows.Next() {
+		if err := variableRows.Scan(&name, &value); err != nil {
+			return err
+		}
+		if name == "group_replication_flow_control_mode" {
+			mode = value
+			continue
+		}
+		if v, err := strconv.ParseFloat(value, 64); err == nil {
+			thresholds[name] = v
+		}
+	}
+	if err := variableRows.Err(); err != nil {
+		return err
+	}
+	// Group Replication plugin is not installed.
+	if mode == "" {
+		return nil
+	}
+
+	ch <- prometheus.MustNewConstMetric(performanceSchemaFlowControlModeDesc, prometheus.GaugeValue, 1, mode)
+	for _, threshold := range []struct {
+		variable string
+		desc     *prometheus.Desc
+	}{
+		{"group_replication_flow_control_certifier_threshold", performanceSchemaFlowControlCertifierThresholdDesc},
+		{"group_replication_flow_control_applier_threshold", performanceSchemaFlowControlApplierThresholdDesc},
+		{"group_replication_flow_control_min_quota", performanceSchemaFlowControlMinQuotaDesc},
+		{"group_replication_flow_control_max_quota", performanceSchemaFlowControlMaxQuotaDesc},
+	} {
+		if v, ok := thresholds[threshold.variable]; ok {
+			ch <- prometheus.MustNewConstMetric(threshold.desc, prometheus.GaugeValue, v)
+		}
+	}
+
+	memberRows, err := db.QueryContext(ctx, perfReplicationGroupMemberQueuesQuery)
+	if err != nil {
+		return err
+	}
+	defer memberRows.Close()
+
+	var (
+		memberID, memberHost         string
+		certifierQueue, applierQueue float64
+	)
+	for memberRows.Next() {
+		if err := memberRows.Scan(&memberID, &memberHost, &certifierQueue, &applierQueue); err != nil {
+			return err
+		}
+		ch <- prometheus.MustNewConstMetric(
+			performanceSchemaMemberCertifierQueueDesc, prometheus.GaugeValue, certifierQueue,
+			memberID, memberHost,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			performanceSchemaMemberApplierQueueDesc, prometheus.GaugeValue, applierQueue,
+			memberID, memberHost,
+		)
+		throttling := 0.0
+		if mode == "QUOTA" &&
+			(certifierQueue > thresholds["group_replication_flow_control_certifier_threshold"] ||
+				applierQueue > thresholds["group_replication_flow_control_applier_threshold"]) {
+			throttling = 1
+		}
+		ch <- prometheus.MustNewConstMetric(
+			performanceSchemaMemberThrottlingDesc, prometheus.GaugeValue, throttling,
+			memberID, memberHost,
+		)
+	}
+	return memberRows.Err()
+}
+
+// check interface
+var _ Scraper = ScrapePerfReplicationGroupFlowControl{}
@@ -0,0 +1,81 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapePerfReplicationGroupFlowControl(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"Variable_name", "Value"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("group_replication_flow_control_applier_threshold", "25000").
+		AddRow("group_replication_flow_control_certifier_threshold", "25000").
+		AddRow("group_replication_flow_control_max_quota", "0").
+		AddRow("group_replication_flow_control_min_quota", "0").
+		AddRow("group_replication_flow_control_mode", "QUOTA")
+	mock.ExpectQuery(sanitizeQuery(groupReplicationFlowControlVariablesQuery)).WillReturnRows(rows)
+
+	columns = []string{"MEMBER_ID", "MEMBER_HOST", "COUNT_TRANSACTIONS_IN_QUEUE", "COUNT_TRANSACTIONS_REMOTE_IN_APPLIER_QUEUE"}
+	rows = sqlmock.NewRows(columns).
+		AddRow("uuid1", "db1", 0, 10).
+		AddRow("uuid2", "db2", 2, 30000)
+	mock.ExpectQuery(sanitizeQuery(perfReplicationGroupMemberQueuesQuery)).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapePerfReplicationGroupFlowControl{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{"mode": "QUOTA"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 25000, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 25000, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"member_id": "uuid1", "member_host": "db1"}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"member_id": "uuid1", "member_host": "db1"}, value: 10, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"member_id": "uuid1", "member_host": "db1"}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"member_id": "uuid2", "member_host": "db2"}, value: 2, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"member_id": "uuid2", "member_host": "db2"}, value: 30000, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"member_id": "uuid2", "member_host": "db2"}, value: 1, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -73,12 +73,12 @@ func (ScrapePerfReplicationGroupMembers) Scrape(ctx context.Context, db *sql.DB,
 			values[i] = string(*scanArgs[i].(*sql.RawBytes))
 		}
 
-		var performanceSchemaReplicationGroupMembersMemberDesc = prometheus.NewDesc(
+		var performanceSchemaReplicationGroupMembersMemberDesc = cachedDesc(
 			prometheus.BuildFQName(namespace, performanceSchema, "replication_group_member_info"),
 			"Information about the replication group member: "+
 				"channel_name, member_id, member_host, member_port, member_state. "+
 				"(member_role and member_version where available)",
-			labelNames, nil,
+			labelNames,
 		)
 
 		ch <- prometheus.MustNewConstMetric(performanceSchemaReplicationGroupMembersMemberDesc,
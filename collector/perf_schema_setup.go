@@ -0,0 +1,164 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape `performance_schema.setup_instruments` and `performance_schema.setup_consumers`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	perfSetupInstrumentsQuery = `
+	SELECT
+	    SUBSTRING_INDEX(NAME, '/', 1) AS CLASS,
+	    COUNT(*),
+	    SUM(ENABLED = 'YES')
+	  FROM performance_schema.setup_instruments
+	  GROUP BY CLASS
+	`
+	perfSetupConsumersQuery = `
+	SELECT
+	    NAME,
+	    ENABLED
+	  FROM performance_schema.setup_consumers
+	`
+)
+
+// Tunable flags.
+var (
+	perfSetupRequiredConsumers = kingpin.Flag(
+		"collect.perf_schema.setup.required_consumers",
+		"Comma separated list of consumers other collectors rely on",
+	).Default("global_instrumentation,thread_instrumentation,events_statements_current,statements_digest").String()
+)
+
+// Metric descriptors.
+var (
+	performanceSchemaSetupInstrumentsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "setup_instruments"),
+		"The number of instruments in performance_schema.setup_instruments by class.",
+		[]string{"class"}, nil,
+	)
+	performanceSchemaSetupInstrumentsEnabledDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "setup_instruments_enabled"),
+		"The number of enabled instruments in performance_schema.setup_instruments by class.",
+		[]string{"class"}, nil,
+	)
+	performanceSchemaSetupConsumerEnabledDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "setup_consumer_enabled"),
+		"Whether the consumer is enabled in performance_schema.setup_consumers.",
+		[]string{"consumer"}, nil,
+	)
+	performanceSchemaSetupRequiredConsumersDisabledDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "setup_required_consumers_disabled"),
+		"The number of consumers from --collect.perf_schema.setup.required_consumers which are not enabled.",
+		nil, nil,
+	)
+)
+
+// ScrapePerfSetup collects from `performance_schema.setup_instruments` and `performance_schema.setup_consumers`.
+type ScrapePerfSetup struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapePerfSetup) Name() string {
+	return performanceSchema + ".setup"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapePerfSetup) Help() string {
+	return "Collect metrics from performance_schema.setup_instruments and performance_schema.setup_consumers"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapePerfSetup) Version() float64 {
+	return 5.6
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapePerfSetup) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	instrumentRows, err := db.QueryContext(ctx, perfSetupInstrumentsQuery)
+	if err != nil {
+		return err
+	}
+	defer instrumentRows.Close()
+
+	var (
+		class          string
+		total, enabled uint64
+	)
+	for instrumentRows.Next() {
+		if err := instrumentRows.Scan(&class, &total, &enabled); err != nil {
+			return err
+		}
+		ch <- prometheus.MustNewConstMetric(
+			performanceSchemaSetupInstrumentsDesc, prometheus.GaugeValue, float64(total),
+			class,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			performanceSchemaSetupInstrumentsEnabledDesc, prometheus.GaugeValue, float64(enabled),
+			class,
+		)
+	}
+	if err := instrumentRows.Err(); err != nil {
+		return err
+	}
+
+	consumerRows, err := db.QueryContext(ctx, perfSetupConsumersQuery)
+	if err != nil {
+		return err
+	}
+	defer consumerRows.Close()
+
+	enabledConsumers := map[string]bool{}
+	var consumer, consumerEnabled string
+	for consumerRows.Next() {
+		if err := consumerRows.Scan(&consumer, &consumerEnabled); err != nil {
+			return err
+		}
+		value := 0.0
+		if consumerEnabled == "YES" {
+			value = 1
+			enabledConsumers[consumer] = true
+		}
+		ch <- prometheus.MustNewConstMetric(
+			performanceSchemaSetupConsumerEnabledDesc, prometheus.GaugeValue, value,
+			consumer,
+		)
+	}
+	if err := consumerRows.Err(); err != nil {
+		return err
+	}
+
+	disabled := 0
+	for _, consumer := range strings.Split(*perfSetupRequiredConsumers, ",") {
+		consumer = strings.TrimSpace(consumer)
+		if consumer != "" && !enabledConsumers[consumer] {
+			disabled++
+		}
+	}
+	ch <- prometheus.MustNewConstMetric(
+		performanceSchemaSetupRequiredConsumersDisabledDesc, prometheus.GaugeValue, float64(disabled),
+	)
+	return nil
+}
+
+// check interface
+var _ Scraper = ScrapePerfSetup{}
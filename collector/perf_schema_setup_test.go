@@ -0,0 +1,82 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapePerfSetup(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.perf_schema.setup.required_consumers=events_statements_history,statements_digest",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"CLASS", "COUNT(*)", "SUM(ENABLED = 'YES')"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("statement", 200, 200).
+		AddRow("wait", 400, 50)
+	mock.ExpectQuery(sanitizeQuery(perfSetupInstrumentsQuery)).WillReturnRows(rows)
+
+	columns = []string{"NAME", "ENABLED"}
+	rows = sqlmock.NewRows(columns).
+		AddRow("events_statements_history", "NO").
+		AddRow("statements_digest", "YES")
+	mock.ExpectQuery(sanitizeQuery(perfSetupConsumersQuery)).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapePerfSetup{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	metricExpected := []MetricResult{
+		{labels: labelMap{"class": "statement"}, value: 200, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"class": "statement"}, value: 200, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"class": "wait"}, value: 400, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"class": "wait"}, value: 50, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"consumer": "events_statements_history"}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"consumer": "statements_digest"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 1, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range metricExpected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
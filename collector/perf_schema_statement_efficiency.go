@@ -0,0 +1,162 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape the rows examined per row sent of the top digests of
+// `performance_schema.events_statements_summary_by_digest`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const perfStatementEfficiencyQuery = `
+	SELECT
+	    ifnull(SCHEMA_NAME, 'NONE') as SCHEMA_NAME,
+	    ifnull(DIGEST, 'NONE') as DIGEST,
+	    LEFT(DIGEST_TEXT, %d) as DIGEST_TEXT,
+	    COUNT_STAR,
+	    SUM_ROWS_EXAMINED,
+	    SUM_ROWS_SENT
+	  FROM performance_schema.events_statements_summary_by_digest
+	  WHERE SUM_ROWS_SENT > 0
+	  ORDER BY SUM_ROWS_EXAMINED DESC
+	  LIMIT %d
+	`
+
+// Tunable flags.
+var (
+	perfStatementEfficiencyLimit = kingpin.Flag(
+		"collect.perf_schema.statement_efficiency.limit",
+		"Limit the number of digests by number of rows examined",
+	).Default("50").Int()
+)
+
+// Metric descriptors.
+var (
+	statementEfficiencyRatioDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "statement", "efficiency_ratio"),
+		"The number of rows examined per row sent by the statements of a digest since the previous scrape.",
+		[]string{"schema", "digest", "digest_text"}, nil,
+	)
+	statementEfficiencyBaselineRatioDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "statement", "efficiency_baseline_ratio"),
+		"The number of rows examined per row sent by the statements of a digest since the digest summary was reset.",
+		[]string{"schema", "digest", "digest_text"}, nil,
+	)
+)
+
+// digestRows are the summed rows of the statements of a digest.
+type digestRows struct {
+	count, examined, sent float64
+}
+
+// statementEfficiencyPrevious holds the rows of the top digests of the
+// previous scrape.
+var statementEfficiencyPrevious digestRowsSample
+
+type digestRowsSample struct {
+	mu      sync.Mutex
+	digests map[[2]string]digestRows
+}
+
+// ratios stores digests and returns the rows examined per row sent since
+// the previous sample, for the digests which ran and sent rows since. Digests
+// whose sums went backwards, e.g. after TRUNCATE of the summary table, are
+// skipped.
+func (s *digestRowsSample) ratios(digests map[[2]string]digestRows) map[[2]string]float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ratios := map[[2]string]float64{}
+	for key, cur := range digests {
+		prev, ok := s.digests[key]
+		if !ok || cur.count <= prev.count || cur.sent <= prev.sent || cur.examined < prev.examined {
+			continue
+		}
+		ratios[key] = (cur.examined - prev.examined) / (cur.sent - prev.sent)
+	}
+	s.digests = digests
+	return ratios
+}
+
+// ScrapePerfStatementEfficiency collects the rows examined per row sent of
+// the digests examining most rows, a hint of plan regressions.
+type ScrapePerfStatementEfficiency struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapePerfStatementEfficiency) Name() string {
+	return "perf_schema.statement_efficiency"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapePerfStatementEfficiency) Help() string {
+	return "Collect the rows examined per row sent of the digests examining most rows from performance_schema.events_statements_summary_by_digest"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapePerfStatementEfficiency) Version() float64 {
+	return 5.6
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapePerfStatementEfficiency) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	query := fmt.Sprintf(perfStatementEfficiencyQuery, *perfEventsStatementsDigestTextLimit, *perfStatementEfficiencyLimit)
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var (
+		schemaName, digest, digestText string
+		cur                            digestRows
+		digests                        = map[[2]string]digestRows{}
+		texts                          = map[[2]string]string{}
+	)
+	for rows.Next() {
+		if err := rows.Scan(&schemaName, &digest, &digestText, &cur.count, &cur.examined, &cur.sent); err != nil {
+			return err
+		}
+		key := [2]string{schemaName, digest}
+		digests[key] = cur
+		texts[key] = digestText
+		ch <- prometheus.MustNewConstMetric(
+			statementEfficiencyBaselineRatioDesc, prometheus.GaugeValue, cur.examined/cur.sent,
+			schemaName, digest, digestText,
+		)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if catalogRun(ctx) {
+		return nil
+	}
+	for key, ratio := range statementEfficiencyPrevious.ratios(digests) {
+		ch <- prometheus.MustNewConstMetric(
+			statementEfficiencyRatioDesc, prometheus.GaugeValue, ratio,
+			key[0], key[1], texts[key],
+		)
+	}
+	return nil
+}
+
+// check interface
+var _ Scraper = ScrapePerfStatementEfficiency{}
@@ -0,0 +1,92 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapePerfStatementEfficiency(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.perf_schema.statement_efficiency.limit=50",
+		"--collect.perf_schema.eventsstatements.digest_text_limit=120",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	defer func() { statementEfficiencyPrevious = digestRowsSample{} }()
+
+	columns := []string{"SCHEMA_NAME", "DIGEST", "DIGEST_TEXT", "COUNT_STAR", "SUM_ROWS_EXAMINED", "SUM_ROWS_SENT"}
+	query := sanitizeQuery(fmt.Sprintf(perfStatementEfficiencyQuery, 120, 50))
+	mock.ExpectQuery(query).WillReturnRows(sqlmock.NewRows(columns).
+		AddRow("app", "abc", "SELECT * FROM `t` WHERE `a` = ?", 100, 1000, 100).
+		AddRow("app", "def", "SELECT * FROM `u` WHERE `b` = ?", 10, 50, 10))
+	// After a plan change the first digest examines 100 rows per row sent.
+	mock.ExpectQuery(query).WillReturnRows(sqlmock.NewRows(columns).
+		AddRow("app", "abc", "SELECT * FROM `t` WHERE `a` = ?", 110, 11000, 200).
+		AddRow("app", "def", "SELECT * FROM `u` WHERE `b` = ?", 10, 50, 10))
+
+	labels := labelMap{"schema": "app", "digest": "abc", "digest_text": "SELECT * FROM `t` WHERE `a` = ?"}
+	scrapes := [][]MetricResult{
+		{
+			{labels: labels, value: 10, metricType: dto.MetricType_GAUGE},
+			{labels: labelMap{"schema": "app", "digest": "def", "digest_text": "SELECT * FROM `u` WHERE `b` = ?"}, value: 5, metricType: dto.MetricType_GAUGE},
+		},
+		{
+			{labels: labels, value: 55, metricType: dto.MetricType_GAUGE},
+			{labels: labelMap{"schema": "app", "digest": "def", "digest_text": "SELECT * FROM `u` WHERE `b` = ?"}, value: 5, metricType: dto.MetricType_GAUGE},
+			// The second digest didn't run since the previous scrape.
+			{labels: labels, value: 100, metricType: dto.MetricType_GAUGE},
+		},
+	}
+	for i, expected := range scrapes {
+		ch := make(chan prometheus.Metric)
+		go func() {
+			if err = (ScrapePerfStatementEfficiency{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+				t.Errorf("error calling function on test: %s", err)
+			}
+			close(ch)
+		}()
+
+		convey.Convey(fmt.Sprintf("Metrics comparison of scrape %d", i+1), t, func() {
+			for _, expect := range expected {
+				got := readMetric(<-ch)
+				convey.So(got, convey.ShouldResemble, expect)
+			}
+			_, more := <-ch
+			convey.So(more, convey.ShouldBeFalse)
+		})
+	}
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
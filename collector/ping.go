@@ -0,0 +1,102 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/alecthomas/kingpin/v2"
+	MySQL "github.com/go-sql-driver/mysql"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Tunable flags.
+var (
+	pingQuery = kingpin.Flag(
+		"exporter.ping_query",
+		"Synthetic query run on every scrape, e.g. SELECT 1, its latency is exposed as mysql_exporter_ping_duration_seconds. Disabled when empty.",
+	).Default("").String()
+)
+
+var pingDurationOpts = prometheus.HistogramOpts{
+	Namespace: namespace,
+	Subsystem: exporter,
+	Name:      "ping_duration_seconds",
+	Help:      "Latency of running the synthetic query set with --exporter.ping_query.",
+	Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 16),
+}
+
+// pingDurationTTL is how long the histogram of a target no longer scraped
+// is kept.
+const pingDurationTTL = time.Hour
+
+// pingHistogram is the ping latency histogram of a target.
+type pingHistogram struct {
+	histogram prometheus.Histogram
+	lastUsed  time.Time
+}
+
+// pingDurations are the ping latency histograms by target address. They
+// outlive the Exporter, which is created for every scrape.
+var pingDurations = struct {
+	sync.Mutex
+	byTarget map[string]*pingHistogram
+}{byTarget: map[string]*pingHistogram{}}
+
+// pingDuration returns the ping latency histogram of target, see
+// Exporter.target. Histograms of targets not scraped for pingDurationTTL
+// are dropped.
+func pingDuration(target string) prometheus.Histogram {
+	now := time.Now()
+	key := pingKey(target)
+	pingDurations.Lock()
+	defer pingDurations.Unlock()
+	for k, h := range pingDurations.byTarget {
+		if now.Sub(h.lastUsed) > pingDurationTTL {
+			delete(pingDurations.byTarget, k)
+		}
+	}
+	h, ok := pingDurations.byTarget[key]
+	if !ok {
+		h = &pingHistogram{histogram: prometheus.NewHistogram(pingDurationOpts)}
+		pingDurations.byTarget[key] = h
+	}
+	h.lastUsed = now
+	return h.histogram
+}
+
+// pingKey returns the network and address of the DSN target, so the
+// password is not kept, or target itself when it isn't a DSN.
+func pingKey(target string) string {
+	cfg, err := MySQL.ParseDSN(target)
+	if err != nil {
+		return target
+	}
+	return cfg.Net + "(" + cfg.Addr + ")"
+}
+
+// runPingQuery runs query on db, reading and discarding its result.
+func runPingQuery(ctx context.Context, db *sql.DB, query string) error {
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+	}
+	return rows.Err()
+}
@@ -0,0 +1,65 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestRunPingQuery(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery("SELECT 1")).WillReturnRows(sqlmock.NewRows([]string{"1"}).AddRow(1))
+	mock.ExpectQuery(sanitizeQuery("SELECT 1")).WillReturnError(errors.New("gone away"))
+
+	convey.Convey("Ping query runs", t, func() {
+		convey.So(runPingQuery(context.Background(), db, "SELECT 1"), convey.ShouldBeNil)
+		convey.So(runPingQuery(context.Background(), db, "SELECT 1"), convey.ShouldNotBeNil)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestPingDuration(t *testing.T) {
+	defer func() {
+		pingDurations.byTarget = map[string]*pingHistogram{}
+	}()
+
+	convey.Convey("Ping latency is kept per target address", t, func() {
+		convey.So(pingDuration("user:secret@tcp(db1:3306)/"), convey.ShouldEqual, pingDuration("user:secret@tcp(db1:3306)/?timeout=1s"))
+		convey.So(pingDuration("user:secret@tcp(db1:3306)/"), convey.ShouldNotEqual, pingDuration("user:secret@tcp(db2:3306)/"))
+		convey.So(pingDurations.byTarget, convey.ShouldContainKey, "tcp(db1:3306)")
+		convey.So(pingDuration("db-0xc000010000"), convey.ShouldEqual, pingDuration("db-0xc000010000"))
+	})
+
+	convey.Convey("Idle targets are dropped", t, func() {
+		pingDuration("user:secret@tcp(db3:3306)/")
+		pingDurations.byTarget["tcp(db3:3306)"].lastUsed = time.Now().Add(-2 * pingDurationTTL)
+		pingDuration("user:secret@tcp(db1:3306)/")
+		convey.So(pingDurations.byTarget, convey.ShouldNotContainKey, "tcp(db3:3306)")
+	})
+}
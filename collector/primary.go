@@ -0,0 +1,163 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	MySQL "github.com/go-sql-driver/mysql"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Tunable flags.
+var (
+	primaryCandidates = kingpin.Flag(
+		"exporter.primary_candidates",
+		"Comma separated list of host:port of the servers of a replication topology, IPv6 addresses with a port must be bracketed. Scrapes go to the writable primary among them, which is detected again after connection errors or once it becomes read only.",
+	).Default("").String()
+)
+
+// Metric descriptors.
+var (
+	followedPrimaryDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, exporter, "followed_primary_info"),
+		"The address of the writable primary scrapes are sent to, from --exporter.primary_candidates.",
+		[]string{"address"}, nil,
+	)
+)
+
+// followedPrimary is the primary scrapes of the exporters of
+// followPrimaryDSN are sent to.
+var (
+	followedPrimary  primaryFollower
+	followPrimaryDSN string
+)
+
+// FollowPrimary makes the exporters of dsn, the one of --config.my-cnf,
+// follow the writable primary of --exporter.primary_candidates. Exporters
+// of other DSNs, like the ones of /probe, connect to their own server.
+func FollowPrimary(dsn string) {
+	followPrimaryDSN = dsn
+}
+
+// primaryFollower remembers the writable primary among the candidates.
+type primaryFollower struct {
+	mu      sync.Mutex
+	address string
+}
+
+// candidates returns the addresses of --exporter.primary_candidates.
+func (f *primaryFollower) candidates() []string {
+	var res []string
+	for _, address := range strings.Split(*primaryCandidates, ",") {
+		if address = strings.TrimSpace(address); address != "" {
+			res = append(res, normalizeAddress(address))
+		}
+	}
+	return res
+}
+
+// current returns the address of the followed primary, empty if unknown.
+func (f *primaryFollower) current() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.address
+}
+
+func (f *primaryFollower) set(address string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.address = address
+}
+
+// open returns a connection to the writable primary and the DSN it was
+// opened with. The known primary is tried first, the other candidates are
+// only probed when it can't be reached or has become read only.
+func (f *primaryFollower) open(ctx context.Context, dsn string, open func(context.Context, string) (*sql.DB, error), logger log.Logger) (*sql.DB, string, error) {
+	known := f.current()
+	if known != "" {
+		primaryDSN := withAddress(dsn, known)
+		db, err := open(ctx, primaryDSN)
+		if err == nil {
+			if isWritable(ctx, db, logger) {
+				return db, primaryDSN, nil
+			}
+			db.Close()
+			level.Warn(logger).Log("msg", "Primary is read only, looking for a new primary", "address", known)
+		} else {
+			level.Warn(logger).Log("msg", "Error connecting to primary, looking for a new primary", "address", known, "err", err)
+		}
+	}
+	for _, address := range f.candidates() {
+		if address == known {
+			continue
+		}
+		candidateDSN := withAddress(dsn, address)
+		db, err := open(ctx, candidateDSN)
+		if err != nil {
+			continue
+		}
+		if !isWritable(ctx, db, logger) {
+			db.Close()
+			continue
+		}
+		f.set(address)
+		level.Info(logger).Log("msg", "Following new primary", "address", address, "previous", known)
+		return db, candidateDSN, nil
+	}
+	f.set("")
+	return nil, "", errors.New("no writable primary found in --exporter.primary_candidates")
+}
+
+// isWritable reports whether the server db is connected to is not read only.
+func isWritable(ctx context.Context, db *sql.DB, logger log.Logger) bool {
+	readOnly, err := isReadOnly(ctx, db)
+	if err != nil {
+		level.Debug(logger).Log("msg", "Error querying read_only", "err", err)
+		return false
+	}
+	return !readOnly
+}
+
+// normalizeAddress returns address as host:port, adding the default MySQL
+// port when missing and brackets around IPv6 literals.
+func normalizeAddress(address string) string {
+	if _, _, err := net.SplitHostPort(address); err == nil {
+		return address
+	}
+	host := strings.TrimSuffix(strings.TrimPrefix(address, "["), "]")
+	return net.JoinHostPort(host, "3306")
+}
+
+// withAddress returns dsn with its TCP address replaced by address.
+func withAddress(dsn, address string) string {
+	cfg, err := MySQL.ParseDSN(dsn)
+	if err != nil {
+		return dsn
+	}
+	// Keep networks dialing TCP addresses, like the tunnel ones.
+	if cfg.Net == "unix" {
+		cfg.Net = "tcp"
+	}
+	cfg.Addr = address
+	return cfg.FormatDSN()
+}
@@ -0,0 +1,107 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	MySQL "github.com/go-sql-driver/mysql"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestPrimaryFollower(t *testing.T) {
+	defaultCandidates := *primaryCandidates
+	defer func() {
+		*primaryCandidates = defaultCandidates
+		followedPrimary = primaryFollower{}
+		followPrimaryDSN = ""
+	}()
+	*primaryCandidates = "db1:3306, db2:3306,db3:3306"
+
+	// readOnly maps the addresses which can be connected to their read_only.
+	readOnly := map[string]string{}
+	open := func(ctx context.Context, dsn string) (*sql.DB, error) {
+		cfg, err := MySQL.ParseDSN(dsn)
+		if err != nil {
+			return nil, err
+		}
+		value, ok := readOnly[cfg.Addr]
+		if !ok {
+			return nil, errors.New("connection refused")
+		}
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			return nil, err
+		}
+		mock.ExpectQuery(sanitizeQuery(readOnlyQuery)).WillReturnRows(
+			sqlmock.NewRows([]string{"Variable_name", "Value"}).AddRow("read_only", value))
+		return db, nil
+	}
+	dsn := "user:pass@tcp(localhost:3306)/?lock_wait_timeout=2"
+
+	convey.Convey("Only the exporter of the followed DSN follows the primary", t, func() {
+		FollowPrimary("user:pass@tcp(localhost:3306)/")
+		convey.So(New(context.Background(), "user:pass@tcp(localhost:3306)/", nil, log.NewNopLogger()).followPrimary, convey.ShouldBeTrue)
+		convey.So(New(context.Background(), "user:pass@tcp(db9:3306)/", nil, log.NewNopLogger()).followPrimary, convey.ShouldBeFalse)
+	})
+
+	convey.Convey("Following the writable primary", t, func() {
+		readOnly = map[string]string{"db1:3306": "ON", "db2:3306": "OFF", "db3:3306": "ON"}
+		db, primaryDSN, err := followedPrimary.open(context.Background(), dsn, open, log.NewNopLogger())
+		convey.So(err, convey.ShouldBeNil)
+		db.Close()
+		convey.So(followedPrimary.current(), convey.ShouldEqual, "db2:3306")
+		convey.So(primaryDSN, convey.ShouldEqual, "user:pass@tcp(db2:3306)/?lock_wait_timeout=2")
+
+		// Failover: db2 is down and db3 got promoted.
+		readOnly = map[string]string{"db1:3306": "ON", "db3:3306": "OFF"}
+		db, _, err = followedPrimary.open(context.Background(), dsn, open, log.NewNopLogger())
+		convey.So(err, convey.ShouldBeNil)
+		db.Close()
+		convey.So(followedPrimary.current(), convey.ShouldEqual, "db3:3306")
+
+		// Switchover: db3 became read only and db1 got promoted.
+		readOnly = map[string]string{"db1:3306": "OFF", "db3:3306": "ON"}
+		db, _, err = followedPrimary.open(context.Background(), dsn, open, log.NewNopLogger())
+		convey.So(err, convey.ShouldBeNil)
+		db.Close()
+		convey.So(followedPrimary.current(), convey.ShouldEqual, "db1:3306")
+
+		readOnly = map[string]string{"db1:3306": "ON", "db2:3306": "ON", "db3:3306": "ON"}
+		_, _, err = followedPrimary.open(context.Background(), dsn, open, log.NewNopLogger())
+		convey.So(err, convey.ShouldNotBeNil)
+		convey.So(followedPrimary.current(), convey.ShouldEqual, "")
+	})
+}
+
+func TestNormalizeAddress(t *testing.T) {
+	convey.Convey("Normalizing candidate addresses", t, func() {
+		for address, want := range map[string]string{
+			"db1":                "db1:3306",
+			"db1:3307":           "db1:3307",
+			"10.0.0.1":           "10.0.0.1:3306",
+			"::1":                "[::1]:3306",
+			"[::1]":              "[::1]:3306",
+			"[2001:db8::1]:3307": "[2001:db8::1]:3307",
+		} {
+			convey.So(normalizeAddress(address), convey.ShouldEqual, want)
+		}
+		convey.So(withAddress("user:pass@unix(/tmp/mysql.sock)/", "[::1]:3306"), convey.ShouldEqual, "user:pass@tcp([::1]:3306)/")
+	})
+}
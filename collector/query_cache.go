@@ -0,0 +1,171 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape query cache status, removed in MySQL 8.0.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// Subsystem.
+	queryCache = "query_cache"
+	// Queries.
+	queryCacheStatusQuery    = `SHOW GLOBAL STATUS LIKE 'Qcache%'`
+	queryCacheVariablesQuery = `
+	SHOW GLOBAL VARIABLES
+	  WHERE Variable_name IN ('query_cache_size', 'query_cache_type')
+	`
+)
+
+// queryCacheStatus maps Qcache_* status variables to metrics.
+var queryCacheStatus = map[string]struct {
+	vtype prometheus.ValueType
+	desc  *prometheus.Desc
+}{
+	"Qcache_hits": {prometheus.CounterValue,
+		prometheus.NewDesc(prometheus.BuildFQName(namespace, queryCache, "hits_total"),
+			"The number of query cache hits.", nil, nil)},
+	"Qcache_inserts": {prometheus.CounterValue,
+		prometheus.NewDesc(prometheus.BuildFQName(namespace, queryCache, "inserts_total"),
+			"The number of queries added to the query cache.", nil, nil)},
+	"Qcache_not_cached": {prometheus.CounterValue,
+		prometheus.NewDesc(prometheus.BuildFQName(namespace, queryCache, "not_cached_total"),
+			"The number of noncached queries.", nil, nil)},
+	"Qcache_lowmem_prunes": {prometheus.CounterValue,
+		prometheus.NewDesc(prometheus.BuildFQName(namespace, queryCache, "lowmem_prunes_total"),
+			"The number of queries deleted from the query cache because of low memory.", nil, nil)},
+	"Qcache_queries_in_cache": {prometheus.GaugeValue,
+		prometheus.NewDesc(prometheus.BuildFQName(namespace, queryCache, "queries"),
+			"The number of queries registered in the query cache.", nil, nil)},
+	"Qcache_free_memory": {prometheus.GaugeValue,
+		prometheus.NewDesc(prometheus.BuildFQName(namespace, queryCache, "free_memory_bytes"),
+			"The amount of free memory for the query cache.", nil, nil)},
+	"Qcache_free_blocks": {prometheus.GaugeValue,
+		prometheus.NewDesc(prometheus.BuildFQName(namespace, queryCache, "free_blocks"),
+			"The number of free memory blocks in the query cache.", nil, nil)},
+	"Qcache_total_blocks": {prometheus.GaugeValue,
+		prometheus.NewDesc(prometheus.BuildFQName(namespace, queryCache, "blocks"),
+			"The total number of blocks in the query cache.", nil, nil)},
+}
+
+// Metric descriptors.
+var (
+	queryCacheSizeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, queryCache, "size_bytes"),
+		"The amount of memory allocated for the query cache (query_cache_size).",
+		nil, nil,
+	)
+	queryCacheEnabledDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, queryCache, "enabled"),
+		"Whether the query cache is enabled (query_cache_type is not OFF).",
+		nil, nil,
+	)
+	queryCacheFragmentationDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, queryCache, "fragmentation_ratio"),
+		"The ratio of free to total blocks in the query cache.",
+		nil, nil,
+	)
+)
+
+// ScrapeQueryCache collects query cache status.
+type ScrapeQueryCache struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeQueryCache) Name() string {
+	return queryCache
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeQueryCache) Help() string {
+	return "Collect query cache status from SHOW GLOBAL STATUS and SHOW GLOBAL VARIABLES"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeQueryCache) Version() float64 {
+	return 5.1
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeQueryCache) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	statusRows, err := queryRows(ctx, db, queryCacheStatusQuery)
+	if err != nil {
+		return err
+	}
+	defer statusRows.Close()
+
+	var (
+		name, value string
+		values      = map[string]float64{}
+	)
+	for statusRows.Next() {
+		if err := statusRows.Scan(&name, &value); err != nil {
+			return err
+		}
+		metric, ok := queryCacheStatus[name]
+		if !ok {
+			continue
+		}
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			continue
+		}
+		values[name] = v
+		ch <- prometheus.MustNewConstMetric(metric.desc, metric.vtype, v)
+	}
+	if err := statusRows.Err(); err != nil {
+		return err
+	}
+	// The query cache was removed in MySQL 8.0.
+	if len(values) == 0 {
+		return nil
+	}
+	if total := values["Qcache_total_blocks"]; total > 0 {
+		ch <- prometheus.MustNewConstMetric(queryCacheFragmentationDesc, prometheus.GaugeValue, values["Qcache_free_blocks"]/total)
+	}
+
+	variableRows, err := queryRows(ctx, db, queryCacheVariablesQuery)
+	if err != nil {
+		return err
+	}
+	defer variableRows.Close()
+
+	for variableRows.Next() {
+		if err := variableRows.Scan(&name, &value); err != nil {
+			return err
+		}
+		switch name {
+		case "query_cache_size":
+			if v, err := strconv.ParseFloat(value, 64); err == nil {
+				ch <- prometheus.MustNewConstMetric(queryCacheSizeDesc, prometheus.GaugeValue, v)
+			}
+		case "query_cache_type":
+			enabled := 1.0
+			if value == "OFF" || value == "0" {
+				enabled = 0
+			}
+			ch <- prometheus.MustNewConstMetric(queryCacheEnabledDesc, prometheus.GaugeValue, enabled)
+		}
+	}
+	return variableRows.Err()
+}
+
+// check interface
+var _ Scraper = ScrapeQueryCache{}
@@ -0,0 +1,82 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeQueryCache(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"Variable_name", "Value"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("Qcache_free_blocks", "25").
+		AddRow("Qcache_free_memory", "1048576").
+		AddRow("Qcache_hits", "1000").
+		AddRow("Qcache_inserts", "200").
+		AddRow("Qcache_lowmem_prunes", "3").
+		AddRow("Qcache_not_cached", "40").
+		AddRow("Qcache_queries_in_cache", "150").
+		AddRow("Qcache_total_blocks", "100")
+	mock.ExpectQuery(sanitizeQuery(queryCacheStatusQuery)).WillReturnRows(rows)
+	rows = sqlmock.NewRows(columns).
+		AddRow("query_cache_size", "16777216").
+		AddRow("query_cache_type", "ON")
+	mock.ExpectQuery(sanitizeQuery(queryCacheVariablesQuery)).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeQueryCache{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{}, value: 25, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 1048576, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 1000, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{}, value: 200, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{}, value: 3, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{}, value: 40, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{}, value: 150, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 100, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 0.25, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 16777216, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 1, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
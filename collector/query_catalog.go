@@ -0,0 +1,302 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// queryOverrides are the queries run instead of the collector queries by
+// query ID, set by SetQueryOverrides at startup.
+var queryOverrides = map[string]string{}
+
+// SetQueryOverrides sets the queries run instead of the collector queries
+// with the given query IDs.
+func SetQueryOverrides(overrides map[string]string) {
+	queryOverrides = overrides
+}
+
+// QueryID returns the ID of a collector query, which doesn't change with
+// its whitespace.
+func QueryID(query string) string {
+	sum := sha256.Sum256([]byte(strings.Join(strings.Fields(query), " ")))
+	return hex.EncodeToString(sum[:6])
+}
+
+// overrideQuery returns the override of query, or query.
+func overrideQuery(query string) string {
+	if len(queryOverrides) == 0 {
+		return query
+	}
+	if override, ok := queryOverrides[QueryID(query)]; ok {
+		return override
+	}
+	return query
+}
+
+// CatalogQuery is a query a collector runs.
+type CatalogQuery struct {
+	Collector string
+	ID        string
+	Query     string
+	// Override is the query run instead, if any.
+	Override string
+}
+
+type catalogRunKey struct{}
+
+// catalogRun returns whether ctx is the context of a QueryCatalog run,
+// in which scrapers must not update the state kept across scrapes.
+func catalogRun(ctx context.Context) bool {
+	return ctx.Value(catalogRunKey{}) != nil
+}
+
+// QueryCatalog returns the queries the scrapers may run on a server of the
+// given version. The scrapers run on connections recording their queries,
+// once returning no rows and once failing every query so the fallback
+// queries are listed too. The queries run for the rows of another query,
+// such as the queries per schema, are not listed. A scraper panicking on
+// these connections fails the catalog, its queries would be missing.
+func QueryCatalog(ctx context.Context, scrapers []Scraper, version string) ([]CatalogQuery, error) {
+	number := parseServerVersion(version)
+	ctx = withServerVersion(context.WithValue(ctx, catalogRunKey{}, true), version, number)
+
+	var res []CatalogQuery
+	for _, scraper := range scrapers {
+		if number < scraper.Version() {
+			continue
+		}
+		seen := map[string]bool{}
+		for _, fail := range []bool{false, true} {
+			queries, err := recordQueries(ctx, scraper, fail)
+			if err != nil {
+				return nil, err
+			}
+			for _, query := range queries {
+				id := QueryID(query)
+				if seen[id] {
+					continue
+				}
+				seen[id] = true
+				res = append(res, CatalogQuery{Collector: scraper.Name(), ID: id, Query: query, Override: queryOverrides[id]})
+			}
+		}
+	}
+	return res, nil
+}
+
+// recordQueries runs scraper on a connection returning no rows, or failing
+// every query, and returns the queries it ran.
+func recordQueries(ctx context.Context, scraper Scraper, fail bool) (queries []string, err error) {
+	recorder := &catalogConnector{fail: fail}
+	db := sql.OpenDB(recorder)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		for range ch {
+		}
+	}()
+	defer close(ch)
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("collector %s panicked listing its queries: %v", scraper.Name(), r)
+		}
+	}()
+	scraper.Scrape(ctx, db, ch, log.NewNopLogger())
+	return recorder.queries, nil
+}
+
+// ServerVersion returns the version of the server at dsn.
+func ServerVersion(ctx context.Context, dsn string) (string, error) {
+	db, err := sql.Open(driverName(), dsn)
+	if err != nil {
+		return "", err
+	}
+	defer db.Close()
+	var version string
+	err = db.QueryRowContext(ctx, versionQuery).Scan(&version)
+	return version, err
+}
+
+var (
+	errCatalogQuery       = errors.New("query recorded for the query catalog")
+	errCatalogTransaction = errors.New("transactions are not recorded in the query catalog")
+)
+
+// catalogConnector opens connections recording the queries run on them.
+type catalogConnector struct {
+	fail    bool
+	queries []string
+}
+
+// Connect implements driver.Connector.
+func (c *catalogConnector) Connect(context.Context) (driver.Conn, error) {
+	return &catalogConn{connector: c}, nil
+}
+
+// Driver implements driver.Connector.
+func (c *catalogConnector) Driver() driver.Driver {
+	return nil
+}
+
+// catalogConn records the queries run on it and returns no rows.
+type catalogConn struct {
+	connector *catalogConnector
+}
+
+func (c *catalogConn) record(query string) error {
+	c.connector.queries = append(c.connector.queries, query)
+	if c.connector.fail {
+		return errCatalogQuery
+	}
+	return nil
+}
+
+// QueryContext implements driver.QueryerContext.
+func (c *catalogConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	if err := c.record(query); err != nil {
+		return nil, err
+	}
+	return catalogRows{}, nil
+}
+
+// ExecContext implements driver.ExecerContext.
+func (c *catalogConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	if err := c.record(query); err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(0), nil
+}
+
+// Prepare implements driver.Conn.
+func (c *catalogConn) Prepare(query string) (driver.Stmt, error) {
+	return catalogStmt{conn: c, query: query}, nil
+}
+
+// Close implements driver.Conn.
+func (c *catalogConn) Close() error {
+	return nil
+}
+
+// Begin implements driver.Conn.
+func (c *catalogConn) Begin() (driver.Tx, error) {
+	return nil, errCatalogTransaction
+}
+
+// catalogStmt records the query of a prepared statement when it runs.
+type catalogStmt struct {
+	conn  *catalogConn
+	query string
+}
+
+func (s catalogStmt) Close() error {
+	return nil
+}
+
+func (s catalogStmt) NumInput() int {
+	return -1
+}
+
+func (s catalogStmt) Exec([]driver.Value) (driver.Result, error) {
+	if err := s.conn.record(s.query); err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(0), nil
+}
+
+func (s catalogStmt) Query([]driver.Value) (driver.Rows, error) {
+	if err := s.conn.record(s.query); err != nil {
+		return nil, err
+	}
+	return catalogRows{}, nil
+}
+
+// catalogRows is an empty result.
+type catalogRows struct{}
+
+func (catalogRows) Columns() []string {
+	return nil
+}
+
+func (catalogRows) Close() error {
+	return nil
+}
+
+func (catalogRows) Next([]driver.Value) error {
+	return io.EOF
+}
+
+// overrideConnector opens connections running the overrides of the
+// collector queries instead of them.
+type overrideConnector struct {
+	driver.Connector
+}
+
+// Connect implements driver.Connector.
+func (c overrideConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &overrideConn{wrappedConn{conn}}, nil
+}
+
+// overrideConn wraps a driver.Conn to run the overrides of the queries.
+type overrideConn struct {
+	wrappedConn
+}
+
+// QueryContext implements driver.QueryerContext.
+func (c *overrideConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	return queryer.QueryContext(ctx, overrideQuery(query), args)
+}
+
+// ExecContext implements driver.ExecerContext.
+func (c *overrideConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	return execer.ExecContext(ctx, overrideQuery(query), args)
+}
+
+// PrepareContext implements driver.ConnPrepareContext.
+func (c *overrideConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return preparer.PrepareContext(ctx, overrideQuery(query))
+	}
+	return c.Conn.Prepare(overrideQuery(query))
+}
+
+// Prepare implements driver.Conn.
+func (c *overrideConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
@@ -0,0 +1,90 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestQueryCatalog(t *testing.T) {
+	defer SetQueryOverrides(map[string]string{})
+
+	convey.Convey("The queries of the scrapers are listed for the server version", t, func() {
+		SetQueryOverrides(map[string]string{QueryID(globalStatusQuery): "SHOW GLOBAL STATUS LIKE 'Innodb%'"})
+		scrapers := []Scraper{ScrapeGlobalStatus{}, ScrapeSlaveStatus{}, ScrapeSchemaChanges{}}
+		definitions := schemaChanges.definitions
+
+		queries, err := QueryCatalog(context.Background(), scrapers, "8.4.0")
+		convey.So(err, convey.ShouldBeNil)
+		byCollector := map[string][]string{}
+		for _, q := range queries {
+			convey.So(q.ID, convey.ShouldEqual, QueryID(q.Query))
+			byCollector[q.Collector] = append(byCollector[q.Collector], q.Query)
+		}
+		convey.So(queries[0], convey.ShouldResemble, CatalogQuery{
+			Collector: "global_status",
+			ID:        QueryID(globalStatusQuery),
+			Query:     globalStatusQuery,
+			Override:  "SHOW GLOBAL STATUS LIKE 'Innodb%'",
+		})
+		convey.So(byCollector["slave_status"], convey.ShouldContain, "SHOW REPLICA STATUS")
+		convey.So(byCollector["slave_status"], convey.ShouldNotContain, "SHOW SLAVE STATUS")
+		convey.So(byCollector["info_schema.schema_changes"], convey.ShouldResemble, []string{schemaDefinitionsQuery})
+
+		queries, err = QueryCatalog(context.Background(), scrapers, "5.7.40")
+		convey.So(err, convey.ShouldBeNil)
+		byCollector = map[string][]string{}
+		for _, q := range queries {
+			byCollector[q.Collector] = append(byCollector[q.Collector], q.Query)
+		}
+		convey.So(byCollector["slave_status"], convey.ShouldContain, "SHOW SLAVE STATUS")
+		// The state kept across scrapes is untouched.
+		convey.So(schemaChanges.definitions, convey.ShouldResemble, definitions)
+	})
+
+	convey.Convey("A panicking scraper fails the catalog", t, func() {
+		_, err := QueryCatalog(context.Background(), []Scraper{panickingScraper{}}, "8.4.0")
+		convey.So(err, convey.ShouldNotBeNil)
+	})
+
+	convey.Convey("Query IDs don't depend on whitespace", t, func() {
+		convey.So(QueryID("SELECT 1\n\t FROM dual"), convey.ShouldEqual, QueryID("SELECT 1 FROM dual"))
+		convey.So(QueryID("SELECT 1"), convey.ShouldNotEqual, QueryID("SELECT 2"))
+	})
+
+	convey.Convey("Overridden queries are replaced", t, func() {
+		SetQueryOverrides(map[string]string{QueryID("SELECT 1"): "SELECT 2"})
+		convey.So(overrideQuery("SELECT  1"), convey.ShouldEqual, "SELECT 2")
+		convey.So(overrideQuery("SELECT 3"), convey.ShouldEqual, "SELECT 3")
+	})
+}
+
+// panickingScraper panics on an empty result.
+type panickingScraper struct{}
+
+func (panickingScraper) Name() string     { return "panicking" }
+func (panickingScraper) Help() string     { return "Panics on an empty result" }
+func (panickingScraper) Version() float64 { return 5.1 }
+
+func (panickingScraper) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	var values []string
+	ch <- prometheus.MustNewConstMetric(prometheus.NewDesc("panicking", "", nil, nil), prometheus.GaugeValue, float64(len(values[0])))
+	return nil
+}
@@ -0,0 +1,175 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+
+	"github.com/alecthomas/kingpin/v2"
+	MySQL "github.com/go-sql-driver/mysql"
+)
+
+const (
+	setReadOnlyQuery = `SET SESSION TRANSACTION READ ONLY`
+
+	// Drivers scrape connections use with --exporter.read_only.
+	readOnlyDriverName       = "mysql-read-only"
+	tracedReadOnlyDriverName = "mysql-traced-read-only"
+)
+
+// Tunable flags.
+var (
+	readOnlyMode = kingpin.Flag(
+		"exporter.read_only",
+		"Make scrape connections read only with SET SESSION TRANSACTION READ ONLY and reject queries other than SELECT and SHOW on them.",
+	).Default("false").Bool()
+)
+
+func init() {
+	sql.Register(readOnlyDriverName, readOnlyDriver{MySQL.MySQLDriver{}})
+	sql.Register(tracedReadOnlyDriverName, tracedDriver{readOnlyDriver{MySQL.MySQLDriver{}}})
+}
+
+// checkReadOnlyQuery returns an error unless every statement of query is a
+// SELECT or a SHOW. Queries hold multiple statements with
+// --exporter.batch_show_statements.
+func checkReadOnlyQuery(query string) error {
+	statements := 0
+	for _, statement := range strings.Split(query, ";") {
+		fields := strings.Fields(strings.TrimLeft(statement, " \t\r\n("))
+		if len(fields) == 0 {
+			continue
+		}
+		switch strings.ToUpper(fields[0]) {
+		case "SELECT", "SHOW":
+			statements++
+		default:
+			return errReadOnlyQuery(query)
+		}
+	}
+	if statements == 0 {
+		return errReadOnlyQuery(query)
+	}
+	return nil
+}
+
+func errReadOnlyQuery(query string) error {
+	return fmt.Errorf("only SELECT and SHOW queries are allowed in read only mode: %.40q", query)
+}
+
+// readOnlyDriver wraps the MySQL driver so connections are read only.
+type readOnlyDriver struct {
+	driver.Driver
+}
+
+// Open implements driver.Driver.
+func (d readOnlyDriver) Open(name string) (driver.Conn, error) {
+	conn, err := d.Driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return d.readOnly(context.Background(), conn)
+}
+
+// OpenConnector implements driver.DriverContext.
+func (d readOnlyDriver) OpenConnector(name string) (driver.Connector, error) {
+	connector, err := openConnector(d.Driver, name)
+	if err != nil {
+		return nil, err
+	}
+	return readOnlyConnector{Connector: connector, driver: d}, nil
+}
+
+// readOnly makes conn read only.
+func (d readOnlyDriver) readOnly(ctx context.Context, conn driver.Conn) (driver.Conn, error) {
+	execer, ok := conn.(driver.ExecerContext)
+	if !ok {
+		conn.Close()
+		return nil, fmt.Errorf("driver %T can't execute %s", d.Driver, setReadOnlyQuery)
+	}
+	if _, err := execer.ExecContext(ctx, setReadOnlyQuery, nil); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &readOnlyConn{wrappedConn{conn}}, nil
+}
+
+// readOnlyConnector opens read only connections.
+type readOnlyConnector struct {
+	driver.Connector
+	driver readOnlyDriver
+}
+
+// Connect implements driver.Connector.
+func (c readOnlyConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return c.driver.readOnly(ctx, conn)
+}
+
+// Driver implements driver.Connector.
+func (c readOnlyConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// readOnlyConn wraps a driver.Conn to reject queries which may write.
+type readOnlyConn struct {
+	wrappedConn
+}
+
+// QueryContext implements driver.QueryerContext.
+func (c *readOnlyConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if err := checkReadOnlyQuery(query); err != nil {
+		return nil, err
+	}
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	return queryer.QueryContext(ctx, query, args)
+}
+
+// ExecContext implements driver.ExecerContext.
+func (c *readOnlyConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if err := checkReadOnlyQuery(query); err != nil {
+		return nil, err
+	}
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	return execer.ExecContext(ctx, query, args)
+}
+
+// PrepareContext implements driver.ConnPrepareContext.
+func (c *readOnlyConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if err := checkReadOnlyQuery(query); err != nil {
+		return nil, err
+	}
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return preparer.PrepareContext(ctx, query)
+	}
+	return c.Conn.Prepare(query)
+}
+
+// Prepare implements driver.Conn.
+func (c *readOnlyConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
@@ -0,0 +1,81 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestReadOnlyDriver(t *testing.T) {
+	mockDB, mock, err := sqlmock.NewWithDSN("read_only_driver_test")
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer mockDB.Close()
+	sql.Register("read_only_driver_test", readOnlyDriver{mockDB.Driver()})
+	db, err := sql.Open("read_only_driver_test", "read_only_driver_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec(setReadOnlyQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(sanitizeQuery(globalStatusQuery)).WillReturnRows(
+		sqlmock.NewRows([]string{"Variable_name", "Value"}).AddRow("Uptime", "10"))
+	mock.ExpectQuery(sanitizeQuery(infoSchemaAutoIncrementQuery)).WillReturnRows(
+		sqlmock.NewRows([]string{"table_schema", "table_name", "column_name", "auto_increment", "max_int"}))
+
+	convey.Convey("Read only connections", t, func() {
+		rows, err := db.QueryContext(context.Background(), globalStatusQuery)
+		convey.So(err, convey.ShouldBeNil)
+		rows.Close()
+		rows, err = db.QueryContext(context.Background(), infoSchemaAutoIncrementQuery)
+		convey.So(err, convey.ShouldBeNil)
+		rows.Close()
+
+		_, err = db.ExecContext(context.Background(), "DELETE FROM mysql.user")
+		convey.So(err, convey.ShouldNotBeNil)
+		_, err = db.QueryContext(context.Background(), "UPDATE t SET a = ? WHERE b = 1", 1)
+		convey.So(err, convey.ShouldNotBeNil)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestCheckReadOnlyQuery(t *testing.T) {
+	convey.Convey("Allowed queries", t, func() {
+		for query, allowed := range map[string]bool{
+			"SELECT 1":                                   true,
+			"\n\tselect @@version":                       true,
+			"(SELECT 1) UNION (SELECT 2)":                true,
+			"SHOW GLOBAL STATUS":                         true,
+			"INSERT INTO t VALUES (1)":                   false,
+			"SET GLOBAL read_only = 0":                   false,
+			"KILL QUERY 10":                              false,
+			"SHOW GLOBAL STATUS;\nSHOW GLOBAL VARIABLES": true,
+			"SHOW GLOBAL STATUS; KILL QUERY 10":          false,
+			"":                                           false,
+		} {
+			convey.So(checkReadOnlyQuery(query) == nil, convey.ShouldEqual, allowed)
+		}
+	})
+}
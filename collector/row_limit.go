@@ -0,0 +1,118 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Tunable flags.
+var (
+	rowLimit = kingpin.Flag(
+		"exporter.row_limit",
+		"Maximum number of rows the collectors scanning a row per table read per scrape, 0 for no limit.",
+	).Default("0").Int()
+	collectorRowLimit = kingpin.Flag(
+		"exporter.collector_row_limit",
+		"Maximum number of rows a collector reads per scrape as <collector>=<rows>, overriding --exporter.row_limit. May be repeated.",
+	).StringMap()
+)
+
+var scrapeRowsTruncatedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: prometheus.BuildFQName(namespace, exporter, "scrape_rows_truncated_total"),
+	Help: "Scrapes which stopped reading rows at the row limit, by collector.",
+}, []string{"collector"})
+
+func init() {
+	prometheus.MustRegister(scrapeRowsTruncatedTotal)
+}
+
+// rowLimits are the row limits of the scrapers with a limit other than
+// --exporter.row_limit, set by ParseRowLimits at startup.
+var rowLimits = map[string]int{}
+
+// ParseRowLimits parses the row limits of the scrapers.
+func ParseRowLimits(scrapers []Scraper) error {
+	names := make(map[string]bool, len(scrapers))
+	for _, scraper := range scrapers {
+		names[scraper.Name()] = true
+	}
+	limits := make(map[string]int, len(*collectorRowLimit))
+	for name, value := range *collectorRowLimit {
+		if !names[name] {
+			return fmt.Errorf("unknown collector %q in row limit", name)
+		}
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 0 {
+			return fmt.Errorf("invalid row limit %q for collector %q", value, name)
+		}
+		limits[name] = limit
+	}
+	rowLimits = limits
+	return nil
+}
+
+// rowScanner reads the rows of the queries of a scrape, up to the row limit
+// of the scraper over all its queries.
+type rowScanner struct {
+	name      string
+	limit     int
+	read      int
+	truncated bool
+	logger    log.Logger
+}
+
+func newRowScanner(name string, logger log.Logger) *rowScanner {
+	limit, ok := rowLimits[name]
+	if !ok {
+		limit = *rowLimit
+	}
+	return &rowScanner{name: name, limit: limit, logger: logger}
+}
+
+// scan runs query and calls fn for each row, closing the rows before it
+// returns. Once the row limit is reached the remaining rows, and the rows of
+// later queries, are skipped without error.
+func (s *rowScanner) scan(ctx context.Context, db *sql.DB, fn func(*sql.Rows) error, query string, args ...interface{}) error {
+	if s.truncated {
+		return nil
+	}
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if s.limit > 0 && s.read >= s.limit {
+			s.truncated = true
+			level.Warn(s.logger).Log("msg", "Row limit reached, skipping remaining rows", "limit", s.limit)
+			scrapeRowsTruncatedTotal.WithLabelValues(s.name).Inc()
+			return nil
+		}
+		s.read++
+		if err := fn(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
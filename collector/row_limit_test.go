@@ -0,0 +1,86 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestParseRowLimits(t *testing.T) {
+	defaultLimits := *collectorRowLimit
+	defer func() {
+		*collectorRowLimit = defaultLimits
+		rowLimits = map[string]int{}
+	}()
+	scrapers := []Scraper{ScrapeTableSchema{}}
+
+	convey.Convey("Row limits are parsed", t, func() {
+		*collectorRowLimit = map[string]string{"info_schema.tables": "1000"}
+		convey.So(ParseRowLimits(scrapers), convey.ShouldBeNil)
+		convey.So(rowLimits, convey.ShouldResemble, map[string]int{"info_schema.tables": 1000})
+
+		*collectorRowLimit = map[string]string{"info_schema.tables": "lots"}
+		convey.So(ParseRowLimits(scrapers), convey.ShouldBeError)
+
+		*collectorRowLimit = map[string]string{"info_schema.nope": "10"}
+		convey.So(ParseRowLimits(scrapers), convey.ShouldBeError)
+	})
+}
+
+func TestRowScanner(t *testing.T) {
+	rowLimits = map[string]int{"test": 3}
+	defer func() { rowLimits = map[string]int{} }()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT a").WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("a1").AddRow("a2"))
+	mock.ExpectQuery("SELECT b").WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("b1").AddRow("b2"))
+
+	var names []string
+	scan := func(rows *sql.Rows) error {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return err
+		}
+		names = append(names, name)
+		return nil
+	}
+	before := testutil.ToFloat64(scrapeRowsTruncatedTotal.WithLabelValues("test"))
+
+	convey.Convey("Rows are read up to the limit over all queries", t, func() {
+		scanner := newRowScanner("test", log.NewNopLogger())
+		convey.So(scanner.scan(context.Background(), db, scan, "SELECT a"), convey.ShouldBeNil)
+		convey.So(scanner.scan(context.Background(), db, scan, "SELECT b"), convey.ShouldBeNil)
+		// Skipped without querying.
+		convey.So(scanner.scan(context.Background(), db, scan, "SELECT c"), convey.ShouldBeNil)
+		convey.So(names, convey.ShouldResemble, []string{"a1", "a2", "b1"})
+		convey.So(testutil.ToFloat64(scrapeRowsTruncatedTotal.WithLabelValues("test"))-before, convey.ShouldEqual, 1)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// Labels the schema and table filters apply to.
+const (
+	schemaLabel = "schema"
+	tableLabel  = "table"
+)
+
+// Tunable flags.
+var (
+	schemaInclude = kingpin.Flag(
+		"exporter.schema_include",
+		"Regexp of the schemas to expose metrics of, for all collectors.",
+	).Default("").String()
+	schemaExclude = kingpin.Flag(
+		"exporter.schema_exclude",
+		"Regexp of the schemas not to expose metrics of, for all collectors.",
+	).Default("").String()
+	tableInclude = kingpin.Flag(
+		"exporter.table_include",
+		"Regexp of the tables to expose metrics of, for all collectors.",
+	).Default("").String()
+	tableExclude = kingpin.Flag(
+		"exporter.table_exclude",
+		"Regexp of the tables not to expose metrics of, for all collectors.",
+	).Default("").String()
+	collectorSchemaInclude = kingpin.Flag(
+		"exporter.collector_schema_include",
+		"Regexp of the schemas a collector exposes metrics of, as <collector>=<regexp>, overriding --exporter.schema_include. May be repeated.",
+	).StringMap()
+	collectorSchemaExclude = kingpin.Flag(
+		"exporter.collector_schema_exclude",
+		"Regexp of the schemas a collector doesn't expose metrics of, as <collector>=<regexp>, overriding --exporter.schema_exclude. May be repeated.",
+	).StringMap()
+	collectorTableInclude = kingpin.Flag(
+		"exporter.collector_table_include",
+		"Regexp of the tables a collector exposes metrics of, as <collector>=<regexp>, overriding --exporter.table_include. May be repeated.",
+	).StringMap()
+	collectorTableExclude = kingpin.Flag(
+		"exporter.collector_table_exclude",
+		"Regexp of the tables a collector doesn't expose metrics of, as <collector>=<regexp>, overriding --exporter.table_exclude. May be repeated.",
+	).StringMap()
+)
+
+// schemaFilters are the filters of the scrapers with any, set by
+// ParseSchemaFilters at startup.
+var schemaFilters map[string]*schemaFilter
+
+// nameFilter matches names against anchored include and exclude regexps,
+// nil when not set.
+type nameFilter struct {
+	include, exclude *regexp.Regexp
+}
+
+// matches returns whether name is included and not excluded.
+func (f nameFilter) matches(name string) bool {
+	if f.include != nil && !f.include.MatchString(name) {
+		return false
+	}
+	return f.exclude == nil || !f.exclude.MatchString(name)
+}
+
+// schemaFilter drops the metrics whose schema or table label doesn't match.
+type schemaFilter struct {
+	schema, table nameFilter
+}
+
+// keep returns whether metric passes the filter. Metrics without schema and
+// table labels always do.
+func (f *schemaFilter) keep(metric prometheus.Metric) bool {
+	var m dto.Metric
+	if err := metric.Write(&m); err != nil {
+		return true
+	}
+	for _, label := range m.GetLabel() {
+		switch label.GetName() {
+		case schemaLabel:
+			if !f.schema.matches(label.GetValue()) {
+				return false
+			}
+		case tableLabel:
+			if !f.table.matches(label.GetValue()) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// filter returns a channel sending the metrics passing the filter to ch,
+// and a function to call once done sending.
+func (f *schemaFilter) filter(ch chan<- prometheus.Metric) (chan<- prometheus.Metric, func()) {
+	filtered := make(chan prometheus.Metric)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for metric := range filtered {
+			if f.keep(metric) {
+				ch <- metric
+			}
+		}
+	}()
+	return filtered, func() {
+		close(filtered)
+		<-done
+	}
+}
+
+// ParseSchemaFilters compiles the schema and table filters of the scrapers.
+func ParseSchemaFilters(scrapers []Scraper) error {
+	names := make(map[string]bool, len(scrapers))
+	for _, scraper := range scrapers {
+		names[scraper.Name()] = true
+	}
+	for _, flag := range []map[string]string{*collectorSchemaInclude, *collectorSchemaExclude, *collectorTableInclude, *collectorTableExclude} {
+		for name := range flag {
+			if !names[name] {
+				return fmt.Errorf("unknown collector %q in schema filter", name)
+			}
+		}
+	}
+
+	filters := map[string]*schemaFilter{}
+	for _, scraper := range scrapers {
+		name := scraper.Name()
+		var (
+			f   schemaFilter
+			err error
+		)
+		if f.schema.include, err = filterRegexp(name, *schemaInclude, *collectorSchemaInclude); err != nil {
+			return err
+		}
+		if f.schema.exclude, err = filterRegexp(name, *schemaExclude, *collectorSchemaExclude); err != nil {
+			return err
+		}
+		if f.table.include, err = filterRegexp(name, *tableInclude, *collectorTableInclude); err != nil {
+			return err
+		}
+		if f.table.exclude, err = filterRegexp(name, *tableExclude, *collectorTableExclude); err != nil {
+			return err
+		}
+		if f != (schemaFilter{}) {
+			filters[name] = &f
+		}
+	}
+	schemaFilters = filters
+	return nil
+}
+
+// filterRegexp compiles the regexp of the named scraper, or the global one,
+// nil if neither is set.
+func filterRegexp(name, global string, perCollector map[string]string) (*regexp.Regexp, error) {
+	expr := global
+	if e, ok := perCollector[name]; ok {
+		expr = e
+	}
+	if expr == "" {
+		return nil, nil
+	}
+	re, err := regexp.Compile("^(?:" + expr + ")$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema filter %q for collector %q: %w", expr, name, err)
+	}
+	return re, nil
+}
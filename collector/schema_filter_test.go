@@ -0,0 +1,87 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestParseSchemaFilters(t *testing.T) {
+	oldSchemaInclude, oldSchemaExclude, oldTableExclude := *schemaInclude, *schemaExclude, *tableExclude
+	oldCollectorSchemaInclude := *collectorSchemaInclude
+	defer func() {
+		*schemaInclude, *schemaExclude, *tableExclude = oldSchemaInclude, oldSchemaExclude, oldTableExclude
+		*collectorSchemaInclude = oldCollectorSchemaInclude
+		schemaFilters = nil
+	}()
+
+	scrapers := []Scraper{ScrapeTableSchema{}, ScrapeInnodbStats{}, ScrapeGlobalStatus{}}
+	tableDesc := prometheus.NewDesc("table_metric", "", []string{"schema", "table"}, nil)
+	schemaDesc := prometheus.NewDesc("schema_metric", "", []string{"schema"}, nil)
+	keep := func(name string, desc *prometheus.Desc, labels ...string) bool {
+		filter := schemaFilters[name]
+		if filter == nil {
+			return true
+		}
+		return filter.keep(prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, 1, labels...))
+	}
+
+	convey.Convey("Schema and table filters", t, func() {
+		*schemaExclude = "tmp_.*|test"
+		*tableExclude = ".*_old"
+		*collectorSchemaInclude = map[string]string{ScrapeInnodbStats{}.Name(): "shop"}
+		convey.So(ParseSchemaFilters(scrapers), convey.ShouldBeNil)
+
+		convey.So(keep("info_schema.tables", tableDesc, "shop", "orders"), convey.ShouldBeTrue)
+		convey.So(keep("info_schema.tables", tableDesc, "tmp_import", "orders"), convey.ShouldBeFalse)
+		convey.So(keep("info_schema.tables", tableDesc, "testing", "orders"), convey.ShouldBeTrue)
+		convey.So(keep("info_schema.tables", tableDesc, "shop", "orders_old"), convey.ShouldBeFalse)
+		convey.So(keep("mysql.innodb_stats", schemaDesc, "shop"), convey.ShouldBeTrue)
+		convey.So(keep("mysql.innodb_stats", schemaDesc, "wiki"), convey.ShouldBeFalse)
+		// Metrics without schema labels are kept.
+		convey.So(keep("global_status", prometheus.NewDesc("up", "", nil, nil)), convey.ShouldBeTrue)
+
+		*collectorSchemaInclude = map[string]string{"unknown": "shop"}
+		convey.So(ParseSchemaFilters(scrapers), convey.ShouldNotBeNil)
+		*collectorSchemaInclude = map[string]string{}
+		*schemaInclude = "("
+		convey.So(ParseSchemaFilters(scrapers), convey.ShouldNotBeNil)
+	})
+}
+
+func TestSchemaFilterChannel(t *testing.T) {
+	filter := &schemaFilter{}
+	var err error
+	if filter.schema.exclude, err = filterRegexp("", "test", nil); err != nil {
+		t.Fatal(err)
+	}
+	desc := prometheus.NewDesc("schema_metric", "", []string{"schema"}, nil)
+
+	ch := make(chan prometheus.Metric, 2)
+	filtered, done := filter.filter(ch)
+	filtered <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, 1, "shop")
+	filtered <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, 2, "test")
+	done()
+	close(ch)
+
+	convey.Convey("Filtered metrics", t, func() {
+		got := readMetric(<-ch)
+		convey.So(got.labels, convey.ShouldResemble, labelMap{"schema": "shop"})
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+}
@@ -0,0 +1,151 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+)
+
+const readOnlyQuery = `SHOW GLOBAL VARIABLES WHERE Variable_name IN ('read_only', 'super_read_only')`
+
+// Scrape policies, where a scraper runs.
+const (
+	policyAny     = "any"
+	policyPrimary = "primary"
+	policyReplica = "replica"
+)
+
+// Tunable flags.
+var (
+	replicaAwareScraping = kingpin.Flag(
+		"exporter.replica_aware",
+		"Run heavyweight collectors (table sizes, statement digests) only on read-only servers.",
+	).Default("false").Bool()
+	scrapePolicyFlags = kingpin.Flag(
+		"exporter.scrape_policy",
+		"Where a collector runs, as <collector>=<any|primary|replica>, evaluated against read_only/super_read_only at scrape time. May be repeated.",
+	).StringMap()
+)
+
+// defaultScrapePolicies are the policies used with --exporter.replica_aware.
+var defaultScrapePolicies = map[string]string{
+	ScrapeTableSchema{}.Name():             policyReplica,
+	ScrapeTableFragmentation{}.Name():      policyReplica,
+	ScrapeSchemaInventory{}.Name():         policyReplica,
+	ScrapeSchemaChanges{}.Name():           policyReplica,
+	ScrapeCharsetMismatch{}.Name():         policyReplica,
+	ScrapeAutoIncrementColumns{}.Name():    policyReplica,
+	ScrapePerfEventsStatements{}.Name():    policyReplica,
+	ScrapePerfEventsStatementsSum{}.Name(): policyReplica,
+	ScrapeInnodbBufferPoolTables{}.Name():  policyReplica,
+}
+
+// CheckScrapePolicies validates the --exporter.scrape_policy flags against
+// the known scrapers.
+func CheckScrapePolicies(scrapers []Scraper) error {
+	names := make(map[string]bool, len(scrapers))
+	for _, scraper := range scrapers {
+		names[scraper.Name()] = true
+	}
+	for name, policy := range *scrapePolicyFlags {
+		if !names[name] {
+			return fmt.Errorf("unknown collector %q in scrape policy", name)
+		}
+		switch policy {
+		case policyAny, policyPrimary, policyReplica:
+		default:
+			return fmt.Errorf("invalid scrape policy %q for collector %q", policy, name)
+		}
+	}
+	return nil
+}
+
+// scrapePolicy returns the policy of the named scraper.
+func scrapePolicy(name string) string {
+	if policy, ok := (*scrapePolicyFlags)[name]; ok {
+		return policy
+	}
+	if *replicaAwareScraping {
+		if policy, ok := defaultScrapePolicies[name]; ok {
+			return policy
+		}
+	}
+	return policyAny
+}
+
+// filterByPolicy drops the scrapers whose policy doesn't match the role of
+// the server. The role is only queried when a scraper needs it, on error all
+// scrapers are kept.
+func filterByPolicy(ctx context.Context, db *sql.DB, scrapers []Scraper, logger log.Logger) []Scraper {
+	needsRole := false
+	for _, scraper := range scrapers {
+		if scrapePolicy(scraper.Name()) != policyAny {
+			needsRole = true
+			break
+		}
+	}
+	if !needsRole {
+		return scrapers
+	}
+
+	readOnly, err := isReadOnly(ctx, db)
+	if err != nil {
+		level.Error(logger).Log("msg", "Error querying read_only, ignoring scrape policies", "err", err)
+		return scrapers
+	}
+	res := make([]Scraper, 0, len(scrapers))
+	for _, scraper := range scrapers {
+		switch scrapePolicy(scraper.Name()) {
+		case policyPrimary:
+			if readOnly {
+				level.Debug(logger).Log("msg", "Skipping primary only scraper on read-only server", "scraper", scraper.Name())
+				continue
+			}
+		case policyReplica:
+			if !readOnly {
+				level.Debug(logger).Log("msg", "Skipping replica only scraper on writable server", "scraper", scraper.Name())
+				continue
+			}
+		}
+		res = append(res, scraper)
+	}
+	return res
+}
+
+// isReadOnly returns whether read_only or super_read_only is enabled.
+func isReadOnly(ctx context.Context, db *sql.DB) (bool, error) {
+	rows, err := db.QueryContext(ctx, readOnlyQuery)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	readOnly := false
+	var name, value string
+	for rows.Next() {
+		if err := rows.Scan(&name, &value); err != nil {
+			return false, err
+		}
+		if v, ok := parseStatus(sql.RawBytes(value)); ok && v == 1 {
+			readOnly = true
+		}
+	}
+	return readOnly, rows.Err()
+}
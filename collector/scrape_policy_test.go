@@ -0,0 +1,80 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestFilterByPolicy(t *testing.T) {
+	oldReplicaAware, oldPolicies := *replicaAwareScraping, *scrapePolicyFlags
+	defer func() {
+		*replicaAwareScraping, *scrapePolicyFlags = oldReplicaAware, oldPolicies
+	}()
+	*replicaAwareScraping = true
+	*scrapePolicyFlags = map[string]string{ScrapeMasterStatus{}.Name(): policyPrimary}
+
+	scrapers := []Scraper{ScrapeTableSchema{}, ScrapeMasterStatus{}, ScrapeSlaveStatus{}}
+	names := func(scrapers []Scraper) []string {
+		var res []string
+		for _, scraper := range scrapers {
+			res = append(res, scraper.Name())
+		}
+		return res
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"Variable_name", "Value"}
+	mock.ExpectQuery(sanitizeQuery(readOnlyQuery)).WillReturnRows(sqlmock.NewRows(columns).
+		AddRow("read_only", "OFF").AddRow("super_read_only", "OFF"))
+	mock.ExpectQuery(sanitizeQuery(readOnlyQuery)).WillReturnRows(sqlmock.NewRows(columns).
+		AddRow("read_only", "ON").AddRow("super_read_only", "OFF"))
+
+	convey.Convey("Scrapers filtered by server role", t, func() {
+		convey.So(names(filterByPolicy(context.Background(), db, scrapers, log.NewNopLogger())), convey.ShouldResemble,
+			[]string{"master_status", "slave_status"})
+		convey.So(names(filterByPolicy(context.Background(), db, scrapers, log.NewNopLogger())), convey.ShouldResemble,
+			[]string{"info_schema.tables", "slave_status"})
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestCheckScrapePolicies(t *testing.T) {
+	oldPolicies := *scrapePolicyFlags
+	defer func() { *scrapePolicyFlags = oldPolicies }()
+
+	scrapers := []Scraper{ScrapeSlaveStatus{}}
+	convey.Convey("Scrape policies are validated", t, func() {
+		*scrapePolicyFlags = map[string]string{"slave_status": policyReplica}
+		convey.So(CheckScrapePolicies(scrapers), convey.ShouldBeNil)
+		*scrapePolicyFlags = map[string]string{"slave_status": "sometimes"}
+		convey.So(CheckScrapePolicies(scrapers), convey.ShouldNotBeNil)
+		*scrapePolicyFlags = map[string]string{"unknown": policyAny}
+		convey.So(CheckScrapePolicies(scrapers), convey.ShouldNotBeNil)
+	})
+}
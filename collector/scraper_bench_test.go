@@ -0,0 +1,199 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Sizes of the benchmark datasets, modelled after large production servers.
+const (
+	benchmarkStatusVariables = 10000
+	benchmarkSchemas         = 100
+	benchmarkTables          = 50000
+	benchmarkProcesses       = 2000
+)
+
+// benchmarkQuery is the result set returned for a query of a scraper.
+type benchmarkQuery struct {
+	query   string
+	columns []string
+	rows    int
+	row     func(i int) []driver.Value
+}
+
+// result returns a fresh result set, sqlmock rows can only be read once.
+func (q benchmarkQuery) result() *sqlmock.Rows {
+	rows := sqlmock.NewRows(q.columns)
+	for i := 0; i < q.rows; i++ {
+		rows.AddRow(q.row(i)...)
+	}
+	return rows
+}
+
+// statusVariableName returns names hitting the different code paths of the
+// global_status and global_variables scrapers.
+func statusVariableName(i int) string {
+	prefixes := []string{"Com_stmt_", "Handler_read_", "Innodb_buffer_pool_pages_", "Innodb_rows_", "Performance_schema_lost_", "Status_"}
+	return fmt.Sprintf("%s%d", prefixes[i%len(prefixes)], i)
+}
+
+func schemaName(i int) string {
+	return fmt.Sprintf("schema_%d", i%benchmarkSchemas)
+}
+
+func tableName(i int) string {
+	return fmt.Sprintf("table_%d", i)
+}
+
+// scraperBenchmarks returns the scrapers to benchmark with their datasets.
+func scraperBenchmarks() []struct {
+	scraper Scraper
+	queries []benchmarkQuery
+} {
+	tableSchemaQueries := []benchmarkQuery{{
+		query:   dbListQuery,
+		columns: []string{"SCHEMA_NAME"},
+		rows:    benchmarkSchemas,
+		row:     func(i int) []driver.Value { return []driver.Value{schemaName(i)} },
+	}}
+	for s := 0; s < benchmarkSchemas; s++ {
+		s := s
+		tableSchemaQueries = append(tableSchemaQueries, benchmarkQuery{
+			query:   fmt.Sprintf(tableSchemaQuery, schemaName(s)),
+			columns: []string{"TABLE_SCHEMA", "TABLE_NAME", "TABLE_TYPE", "ENGINE", "VERSION", "ROW_FORMAT", "TABLE_ROWS", "DATA_LENGTH", "INDEX_LENGTH", "DATA_FREE", "CREATE_OPTIONS"},
+			rows:    benchmarkTables / benchmarkSchemas,
+			row: func(i int) []driver.Value {
+				return []driver.Value{schemaName(s), tableName(i), "BASE TABLE", "InnoDB", 10, "Dynamic", 1000 * i, 16384 * i, 8192 * i, 4096, ""}
+			},
+		})
+	}
+
+	return []struct {
+		scraper Scraper
+		queries []benchmarkQuery
+	}{
+		{ScrapeGlobalStatus{}, []benchmarkQuery{{
+			query:   globalStatusQuery,
+			columns: []string{"Variable_name", "Value"},
+			rows:    benchmarkStatusVariables,
+			row:     func(i int) []driver.Value { return []driver.Value{statusVariableName(i), fmt.Sprint(i)} },
+		}}},
+		{ScrapeGlobalVariables{}, []benchmarkQuery{{
+			query:   globalVariablesQuery,
+			columns: []string{"Variable_name", "Value"},
+			rows:    benchmarkStatusVariables,
+			row:     func(i int) []driver.Value { return []driver.Value{fmt.Sprintf("variable_%d", i), fmt.Sprint(i)} },
+		}}},
+		{ScrapeTableSchema{}, tableSchemaQueries},
+		{ScrapeTableStat{}, []benchmarkQuery{{
+			query:   userstatCheckQuery,
+			columns: []string{"Variable_name", "Value"},
+			rows:    1,
+			row:     func(i int) []driver.Value { return []driver.Value{"userstat", "ON"} },
+		}, {
+			query:   tableStatQuery,
+			columns: []string{"TABLE_SCHEMA", "TABLE_NAME", "ROWS_READ", "ROWS_CHANGED", "ROWS_CHANGED_X_INDEXES"},
+			rows:    benchmarkTables,
+			row: func(i int) []driver.Value {
+				return []driver.Value{schemaName(i), tableName(i), 1000 * i, 100 * i, 300 * i}
+			},
+		}}},
+		{ScrapeAutoIncrementColumns{}, []benchmarkQuery{{
+			query:   infoSchemaAutoIncrementQuery,
+			columns: []string{"table_schema", "table_name", "column_name", "auto_increment", "max_int"},
+			rows:    benchmarkTables,
+			row: func(i int) []driver.Value {
+				return []driver.Value{schemaName(i), tableName(i), "id", i, 2147483647}
+			},
+		}}},
+		{ScrapePerfTableIOWaits{}, []benchmarkQuery{{
+			query:   perfTableIOWaitsQuery,
+			columns: []string{"OBJECT_SCHEMA", "OBJECT_NAME", "COUNT_FETCH", "COUNT_INSERT", "COUNT_UPDATE", "COUNT_DELETE", "SUM_TIMER_FETCH", "SUM_TIMER_INSERT", "SUM_TIMER_UPDATE", "SUM_TIMER_DELETE"},
+			rows:    benchmarkTables,
+			row: func(i int) []driver.Value {
+				return []driver.Value{schemaName(i), tableName(i), i, i, i, i, 1000 * i, 1000 * i, 1000 * i, 1000 * i}
+			},
+		}}},
+		{ScrapePerfIndexIOWaits{}, []benchmarkQuery{{
+			query:   perfIndexIOWaitsQuery,
+			columns: []string{"OBJECT_SCHEMA", "OBJECT_NAME", "INDEX_NAME", "COUNT_FETCH", "COUNT_INSERT", "COUNT_UPDATE", "COUNT_DELETE", "SUM_TIMER_FETCH", "SUM_TIMER_INSERT", "SUM_TIMER_UPDATE", "SUM_TIMER_DELETE"},
+			rows:    benchmarkTables,
+			row: func(i int) []driver.Value {
+				return []driver.Value{schemaName(i), tableName(i), "PRIMARY", i, i, i, i, 1000 * i, 1000 * i, 1000 * i, 1000 * i}
+			},
+		}}},
+		{ScrapeProcesslist{}, []benchmarkQuery{{
+			query:   fmt.Sprintf(infoSchemaProcesslistQuery, 0),
+			columns: []string{"user", "host", "command", "state", "processes", "seconds"},
+			rows:    benchmarkProcesses,
+			row: func(i int) []driver.Value {
+				return []driver.Value{fmt.Sprintf("user_%d", i%50), fmt.Sprintf("10.0.%d.%d", i/250, i%250), "Query", "executing", 1, i % 60}
+			},
+		}}},
+	}
+}
+
+// BenchmarkScrapers runs the scrapers against large datasets, compare runs
+// with `make benchmark-compare`.
+func BenchmarkScrapers(b *testing.B) {
+	// Apply the flag defaults.
+	if _, err := kingpin.CommandLine.Parse([]string{}); err != nil {
+		b.Fatal(err)
+	}
+
+	for _, bm := range scraperBenchmarks() {
+		bm := bm
+		b.Run(bm.scraper.Name(), func(b *testing.B) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				b.Fatalf("error opening a stub database connection: %s", err)
+			}
+			defer db.Close()
+
+			ch := make(chan prometheus.Metric)
+			go func() {
+				for range ch {
+				}
+			}()
+			defer close(ch)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				for _, q := range bm.queries {
+					mock.ExpectQuery(sanitizeQuery(q.query)).WillReturnRows(q.result())
+				}
+				b.StartTimer()
+
+				if err := bm.scraper.Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+					b.Fatalf("error calling function on test: %s", err)
+				}
+			}
+			b.StopTimer()
+			if err := mock.ExpectationsWereMet(); err != nil {
+				b.Errorf("there were unfulfilled exceptions: %s", err)
+			}
+		})
+	}
+}
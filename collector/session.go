@@ -0,0 +1,238 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+)
+
+const multiStatementsParam = "multiStatements=true"
+
+// Tunable flags.
+var (
+	batchShowStatements = kingpin.Flag(
+		"exporter.batch_show_statements",
+		"Run the SHOW statements of the cheap collectors in one multi-statement round trip per scrape.",
+	).Default("false").Bool()
+)
+
+// batchedQueries are the queries of the scrapers which run batched in a
+// scrape session with --exporter.batch_show_statements.
+var batchedQueries = map[string][]string{
+	ScrapeGlobalStatus{}.Name():     {globalStatusQuery},
+	ScrapeGlobalVariables{}.Name():  {globalVariablesQuery},
+	ScrapeConfigCompliance{}.Name(): {globalVariablesQuery},
+	ScrapeUptime{}.Name():           {uptimeQuery},
+	ScrapeQueryCache{}.Name():       {queryCacheStatusQuery, queryCacheVariablesQuery},
+	ScrapeFirewallAudit{}.Name():    {firewallAuditStatusQuery},
+	ScrapePerfConnections{}.Name():  {connectionsStatusQuery},
+}
+
+// resultRows are the rows of a query result, *sql.Rows or rows buffered by
+// a scrape session.
+type resultRows interface {
+	Columns() ([]string, error)
+	Next() bool
+	Scan(dest ...interface{}) error
+	Err() error
+	Close() error
+}
+
+type scrapeSessionKey struct{}
+
+// scrapeSession holds the results of the queries run batched for a scrape.
+type scrapeSession struct {
+	results map[string]*bufferedResult
+}
+
+// newScrapeSession runs the batched queries of the scrapers in one round
+// trip. It returns nil unless at least two queries ran. Queries failing in
+// the batch, and the ones after them, are left to the scrapers.
+func newScrapeSession(ctx context.Context, db *sql.DB, scrapers []Scraper, logger log.Logger) *scrapeSession {
+	var queries []string
+	seen := map[string]bool{}
+	for _, scraper := range scrapers {
+		for _, query := range batchedQueries[scraper.Name()] {
+			if !seen[query] {
+				seen[query] = true
+				queries = append(queries, query)
+			}
+		}
+	}
+	if len(queries) < 2 {
+		return nil
+	}
+
+	statements := make([]string, len(queries))
+	for i, query := range queries {
+		statements[i] = overrideQuery(query)
+	}
+	rows, err := db.QueryContext(ctx, strings.Join(statements, ";\n"))
+	if err != nil {
+		level.Debug(logger).Log("msg", "Error running batched statements", "err", err)
+		return nil
+	}
+	defer rows.Close()
+
+	s := &scrapeSession{results: make(map[string]*bufferedResult, len(queries))}
+	for i, query := range queries {
+		if i > 0 && !rows.NextResultSet() {
+			level.Debug(logger).Log("msg", "Error running batched statement", "query", query, "err", rows.Err())
+			break
+		}
+		result, err := bufferResult(rows)
+		if err != nil {
+			level.Debug(logger).Log("msg", "Error reading batched statement", "query", query, "err", err)
+			break
+		}
+		s.results[query] = result
+	}
+	return s
+}
+
+// withScrapeSession returns a context carrying s for queryRows.
+func withScrapeSession(ctx context.Context, s *scrapeSession) context.Context {
+	if s == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, scrapeSessionKey{}, s)
+}
+
+// queryRows returns the result of query from the scrape session of ctx, or
+// runs it on db.
+func queryRows(ctx context.Context, db *sql.DB, query string) (resultRows, error) {
+	if s, ok := ctx.Value(scrapeSessionKey{}).(*scrapeSession); ok {
+		if result, ok := s.results[query]; ok {
+			return &bufferedRows{result: result, row: -1}, nil
+		}
+	}
+	return db.QueryContext(ctx, query)
+}
+
+// bufferedResult is a result set read into memory.
+type bufferedResult struct {
+	columns []string
+	rows    [][][]byte
+}
+
+// bufferResult reads the current result set of rows.
+func bufferResult(rows *sql.Rows) (*bufferedResult, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	result := &bufferedResult{columns: columns}
+	scanArgs := make([]interface{}, len(columns))
+	for i := range scanArgs {
+		scanArgs[i] = &sql.RawBytes{}
+	}
+	for rows.Next() {
+		if err := rows.Scan(scanArgs...); err != nil {
+			return nil, err
+		}
+		row := make([][]byte, len(columns))
+		for i, arg := range scanArgs {
+			if value := *arg.(*sql.RawBytes); value != nil {
+				row[i] = append([]byte{}, value...)
+			}
+		}
+		result.rows = append(result.rows, row)
+	}
+	return result, rows.Err()
+}
+
+// bufferedRows iterates over a bufferedResult.
+type bufferedRows struct {
+	result *bufferedResult
+	row    int
+}
+
+func (r *bufferedRows) Columns() ([]string, error) {
+	return r.result.columns, nil
+}
+
+func (r *bufferedRows) Next() bool {
+	r.row++
+	return r.row < len(r.result.rows)
+}
+
+func (r *bufferedRows) Err() error {
+	return nil
+}
+
+func (r *bufferedRows) Close() error {
+	return nil
+}
+
+// Scan copies the columns of the current row into dest, converting them
+// like *sql.Rows does for the types scrapers use.
+func (r *bufferedRows) Scan(dest ...interface{}) error {
+	if r.row < 0 || r.row >= len(r.result.rows) {
+		return fmt.Errorf("sql: Scan called without calling Next")
+	}
+	row := r.result.rows[r.row]
+	if len(dest) != len(row) {
+		return fmt.Errorf("sql: expected %d destination arguments in Scan, not %d", len(row), len(dest))
+	}
+	for i, value := range row {
+		if err := convertColumn(dest[i], value); err != nil {
+			return fmt.Errorf("sql: Scan error on column index %d, name %q: %w", i, r.result.columns[i], err)
+		}
+	}
+	return nil
+}
+
+func convertColumn(dest interface{}, value []byte) error {
+	switch d := dest.(type) {
+	case *sql.RawBytes:
+		*d = value
+		return nil
+	case *[]byte:
+		*d = value
+		return nil
+	case *interface{}:
+		*d = value
+		return nil
+	case sql.Scanner:
+		if value == nil {
+			return d.Scan(nil)
+		}
+		return d.Scan(value)
+	}
+	if value == nil {
+		return fmt.Errorf("converting NULL to %T is unsupported", dest)
+	}
+	var err error
+	switch d := dest.(type) {
+	case *string:
+		*d = string(value)
+	case *int64:
+		*d, err = strconv.ParseInt(string(value), 10, 64)
+	case *uint64:
+		*d, err = strconv.ParseUint(string(value), 10, 64)
+	case *float64:
+		*d, err = strconv.ParseFloat(string(value), 64)
+	default:
+		return fmt.Errorf("unsupported Scan, storing %q into type %T", value, dest)
+	}
+	return err
+}
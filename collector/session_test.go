@@ -0,0 +1,132 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeSession(t *testing.T) {
+	defaultExpected := *configComplianceExpected
+	*configComplianceExpected = map[string]string{"sync_binlog": "1"}
+	defer func() { *configComplianceExpected = defaultExpected }()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"Variable_name", "Value"}
+	mock.ExpectQuery(regexp.QuoteMeta(globalVariablesQuery+";\n"+uptimeQuery)).WillReturnRows(
+		sqlmock.NewRows(columns).AddRow("max_connections", "151").AddRow("sync_binlog", "1"),
+		sqlmock.NewRows(columns).AddRow("Uptime", "3600"),
+	)
+
+	// The variables are queried once for both collectors.
+	scrapers := []Scraper{ScrapeGlobalVariables{}, ScrapeConfigCompliance{}, ScrapeUptime{}, ScrapeSlaveStatus{}}
+	ctx := withScrapeSession(context.Background(), newScrapeSession(context.Background(), db, scrapers, log.NewNopLogger()))
+
+	convey.Convey("Scrapers read the batched results", t, func() {
+		ch := make(chan prometheus.Metric)
+		go func() {
+			if err := (ScrapeConfigCompliance{}).Scrape(ctx, db, ch, log.NewNopLogger()); err != nil {
+				t.Errorf("error calling function on test: %s", err)
+			}
+			if err := (ScrapeUptime{}).Scrape(ctx, db, ch, log.NewNopLogger()); err != nil {
+				t.Errorf("error calling function on test: %s", err)
+			}
+			close(ch)
+		}()
+		var got []MetricResult
+		for m := range ch {
+			got = append(got, readMetric(m))
+		}
+		convey.So(got, convey.ShouldHaveLength, 3)
+		convey.So(got[0], convey.ShouldResemble, MetricResult{labels: labelMap{"variable": "sync_binlog"}, value: 1, metricType: dto.MetricType_GAUGE})
+		convey.So(got[1], convey.ShouldResemble, MetricResult{labels: labelMap{}, value: 3600, metricType: dto.MetricType_GAUGE})
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeSessionTraced(t *testing.T) {
+	mockDB, mock, err := sqlmock.NewWithDSN("traced_session_test")
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer mockDB.Close()
+	sql.Register("traced_session_test", tracedDriver{mockDB.Driver()})
+	db, err := sql.Open("traced_session_test", "traced_session_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	columns := []string{"Variable_name", "Value"}
+	mock.ExpectQuery(regexp.QuoteMeta(globalVariablesQuery+";\n"+uptimeQuery)).WillReturnRows(
+		sqlmock.NewRows(columns).AddRow("max_connections", "151"),
+		sqlmock.NewRows(columns).AddRow("Uptime", "3600"),
+	)
+
+	convey.Convey("Batched results are read through the traced driver", t, func() {
+		s := newScrapeSession(context.Background(), db, []Scraper{ScrapeGlobalVariables{}, ScrapeUptime{}}, log.NewNopLogger())
+		convey.So(s, convey.ShouldNotBeNil)
+		convey.So(s.results, convey.ShouldContainKey, globalVariablesQuery)
+		convey.So(s.results, convey.ShouldContainKey, uptimeQuery)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestBufferedRowsScan(t *testing.T) {
+	rows := &bufferedRows{
+		result: &bufferedResult{
+			columns: []string{"name", "value", "null"},
+			rows:    [][][]byte{{[]byte("a"), []byte("1.5"), nil}},
+		},
+		row: -1,
+	}
+	var (
+		name  string
+		value float64
+		null  string
+		raw   []byte
+	)
+	convey.Convey("Columns are converted like database/sql does", t, func() {
+		convey.So(rows.Scan(&name, &value, &raw), convey.ShouldNotBeNil)
+		convey.So(rows.Next(), convey.ShouldBeTrue)
+		convey.So(rows.Scan(&name, &value, &raw), convey.ShouldBeNil)
+		convey.So(name, convey.ShouldEqual, "a")
+		convey.So(value, convey.ShouldEqual, 1.5)
+		convey.So(raw, convey.ShouldBeNil)
+		convey.So(rows.Scan(&name, &value, &null), convey.ShouldNotBeNil)
+		convey.So(rows.Next(), convey.ShouldBeFalse)
+	})
+}
@@ -63,7 +63,7 @@ func (ScrapeSlaveHosts) Version() float64 {
 
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
 func (ScrapeSlaveHosts) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
-	slaveHostsRows, err := db.QueryContext(ctx, slaveHostsQuery)
+	slaveHostsRows, err := db.QueryContext(ctx, compatStatement(ctx, slaveHostsQuery))
 	if err != nil {
 		return err
 	}
@@ -17,12 +17,18 @@ package collector
 
 import (
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"fmt"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/alecthomas/kingpin/v2"
 	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -31,10 +37,117 @@ const (
 	slaveStatus = "slave_status"
 )
 
+// Tunable flags.
+var (
+	slaveStatusErrorMessageLimit = kingpin.Flag(
+		"collect.slave_status.error_message_limit",
+		"Maximum length of the replication error messages of mysql_slave_status_last_error_info",
+	).Default("120").Int()
+)
+
+// slaveStatusThreadStates are the states of Slave_IO_Running and
+// Slave_SQL_Running.
+var slaveStatusThreadStates = []string{"Yes", "No", "Connecting", "Preparing"}
+
+// slaveStatusErrorTimestampLayout is the layout of Last_IO_Error_Timestamp
+// and Last_SQL_Error_Timestamp.
+const slaveStatusErrorTimestampLayout = "060102 15:04:05"
+
 var slaveStatusQueries = [2]string{"SHOW ALL SLAVES STATUS", "SHOW SLAVE STATUS"}
 var slaveStatusQuerySuffixes = [3]string{" NONBLOCKING", " NOLOCK", ""}
 
-var ()
+// Metric labels.
+var (
+	slaveStatusLabels     = []string{"master_host", "master_uuid", "channel_name", "connection_name"}
+	slaveStatusGTIDLabels = []string{"master_host", "master_uuid", "channel_name", "connection_name", "executed_server_id", "partition"}
+)
+
+// Metric descriptors.
+var (
+	slaveStatusSQLDelayDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, slaveStatus, "sql_delay_seconds"),
+		"Number of seconds the replica is configured to lag behind the source (SQL_Delay).",
+		slaveStatusLabels, nil,
+	)
+	slaveStatusSQLRemainingDelayDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, slaveStatus, "sql_remaining_delay_seconds"),
+		"Number of seconds left of SQL_Delay while the SQL thread waits for it, 0 when not waiting (SQL_Remaining_Delay).",
+		slaveStatusLabels, nil,
+	)
+	slaveStatusLagUnknownDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, slaveStatus, "lag_unknown"),
+		"Whether Seconds_Behind_Master is NULL, e.g. while the IO or SQL thread is stopped, and mysql_slave_status_seconds_behind_master is missing.",
+		slaveStatusLabels, nil,
+	)
+	slaveStatusThreadStateDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, slaveStatus, "thread_state"),
+		"The state of the replication IO or SQL thread, 1 for the current state among Yes, No, Connecting and Preparing (Slave_IO_Running, Slave_SQL_Running).",
+		[]string{"channel_name", "connection_name", "thread", "state"}, nil,
+	)
+	slaveStatusLastErrorNumberDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, slaveStatus, "last_error_number"),
+		"The number of the last error of the replication IO or SQL thread, 0 when there was none (Last_IO_Errno, Last_SQL_Errno).",
+		[]string{"channel_name", "connection_name", "thread"}, nil,
+	)
+	slaveStatusLastErrorInfoDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, slaveStatus, "last_error_info"),
+		"The last error of the replication IO or SQL thread, with its message truncated and a hash of the full message.",
+		[]string{"channel_name", "connection_name", "thread", "errno", "message", "message_hash"}, nil,
+	)
+	slaveStatusLastErrorTimestampDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, slaveStatus, "last_error_timestamp_seconds"),
+		"The time of the last error of the replication IO or SQL thread, read in the time zone of the exporter.",
+		[]string{"channel_name", "connection_name", "thread"}, nil,
+	)
+	slaveStatusSourceInfoDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, slaveStatus, "source_info"),
+		"The source a replication channel replicates from.",
+		[]string{"channel_name", "connection_name", "master_host", "master_port", "master_uuid"}, nil,
+	)
+	slaveStatusTLSInfoDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, slaveStatus, "tls_info"),
+		"The TLS settings of the connection of a replication channel to its source. ssl_ca_configured tells whether Master_SSL_CA_File or Master_SSL_CA_Path is set.",
+		[]string{"channel_name", "connection_name", "master_host", "ssl_allowed", "ssl_verify_server_cert", "ssl_cipher", "tls_version", "ssl_ca_configured"}, nil,
+	)
+	slaveStatusTLSEncryptedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, slaveStatus, "tls_encrypted"),
+		"Whether the connection of a replication channel to its source is encrypted (Master_SSL_Allowed is Yes).",
+		[]string{"channel_name", "connection_name", "master_host"}, nil,
+	)
+	slaveStatusSourceChangedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, slaveStatus, "source_changed_total"),
+		"Number of times the Master_Host or Master_UUID of a replication channel changed between scrapes.",
+		[]string{"channel_name", "connection_name"}, nil,
+	)
+)
+
+// slaveStatusSources tracks the source of every replication channel, by
+// target.
+var slaveStatusSources = replicationSources{}
+
+// replicationSources counts source changes of replication channels, such as
+// a failover repointing a replica.
+type replicationSources struct {
+	mu      sync.Mutex
+	sources map[string]string
+	changes map[string]float64
+}
+
+// observe records the source of a channel and returns the number of source
+// changes seen so far. The first source seen for a channel is not a change.
+func (r *replicationSources) observe(channel, source string) float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.sources == nil {
+		r.sources = map[string]string{}
+		r.changes = map[string]float64{}
+	}
+	if prev, ok := r.sources[channel]; ok && prev != source {
+		r.changes[channel]++
+	}
+	r.sources[channel] = source
+	return r.changes[channel]
+}
 
 func columnIndex(slaveCols []string, colName string) int {
 	for idx := range slaveCols {
@@ -79,11 +192,11 @@ func (ScrapeSlaveStatus) Scrape(ctx context.Context, db *sql.DB, ch chan<- prome
 	)
 	// Try the both syntax for MySQL/Percona and MariaDB
 	for _, query := range slaveStatusQueries {
-		slaveStatusRows, err = db.QueryContext(ctx, query)
+		slaveStatusRows, err = db.QueryContext(ctx, compatStatement(ctx, query))
 		if err != nil { // MySQL/Percona
 			// Leverage lock-free SHOW SLAVE STATUS by guessing the right suffix
 			for _, suffix := range slaveStatusQuerySuffixes {
-				slaveStatusRows, err = db.QueryContext(ctx, fmt.Sprint(query, suffix))
+				slaveStatusRows, err = db.QueryContext(ctx, compatStatement(ctx, fmt.Sprint(query, suffix)))
 				if err == nil {
 					break
 				}
@@ -101,6 +214,7 @@ func (ScrapeSlaveStatus) Scrape(ctx context.Context, db *sql.DB, ch chan<- prome
 	if err != nil {
 		return err
 	}
+	slaveCols = legacyReplicationColumns(slaveCols)
 
 	for slaveStatusRows.Next() {
 		// As the number of columns varies with mysqld versions,
@@ -120,56 +234,86 @@ func (ScrapeSlaveStatus) Scrape(ctx context.Context, db *sql.DB, ch chan<- prome
 		channelName := columnValue(scanArgs, slaveCols, "Channel_Name")       // MySQL & Percona
 		connectionName := columnValue(scanArgs, slaveCols, "Connection_name") // MariaDB
 
+		// A single odd column must not hide all other replication metrics.
+		skipColumn := func(col string, err error) {
+			level.Warn(logger).Log("msg", "Skipping unparsable column of SHOW SLAVE STATUS", "column", col,
+				"channel_name", channelName, "connection_name", connectionName, "err", err)
+			countScrapeError("collect."+slaveStatus, parseError{err})
+		}
+
 		for i, col := range slaveCols {
 			switch col {
 			case "Executed_Gtid_Set":
 				GTIDs, err := ParseGTID(string(*scanArgs[i].(*sql.RawBytes)))
 				if err != nil {
-					return err
+					skipColumn(col, err)
+					continue
 				}
+				startDesc := cachedDesc(
+					prometheus.BuildFQName(namespace, slaveStatus, strings.ToLower(col)+"_start"),
+					"Executed GTID from SHOW SLAVE STATUS.",
+					slaveStatusGTIDLabels,
+				)
+				endDesc := cachedDesc(
+					prometheus.BuildFQName(namespace, slaveStatus, strings.ToLower(col)+"_end"),
+					"Executed GTID from SHOW SLAVE STATUS.",
+					slaveStatusGTIDLabels,
+				)
 				for _, item := range GTIDs {
 					ch <- prometheus.MustNewConstMetric(
-						prometheus.NewDesc(
-							prometheus.BuildFQName(namespace, slaveStatus, strings.ToLower(col)+"_start"),
-							"Executed GTID from SHOW SLAVE STATUS.",
-							[]string{"master_host", "master_uuid", "channel_name", "connection_name", "executed_server_id", "partition"},
-							nil,
-						), prometheus.GaugeValue, float64(item.FirstTransaction),
+						startDesc, prometheus.GaugeValue, float64(item.FirstTransaction),
 						masterHost, masterUUID, channelName, connectionName, item.ServerId, "")
 					ch <- prometheus.MustNewConstMetric(
-						prometheus.NewDesc(
-							prometheus.BuildFQName(namespace, slaveStatus, strings.ToLower(col)+"_end"),
-							"Executed GTID from SHOW SLAVE STATUS.",
-							[]string{"master_host", "master_uuid", "channel_name", "connection_name", "executed_server_id", "partition"},
-							nil,
-						), prometheus.GaugeValue, float64(item.LastTransaction),
+						endDesc, prometheus.GaugeValue, float64(item.LastTransaction),
 						masterHost, masterUUID, channelName, connectionName, item.ServerId, "")
 				}
 			case "Master_Log_File", "Relay_Master_Log_File":
-				ss := strings.Split(string(*scanArgs[i].(*sql.RawBytes)), ".")
+				file := string(*scanArgs[i].(*sql.RawBytes))
+				if file == "" {
+					// Nothing received from the source yet.
+					continue
+				}
+				ss := strings.Split(file, ".")
 				if len(ss) < 2 {
-					return fmt.Errorf("split %s by `.` item not enough", string(*scanArgs[i].(*sql.RawBytes)))
+					skipColumn(col, fmt.Errorf("split %s by `.` item not enough", file))
+					continue
 				}
-				value, err := strconv.ParseFloat(ss[1], 64)
+				value, err := strconv.ParseFloat(ss[len(ss)-1], 64)
 				if err != nil {
-					return err
+					skipColumn(col, err)
+					continue
 				}
 				ch <- prometheus.MustNewConstMetric(
-					prometheus.NewDesc(
+					cachedDesc(
 						prometheus.BuildFQName(namespace, slaveStatus, strings.ToLower(col)+"_num"),
 						"Receive master log file num from SHOW SLAVE STATUS.",
-						[]string{"master_host", "master_uuid", "channel_name", "connection_name"},
-						nil,
+						slaveStatusLabels,
 					), prometheus.UntypedValue, value,
 					masterHost, masterUUID, channelName, connectionName)
+			case "SQL_Delay", "SQL_Remaining_Delay":
+				// SQL_Remaining_Delay is NULL unless the SQL thread is waiting
+				// for the delay to elapse, report that as no delay left.
+				desc := slaveStatusSQLDelayDesc
+				if col == "SQL_Remaining_Delay" {
+					desc = slaveStatusSQLRemainingDelayDesc
+				}
+				value, ok := parseStatus(*scanArgs[i].(*sql.RawBytes))
+				if !ok {
+					value = 0
+				}
+				ch <- prometheus.MustNewConstMetric(
+					desc, prometheus.GaugeValue, value,
+					masterHost, masterUUID, channelName, connectionName,
+				)
+				// Keep the generic metric, existing rules subtract it from Seconds_Behind_Master.
+				fallthrough
 			default:
 				if value, ok := parseStatus(*scanArgs[i].(*sql.RawBytes)); ok { // Silently skip unparsable values.
 					ch <- prometheus.MustNewConstMetric(
-						prometheus.NewDesc(
+						cachedDesc(
 							prometheus.BuildFQName(namespace, slaveStatus, strings.ToLower(col)),
 							"Generic metric from SHOW SLAVE STATUS.",
-							[]string{"master_host", "master_uuid", "channel_name", "connection_name"},
-							nil,
+							slaveStatusLabels,
 						),
 						prometheus.UntypedValue,
 						value,
@@ -178,9 +322,139 @@ func (ScrapeSlaveStatus) Scrape(ctx context.Context, db *sql.DB, ch chan<- prome
 				}
 			}
 		}
+
+		if i := columnIndex(slaveCols, "Seconds_Behind_Master"); i != -1 {
+			unknown := 0.0
+			if _, ok := parseStatus(*scanArgs[i].(*sql.RawBytes)); !ok {
+				unknown = 1
+			}
+			ch <- prometheus.MustNewConstMetric(
+				slaveStatusLagUnknownDesc, prometheus.GaugeValue, unknown,
+				masterHost, masterUUID, channelName, connectionName,
+			)
+		}
+
+		for _, thread := range []string{"IO", "SQL"} {
+			if columnIndex(slaveCols, "Slave_"+thread+"_Running") == -1 {
+				continue
+			}
+			scrapeSlaveStatusThreadState(ch, thread, channelName, connectionName,
+				columnValue(scanArgs, slaveCols, "Slave_"+thread+"_Running"))
+		}
+		for _, thread := range []string{"IO", "SQL"} {
+			if columnIndex(slaveCols, "Last_"+thread+"_Errno") == -1 {
+				continue
+			}
+			scrapeSlaveStatusLastError(ch, thread, channelName, connectionName,
+				columnValue(scanArgs, slaveCols, "Last_"+thread+"_Errno"),
+				columnValue(scanArgs, slaveCols, "Last_"+thread+"_Error"),
+				columnValue(scanArgs, slaveCols, "Last_"+thread+"_Error_Timestamp"),
+			)
+		}
+
+		masterPort := columnValue(scanArgs, slaveCols, "Master_Port")
+		ch <- prometheus.MustNewConstMetric(
+			slaveStatusSourceInfoDesc, prometheus.GaugeValue, 1,
+			channelName, connectionName, masterHost, masterPort, masterUUID,
+		)
+		if columnIndex(slaveCols, "Master_SSL_Allowed") != -1 {
+			sslAllowed := columnValue(scanArgs, slaveCols, "Master_SSL_Allowed")
+			caConfigured := "No"
+			if columnValue(scanArgs, slaveCols, "Master_SSL_CA_File") != "" || columnValue(scanArgs, slaveCols, "Master_SSL_CA_Path") != "" {
+				caConfigured = "Yes"
+			}
+			ch <- prometheus.MustNewConstMetric(
+				slaveStatusTLSInfoDesc, prometheus.GaugeValue, 1,
+				channelName, connectionName, masterHost, sslAllowed,
+				columnValue(scanArgs, slaveCols, "Master_SSL_Verify_Server_Cert"),
+				columnValue(scanArgs, slaveCols, "Master_SSL_Cipher"),
+				columnValue(scanArgs, slaveCols, "Master_TLS_Version"),
+				caConfigured,
+			)
+			encrypted := 0.0
+			if sslAllowed == "Yes" {
+				encrypted = 1
+			}
+			ch <- prometheus.MustNewConstMetric(
+				slaveStatusTLSEncryptedDesc, prometheus.GaugeValue, encrypted,
+				channelName, connectionName, masterHost,
+			)
+		}
+		changes := slaveStatusSources.observe(scrapeTarget(ctx)+"/"+channelName+"/"+connectionName, masterHost+"/"+masterUUID)
+		ch <- prometheus.MustNewConstMetric(
+			slaveStatusSourceChangedDesc, prometheus.CounterValue, changes,
+			channelName, connectionName,
+		)
 	}
 	return nil
 }
 
+// scrapeSlaveStatusThreadState sends the state set of the IO or SQL thread
+// of a replication channel. A state not in slaveStatusThreadStates gets a
+// series of its own.
+func scrapeSlaveStatusThreadState(ch chan<- prometheus.Metric, thread, channelName, connectionName, state string) {
+	thread = strings.ToLower(thread)
+	known := false
+	for _, s := range slaveStatusThreadStates {
+		value := 0.0
+		if strings.EqualFold(s, state) {
+			value, known = 1, true
+		}
+		ch <- prometheus.MustNewConstMetric(
+			slaveStatusThreadStateDesc, prometheus.GaugeValue, value,
+			channelName, connectionName, thread, s,
+		)
+	}
+	if !known && state != "" {
+		ch <- prometheus.MustNewConstMetric(
+			slaveStatusThreadStateDesc, prometheus.GaugeValue, 1,
+			channelName, connectionName, thread, state,
+		)
+	}
+}
+
+// scrapeSlaveStatusLastError sends the last error of the IO or SQL thread of
+// a replication channel. Only the current error has an info metric.
+func scrapeSlaveStatusLastError(ch chan<- prometheus.Metric, thread, channelName, connectionName, errno, message, timestamp string) {
+	thread = strings.ToLower(thread)
+	number, err := strconv.ParseFloat(errno, 64)
+	if err != nil {
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(
+		slaveStatusLastErrorNumberDesc, prometheus.GaugeValue, number,
+		channelName, connectionName, thread,
+	)
+	if number == 0 {
+		return
+	}
+	sum := sha256.Sum256([]byte(message))
+	ch <- prometheus.MustNewConstMetric(
+		slaveStatusLastErrorInfoDesc, prometheus.GaugeValue, 1,
+		channelName, connectionName, thread, errno,
+		truncateRunes(message, *slaveStatusErrorMessageLimit), hex.EncodeToString(sum[:6]),
+	)
+	if ts, err := time.ParseInLocation(slaveStatusErrorTimestampLayout, timestamp, time.Local); err == nil {
+		ch <- prometheus.MustNewConstMetric(
+			slaveStatusLastErrorTimestampDesc, prometheus.GaugeValue, float64(ts.Unix()),
+			channelName, connectionName, thread,
+		)
+	}
+}
+
+// truncateRunes returns the first n runes of s.
+func truncateRunes(s string, n int) string {
+	if n <= 0 {
+		return s
+	}
+	for i := range s {
+		if n == 0 {
+			return s[:i]
+		}
+		n--
+	}
+	return s
+}
+
 // check interface
 var _ Scraper = ScrapeSlaveStatus{}
@@ -15,9 +15,12 @@ package collector
 
 import (
 	"context"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alecthomas/kingpin/v2"
 	"github.com/go-kit/log"
 	"github.com/prometheus/client_golang/prometheus"
 	dto "github.com/prometheus/client_model/go"
@@ -25,6 +28,8 @@ import (
 )
 
 func TestScrapeSlaveStatus(t *testing.T) {
+	slaveStatusSources = replicationSources{}
+
 	db, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("error opening a stub database connection: %s", err)
@@ -52,6 +57,107 @@ func TestScrapeSlaveStatus(t *testing.T) {
 		{labels: labelMap{"channel_name": "", "connection_name": "", "master_host": "127.0.0.1", "master_uuid": ""}, value: 4, metricType: dto.MetricType_UNTYPED},
 		{labels: labelMap{"channel_name": "", "connection_name": "", "master_host": "127.0.0.1", "master_uuid": "", "executed_server_id": "215d19f8-7eca-11ed-9d98-00163e000147", "partition": ""}, value: 244965, metricType: dto.MetricType_GAUGE},
 		{labels: labelMap{"channel_name": "", "connection_name": "", "master_host": "127.0.0.1", "master_uuid": "", "executed_server_id": "215d19f8-7eca-11ed-9d98-00163e000147", "partition": ""}, value: 258014, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "", "connection_name": "", "master_host": "127.0.0.1", "master_uuid": ""}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "", "connection_name": "", "thread": "io", "state": "Yes"}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "", "connection_name": "", "thread": "io", "state": "No"}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "", "connection_name": "", "thread": "io", "state": "Connecting"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "", "connection_name": "", "thread": "io", "state": "Preparing"}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "", "connection_name": "", "thread": "sql", "state": "Yes"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "", "connection_name": "", "thread": "sql", "state": "No"}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "", "connection_name": "", "thread": "sql", "state": "Connecting"}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "", "connection_name": "", "thread": "sql", "state": "Preparing"}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "", "connection_name": "", "master_host": "127.0.0.1", "master_port": "", "master_uuid": ""}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "", "connection_name": ""}, value: 0, metricType: dto.MetricType_COUNTER},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range counterExpected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeSlaveStatusUnparsableColumns(t *testing.T) {
+	slaveStatusSources = replicationSources{}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"Master_Host", "Master_Log_File", "Executed_Gtid_Set", "Seconds_Behind_Master"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("127.0.0.1", "binlog", "0-1-2,garbage", "2")
+	mock.ExpectQuery(sanitizeQuery("SHOW SLAVE STATUS")).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeSlaveStatus{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	counterExpected := []MetricResult{
+		{labels: labelMap{"channel_name": "", "connection_name": "", "master_host": "127.0.0.1", "master_uuid": ""}, value: 2, metricType: dto.MetricType_UNTYPED},
+		{labels: labelMap{"channel_name": "", "connection_name": "", "master_host": "127.0.0.1", "master_uuid": ""}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "", "connection_name": "", "master_host": "127.0.0.1", "master_port": "", "master_uuid": ""}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "", "connection_name": ""}, value: 0, metricType: dto.MetricType_COUNTER},
+	}
+	convey.Convey("Unparsable columns are skipped", t, func() {
+		for _, expect := range counterExpected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeSlaveStatusSQLDelay(t *testing.T) {
+	slaveStatusSources = replicationSources{}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"Master_Host", "Channel_Name", "SQL_Delay", "SQL_Remaining_Delay"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("127.0.0.1", "delayed", "3600", "1200").
+		AddRow("127.0.0.2", "", "3600", nil)
+	mock.ExpectQuery(sanitizeQuery("SHOW SLAVE STATUS")).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeSlaveStatus{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	counterExpected := []MetricResult{
+		{labels: labelMap{"channel_name": "delayed", "connection_name": "", "master_host": "127.0.0.1", "master_uuid": ""}, value: 3600, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "delayed", "connection_name": "", "master_host": "127.0.0.1", "master_uuid": ""}, value: 3600, metricType: dto.MetricType_UNTYPED},
+		{labels: labelMap{"channel_name": "delayed", "connection_name": "", "master_host": "127.0.0.1", "master_uuid": ""}, value: 1200, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "delayed", "connection_name": "", "master_host": "127.0.0.1", "master_uuid": ""}, value: 1200, metricType: dto.MetricType_UNTYPED},
+		{labels: labelMap{"channel_name": "delayed", "connection_name": "", "master_host": "127.0.0.1", "master_port": "", "master_uuid": ""}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "delayed", "connection_name": ""}, value: 0, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"channel_name": "", "connection_name": "", "master_host": "127.0.0.2", "master_uuid": ""}, value: 3600, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "", "connection_name": "", "master_host": "127.0.0.2", "master_uuid": ""}, value: 3600, metricType: dto.MetricType_UNTYPED},
+		{labels: labelMap{"channel_name": "", "connection_name": "", "master_host": "127.0.0.2", "master_uuid": ""}, value: 0, metricType: dto.MetricType_GAUGE},
 	}
 	convey.Convey("Metrics comparison", t, func() {
 		for _, expect := range counterExpected {
@@ -65,3 +171,255 @@ func TestScrapeSlaveStatus(t *testing.T) {
 		t.Errorf("there were unfulfilled exceptions: %s", err)
 	}
 }
+
+func TestScrapeSlaveStatusSourceChanged(t *testing.T) {
+	slaveStatusSources = replicationSources{}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	// The replica of another target has a different source on the same
+	// channel.
+	scrapes := []struct {
+		target, host string
+		changes      float64
+	}{
+		{"db1", "10.0.0.1", 0},
+		{"db2", "10.0.0.9", 0},
+		{"db1", "10.0.0.1", 0},
+		{"db1", "10.0.0.2", 1},
+	}
+	columns := []string{"Master_Host", "Master_Port", "Master_UUID"}
+	for _, scrape := range scrapes {
+		mock.ExpectQuery(sanitizeQuery("SHOW SLAVE STATUS")).WillReturnRows(
+			sqlmock.NewRows(columns).AddRow(scrape.host, "3306", "uuid-"+scrape.host))
+	}
+
+	convey.Convey("Source changes are counted per target", t, func() {
+		for _, scrape := range scrapes {
+			ctx := context.WithValue(context.Background(), targetKey{}, scrape.target)
+			ch := make(chan prometheus.Metric)
+			go func() {
+				if err := (ScrapeSlaveStatus{}).Scrape(ctx, db, ch, log.NewNopLogger()); err != nil {
+					t.Errorf("error calling function on test: %s", err)
+				}
+				close(ch)
+			}()
+			var got MetricResult
+			for m := range ch {
+				got = readMetric(m)
+			}
+			convey.So(got, convey.ShouldResemble, MetricResult{labels: labelMap{"channel_name": "", "connection_name": ""}, value: scrape.changes, metricType: dto.MetricType_COUNTER})
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeSlaveStatusLagUnknown(t *testing.T) {
+	slaveStatusSources = replicationSources{}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"Master_Host", "Slave_IO_Running", "Seconds_Behind_Master"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("127.0.0.1", "No", nil)
+	mock.ExpectQuery(sanitizeQuery("SHOW SLAVE STATUS")).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeSlaveStatus{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	counterExpected := []MetricResult{
+		{labels: labelMap{"channel_name": "", "connection_name": "", "master_host": "127.0.0.1", "master_uuid": ""}, value: 0, metricType: dto.MetricType_UNTYPED},
+		{labels: labelMap{"channel_name": "", "connection_name": "", "master_host": "127.0.0.1", "master_uuid": ""}, value: 1, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("A NULL Seconds_Behind_Master is reported as unknown lag", t, func() {
+		for _, expect := range counterExpected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+		for range ch {
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeSlaveStatusLastError(t *testing.T) {
+	slaveStatusSources = replicationSources{}
+	_, err := kingpin.CommandLine.Parse([]string{"--collect.slave_status.error_message_limit=20"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	message := "Error connecting to source 'repl@10.0.0.1:3306'. This was attempt 3/86400, with a delay of 60 seconds between attempts."
+	columns := []string{"Master_Host", "Last_IO_Errno", "Last_IO_Error", "Last_IO_Error_Timestamp", "Last_SQL_Errno", "Last_SQL_Error", "Last_SQL_Error_Timestamp"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("10.0.0.1", "2003", message, "230510 12:34:56", "0", "", "")
+	mock.ExpectQuery(sanitizeQuery("SHOW SLAVE STATUS")).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeSlaveStatus{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	ts := time.Date(2023, 5, 10, 12, 34, 56, 0, time.Local)
+	expected := []MetricResult{
+		{labels: labelMap{"channel_name": "", "connection_name": "", "thread": "io"}, value: 2003, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "", "connection_name": "", "thread": "io", "errno": "2003", "message": "Error connecting to ", "message_hash": "72fef169a3e7"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "", "connection_name": "", "thread": "io"}, value: float64(ts.Unix()), metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "", "connection_name": "", "thread": "sql"}, value: 0, metricType: dto.MetricType_GAUGE},
+	}
+	var got []MetricResult
+	for m := range ch {
+		if desc := m.Desc().String(); strings.Contains(desc, "slave_status_last_error_") {
+			got = append(got, readMetric(m))
+		}
+	}
+	convey.Convey("Last errors of the replication threads", t, func() {
+		convey.So(got, convey.ShouldResemble, expected)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestTruncateRunes(t *testing.T) {
+	convey.Convey("Strings are truncated by rune", t, func() {
+		convey.So(truncateRunes("héllo", 2), convey.ShouldEqual, "hé")
+		convey.So(truncateRunes("héllo", 10), convey.ShouldEqual, "héllo")
+		convey.So(truncateRunes("héllo", 0), convey.ShouldEqual, "héllo")
+	})
+}
+
+func TestScrapeSlaveStatusThreadState(t *testing.T) {
+	slaveStatusSources = replicationSources{}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"Master_Host", "Channel_Name", "Slave_IO_Running", "Slave_SQL_Running"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("10.0.0.1", "a", "Preparing", "No").
+		AddRow("10.0.0.2", "b", "Reconnecting", "No")
+	mock.ExpectQuery(sanitizeQuery("SHOW SLAVE STATUS")).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeSlaveStatus{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{"channel_name": "a", "connection_name": "", "thread": "io", "state": "Yes"}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "a", "connection_name": "", "thread": "io", "state": "No"}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "a", "connection_name": "", "thread": "io", "state": "Connecting"}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "a", "connection_name": "", "thread": "io", "state": "Preparing"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "a", "connection_name": "", "thread": "sql", "state": "Yes"}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "a", "connection_name": "", "thread": "sql", "state": "No"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "a", "connection_name": "", "thread": "sql", "state": "Connecting"}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "a", "connection_name": "", "thread": "sql", "state": "Preparing"}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "b", "connection_name": "", "thread": "io", "state": "Yes"}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "b", "connection_name": "", "thread": "io", "state": "No"}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "b", "connection_name": "", "thread": "io", "state": "Connecting"}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "b", "connection_name": "", "thread": "io", "state": "Preparing"}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "b", "connection_name": "", "thread": "io", "state": "Reconnecting"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "b", "connection_name": "", "thread": "sql", "state": "Yes"}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "b", "connection_name": "", "thread": "sql", "state": "No"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "b", "connection_name": "", "thread": "sql", "state": "Connecting"}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "b", "connection_name": "", "thread": "sql", "state": "Preparing"}, value: 0, metricType: dto.MetricType_GAUGE},
+	}
+	var got []MetricResult
+	for m := range ch {
+		if desc := m.Desc().String(); strings.Contains(desc, "slave_status_thread_state") {
+			got = append(got, readMetric(m))
+		}
+	}
+	convey.Convey("States of the replication threads", t, func() {
+		convey.So(got, convey.ShouldResemble, expected)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeSlaveStatusTLS(t *testing.T) {
+	slaveStatusSources = replicationSources{}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"Master_Host", "Channel_Name", "Master_SSL_Allowed", "Master_SSL_CA_File", "Master_SSL_CA_Path", "Master_SSL_Cipher", "Master_SSL_Verify_Server_Cert", "Master_TLS_Version"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("10.0.0.1", "secure", "Yes", "/etc/mysql/ca.pem", "", "ECDHE-RSA-AES256-GCM-SHA384", "Yes", "TLSv1.3").
+		AddRow("10.0.0.2", "plain", "No", "", "", "", "No", "")
+	mock.ExpectQuery(sanitizeQuery("SHOW SLAVE STATUS")).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeSlaveStatus{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{"channel_name": "secure", "connection_name": "", "master_host": "10.0.0.1", "ssl_allowed": "Yes", "ssl_verify_server_cert": "Yes", "ssl_cipher": "ECDHE-RSA-AES256-GCM-SHA384", "tls_version": "TLSv1.3", "ssl_ca_configured": "Yes"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "secure", "connection_name": "", "master_host": "10.0.0.1"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "plain", "connection_name": "", "master_host": "10.0.0.2", "ssl_allowed": "No", "ssl_verify_server_cert": "No", "ssl_cipher": "", "tls_version": "", "ssl_ca_configured": "No"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "plain", "connection_name": "", "master_host": "10.0.0.2"}, value: 0, metricType: dto.MetricType_GAUGE},
+	}
+	var got []MetricResult
+	for m := range ch {
+		if desc := m.Desc().String(); strings.Contains(desc, "slave_status_tls_") {
+			got = append(got, readMetric(m))
+		}
+	}
+	convey.Convey("TLS settings of the replication channels", t, func() {
+		convey.So(got, convey.ShouldResemble, expected)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"regexp"
+	"strings"
+)
+
+// renamedStatementsVersion is the MySQL version which removed the
+// statements using the master/slave terminology.
+const renamedStatementsVersion = 8.4
+
+// renamedStatements maps removed statements to their replacement.
+var renamedStatements = []struct {
+	old, new string
+}{
+	{"SHOW MASTER STATUS", "SHOW BINARY LOG STATUS"},
+	{"SHOW MASTER LOGS", "SHOW BINARY LOGS"},
+	{"SHOW SLAVE HOSTS", "SHOW REPLICAS"},
+	{"SHOW SLAVE STATUS", "SHOW REPLICA STATUS"},
+	{"RESET MASTER", "RESET BINARY LOGS AND GTIDS"},
+	{"RESET SLAVE", "RESET REPLICA"},
+}
+
+// Words of the renamed columns of SHOW REPLICA STATUS, e.g. Source_Host or
+// Seconds_Behind_Source.
+var (
+	sourceColumnRE  = regexp.MustCompile(`(^|_)(Source|source)(_|$)`)
+	replicaColumnRE = regexp.MustCompile(`(^|_)(Replica|replica)(_|$)`)
+)
+
+type serverVersionKey struct{}
+
+// serverVersion is the version of the scraped server.
+type serverVersion struct {
+	version string
+	number  float64
+}
+
+// withServerVersion returns a context carrying the version of the scraped
+// server, for scrapers to pick the statements it supports.
+func withServerVersion(ctx context.Context, version string, number float64) context.Context {
+	return context.WithValue(ctx, serverVersionKey{}, serverVersion{version: version, number: number})
+}
+
+// compatStatement returns query with the statements removed from the
+// scraped server replaced. Without a known version query is returned as is.
+func compatStatement(ctx context.Context, query string) string {
+	v, ok := ctx.Value(serverVersionKey{}).(serverVersion)
+	// MariaDB keeps the old statements, getMySQLVersion returns 999 for
+	// unknown versions.
+	if !ok || v.number < renamedStatementsVersion || v.number == 999 || strings.Contains(strings.ToLower(v.version), "mariadb") {
+		return query
+	}
+	for _, r := range renamedStatements {
+		if strings.HasPrefix(query, r.old) {
+			return r.new + query[len(r.old):]
+		}
+	}
+	return query
+}
+
+// legacyReplicationColumns renames the columns of SHOW REPLICA STATUS to
+// their SHOW SLAVE STATUS names, so metric and label names don't change
+// with the statement used.
+func legacyReplicationColumns(columns []string) []string {
+	res := make([]string, len(columns))
+	for i, column := range columns {
+		column = sourceColumnRE.ReplaceAllStringFunc(column, func(s string) string {
+			return strings.NewReplacer("Source", "Master", "source", "master").Replace(s)
+		})
+		res[i] = replicaColumnRE.ReplaceAllStringFunc(column, func(s string) string {
+			return strings.NewReplacer("Replica", "Slave", "replica", "slave").Replace(s)
+		})
+	}
+	return res
+}
@@ -0,0 +1,97 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	MySQL "github.com/go-sql-driver/mysql"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestCompatStatement(t *testing.T) {
+	tests := []struct {
+		ctx      context.Context
+		query    string
+		expected string
+	}{
+		{context.Background(), "SHOW MASTER STATUS", "SHOW MASTER STATUS"},
+		{withServerVersion(context.Background(), "8.0.36", 8.0), "SHOW MASTER STATUS", "SHOW MASTER STATUS"},
+		{withServerVersion(context.Background(), "8.4.0", 8.4), "SHOW MASTER STATUS", "SHOW BINARY LOG STATUS"},
+		{withServerVersion(context.Background(), "9.1.0", 9.1), "SHOW SLAVE STATUS NONBLOCKING", "SHOW REPLICA STATUS NONBLOCKING"},
+		{withServerVersion(context.Background(), "9.1.0", 9.1), "SHOW SLAVE HOSTS", "SHOW REPLICAS"},
+		{withServerVersion(context.Background(), "9.1.0", 9.1), "SHOW GLOBAL STATUS", "SHOW GLOBAL STATUS"},
+		{withServerVersion(context.Background(), "11.4.2-MariaDB", 11.4), "SHOW SLAVE HOSTS", "SHOW SLAVE HOSTS"},
+	}
+
+	convey.Convey("Renamed statements", t, func() {
+		for _, tt := range tests {
+			convey.So(compatStatement(tt.ctx, tt.query), convey.ShouldEqual, tt.expected)
+		}
+	})
+
+	convey.Convey("Renamed columns", t, func() {
+		convey.So(legacyReplicationColumns([]string{
+			"Replica_IO_State", "Source_Host", "Source_Log_File", "Relay_Source_Log_File", "Replica_IO_Running",
+			"Replicate_Do_DB", "Seconds_Behind_Source", "Source_UUID", "Channel_Name",
+		}), convey.ShouldResemble, []string{
+			"Slave_IO_State", "Master_Host", "Master_Log_File", "Relay_Master_Log_File", "Slave_IO_Running",
+			"Replicate_Do_DB", "Seconds_Behind_Master", "Master_UUID", "Channel_Name",
+		})
+	})
+}
+
+func TestScrapeSlaveStatusReplicaStatement(t *testing.T) {
+	slaveStatusSources = replicationSources{}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"Source_Host", "Source_UUID", "Seconds_Behind_Source", "Channel_Name"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("127.0.0.1", "215d19f8-7eca-11ed-9d98-00163e000147", "3", "")
+	mock.ExpectQuery(sanitizeQuery("SHOW ALL SLAVES STATUS")).WillReturnError(&MySQL.MySQLError{Number: 1064})
+	mock.ExpectQuery(sanitizeQuery("SHOW REPLICA STATUS")).WillReturnRows(rows)
+
+	ctx := withServerVersion(context.Background(), "8.4.0", 8.4)
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeSlaveStatus{}).Scrape(ctx, db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	labels := labelMap{"channel_name": "", "connection_name": "", "master_host": "127.0.0.1", "master_uuid": "215d19f8-7eca-11ed-9d98-00163e000147"}
+	convey.Convey("Metrics keep their SHOW SLAVE STATUS names", t, func() {
+		m := <-ch
+		convey.So(m.Desc().String(), convey.ShouldContainSubstring, "mysql_slave_status_seconds_behind_master")
+		convey.So(readMetric(m), convey.ShouldResemble, MetricResult{labels: labels, value: 3, metricType: dto.MetricType_UNTYPED})
+		for range ch {
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// Stream collects the same metrics as Collect, but gathers them one scraper
+// at a time and hands each batch of metric families to fn, so the output of
+// all scrapers is never held in memory at once. The metrics of the exporter
+// come in the last batch. The scrape stops at the first error returned by fn.
+func (e *Exporter) Stream(fn func([]*dto.MetricFamily) error) error {
+	var exporterMetrics metricsCollector
+	up := 0.0
+
+	scrapeTime := time.Now()
+	if run, err := e.begin(e.ctx); err == nil {
+		defer run.close()
+		up = 1.0
+		exporterMetrics = append(exporterMetrics, run.metrics...)
+		for _, scraper := range run.scrapers {
+			if skipped, ok := run.skip(scraper); !ok {
+				if skipped != nil {
+					exporterMetrics = append(exporterMetrics, skipped)
+				}
+				continue
+			}
+			mfs, metrics, err := e.gatherScraper(run, scraper)
+			exporterMetrics = append(exporterMetrics, metrics...)
+			if err != nil {
+				level.Error(e.logger).Log("msg", "Error gathering scraper metrics", "scraper", scraper.Name(), "err", err)
+			}
+			if err := fn(mfs); err != nil {
+				return err
+			}
+		}
+	}
+	if tracingEnabled {
+		observeDuration(e.ctx, scrapeDurationHistogram, time.Since(scrapeTime).Seconds())
+	}
+	exporterMetrics = append(exporterMetrics, prometheus.MustNewConstMetric(mysqlUp, prometheus.GaugeValue, up))
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(exporterMetrics)
+	if e.pingQuery != "" {
+		registry.MustRegister(pingDuration(e.target))
+	}
+	mfs, err := registry.Gather()
+	if err != nil {
+		return err
+	}
+	return fn(mfs)
+}
+
+// gatherScraper runs a single scraper of run and gathers its metrics,
+// returns the gathered families and the collector success and duration
+// metrics of the exporter.
+func (e *Exporter) gatherScraper(run *scrapeRun, scraper Scraper) ([]*dto.MetricFamily, []prometheus.Metric, error) {
+	c := &scraperCollector{e: e, run: run, scraper: scraper}
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(c)
+	mfs, err := registry.Gather()
+	return mfs, c.metrics, err
+}
+
+// scraperCollector is an unchecked prometheus.Collector running one scraper.
+type scraperCollector struct {
+	e       *Exporter
+	run     *scrapeRun
+	scraper Scraper
+	metrics []prometheus.Metric
+}
+
+// Describe implements prometheus.Collector.
+func (c *scraperCollector) Describe(ch chan<- *prometheus.Desc) {}
+
+// Collect implements prometheus.Collector.
+func (c *scraperCollector) Collect(ch chan<- prometheus.Metric) {
+	c.metrics = c.e.runScraper(c.run, c.scraper, ch)
+}
+
+// metricsCollector is an unchecked prometheus.Collector of fixed metrics.
+type metricsCollector []prometheus.Metric
+
+// Describe implements prometheus.Collector.
+func (c metricsCollector) Describe(ch chan<- *prometheus.Desc) {}
+
+// Collect implements prometheus.Collector.
+func (c metricsCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, m := range c {
+		ch <- m
+	}
+}
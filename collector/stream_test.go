@@ -0,0 +1,103 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestGatherScraper(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"Variable_name", "Value"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("Com_select", "10").
+		AddRow("Com_insert", "20").
+		AddRow("Threads_running", "3")
+	mock.ExpectQuery(sanitizeQuery(globalStatusQuery)).WillReturnRows(rows)
+	mock.ExpectQuery(sanitizeQuery(globalStatusQuery)).WillReturnError(fmt.Errorf("access denied"))
+
+	e := NewWithDB(context.Background(), db, nil, log.NewNopLogger())
+	run := &scrapeRun{e: e, ctx: context.Background(), db: db}
+
+	convey.Convey("Successful scrape", t, func() {
+		mfs, metrics, err := e.gatherScraper(run, ScrapeGlobalStatus{})
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(readMetric(metrics[0]).value, convey.ShouldEqual, 1)
+		convey.So(mfs, convey.ShouldHaveLength, 2)
+		convey.So(mfs[0].GetName(), convey.ShouldEqual, "mysql_global_status_commands_total")
+		convey.So(mfs[0].GetMetric(), convey.ShouldHaveLength, 2)
+		convey.So(mfs[1].GetName(), convey.ShouldEqual, "mysql_global_status_threads_running")
+	})
+
+	convey.Convey("Failed scrape", t, func() {
+		mfs, metrics, _ := e.gatherScraper(run, ScrapeGlobalStatus{})
+		convey.So(readMetric(metrics[0]).value, convey.ShouldEqual, 0)
+		convey.So(mfs, convey.ShouldBeEmpty)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestStreamWithDB(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectPing()
+	mock.ExpectQuery(sanitizeQuery(versionQuery)).WillReturnRows(sqlmock.NewRows([]string{"@@version"}).AddRow("8.0.32"))
+	mock.ExpectQuery(sanitizeQuery(uptimeQuery)).WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).AddRow("Uptime", "42"))
+	mock.ExpectPing()
+
+	exporter := NewWithDB(context.Background(), db, []Scraper{ScrapeUptime{}}, log.NewNopLogger())
+
+	var names []string
+	err = exporter.Stream(func(mfs []*dto.MetricFamily) error {
+		for _, mf := range mfs {
+			names = append(names, mf.GetName())
+		}
+		return nil
+	})
+	convey.Convey("Streamed metrics", t, func() {
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(names, convey.ShouldContain, "mysql_uptime_seconds")
+		convey.So(names, convey.ShouldContain, "mysql_exporter_collector_success")
+		convey.So(names, convey.ShouldContain, "mysql_up")
+	})
+
+	convey.Convey("The database stays open", t, func() {
+		convey.So(db.Ping(), convey.ShouldBeNil)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape `sys.schema_unused_indexes` and `sys.schema_redundant_indexes`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// Indexes are unused when they had no I/O since mysqld started.
+	sysSchemaUnusedIndexesQuery = `
+	SELECT
+	    object_schema,
+	    object_name,
+	    index_name
+	  FROM ` + sysSchema + `.schema_unused_indexes
+	  ORDER BY object_schema, object_name, index_name
+	`
+	sysSchemaRedundantIndexesQuery = `
+	SELECT
+	    table_schema,
+	    table_name,
+	    redundant_index_name,
+	    dominant_index_name
+	  FROM ` + sysSchema + `.schema_redundant_indexes
+	  ORDER BY table_schema, table_name, redundant_index_name
+	`
+)
+
+// Tunable flags.
+var (
+	sysSchemaIndexesInfo = kingpin.Flag(
+		"collect.sys.schema_indexes.info",
+		"Expose an info metric for each unused and redundant index",
+	).Default("false").Bool()
+)
+
+// Metric descriptors.
+var (
+	sysSchemaUnusedIndexesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, sysSchema, "schema_unused_indexes"),
+		"The number of indexes of a schema without I/O since mysqld started, from sys.schema_unused_indexes.",
+		[]string{"schema"}, nil,
+	)
+	sysSchemaRedundantIndexesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, sysSchema, "schema_redundant_indexes"),
+		"The number of indexes of a schema duplicating or prefixing another index, from sys.schema_redundant_indexes.",
+		[]string{"schema"}, nil,
+	)
+	sysSchemaUnusedIndexInfoDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, sysSchema, "schema_unused_index_info"),
+		"An index without I/O since mysqld started.",
+		[]string{"schema", "table", "index"}, nil,
+	)
+	sysSchemaRedundantIndexInfoDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, sysSchema, "schema_redundant_index_info"),
+		"An index made redundant by the dominant index.",
+		[]string{"schema", "table", "index", "dominant_index"}, nil,
+	)
+)
+
+// ScrapeSysSchemaIndexes collects unused and redundant indexes from the sys schema.
+type ScrapeSysSchemaIndexes struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeSysSchemaIndexes) Name() string {
+	return sysSchema + ".schema_indexes"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeSysSchemaIndexes) Help() string {
+	return "Collect unused and redundant indexes per schema from sys.schema_unused_indexes and sys.schema_redundant_indexes"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeSysSchemaIndexes) Version() float64 {
+	return 5.7
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeSysSchemaIndexes) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	unusedRows, err := db.QueryContext(ctx, sysSchemaUnusedIndexesQuery)
+	if err != nil {
+		return err
+	}
+	defer unusedRows.Close()
+
+	var (
+		schema, table, index string
+		unused               schemaCounts
+	)
+	for unusedRows.Next() {
+		if err := unusedRows.Scan(&schema, &table, &index); err != nil {
+			return err
+		}
+		unused.add(schema)
+		if *sysSchemaIndexesInfo {
+			ch <- prometheus.MustNewConstMetric(sysSchemaUnusedIndexInfoDesc, prometheus.GaugeValue, 1, schema, table, index)
+		}
+	}
+	if err := unusedRows.Err(); err != nil {
+		return err
+	}
+	unused.collect(ch, sysSchemaUnusedIndexesDesc)
+
+	redundantRows, err := db.QueryContext(ctx, sysSchemaRedundantIndexesQuery)
+	if err != nil {
+		return err
+	}
+	defer redundantRows.Close()
+
+	var (
+		dominantIndex string
+		redundant     schemaCounts
+	)
+	for redundantRows.Next() {
+		if err := redundantRows.Scan(&schema, &table, &index, &dominantIndex); err != nil {
+			return err
+		}
+		redundant.add(schema)
+		if *sysSchemaIndexesInfo {
+			ch <- prometheus.MustNewConstMetric(sysSchemaRedundantIndexInfoDesc, prometheus.GaugeValue, 1, schema, table, index, dominantIndex)
+		}
+	}
+	if err := redundantRows.Err(); err != nil {
+		return err
+	}
+	redundant.collect(ch, sysSchemaRedundantIndexesDesc)
+	return nil
+}
+
+// schemaCounts counts rows by schema, keeping the order schemas were seen in.
+type schemaCounts struct {
+	schemas []string
+	counts  map[string]float64
+}
+
+func (c *schemaCounts) add(schema string) {
+	if c.counts == nil {
+		c.counts = map[string]float64{}
+	}
+	if _, ok := c.counts[schema]; !ok {
+		c.schemas = append(c.schemas, schema)
+	}
+	c.counts[schema]++
+}
+
+// collect sends the count of each schema as a gauge of desc.
+func (c *schemaCounts) collect(ch chan<- prometheus.Metric, desc *prometheus.Desc) {
+	for _, schema := range c.schemas {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, c.counts[schema], schema)
+	}
+}
+
+// check interface
+var _ Scraper = ScrapeSysSchemaIndexes{}
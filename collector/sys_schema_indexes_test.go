@@ -0,0 +1,79 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeSysSchemaIndexes(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.sys.schema_indexes.info",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(sysSchemaUnusedIndexesQuery)).WillReturnRows(
+		sqlmock.NewRows([]string{"object_schema", "object_name", "index_name"}).
+			AddRow("shop", "orders", "idx_created").
+			AddRow("shop", "users", "idx_email").
+			AddRow("wiki", "pages", "idx_title"))
+	mock.ExpectQuery(sanitizeQuery(sysSchemaRedundantIndexesQuery)).WillReturnRows(
+		sqlmock.NewRows([]string{"table_schema", "table_name", "redundant_index_name", "dominant_index_name"}).
+			AddRow("shop", "orders", "idx_user", "idx_user_created"))
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeSysSchemaIndexes{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{"schema": "shop", "table": "orders", "index": "idx_created"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"schema": "shop", "table": "users", "index": "idx_email"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"schema": "wiki", "table": "pages", "index": "idx_title"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"schema": "shop"}, value: 2, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"schema": "wiki"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"schema": "shop", "table": "orders", "index": "idx_user", "dominant_index": "idx_user_created"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"schema": "shop"}, value: 1, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -0,0 +1,400 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	MySQL "github.com/go-sql-driver/mysql"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// TracerName is the name of the tracer scrape spans are created with.
+	TracerName = "github.com/prometheus/mysqld_exporter"
+	// tracedDriverName is the driver scrape connections use when tracing is enabled.
+	tracedDriverName = "mysql-traced"
+)
+
+// Tunable flags.
+var (
+	tracingEndpoint = kingpin.Flag(
+		"tracing.otlp-endpoint",
+		"host:port of the OTLP/HTTP collector to send scrape traces to, tracing is disabled if empty.",
+	).Default("").String()
+	tracingInsecure = kingpin.Flag(
+		"tracing.otlp-insecure",
+		"Send traces to the OTLP/HTTP collector without TLS.",
+	).Default("false").Bool()
+	tracingSamplingRatio = kingpin.Flag(
+		"tracing.sampling-ratio",
+		"Ratio of scrapes to trace.",
+	).Default("1").Float64()
+)
+
+// tracingEnabled is set once SetupTracing installed a tracer provider.
+var tracingEnabled bool
+
+// Duration histograms carrying the trace of the observed scrape as
+// exemplar, only observed with tracing enabled. The collector_duration_seconds
+// gauge can't carry exemplars.
+var (
+	scrapeDurationHistogram = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    prometheus.BuildFQName(namespace, exporter, "scrape_seconds"),
+		Help:    "Duration of scrapes, with the trace of the scrape as exemplar.",
+		Buckets: []float64{.05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60},
+	})
+	collectorDurationHistogram = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    prometheus.BuildFQName(namespace, exporter, "collector_scrape_seconds"),
+		Help:    "Duration of collectors, with the span of the collector as exemplar.",
+		Buckets: []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+	}, []string{"collector"})
+)
+
+func init() {
+	sql.Register(tracedDriverName, tracedDriver{MySQL.MySQLDriver{}})
+	prometheus.MustRegister(scrapeDurationHistogram, collectorDurationHistogram)
+}
+
+// SetupTracing installs a tracer provider exporting to --tracing.otlp-endpoint
+// and returns a function flushing the remaining spans. Without an endpoint
+// tracing stays disabled and the returned function is a no-op.
+func SetupTracing(ctx context.Context, logger log.Logger) (func(context.Context) error, error) {
+	if *tracingEndpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+	opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(*tracingEndpoint)}
+	if *tracingInsecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(*tracingSamplingRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceNameKey.String("mysqld_exporter"))),
+	)
+	otel.SetTracerProvider(provider)
+	tracingEnabled = true
+	level.Info(logger).Log("msg", "Tracing enabled", "endpoint", *tracingEndpoint)
+	return provider.Shutdown, nil
+}
+
+// TracingEnabled reports whether scrapes are traced. Exemplars are only
+// exposed in the OpenMetrics format.
+func TracingEnabled() bool {
+	return tracingEnabled
+}
+
+// observeDuration observes seconds on o, with the span in ctx as exemplar
+// if it is sampled.
+func observeDuration(ctx context.Context, o prometheus.Observer, seconds float64) {
+	sc := trace.SpanContextFromContext(ctx)
+	if eo, ok := o.(prometheus.ExemplarObserver); ok && sc.IsSampled() {
+		eo.ObserveWithExemplar(seconds, prometheus.Labels{
+			"trace_id": sc.TraceID().String(),
+			"span_id":  sc.SpanID().String(),
+		})
+		return
+	}
+	o.Observe(seconds)
+}
+
+// driverName returns the driver scrape connections are opened with.
+func driverName() string {
+	switch {
+	case tracingEnabled && *readOnlyMode:
+		return tracedReadOnlyDriverName
+	case tracingEnabled:
+		return tracedDriverName
+	case *readOnlyMode:
+		return readOnlyDriverName
+	}
+	return "mysql"
+}
+
+// startSpan starts a span from the exporter tracer.
+func startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(TracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// endSpan records err on span and ends it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// tracedDriver wraps the MySQL driver so every query is a span which is a
+// child of the scraper span in the query context.
+type tracedDriver struct {
+	driver.Driver
+}
+
+// Open implements driver.Driver.
+func (d tracedDriver) Open(name string) (driver.Conn, error) {
+	conn, err := d.Driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &tracedConn{wrappedConn{conn}}, nil
+}
+
+// OpenConnector implements driver.DriverContext.
+func (d tracedDriver) OpenConnector(name string) (driver.Connector, error) {
+	connector, err := openConnector(d.Driver, name)
+	if err != nil {
+		return nil, err
+	}
+	return tracedConnector{Connector: connector, driver: d}, nil
+}
+
+// tracedConnector opens traced connections.
+type tracedConnector struct {
+	driver.Connector
+	driver tracedDriver
+}
+
+// Connect implements driver.Connector.
+func (c tracedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &tracedConn{wrappedConn{conn}}, nil
+}
+
+// Driver implements driver.Connector.
+func (c tracedConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// wrappedConn wraps a driver.Conn, forwarding the optional interfaces the
+// MySQL driver implements which are not about running queries.
+type wrappedConn struct {
+	driver.Conn
+}
+
+// tracedConn wraps a driver.Conn to trace its queries.
+type tracedConn struct {
+	wrappedConn
+}
+
+func querySpan(ctx context.Context, query string) (context.Context, trace.Span) {
+	return startSpan(ctx, "sql.query", semconv.DBSystemMySQL, semconv.DBStatementKey.String(query))
+}
+
+// QueryContext implements driver.QueryerContext.
+func (c *tracedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	ctx, span := querySpan(ctx, query)
+	rows, err := queryer.QueryContext(ctx, query, args)
+	if err == driver.ErrSkip {
+		// database/sql retries with a prepared statement, which gets its own
+		// span. Spans which are not ended are never exported.
+		return nil, err
+	}
+	if err != nil {
+		endSpan(span, err)
+		return nil, err
+	}
+	return &tracedRows{Rows: rows, span: span}, nil
+}
+
+// ExecContext implements driver.ExecerContext.
+func (c *tracedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	ctx, span := querySpan(ctx, query)
+	res, err := execer.ExecContext(ctx, query, args)
+	if err == driver.ErrSkip {
+		return nil, err
+	}
+	if err == nil {
+		if n, err := res.RowsAffected(); err == nil {
+			span.SetAttributes(attribute.Int64("db.rows_affected", n))
+		}
+	}
+	endSpan(span, err)
+	return res, err
+}
+
+// PrepareContext implements driver.ConnPrepareContext.
+func (c *tracedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var (
+		stmt driver.Stmt
+		err  error
+	)
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &tracedStmt{Stmt: stmt, query: query}, nil
+}
+
+// BeginTx implements driver.ConnBeginTx.
+func (c *wrappedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin() //nolint:staticcheck
+}
+
+// Ping implements driver.Pinger.
+func (c *wrappedConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+// ResetSession implements driver.SessionResetter.
+func (c *wrappedConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+// IsValid implements driver.Validator.
+func (c *wrappedConn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+// CheckNamedValue implements driver.NamedValueChecker.
+func (c *wrappedConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// tracedStmt wraps a prepared driver.Stmt.
+type tracedStmt struct {
+	driver.Stmt
+	query string
+}
+
+// QueryContext implements driver.StmtQueryContext.
+func (s *tracedStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := s.Stmt.(driver.StmtQueryContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	ctx, span := querySpan(ctx, s.query)
+	rows, err := queryer.QueryContext(ctx, args)
+	if err != nil {
+		endSpan(span, err)
+		return nil, err
+	}
+	return &tracedRows{Rows: rows, span: span}, nil
+}
+
+// ExecContext implements driver.StmtExecContext.
+func (s *tracedStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := s.Stmt.(driver.StmtExecContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	ctx, span := querySpan(ctx, s.query)
+	res, err := execer.ExecContext(ctx, args)
+	endSpan(span, err)
+	return res, err
+}
+
+// CheckNamedValue implements driver.NamedValueChecker.
+func (s *tracedStmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// tracedRows counts the rows read and ends the query span once closed.
+type tracedRows struct {
+	driver.Rows
+	span  trace.Span
+	count int64
+	err   error
+}
+
+// Next implements driver.Rows.
+func (r *tracedRows) Next(dest []driver.Value) error {
+	err := r.Rows.Next(dest)
+	switch err {
+	case nil:
+		r.count++
+	case io.EOF:
+	default:
+		r.err = err
+	}
+	return err
+}
+
+// HasNextResultSet implements driver.RowsNextResultSet, for the batched
+// statements of a scrape session.
+func (r *tracedRows) HasNextResultSet() bool {
+	next, ok := r.Rows.(driver.RowsNextResultSet)
+	return ok && next.HasNextResultSet()
+}
+
+// NextResultSet implements driver.RowsNextResultSet.
+func (r *tracedRows) NextResultSet() error {
+	next, ok := r.Rows.(driver.RowsNextResultSet)
+	if !ok {
+		return io.EOF
+	}
+	return next.NextResultSet()
+}
+
+// Close implements driver.Rows.
+func (r *tracedRows) Close() error {
+	err := r.Rows.Close()
+	if r.span != nil {
+		r.span.SetAttributes(attribute.Int64("db.rows", r.count))
+		endSpan(r.span, r.err)
+		r.span = nil
+	}
+	return err
+}
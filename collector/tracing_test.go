@@ -0,0 +1,105 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracedDriver(t *testing.T) {
+	mockDB, mock, err := sqlmock.NewWithDSN("traced_driver_test")
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer mockDB.Close()
+	sql.Register("traced_driver_test", tracedDriver{mockDB.Driver()})
+	db, err := sql.Open("traced_driver_test", "traced_driver_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	recorder := tracetest.NewSpanRecorder()
+	defaultProvider := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(defaultProvider)
+
+	mock.ExpectQuery(sanitizeQuery(globalStatusQuery)).WillReturnRows(
+		sqlmock.NewRows([]string{"Variable_name", "Value"}).AddRow("Uptime", "10").AddRow("Questions", "20"))
+
+	ctx, span := startSpan(context.Background(), "scraper global_status")
+	rows, err := db.QueryContext(ctx, globalStatusQuery)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for rows.Next() {
+	}
+	rows.Close()
+	endSpan(span, nil)
+
+	convey.Convey("Query spans", t, func() {
+		spans := recorder.Ended()
+		convey.So(spans, convey.ShouldHaveLength, 2)
+		query := spans[0]
+		convey.So(query.Name(), convey.ShouldEqual, "sql.query")
+		convey.So(query.Parent().SpanID(), convey.ShouldEqual, spans[1].SpanContext().SpanID())
+		convey.So(query.Attributes(), convey.ShouldContain, attribute.String("db.statement", globalStatusQuery))
+		convey.So(query.Attributes(), convey.ShouldContain, attribute.Int64("db.rows", 2))
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestObserveDuration(t *testing.T) {
+	defaultProvider := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider())
+	defer otel.SetTracerProvider(defaultProvider)
+
+	histogram := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "test_seconds", Buckets: []float64{1}})
+	ctx, span := startSpan(context.Background(), "scrape")
+	observeDuration(ctx, histogram, 0.5)
+	span.End()
+	observeDuration(context.Background(), histogram, 2)
+
+	convey.Convey("Exemplar of the sampled span", t, func() {
+		m := &dto.Metric{}
+		convey.So(histogram.Write(m), convey.ShouldBeNil)
+		convey.So(m.GetHistogram().GetSampleCount(), convey.ShouldEqual, 2)
+		exemplar := m.GetHistogram().GetBucket()[0].GetExemplar()
+		convey.So(exemplar.GetValue(), convey.ShouldEqual, 0.5)
+		convey.So(exemplar.GetLabel(), convey.ShouldHaveLength, 2)
+		for _, label := range exemplar.GetLabel() {
+			switch label.GetName() {
+			case "trace_id":
+				convey.So(label.GetValue(), convey.ShouldEqual, span.SpanContext().TraceID().String())
+			case "span_id":
+				convey.So(label.GetValue(), convey.ShouldEqual, span.SpanContext().SpanID().String())
+			}
+		}
+	})
+}
@@ -0,0 +1,90 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape `SHOW GLOBAL STATUS LIKE 'Uptime'`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const uptimeQuery = `SHOW GLOBAL STATUS LIKE 'Uptime'`
+
+// Metric descriptors.
+var (
+	uptimeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "uptime_seconds"),
+		"Number of seconds the server has been up.",
+		[]string{}, nil,
+	)
+	startTimeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "start_time_seconds"),
+		"Start time of the server since unix epoch in seconds, derived from Uptime.",
+		[]string{}, nil,
+	)
+)
+
+// ScrapeUptime collects from `SHOW GLOBAL STATUS LIKE 'Uptime'`.
+type ScrapeUptime struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeUptime) Name() string {
+	return "uptime"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeUptime) Help() string {
+	return "Collect the server uptime and start time"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeUptime) Version() float64 {
+	return 5.1
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeUptime) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	var (
+		name   string
+		uptime int64
+	)
+	rows, err := queryRows(ctx, db, uptimeQuery)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return sql.ErrNoRows
+	}
+	if err := rows.Scan(&name, &uptime); err != nil {
+		return err
+	}
+
+	ch <- prometheus.MustNewConstMetric(uptimeDesc, prometheus.GaugeValue, float64(uptime))
+	// Uptime only has a resolution of a second, so the start time may move
+	// by a second between scrapes.
+	ch <- prometheus.MustNewConstMetric(startTimeDesc, prometheus.GaugeValue, float64(time.Now().Unix()-uptime))
+	return nil
+}
+
+// check interface
+var _ Scraper = ScrapeUptime{}
@@ -0,0 +1,59 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeUptime(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(uptimeQuery)).WillReturnRows(
+		sqlmock.NewRows([]string{"Variable_name", "Value"}).AddRow("Uptime", "3600"))
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeUptime{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	start := float64(time.Now().Unix() - 3600)
+	convey.Convey("Metrics comparison", t, func() {
+		got := readMetric(<-ch)
+		convey.So(got, convey.ShouldResemble, MetricResult{labels: labelMap{}, value: 3600, metricType: dto.MetricType_GAUGE})
+		got = readMetric(<-ch)
+		convey.So(got.metricType, convey.ShouldEqual, dto.MetricType_GAUGE)
+		convey.So(got.value, convey.ShouldAlmostEqual, start, 2)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -0,0 +1,267 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// Metric naming modes.
+const (
+	namingFork     = "fork"
+	namingUpstream = "upstream"
+	namingBoth     = "both"
+	// namingPMM is selected by --compatibility.pmm.
+	namingPMM = "pmm"
+)
+
+var (
+	compatibilityNaming = kingpin.Flag(
+		"compatibility.naming",
+		"Metric naming: 'fork' for this exporter's names, 'upstream' for prometheus/mysqld_exporter names only, 'both' to emit upstream names next to fork names.",
+	).Default(namingFork).Enum(namingFork, namingUpstream, namingBoth)
+)
+
+// metricNaming returns the naming mode selected by the flags.
+func metricNaming() string {
+	if *pmmCompatibility {
+		return namingPMM
+	}
+	return *compatibilityNaming
+}
+
+// upstreamMetricNames maps metric names of this exporter to the name
+// prometheus/mysqld_exporter uses for the same value.
+var upstreamMetricNames = map[string]string{
+	"mysql_master_status_binlog_file_num": "mysql_binlog_file_number",
+}
+
+// upstreamMetrics lists the fixed metric names of prometheus/mysqld_exporter.
+var upstreamMetrics = map[string]bool{
+	"mysql_up": true,
+	"mysql_exporter_collector_duration_seconds":         true,
+	"mysql_exporter_collector_success":                  true,
+	"mysql_exporter_last_scrape_error":                  true,
+	"mysql_exporter_scrapes_total":                      true,
+	"mysql_exporter_scrape_errors_total":                true,
+	"mysql_version_info":                                true,
+	"mysql_transaction_isolation":                       true,
+	"mysql_galera_status_info":                          true,
+	"mysql_galera_variables_info":                       true,
+	"mysql_galera_gcache_size_bytes":                    true,
+	"mysql_binlog_file_number":                          true,
+	"mysql_binlog_files":                                true,
+	"mysql_binlog_size_bytes":                           true,
+	"mysql_heartbeat_now_timestamp_seconds":             true,
+	"mysql_heartbeat_stored_timestamp_seconds":          true,
+	"mysql_heartbeat_mysql_slave_hosts_info":            true,
+	"mysql_engine_innodb_queries_inside_innodb":         true,
+	"mysql_engine_innodb_queries_in_queue":              true,
+	"mysql_engine_innodb_read_views_open_inside_innodb": true,
+
+	"mysql_global_status_buffer_pool_dirty_pages":        true,
+	"mysql_global_status_buffer_pool_page_changes_total": true,
+	"mysql_global_status_buffer_pool_pages":              true,
+	"mysql_global_status_commands_total":                 true,
+	"mysql_global_status_connection_errors_total":        true,
+	"mysql_global_status_handlers_total":                 true,
+	"mysql_global_status_innodb_row_ops_total":           true,
+	"mysql_global_status_performance_schema_lost_total":  true,
+
+	"mysql_info_schema_auto_increment_column":                  true,
+	"mysql_info_schema_auto_increment_column_max":              true,
+	"mysql_info_schema_innodb_tablespace_allocated_size_bytes": true,
+	"mysql_info_schema_innodb_tablespace_file_size_bytes":      true,
+	"mysql_info_schema_innodb_tablespace_space_info":           true,
+	"mysql_info_schema_processlist_processes_by_host":          true,
+	"mysql_info_schema_processlist_processes_by_user":          true,
+	"mysql_info_schema_processlist_seconds":                    true,
+	"mysql_info_schema_processlist_threads":                    true,
+	"mysql_info_schema_query_response_time_seconds":            true,
+	"mysql_info_schema_read_query_response_time_seconds":       true,
+	"mysql_info_schema_write_query_response_time_seconds":      true,
+	"mysql_info_schema_table_rows":                             true,
+	"mysql_info_schema_table_size":                             true,
+	"mysql_info_schema_table_version":                          true,
+
+	"mysql_sys_connections_total":            true,
+	"mysql_sys_current_connections":          true,
+	"mysql_sys_current_memory_bytes":         true,
+	"mysql_sys_file_io_seconds_total":        true,
+	"mysql_sys_file_ios_total":               true,
+	"mysql_sys_memory_allocated_bytes_total": true,
+	"mysql_sys_statement_latency":            true,
+	"mysql_sys_statements_total":             true,
+	"mysql_sys_table_scans_total":            true,
+	"mysql_sys_unique_hosts_total":           true,
+
+	"mysql_perf_schema_replication_group_member_info":                               true,
+	"mysql_perf_schema_conflicts_detected_total":                                    true,
+	"mysql_perf_schema_transactions_checked_total":                                  true,
+	"mysql_perf_schema_transactions_in_queue":                                       true,
+	"mysql_perf_schema_transactions_local_proposed_total":                           true,
+	"mysql_perf_schema_transactions_local_rollback_total":                           true,
+	"mysql_perf_schema_transactions_remote_applied_total":                           true,
+	"mysql_perf_schema_transactions_remote_in_applier_queue":                        true,
+	"mysql_perf_schema_transactions_rows_validating_total":                          true,
+	"mysql_perf_schema_applying_transaction_immediate_commit_timestamp_seconds":     true,
+	"mysql_perf_schema_applying_transaction_original_commit_timestamp_seconds":      true,
+	"mysql_perf_schema_applying_transaction_start_apply_timestamp_seconds":          true,
+	"mysql_perf_schema_last_applied_transaction_end_apply_timestamp_seconds":        true,
+	"mysql_perf_schema_last_applied_transaction_immediate_commit_timestamp_seconds": true,
+	"mysql_perf_schema_last_applied_transaction_original_commit_timestamp_seconds":  true,
+	"mysql_perf_schema_last_applied_transaction_start_apply_timestamp_seconds":      true,
+}
+
+// upstreamMetricPrefixes start the metric names prometheus/mysqld_exporter
+// derives from server variables, statuses and columns, or of its whole
+// collectors. The names this exporter adds under such a prefix are excluded
+// in except.
+var upstreamMetricPrefixes = []struct {
+	prefix string
+	except []string
+}{
+	{prefix: "mysql_global_status_", except: []string{
+		"mysql_global_status_buffer_pool_dump_progress_percent",
+		"mysql_global_status_buffer_pool_dump_state",
+		"mysql_global_status_buffer_pool_load_progress_percent",
+		"mysql_global_status_buffer_pool_load_state",
+		"mysql_global_status_command_groups_total",
+		"mysql_global_status_rate_per_second",
+	}},
+	{prefix: "mysql_global_variables_"},
+	{prefix: "mysql_galera_evs_repl_latency_"},
+	{prefix: "mysql_slave_status_", except: []string{
+		"mysql_slave_status_executed_gtid_set_end",
+		"mysql_slave_status_executed_gtid_set_start",
+		"mysql_slave_status_lag_unknown",
+		"mysql_slave_status_last_error_info",
+		"mysql_slave_status_last_error_number",
+		"mysql_slave_status_last_error_timestamp_seconds",
+		"mysql_slave_status_master_log_file_num",
+		"mysql_slave_status_relay_master_log_file_num",
+		"mysql_slave_status_source_changed_total",
+		"mysql_slave_status_source_info",
+		"mysql_slave_status_sql_delay_seconds",
+		"mysql_slave_status_sql_remaining_delay_seconds",
+		"mysql_slave_status_thread_state",
+		"mysql_slave_status_tls_encrypted",
+		"mysql_slave_status_tls_info",
+	}},
+	{prefix: "mysql_mysql_", except: []string{
+		"mysql_mysql_accounts_failed_login_tracking",
+		"mysql_mysql_accounts_locked",
+		"mysql_mysql_innodb_index_stats_indexes",
+		"mysql_mysql_innodb_index_stats_max_staleness_seconds",
+		"mysql_mysql_innodb_table_stats_max_staleness_seconds",
+		"mysql_mysql_innodb_table_stats_tables",
+		"mysql_mysql_password_policy_setting",
+		"mysql_mysql_roles",
+		"mysql_mysql_users_password_expired",
+		"mysql_mysql_users_password_too_old",
+	}},
+	{prefix: "mysql_engine_tokudb_"},
+	{prefix: "mysql_info_schema_innodb_metrics_"},
+	{prefix: "mysql_info_schema_innodb_cmp_"},
+	{prefix: "mysql_info_schema_innodb_cmpmem_"},
+	{prefix: "mysql_info_schema_client_statistics_"},
+	{prefix: "mysql_info_schema_user_statistics_"},
+	{prefix: "mysql_info_schema_table_statistics_"},
+	{prefix: "mysql_info_schema_schema_statistics_"},
+	{prefix: "mysql_info_schema_replica_host_"},
+	{prefix: "mysql_perf_schema_events_statements_"},
+	{prefix: "mysql_perf_schema_events_waits_"},
+	{prefix: "mysql_perf_schema_file_events_"},
+	{prefix: "mysql_perf_schema_file_instances_"},
+	{prefix: "mysql_perf_schema_index_io_waits_"},
+	{prefix: "mysql_perf_schema_table_io_waits_"},
+	{prefix: "mysql_perf_schema_sql_lock_waits_"},
+	{prefix: "mysql_perf_schema_external_lock_waits_"},
+	{prefix: "mysql_perf_schema_memory_events_"},
+}
+
+// isUpstreamMetric returns whether prometheus/mysqld_exporter has the metric
+// name. Metrics outside the mysql namespace, like the go and process ones,
+// are common to both exporters.
+func isUpstreamMetric(name string) bool {
+	if !strings.HasPrefix(name, "mysql_") || upstreamMetrics[name] {
+		return true
+	}
+	for _, p := range upstreamMetricPrefixes {
+		if !strings.HasPrefix(name, p.prefix) {
+			continue
+		}
+		for _, except := range p.except {
+			if name == except {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// namingGatherer rewrites metric names of the wrapped gatherer for
+// compatibility with prometheus/mysqld_exporter or Percona PMM.
+type namingGatherer struct {
+	gatherer prometheus.Gatherer
+	mode     string
+}
+
+// Gather implements prometheus.Gatherer.
+func (g namingGatherer) Gather() ([]*dto.MetricFamily, error) {
+	mfs, err := g.gatherer.Gather()
+	return renameFamilies(mfs, g.mode), err
+}
+
+// renameFamilies applies the naming mode to gathered metric families.
+func renameFamilies(mfs []*dto.MetricFamily, mode string) []*dto.MetricFamily {
+	if mode == namingFork || mode == "" {
+		return mfs
+	}
+	if mode == namingPMM {
+		return pmmFamilies(mfs)
+	}
+
+	gathered := make(map[string]bool, len(mfs))
+	for _, mf := range mfs {
+		gathered[mf.GetName()] = true
+	}
+
+	res := make([]*dto.MetricFamily, 0, len(mfs))
+	for _, mf := range mfs {
+		upstreamName, renamed := upstreamMetricNames[mf.GetName()]
+		// Prefer the value upstream itself would report.
+		if renamed && !gathered[upstreamName] {
+			gathered[upstreamName] = true
+			res = append(res, &dto.MetricFamily{
+				Name:   &upstreamName,
+				Help:   mf.Help,
+				Type:   mf.Type,
+				Metric: mf.Metric,
+			})
+		}
+		if mode == namingUpstream && (renamed || !isUpstreamMetric(mf.GetName())) {
+			continue
+		}
+		res = append(res, mf)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].GetName() < res[j].GetName() })
+	return res
+}
@@ -0,0 +1,107 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestNamingGatherer(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	for _, name := range []string{"mysql_master_status_binlog_file_num", "mysql_master_status_binlog_pos", "mysql_up"} {
+		g := prometheus.NewGauge(prometheus.GaugeOpts{Name: name, Help: name})
+		g.Set(1)
+		registry.MustRegister(g)
+	}
+
+	names := func(mode string) []string {
+		mfs, err := namingGatherer{gatherer: registry, mode: mode}.Gather()
+		convey.So(err, convey.ShouldBeNil)
+		var res []string
+		for _, mf := range mfs {
+			res = append(res, mf.GetName())
+		}
+		return res
+	}
+
+	convey.Convey("Naming modes", t, func() {
+		convey.So(names(namingFork), convey.ShouldResemble, []string{
+			"mysql_master_status_binlog_file_num", "mysql_master_status_binlog_pos", "mysql_up",
+		})
+		convey.So(names(namingUpstream), convey.ShouldResemble, []string{
+			"mysql_binlog_file_number", "mysql_up",
+		})
+		convey.So(names(namingBoth), convey.ShouldResemble, []string{
+			"mysql_binlog_file_number", "mysql_master_status_binlog_file_num", "mysql_master_status_binlog_pos", "mysql_up",
+		})
+	})
+}
+
+func TestPMMNaming(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	binlog := prometheus.NewGauge(prometheus.GaugeOpts{Name: "mysql_master_status_binlog_file_num", Help: "binlog"})
+	binlog.Set(7)
+	threads := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "mysql_info_schema_processlist_threads", Help: "threads"}, []string{"command", "state"})
+	threads.WithLabelValues("query", "sending_data").Set(2)
+	threads.WithLabelValues("execute", "sending_data").Set(3)
+	threads.WithLabelValues("sleep", "unknown").Set(4)
+	up := prometheus.NewGauge(prometheus.GaugeOpts{Name: "mysql_up", Help: "up"})
+	registry.MustRegister(binlog, threads, up)
+
+	convey.Convey("PMM naming", t, func() {
+		mfs, err := namingGatherer{gatherer: registry, mode: namingPMM}.Gather()
+		convey.So(err, convey.ShouldBeNil)
+		got := map[string]map[string]float64{}
+		for _, mf := range mfs {
+			got[mf.GetName()] = map[string]float64{}
+			for _, m := range mf.Metric {
+				var labels []string
+				for _, lp := range m.Label {
+					labels = append(labels, lp.GetName()+"="+lp.GetValue())
+				}
+				got[mf.GetName()][strings.Join(labels, ",")] = m.GetGauge().GetValue()
+			}
+		}
+		convey.So(got, convey.ShouldResemble, map[string]map[string]float64{
+			"mysql_binlog_file_number":  {"": 7},
+			"mysql_info_schema_threads": {"state=sending_data": 5, "state=unknown": 4},
+			"mysql_up":                  {"": 0},
+		})
+	})
+}
+
+func TestIsUpstreamMetric(t *testing.T) {
+	for name, want := range map[string]bool{
+		"go_goroutines":                      true,
+		"mysql_up":                           true,
+		"mysql_global_status_bytes_received": true,
+		"mysql_global_status_buffer_pool_dump_state":          false,
+		"mysql_slave_status_seconds_behind_master":            true,
+		"mysql_slave_status_source_changed_total":             false,
+		"mysql_mysql_select_priv":                             true,
+		"mysql_mysql_roles":                                   false,
+		"mysql_canary_write_success":                          false,
+		"mysql_exporter_target_inflight_scrapes":              false,
+		"mysql_info_schema_innodb_metrics_lock_timeouts":      true,
+		"mysql_info_schema_processlist_processes_detail_time": false,
+	} {
+		if got := isUpstreamMetric(name); got != want {
+			t.Errorf("%s: want %v, got %v", name, want, got)
+		}
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
@@ -62,16 +63,41 @@ type Config struct {
 }
 
 type MySqlConfig struct {
-	User                  string `ini:"user"`
-	Password              string `ini:"password"`
-	Host                  string `ini:"host"`
-	Port                  int    `ini:"port"`
-	Socket                string `ini:"socket"`
-	SslCa                 string `ini:"ssl-ca"`
-	SslCert               string `ini:"ssl-cert"`
-	SslKey                string `ini:"ssl-key"`
-	TlsInsecureSkipVerify bool   `ini:"ssl-skip-verfication"`
-	Tls                   string `ini:"tls"`
+	User                  string        `ini:"user"`
+	Password              string        `ini:"password"`
+	PasswordFile          string        `ini:"password_file"`
+	PasswordCommand       string        `ini:"password_command"`
+	PasswordTTL           time.Duration `ini:"password_ttl"`
+	Host                  string        `ini:"host"`
+	Port                  int           `ini:"port"`
+	Socket                string        `ini:"socket"`
+	SslCa                 string        `ini:"ssl-ca"`
+	SslCert               string        `ini:"ssl-cert"`
+	SslKey                string        `ini:"ssl-key"`
+	TlsInsecureSkipVerify bool          `ini:"ssl-skip-verfication"`
+	Tls                   string        `ini:"tls"`
+	SSHHost               string        `ini:"ssh-host"`
+	SSHUser               string        `ini:"ssh-user"`
+	SSHKey                string        `ini:"ssh-key"`
+	SSHKnownHosts         string        `ini:"ssh-known-hosts"`
+	Socks5Proxy           string        `ini:"socks5-proxy"`
+	Socks5User            string        `ini:"socks5-user"`
+	Socks5Password        string        `ini:"socks5-password"`
+
+	// section is the name of the section, the auth module.
+	section string
+}
+
+// TunnelNet returns the DSN network of the SSH bastion or SOCKS5 proxy of
+// the auth module.
+func TunnelNet(authModule string) string {
+	return "tunnel-" + authModule
+}
+
+// HasTunnel returns whether the section connects through an SSH bastion or
+// a SOCKS5 proxy.
+func (m MySqlConfig) HasTunnel() bool {
+	return m.SSHHost != "" || m.Socks5Proxy != ""
 }
 
 type MySqlConfigHandler struct {
@@ -133,6 +159,7 @@ func (ch *MySqlConfigHandler) ReloadConfig(filename string, mysqldAddress string
 
 		mysqlcfg := &MySqlConfig{
 			TlsInsecureSkipVerify: tlsInsecureSkipVerify,
+			section:               sectionName,
 		}
 
 		// FIXME: this error check seems orphaned
@@ -167,17 +194,24 @@ func (m MySqlConfig) validateConfig() error {
 	if m.User == "" {
 		return fmt.Errorf("no user specified in section or parent")
 	}
-	if m.Password == "" {
+	if m.Password == "" && m.PasswordFile == "" && m.PasswordCommand == "" {
 		return fmt.Errorf("no password specified in section or parent")
 	}
+	if m.SSHHost != "" && m.Socks5Proxy != "" {
+		return fmt.Errorf("only one of ssh-host and socks5-proxy can be specified")
+	}
 
 	return nil
 }
 
 func (m MySqlConfig) FormDSN(target string) (string, error) {
+	password, err := m.password()
+	if err != nil {
+		return "", err
+	}
 	config := mysql.NewConfig()
 	config.User = m.User
-	config.Passwd = m.Password
+	config.Passwd = password
 	config.Net = "tcp"
 	if target == "" {
 		if m.Socket == "" {
@@ -203,6 +237,12 @@ func (m MySqlConfig) FormDSN(target string) (string, error) {
 		}
 		config.Addr = target
 	}
+	if m.HasTunnel() {
+		if config.Net == "unix" {
+			return "", fmt.Errorf("socket can't be used with ssh-host or socks5-proxy")
+		}
+		config.Net = TunnelNet(m.section)
+	}
 
 	if m.TlsInsecureSkipVerify {
 		config.TLSConfig = "skip-verify"
@@ -253,3 +253,28 @@ func TestFormDSNWithCustomTls(t *testing.T) {
 
 	})
 }
+
+func TestFormDSNWithTunnel(t *testing.T) {
+	c := MySqlConfigHandler{
+		Config: &Config{},
+	}
+
+	convey.Convey("Auth modules connect through their own tunnel", t, func() {
+		if err := c.ReloadConfig("testdata/client_tunnel.cnf", "localhost:3306", "", false, log.NewNopLogger()); err != nil {
+			t.Error(err)
+		}
+		cfg := c.GetConfig()
+		section := cfg.Sections["client.proxied"]
+		convey.So(section.HasTunnel(), convey.ShouldBeTrue)
+		dsn, err := section.FormDSN("server1:3306")
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(dsn, convey.ShouldEqual, "test:foo@tunnel-client.proxied(server1:3306)/")
+
+		_, err = section.FormDSN("unix:///tmp/mysql.sock")
+		convey.So(err, convey.ShouldNotBeNil)
+
+		dsn, err = cfg.Sections["client"].FormDSN("server1:3306")
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(dsn, convey.ShouldEqual, "root:abc@tcp(server1:3306)/")
+	})
+}
@@ -0,0 +1,116 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultPasswordTTL is how long a password read from password_file or
+	// password_command is cached without password_ttl.
+	defaultPasswordTTL = time.Minute
+	// passwordCommandTimeout bounds the run time of password_command.
+	passwordCommandTimeout = 30 * time.Second
+)
+
+// passwords caches the passwords read from files and commands.
+var passwords = secretCache{secrets: map[string]*cachedSecret{}}
+
+type cachedSecret struct {
+	// mu serializes the reads of the secret, so a slow password_command only
+	// holds up the lookups of its own secret.
+	mu      sync.Mutex
+	value   string
+	expires time.Time
+}
+
+type secretCache struct {
+	mu      sync.Mutex
+	secrets map[string]*cachedSecret
+}
+
+// get returns the secret cached under key, or reads it with read and caches
+// it for ttl. Failed reads are not cached.
+func (c *secretCache) get(key string, ttl time.Duration, now time.Time, read func() (string, error)) (string, error) {
+	c.mu.Lock()
+	s, ok := c.secrets[key]
+	if !ok {
+		s = &cachedSecret{}
+		c.secrets[key] = s
+	}
+	c.mu.Unlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.Before(s.expires) {
+		return s.value, nil
+	}
+	value, err := read()
+	if err != nil {
+		return "", err
+	}
+	s.value, s.expires = value, now.Add(ttl)
+	return value, nil
+}
+
+// password returns the password of the section: the output of
+// password_command, else the content of password_file, else password.
+func (m MySqlConfig) password() (string, error) {
+	ttl := m.PasswordTTL
+	if ttl <= 0 {
+		ttl = defaultPasswordTTL
+	}
+	switch {
+	case m.PasswordCommand != "":
+		return passwords.get("command:"+m.PasswordCommand, ttl, time.Now(), func() (string, error) {
+			return runPasswordCommand(m.PasswordCommand)
+		})
+	case m.PasswordFile != "":
+		return passwords.get("file:"+m.PasswordFile, ttl, time.Now(), func() (string, error) {
+			b, err := os.ReadFile(m.PasswordFile)
+			if err != nil {
+				return "", fmt.Errorf("failed to read password_file: %w", err)
+			}
+			return strings.TrimRight(string(b), "\r\n"), nil
+		})
+	}
+	return m.Password, nil
+}
+
+// runPasswordCommand runs command with /bin/sh and returns its output
+// without the trailing newline.
+func runPasswordCommand(command string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), passwordCommandTimeout)
+	defer cancel()
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "/bin/sh", "-c", command)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to run password_command: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	password := strings.TrimRight(string(out), "\r\n")
+	if password == "" {
+		return "", fmt.Errorf("password_command returned no password")
+	}
+	return password, nil
+}
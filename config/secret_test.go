@@ -0,0 +1,126 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestFormDSNWithPasswordSources(t *testing.T) {
+	dir := t.TempDir()
+	passwordFile := filepath.Join(dir, "password")
+	if err := os.WriteFile(passwordFile, []byte("fromfile\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cnf := filepath.Join(dir, "client.cnf")
+	content := "[client]\nuser = root\npassword = abc\nhost = server2\n" +
+		"[client.file]\nuser = test\npassword_file = " + passwordFile + "\n" +
+		"[client.command]\nuser = test\npassword_command = printf '%s\\n' fromcommand\npassword_ttl = 10s\n"
+	if err := os.WriteFile(cnf, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	convey.Convey("Passwords from files and commands", t, func() {
+		c := MySqlConfigHandler{
+			Config: &Config{},
+		}
+		if err := c.ReloadConfig(cnf, "localhost:3306", "", false, log.NewNopLogger()); err != nil {
+			t.Error(err)
+		}
+		cfg := c.GetConfig()
+
+		convey.Convey("Password file", func() {
+			dsn, err := cfg.Sections["client.file"].FormDSN("server1:3306")
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(dsn, convey.ShouldEqual, "test:fromfile@tcp(server1:3306)/")
+		})
+		convey.Convey("Password command", func() {
+			section := cfg.Sections["client.command"]
+			convey.So(section.PasswordTTL, convey.ShouldEqual, 10*time.Second)
+			dsn, err := section.FormDSN("server1:3306")
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(dsn, convey.ShouldEqual, "test:fromcommand@tcp(server1:3306)/")
+		})
+		convey.Convey("Failing password command", func() {
+			section := cfg.Sections["client.command"]
+			section.PasswordCommand = "exit 1"
+			_, err := section.FormDSN("server1:3306")
+			convey.So(err, convey.ShouldNotBeNil)
+		})
+	})
+}
+
+func TestSecretCache(t *testing.T) {
+	cache := secretCache{secrets: map[string]*cachedSecret{}}
+	reads := 0
+	read := func() (string, error) {
+		reads++
+		return "secret", nil
+	}
+	now := time.Unix(1000, 0)
+
+	for _, at := range []time.Duration{0, 30 * time.Second, 59 * time.Second} {
+		if v, err := cache.get("key", time.Minute, now.Add(at), read); v != "secret" || err != nil {
+			t.Fatalf("want secret, got %q, %v", v, err)
+		}
+	}
+	if reads != 1 {
+		t.Errorf("want 1 read within the TTL, got %d", reads)
+	}
+	if _, err := cache.get("key", time.Minute, now.Add(time.Minute), read); err != nil {
+		t.Fatal(err)
+	}
+	if reads != 2 {
+		t.Errorf("want a read once the TTL expired, got %d reads", reads)
+	}
+
+	if _, err := cache.get("failing", time.Minute, now, func() (string, error) { return "", errors.New("denied") }); err == nil {
+		t.Error("want the error of the read")
+	}
+	if v, _ := cache.get("failing", time.Minute, now, read); v != "secret" {
+		t.Errorf("want failed reads not to be cached, got %q", v)
+	}
+}
+
+func TestSecretCacheSlowRead(t *testing.T) {
+	cache := secretCache{secrets: map[string]*cachedSecret{}}
+	now := time.Unix(1000, 0)
+	release := make(chan struct{})
+	started := make(chan struct{})
+	go cache.get("slow", time.Minute, now, func() (string, error) {
+		close(started)
+		<-release
+		return "slow", nil
+	})
+	<-started
+	defer close(release)
+
+	done := make(chan struct{})
+	go func() {
+		cache.get("fast", time.Minute, now, func() (string, error) { return "fast", nil })
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("lookup of a secret blocked by the read of another one")
+	}
+}
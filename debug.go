@@ -0,0 +1,151 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/pprof"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/go-sql-driver/mysql"
+	"github.com/prometheus/mysqld_exporter/collector"
+)
+
+const debugProcesslistQuery = `
+	SELECT
+	    ID,
+	    IFNULL(STATE, ''),
+	    IFNULL(INFO, '')
+	  FROM information_schema.processlist
+	  WHERE ID IN (%s)
+	`
+
+var (
+	enableDebug = kingpin.Flag(
+		"web.enable-debug",
+		"Serve /debug/pprof, /debug/scrapes, which lists in-flight scrapes with their running scrapers and SQL, and /debug/diff, which compares the last two scrapes. Protected by the web config authentication.",
+	).Default("false").Bool()
+)
+
+// registerDebugHandlers registers the debug endpoints on the default mux
+// when --web.enable-debug is set. net/http/pprof is not imported for its
+// side effects so the profiles are only served when asked for.
+func registerDebugHandlers(logger log.Logger) {
+	if !*enableDebug {
+		return
+	}
+	http.HandleFunc("/debug/pprof/", pprof.Index)
+	http.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	http.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	http.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	http.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	http.HandleFunc("/debug/scrapes", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+		writeInFlightScrapes(ctx, w, openDebugDB, collector.InFlightScrapes(), time.Now(), logger)
+	})
+	http.HandleFunc("/debug/diff", handleDiff)
+	level.Info(logger).Log("msg", "Debug endpoints enabled", "paths", "/debug/pprof/,/debug/scrapes,/debug/diff")
+}
+
+// openDebugDB opens a connection to the server of dsn to look up the
+// statements of the scrape connections.
+func openDebugDB(dsn string) (*sql.DB, error) {
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1)
+	return db, nil
+}
+
+// debugQuery is the statement a scrape connection is executing.
+type debugQuery struct{ state, info string }
+
+// writeInFlightScrapes writes the running scrapes and the statement each
+// scrape connection is executing, looked up on the server the scrape is
+// connected to with a connection from open. Scrapes without a DSN, like the
+// ones of embedders, are listed without statement.
+func writeInFlightScrapes(ctx context.Context, w io.Writer, open func(dsn string) (*sql.DB, error), scrapes []collector.InFlightScrape, now time.Time, logger log.Logger) {
+	idsByDSN := map[string][]string{}
+	for _, s := range scrapes {
+		if s.DSN != "" && s.ConnectionID != 0 {
+			idsByDSN[s.DSN] = append(idsByDSN[s.DSN], fmt.Sprint(s.ConnectionID))
+		}
+	}
+	queries := map[string]map[uint64]debugQuery{}
+	for dsn, ids := range idsByDSN {
+		queries[dsn] = queryProcesslist(ctx, open, dsn, ids, logger)
+	}
+
+	fmt.Fprintf(w, "%d scrapes in flight\n", len(scrapes))
+	for _, s := range scrapes {
+		address := "unknown"
+		if cfg, err := mysql.ParseDSN(s.DSN); s.DSN != "" && err == nil {
+			address = cfg.Addr
+		}
+		fmt.Fprintf(w, "\nscrape address=%s connection_id=%d running=%s\n", address, s.ConnectionID, now.Sub(s.Start).Round(time.Millisecond))
+		names := make([]string, 0, len(s.Scrapers))
+		for name := range s.Scrapers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(w, "  scraper=%s running=%s\n", name, now.Sub(s.Scrapers[name]).Round(time.Millisecond))
+		}
+		if q, ok := queries[s.DSN][s.ConnectionID]; ok && q.info != "" {
+			fmt.Fprintf(w, "  state=%q sql=%q\n", q.state, q.info)
+		}
+	}
+}
+
+// queryProcesslist returns the statements the connections ids are executing
+// on the server of dsn.
+func queryProcesslist(ctx context.Context, open func(dsn string) (*sql.DB, error), dsn string, ids []string, logger log.Logger) map[uint64]debugQuery {
+	queries := map[uint64]debugQuery{}
+	db, err := open(dsn)
+	if err != nil {
+		level.Error(logger).Log("msg", "Error opening connection to database", "err", err)
+		return queries
+	}
+	defer db.Close()
+	rows, err := db.QueryContext(ctx, fmt.Sprintf(debugProcesslistQuery, strings.Join(ids, ",")))
+	if err != nil {
+		level.Error(logger).Log("msg", "Error querying processlist", "err", err)
+		return queries
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			id uint64
+			q  debugQuery
+		)
+		if err := rows.Scan(&id, &q.state, &q.info); err != nil {
+			level.Error(logger).Log("msg", "Error scanning processlist", "err", err)
+			break
+		}
+		queries[id] = q
+	}
+	return queries
+}
@@ -0,0 +1,95 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	"github.com/prometheus/mysqld_exporter/collector"
+)
+
+func TestWriteInFlightScrapes(t *testing.T) {
+	// Both servers have a connection 12, the one of each scrape is looked
+	// up on its own server.
+	query := regexp.QuoteMeta(strings.Join(strings.Fields(fmt.Sprintf(debugProcesslistQuery, "12")), " "))
+	dbs := map[string]*sql.DB{}
+	var mocks []sqlmock.Sqlmock
+	for dsn, info := range map[string]string{
+		"user:pass@tcp(db1:3306)/": "SELECT 1",
+		"user:pass@tcp(db2:3306)/": "SELECT 2",
+	} {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("error opening a stub database connection: %s", err)
+		}
+		defer db.Close()
+		mock.ExpectQuery(query).WillReturnRows(sqlmock.NewRows([]string{"ID", "STATE", "INFO"}).AddRow(12, "executing", info))
+		dbs[dsn] = db
+		mocks = append(mocks, mock)
+	}
+	open := func(dsn string) (*sql.DB, error) {
+		return dbs[dsn], nil
+	}
+
+	now := time.Unix(1000, 0)
+	scrapes := []collector.InFlightScrape{{
+		ConnectionID: 12,
+		DSN:          "user:pass@tcp(db1:3306)/",
+		Start:        now.Add(-9 * time.Second),
+		Scrapers: map[string]time.Time{
+			"perf_schema.eventsstatements": now.Add(-8 * time.Second),
+			"global_status":                now.Add(-2 * time.Second),
+		},
+	}, {
+		ConnectionID: 12,
+		DSN:          "user:pass@tcp(db2:3306)/",
+		Start:        now.Add(-3 * time.Second),
+		Scrapers:     map[string]time.Time{},
+	}, {
+		Start:    now.Add(-time.Second),
+		Scrapers: map[string]time.Time{},
+	}}
+
+	var b strings.Builder
+	writeInFlightScrapes(context.Background(), &b, open, scrapes, now, log.NewNopLogger())
+
+	want := `3 scrapes in flight
+
+scrape address=db1:3306 connection_id=12 running=9s
+  scraper=global_status running=2s
+  scraper=perf_schema.eventsstatements running=8s
+  state="executing" sql="SELECT 1"
+
+scrape address=db2:3306 connection_id=12 running=3s
+  state="executing" sql="SELECT 2"
+
+scrape address=unknown connection_id=0 running=1s
+`
+	if b.String() != want {
+		t.Errorf("want:\n%s\ngot:\n%s", want, b.String())
+	}
+	for _, mock := range mocks {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled exceptions: %s", err)
+		}
+	}
+}
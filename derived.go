@@ -0,0 +1,377 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/model"
+	"gopkg.in/ini.v1"
+)
+
+// derivedMetric is a metric computed from other gathered metrics.
+//
+// It is configured with an ini section per metric, for example:
+//
+//	[mysql_derived_buffer_pool_hit_ratio]
+//	help = InnoDB buffer pool hit ratio.
+//	expr = 1 - mysql_global_status_innodb_buffer_pool_reads / mysql_global_status_innodb_buffer_pool_read_requests
+type derivedMetric struct {
+	name string
+	help string
+	expr derivedExpr
+}
+
+// parseDerivedMetrics reads derived metric definitions from an ini file.
+func parseDerivedMetrics(config interface{}) ([]derivedMetric, error) {
+	cfg, err := ini.Load(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading ini file: %s", err)
+	}
+	var metrics []derivedMetric
+	for _, sec := range cfg.Sections() {
+		name := sec.Name()
+		if name == ini.DefaultSection {
+			continue
+		}
+		if !model.IsValidMetricName(model.LabelValue(name)) {
+			return nil, fmt.Errorf("invalid derived metric name %q", name)
+		}
+		exprStr := sec.Key("expr").String()
+		if exprStr == "" {
+			return nil, fmt.Errorf("no expr specified for derived metric %q", name)
+		}
+		expr, err := parseDerivedExpr(exprStr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse expr of derived metric %q: %s", name, err)
+		}
+		help := sec.Key("help").MustString("Derived metric: " + exprStr)
+		metrics = append(metrics, derivedMetric{name: name, help: help, expr: expr})
+	}
+	return metrics, nil
+}
+
+// derivedGatherer appends derived metrics to the result of the wrapped gatherer.
+type derivedGatherer struct {
+	gatherer prometheus.Gatherer
+	metrics  []derivedMetric
+	logger   log.Logger
+}
+
+// Gather implements prometheus.Gatherer.
+func (g derivedGatherer) Gather() ([]*dto.MetricFamily, error) {
+	mfs, err := g.gatherer.Gather()
+	if len(g.metrics) == 0 {
+		return mfs, err
+	}
+	byName := make(map[string]*dto.MetricFamily, len(mfs))
+	for _, mf := range mfs {
+		byName[mf.GetName()] = mf
+	}
+	for _, m := range g.metrics {
+		if _, ok := byName[m.name]; ok {
+			level.Warn(g.logger).Log("msg", "Derived metric name collides with a gathered metric", "metric", m.name)
+			continue
+		}
+		value, evalErr := m.expr.eval(byName)
+		if evalErr != nil {
+			level.Debug(g.logger).Log("msg", "Skipping derived metric", "metric", m.name, "err", evalErr)
+			continue
+		}
+		name, help := m.name, m.help
+		mfs = append(mfs, &dto.MetricFamily{
+			Name:   &name,
+			Help:   &help,
+			Type:   dto.MetricType_GAUGE.Enum(),
+			Metric: []*dto.Metric{{Gauge: &dto.Gauge{Value: &value}}},
+		})
+	}
+	sort.Slice(mfs, func(i, j int) bool { return mfs[i].GetName() < mfs[j].GetName() })
+	return mfs, err
+}
+
+// derivedExpr is a node of a parsed derived metric expression.
+type derivedExpr interface {
+	eval(families map[string]*dto.MetricFamily) (float64, error)
+}
+
+type derivedNumber float64
+
+func (n derivedNumber) eval(map[string]*dto.MetricFamily) (float64, error) {
+	return float64(n), nil
+}
+
+// derivedSelector selects exactly one sample by metric name and label matchers.
+type derivedSelector struct {
+	name   string
+	labels map[string]string
+}
+
+func (s derivedSelector) eval(families map[string]*dto.MetricFamily) (float64, error) {
+	mf, ok := families[s.name]
+	if !ok {
+		return 0, fmt.Errorf("metric %s not found", s.name)
+	}
+	var (
+		value float64
+		found int
+	)
+	for _, m := range mf.GetMetric() {
+		if !selectorMatches(s.labels, m.GetLabel()) {
+			continue
+		}
+		found++
+		switch {
+		case m.Gauge != nil:
+			value = m.GetGauge().GetValue()
+		case m.Counter != nil:
+			value = m.GetCounter().GetValue()
+		case m.Untyped != nil:
+			value = m.GetUntyped().GetValue()
+		default:
+			return 0, fmt.Errorf("metric %s has unsupported type %s", s.name, mf.GetType())
+		}
+	}
+	if found != 1 {
+		return 0, fmt.Errorf("selector for %s matched %d series, expected 1", s.name, found)
+	}
+	return value, nil
+}
+
+func selectorMatches(want map[string]string, labels []*dto.LabelPair) bool {
+	matched := 0
+	for _, l := range labels {
+		if v, ok := want[l.GetName()]; ok {
+			if v != l.GetValue() {
+				return false
+			}
+			matched++
+		}
+	}
+	return matched == len(want)
+}
+
+type derivedBinary struct {
+	op          byte
+	left, right derivedExpr
+}
+
+func (b derivedBinary) eval(families map[string]*dto.MetricFamily) (float64, error) {
+	l, err := b.left.eval(families)
+	if err != nil {
+		return 0, err
+	}
+	r, err := b.right.eval(families)
+	if err != nil {
+		return 0, err
+	}
+	var value float64
+	switch b.op {
+	case '+':
+		value = l + r
+	case '-':
+		value = l - r
+	case '*':
+		value = l * r
+	case '/':
+		if r == 0 {
+			return 0, fmt.Errorf("division by zero")
+		}
+		value = l / r
+	}
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, fmt.Errorf("result is not a finite number")
+	}
+	return value, nil
+}
+
+// parseDerivedExpr parses an arithmetic expression of numbers and metric
+// selectors, combined with + - * / and parentheses.
+func parseDerivedExpr(s string) (derivedExpr, error) {
+	p := &derivedParser{input: s}
+	expr, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos != len(p.input) {
+		return nil, fmt.Errorf("unexpected %q at position %d", p.input[p.pos:], p.pos)
+	}
+	return expr, nil
+}
+
+type derivedParser struct {
+	input string
+	pos   int
+}
+
+func (p *derivedParser) skipSpace() {
+	for p.pos < len(p.input) && (p.input[p.pos] == ' ' || p.input[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// skipDigits skips the digits and decimal points of a number.
+func (p *derivedParser) skipDigits() {
+	for p.pos < len(p.input) && strings.IndexByte("0123456789.", p.input[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+func (p *derivedParser) peek() byte {
+	p.skipSpace()
+	if p.pos >= len(p.input) {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+func (p *derivedParser) parseSum() (derivedExpr, error) {
+	left, err := p.parseProduct()
+	if err != nil {
+		return nil, err
+	}
+	for op := p.peek(); op == '+' || op == '-'; op = p.peek() {
+		p.pos++
+		right, err := p.parseProduct()
+		if err != nil {
+			return nil, err
+		}
+		left = derivedBinary{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *derivedParser) parseProduct() (derivedExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for op := p.peek(); op == '*' || op == '/'; op = p.peek() {
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = derivedBinary{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *derivedParser) parseUnary() (derivedExpr, error) {
+	if p.peek() == '-' {
+		p.pos++
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return derivedBinary{op: '-', left: derivedNumber(0), right: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *derivedParser) parsePrimary() (derivedExpr, error) {
+	c := p.peek()
+	switch {
+	case c == '(':
+		p.pos++
+		expr, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ')' {
+			return nil, fmt.Errorf("missing closing parenthesis at position %d", p.pos)
+		}
+		p.pos++
+		return expr, nil
+	case c >= '0' && c <= '9' || c == '.':
+		start := p.pos
+		p.skipDigits()
+		// An exponent may be signed, like 1e-5 or 2E+3.
+		if p.pos < len(p.input) && (p.input[p.pos] == 'e' || p.input[p.pos] == 'E') {
+			p.pos++
+			if p.pos < len(p.input) && (p.input[p.pos] == '+' || p.input[p.pos] == '-') {
+				p.pos++
+			}
+			p.skipDigits()
+		}
+		value, err := strconv.ParseFloat(p.input[start:p.pos], 64)
+		if err != nil {
+			return nil, err
+		}
+		return derivedNumber(value), nil
+	case isNameChar(c, true):
+		start := p.pos
+		for p.pos < len(p.input) && isNameChar(p.input[p.pos], false) {
+			p.pos++
+		}
+		sel := derivedSelector{name: p.input[start:p.pos], labels: map[string]string{}}
+		if p.pos < len(p.input) && p.input[p.pos] == '{' {
+			p.pos++
+			if err := p.parseLabels(sel.labels); err != nil {
+				return nil, err
+			}
+		}
+		return sel, nil
+	case c == 0:
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	return nil, fmt.Errorf("unexpected %q at position %d", c, p.pos)
+}
+
+// parseLabels parses `name="value", ...}` after an opening brace.
+func (p *derivedParser) parseLabels(labels map[string]string) error {
+	for {
+		if p.peek() == '}' {
+			p.pos++
+			return nil
+		}
+		start := p.pos
+		for p.pos < len(p.input) && isNameChar(p.input[p.pos], p.pos == start) && p.input[p.pos] != ':' {
+			p.pos++
+		}
+		name := p.input[start:p.pos]
+		if name == "" {
+			return fmt.Errorf("expected label name at position %d", p.pos)
+		}
+		if p.peek() != '=' {
+			return fmt.Errorf("expected '=' at position %d", p.pos)
+		}
+		p.pos++
+		if p.peek() != '"' {
+			return fmt.Errorf("expected quoted label value at position %d", p.pos)
+		}
+		end := strings.IndexByte(p.input[p.pos+1:], '"')
+		if end < 0 {
+			return fmt.Errorf("unterminated label value at position %d", p.pos)
+		}
+		labels[name] = p.input[p.pos+1 : p.pos+1+end]
+		p.pos += end + 2
+		if p.peek() == ',' {
+			p.pos++
+		}
+	}
+}
+
+func isNameChar(c byte, first bool) bool {
+	return c == '_' || c == ':' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (!first && c >= '0' && c <= '9')
+}
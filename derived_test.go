@@ -0,0 +1,118 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestParseDerivedMetrics(t *testing.T) {
+	convey.Convey("Valid definitions", t, func() {
+		metrics, err := parseDerivedMetrics([]byte(`
+[mysql_derived_buffer_pool_hit_ratio]
+help = InnoDB buffer pool hit ratio.
+expr = 1 - mysql_global_status_innodb_buffer_pool_reads / mysql_global_status_innodb_buffer_pool_read_requests
+
+[mysql_derived_select_ratio]
+expr = mysql_global_status_commands_total{command="select"} / (mysql_global_status_questions + 0)
+`))
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(metrics, convey.ShouldHaveLength, 2)
+		convey.So(metrics[0].name, convey.ShouldEqual, "mysql_derived_buffer_pool_hit_ratio")
+		convey.So(metrics[0].help, convey.ShouldEqual, "InnoDB buffer pool hit ratio.")
+		convey.So(metrics[1].help, convey.ShouldStartWith, "Derived metric: ")
+	})
+
+	convey.Convey("Numbers with signed exponents", t, func() {
+		for expr, want := range map[string]float64{
+			"1e-5":      1e-5,
+			"2E+3":      2e3,
+			"1.5e3 - 1": 1499,
+			"2e-1*10":   2,
+			"3E2":       300,
+			"-1e-2 + 1": 0.99,
+		} {
+			e, err := parseDerivedExpr(expr)
+			convey.So(err, convey.ShouldBeNil)
+			value, err := e.eval(nil)
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(value, convey.ShouldAlmostEqual, want)
+		}
+	})
+
+	convey.Convey("Invalid definitions", t, func() {
+		for _, cfg := range []string{
+			"[1invalid]\nexpr = 1",
+			"[mysql_derived_empty]\nhelp = no expr",
+			"[mysql_derived_unbalanced]\nexpr = (a + b",
+			"[mysql_derived_trailing]\nexpr = a b",
+			"[mysql_derived_labels]\nexpr = a{b=c}",
+			"[mysql_derived_exponent]\nexpr = 1e+",
+		} {
+			_, err := parseDerivedMetrics([]byte(cfg))
+			convey.So(err, convey.ShouldNotBeNil)
+		}
+	})
+}
+
+func TestDerivedGatherer(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	reads := prometheus.NewGauge(prometheus.GaugeOpts{Name: "reads"})
+	requests := prometheus.NewCounter(prometheus.CounterOpts{Name: "requests"})
+	commands := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "commands"}, []string{"command"})
+	registry.MustRegister(reads, requests, commands)
+	reads.Set(25)
+	requests.Add(100)
+	commands.WithLabelValues("select").Add(30)
+	commands.WithLabelValues("insert").Add(10)
+
+	metrics, err := parseDerivedMetrics([]byte(`
+[hit_ratio]
+expr = 1 - reads / requests
+[select_share]
+expr = commands{command="select"} / requests * 100
+[ambiguous]
+expr = commands
+[missing]
+expr = nonexistent
+[zero_division]
+expr = reads / (requests - 100)
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	convey.Convey("Derived metrics are appended", t, func() {
+		mfs, err := derivedGatherer{gatherer: registry, metrics: metrics, logger: log.NewNopLogger()}.Gather()
+		convey.So(err, convey.ShouldBeNil)
+
+		got := map[string]float64{}
+		for _, mf := range mfs {
+			for _, m := range mf.GetMetric() {
+				if m.Gauge != nil {
+					got[mf.GetName()] = m.GetGauge().GetValue()
+				}
+			}
+		}
+		convey.So(got, convey.ShouldResemble, map[string]float64{
+			"hit_ratio":    0.75,
+			"reads":        25,
+			"select_share": 30,
+		})
+	})
+}
@@ -0,0 +1,66 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus/mysqld_exporter/collector"
+)
+
+var (
+	addressFamily = kingpin.Flag(
+		"exporter.address_family",
+		"Address family used to connect to MySQL over TCP, one of any, ipv4 or ipv6.",
+	).Default("any").Enum("any", "ipv4", "ipv6")
+	dialFallbackDelay = kingpin.Flag(
+		"exporter.dial_fallback_delay",
+		"Delay before falling back to the other address family when a host resolves to both IPv4 and IPv6 addresses, negative to disable the fallback.",
+	).Default("300ms").Duration()
+)
+
+// tcpNetwork returns the network to dial for --exporter.address_family.
+func tcpNetwork() string {
+	switch *addressFamily {
+	case "ipv4":
+		return "tcp4"
+	case "ipv6":
+		return "tcp6"
+	}
+	return "tcp"
+}
+
+// newDialer returns a dialer racing the IPv4 and IPv6 addresses of dual-stack
+// hosts, preferring the first address returned by the resolver.
+func newDialer() *net.Dialer {
+	return &net.Dialer{FallbackDelay: *dialFallbackDelay}
+}
+
+// registerDialer replaces the dialer of the driver for tcp DSNs.
+func registerDialer() {
+	collector.RegisterDialContext("tcp", func(ctx context.Context, addr string) (net.Conn, error) {
+		return collector.DialTimed(ctx, newDialer(), tcpNetwork(), addr)
+	})
+}
+
+// joinHostPort returns host:port with IPv6 literals bracketed. host may
+// already be bracketed.
+func joinHostPort(host string, port uint) string {
+	host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	return net.JoinHostPort(host, strconv.FormatUint(uint64(port), 10))
+}
@@ -0,0 +1,49 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+func TestParseMycnfIPv6(t *testing.T) {
+	for _, host := range []string{"::1", "[::1]"} {
+		dsn, err := parseMycnf([]byte("[client]\nuser=root\npassword=abc\nport=3307\nhost=" + host + "\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "root:abc@tcp([::1]:3307)/"; dsn != want {
+			t.Errorf("host %s: want %q, got %q", host, want, dsn)
+		}
+	}
+}
+
+func TestTCPNetwork(t *testing.T) {
+	defaultFamily := *addressFamily
+	defer func() { *addressFamily = defaultFamily }()
+
+	for family, want := range map[string]string{"any": "tcp", "ipv4": "tcp4", "ipv6": "tcp6"} {
+		*addressFamily = family
+		if got := tcpNetwork(); got != want {
+			t.Errorf("family %s: want %s, got %s", family, want, got)
+		}
+	}
+
+	// An IPv4 address can't be dialed as IPv6.
+	*addressFamily = "ipv6"
+	if _, err := newDialer().DialContext(context.Background(), tcpNetwork(), "127.0.0.1:1"); err == nil {
+		t.Error("expected an error dialing an IPv4 address as IPv6")
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/mysqld_exporter/collector"
+)
+
+// registerHistoryHandler registers /debug/history on the default mux when
+// --exporter.history_collectors is set.
+func registerHistoryHandler(logger log.Logger) {
+	if len(collector.HistoryCollectors()) == 0 {
+		return
+	}
+	http.HandleFunc("/debug/history", handleHistory)
+	level.Info(logger).Log("msg", "Scrape history endpoint enabled", "path", "/debug/history")
+}
+
+// handleHistory lists the kept scrapes of the history collectors, or with
+// ?collector= writes their raw output, newest first. ?at= selects the last
+// scrape at or before a unix or RFC3339 time.
+func handleHistory(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("collector")
+	var at time.Time
+	if v := r.URL.Query().Get("at"); v != "" {
+		var err error
+		if at, err = parseHistoryTime(v); err != nil {
+			http.Error(w, fmt.Sprintf("invalid time %q", v), http.StatusBadRequest)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if name == "" {
+		for _, c := range collector.HistoryCollectors() {
+			outputs := collector.RawOutputHistory(c)
+			fmt.Fprintf(w, "%s: %d scrapes kept\n", c, len(outputs))
+			for i := len(outputs) - 1; i >= 0; i-- {
+				fmt.Fprintf(w, "  %s\n", outputs[i].Time.Format(time.RFC3339Nano))
+			}
+		}
+		return
+	}
+	writeHistory(w, name, collector.RawOutputHistory(name), at)
+}
+
+// writeHistory writes outputs newest first, or only the last one at or
+// before at if it is set.
+func writeHistory(w io.Writer, name string, outputs []collector.RawOutput, at time.Time) {
+	written := 0
+	for i := len(outputs) - 1; i >= 0; i-- {
+		if !at.IsZero() && outputs[i].Time.After(at) {
+			continue
+		}
+		fmt.Fprintf(w, "=== %s at %s\n%s", name, outputs[i].Time.Format(time.RFC3339Nano), outputs[i].Output)
+		if !strings.HasSuffix(outputs[i].Output, "\n") {
+			fmt.Fprintln(w)
+		}
+		written++
+		if !at.IsZero() {
+			break
+		}
+	}
+	if written == 0 {
+		fmt.Fprintf(w, "no scrapes of %s kept\n", name)
+	}
+}
+
+func parseHistoryTime(v string) (time.Time, error) {
+	if seconds, err := strconv.ParseFloat(v, 64); err == nil {
+		return time.Unix(0, int64(seconds*float64(time.Second))), nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
@@ -0,0 +1,58 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/prometheus/mysqld_exporter/collector"
+)
+
+func TestWriteHistory(t *testing.T) {
+	start := time.Date(2023, 5, 4, 3, 0, 0, 0, time.UTC)
+	outputs := []collector.RawOutput{
+		{Time: start, Output: "first\n"},
+		{Time: start.Add(time.Minute), Output: "second"},
+	}
+
+	var buf bytes.Buffer
+	writeHistory(&buf, "engine_innodb_status", outputs, time.Time{})
+	want := "=== engine_innodb_status at 2023-05-04T03:01:00Z\nsecond\n" +
+		"=== engine_innodb_status at 2023-05-04T03:00:00Z\nfirst\n"
+	if buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+
+	at, err := parseHistoryTime("2023-05-04T03:00:30Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	writeHistory(&buf, "engine_innodb_status", outputs, at)
+	if want := "=== engine_innodb_status at 2023-05-04T03:00:00Z\nfirst\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+
+	at, err = parseHistoryTime("1683169199")
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	writeHistory(&buf, "engine_innodb_status", outputs, at)
+	if want := "no scrapes of engine_innodb_status kept\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
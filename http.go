@@ -0,0 +1,68 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Metrics about the exporter's own HTTP handlers. The target label is only
+// set for /probe, which scrapes the target of the request.
+var (
+	httpRequestsInFlight = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "mysql_exporter_http_requests_in_flight",
+		Help: "The number of HTTP requests being served by handler and target.",
+	}, []string{"handler", "target"})
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "mysql_exporter_http_request_duration_seconds",
+		Help:    "Duration of HTTP requests by handler, target, method and status code.",
+		Buckets: []float64{.05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"handler", "target", "method", "code"})
+	httpResponseSize = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "mysql_exporter_http_response_size_bytes",
+		Help:    "Size of HTTP responses by handler and target.",
+		Buckets: prometheus.ExponentialBuckets(1024, 4, 8),
+	}, []string{"handler", "target"})
+)
+
+func init() {
+	prometheus.MustRegister(httpRequestsInFlight, httpRequestDuration, httpResponseSize)
+}
+
+// instrumentHandler wraps h with request duration, response size and
+// in-flight metrics labeled with handler.
+func instrumentHandler(handler string, h http.Handler) http.Handler {
+	return instrumentHandlerLabels(prometheus.Labels{"handler": handler, "target": ""}, h)
+}
+
+// instrumentProbeHandler is instrumentHandler for multi-target handlers,
+// labeling the metrics with the target parameter of the request too, see
+// probeTargetLabel.
+func instrumentProbeHandler(handler string, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		labels := prometheus.Labels{"handler": handler, "target": probeTargetLabel(r.URL.Query().Get("target"))}
+		instrumentHandlerLabels(labels, h).ServeHTTP(w, r)
+	})
+}
+
+func instrumentHandlerLabels(labels prometheus.Labels, h http.Handler) http.Handler {
+	return promhttp.InstrumentHandlerInFlight(httpRequestsInFlight.With(labels),
+		promhttp.InstrumentHandlerDuration(httpRequestDuration.MustCurryWith(labels),
+			promhttp.InstrumentHandlerResponseSize(httpResponseSize.MustCurryWith(labels), h),
+		),
+	)
+}
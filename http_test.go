@@ -0,0 +1,71 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/mysqld_exporter/config"
+)
+
+func TestInstrumentHandler(t *testing.T) {
+	var inFlight float64
+	h := instrumentHandler("/test", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		inFlight = testutil.ToFloat64(httpRequestsInFlight.WithLabelValues("/test", ""))
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/test", nil))
+
+	if inFlight != 1 {
+		t.Errorf("want 1 request in flight while serving, got %v", inFlight)
+	}
+	if got := testutil.ToFloat64(httpRequestsInFlight.WithLabelValues("/test", "")); got != 0 {
+		t.Errorf("want 0 requests in flight after serving, got %v", got)
+	}
+	if got := testutil.CollectAndCount(httpRequestDuration, "mysql_exporter_http_request_duration_seconds"); got != 1 {
+		t.Errorf("want 1 request duration series, got %d", got)
+	}
+	if got := testutil.CollectAndCount(httpResponseSize, "mysql_exporter_http_response_size_bytes"); got != 1 {
+		t.Errorf("want 1 response size series, got %d", got)
+	}
+}
+
+func TestInstrumentProbeHandler(t *testing.T) {
+	authModules.Config = &config.Config{Sections: map[string]config.MySqlConfig{
+		"client.db1": {User: "exporter", Password: "secret", Host: "db1"},
+	}}
+	defer func() { authModules.Config = &config.Config{} }()
+	h := instrumentProbeHandler("/probe-test", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/probe-test?target=db1:3306", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/probe-test?target=db2:3306", nil))
+
+	// db2 has no auth module.
+	for _, target := range []string{"db1:3306", probeTargetOther} {
+		labels := prometheus.Labels{"handler": "/probe-test", "target": target, "method": "get", "code": "200"}
+		m := &dto.Metric{}
+		if err := httpRequestDuration.With(labels).(prometheus.Histogram).Write(m); err != nil {
+			t.Fatal(err)
+		}
+		if got := m.GetHistogram().GetSampleCount(); got != 1 {
+			t.Errorf("want 1 request of %s observed, got %d", target, got)
+		}
+	}
+}
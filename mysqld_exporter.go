@@ -24,6 +24,7 @@ import (
 	"os"
 	"path"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/alecthomas/kingpin/v2"
@@ -32,12 +33,16 @@ import (
 	"github.com/go-sql-driver/mysql"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/prometheus/common/promlog"
 	"github.com/prometheus/common/promlog/flag"
 	"github.com/prometheus/common/version"
 	"github.com/prometheus/exporter-toolkit/web"
 	webflag "github.com/prometheus/exporter-toolkit/web/kingpinflag"
 	"github.com/prometheus/mysqld_exporter/collector"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var (
@@ -48,17 +53,23 @@ var (
 	).Default("/metrics").String()
 	timeoutOffset = kingpin.Flag(
 		"timeout-offset",
-		"Offset to subtract from timeout in seconds.",
+		"Offset to subtract from the Prometheus scrape timeout in seconds. Queries still running when the timeout minus this offset has passed are cancelled.",
 	).Default("0.25").Float64()
 	configMycnf = kingpin.Flag(
 		"config.my-cnf",
 		"Path to .my.cnf file to read MySQL credentials from.",
 	).Default(path.Join(os.Getenv("HOME"), ".my.cnf")).String()
+	configDerivedMetrics = kingpin.Flag(
+		"config.derived-metrics",
+		"Path to an ini file defining metrics derived from other scraped metrics.",
+	).Default("").String()
 	tlsInsecureSkipVerify = kingpin.Flag(
 		"tls.insecure-skip-verify",
 		"Ignore certificate and server verification when using a tls connection.",
 	).Bool()
-	dsn string
+	dsn            string
+	derivedMetrics []derivedMetric
+	tiers          = scrapeTiers{}
 )
 
 // scrapers lists all possible collection methods and if they should be enabled by default.
@@ -87,6 +98,7 @@ var scrapers = map[collector.Scraper]bool{
 	collector.ScrapePerfReplicationGroupMemberStats{}:     false,
 	collector.ScrapePerfReplicationApplierStatsByWorker{}: false,
 	collector.ScrapeSysUserSummary{}:                      false,
+	collector.ScrapeSysSchemaIndexes{}:                    false,
 	collector.ScrapeUserStat{}:                            false,
 	collector.ScrapeClientStat{}:                          false,
 	collector.ScrapeTableStat{}:                           false,
@@ -99,6 +111,45 @@ var scrapers = map[collector.Scraper]bool{
 	collector.ScrapeHeartbeat{}:                           false,
 	collector.ScrapeSlaveHosts{}:                          false,
 	collector.ScrapeReplicaHost{}:                         true,
+	collector.ScrapeThreadPool{}:                          false,
+	collector.ScrapePerfPreparedStatements{}:              false,
+	collector.ScrapeTableFragmentation{}:                  false,
+	collector.ScrapeSchemaInventory{}:                     false,
+	collector.ScrapeSchemaChanges{}:                       false,
+	collector.ScrapeBinlogStream{}:                        false,
+	collector.ScrapeConfigCompliance{}:                    false,
+	collector.ScrapeClock{}:                               false,
+	collector.ScrapeCanary{}:                              false,
+	collector.ScrapeTablesWithoutPK{}:                     false,
+	collector.ScrapeInnodbDeadlocks{}:                     false,
+	collector.ScrapePerfReplicationApplierWorkers{}:       false,
+	collector.ScrapePerfSetup{}:                           false,
+	collector.ScrapeUptime{}:                              false,
+	collector.ScrapePerfReplicationGroupFlowControl{}:     false,
+	collector.ScrapeClusterQuorum{}:                       false,
+	collector.ScrapeInnodbClusterMetadata{}:               false,
+	collector.ScrapeEncryption{}:                          false,
+	collector.ScrapePlugins{}:                             false,
+	collector.ScrapeCharsetMismatch{}:                     false,
+	collector.ScrapeQueryCache{}:                          false,
+	collector.ScrapeColumnstore{}:                         false,
+	collector.ScrapeSpider{}:                              false,
+	collector.ScrapePerfEventsStatementsBySchema{}:        false,
+	collector.ScrapePerfEventsStatementsCurrent{}:         false,
+	collector.ScrapePerfFullTableScans{}:                  false,
+	collector.ScrapePerfAccountAuthentication{}:           false,
+	collector.ScrapePerfEventsStagesCurrent{}:             false,
+	collector.ScrapeInnodbStats{}:                         false,
+	collector.ScrapeColumnStatistics{}:                    false,
+	collector.ScrapePasswordPolicy{}:                      false,
+	collector.ScrapeInnodbBufferPoolTables{}:              false,
+	collector.ScrapeIdentity{}:                            false,
+	collector.ScrapeMysqlx{}:                              false,
+	collector.ScrapeFirewallAudit{}:                       false,
+	collector.ScrapeResourceGroups{}:                      false,
+	collector.ScrapePerfStatementEfficiency{}:             false,
+	collector.ScrapePerfConnections{}:                     false,
+	collector.ScrapeGaleraFlowControl{}:                   false,
 }
 
 func filterScrapers(scrapers []collector.Scraper, collectParams []string) []collector.Scraper {
@@ -152,10 +203,20 @@ func parseMycnf(config interface{}) (string, error) {
 			return dsn, fmt.Errorf("password or ssl-key should be specified under [client] in %s", config)
 		}
 	}
-	if socket != "" {
+	dial, err := parseTunnel(sectionTunnelConfig(cfg.Section("client")))
+	if err != nil {
+		return dsn, fmt.Errorf("failed to configure tunnel under [client] in %s: %s", config, err)
+	}
+	switch {
+	case dial != nil && socket != "":
+		return dsn, fmt.Errorf("socket can't be used with ssh-host or socks5-proxy under [client] in %s", config)
+	case dial != nil:
+		registerTunnel(tunnelNet, dial)
+		dsn = fmt.Sprintf("%s%s@%s(%s)/", user, passwordPart, tunnelNet, joinHostPort(host, port))
+	case socket != "":
 		dsn = fmt.Sprintf("%s%s@unix(%s)/", user, passwordPart, socket)
-	} else {
-		dsn = fmt.Sprintf("%s%s@tcp(%s:%d)/", user, passwordPart, host, port)
+	default:
+		dsn = fmt.Sprintf("%s%s@tcp(%s)/", user, passwordPart, joinHostPort(host, port))
 	}
 	if sslCA != "" {
 		if tlsErr := customizeTLS(sslCA, sslCert, sslKey); tlsErr != nil {
@@ -199,47 +260,99 @@ func init() {
 	prometheus.MustRegister(version.NewCollector("mysqld_exporter"))
 }
 
-func newMysqlGatherers(cs ...prometheus.Collector) *prometheus.Gatherers {
+func newMysqlGatherers(logger log.Logger, cs ...prometheus.Collector) prometheus.Gatherer {
 	registry := prometheus.NewRegistry()
 	registry.MustRegister(cs...)
 
-	return &prometheus.Gatherers{
-		prometheus.DefaultGatherer,
-		registry,
+	return derivedGatherer{
+		gatherer: namingGatherer{
+			gatherer: prometheus.Gatherers{
+				prometheus.DefaultGatherer,
+				identityGatherer{gatherer: registry},
+			},
+			mode: metricNaming(),
+		},
+		metrics: derivedMetrics,
+		logger:  logger,
+	}
+}
+
+// identityGatherer adds the identity labels of the MySQL server to the
+// metrics of the wrapped gatherer.
+type identityGatherer struct {
+	gatherer prometheus.Gatherer
+}
+
+// Gather implements prometheus.Gatherer.
+func (g identityGatherer) Gather() ([]*dto.MetricFamily, error) {
+	mfs, err := g.gatherer.Gather()
+	return collector.AddIdentityLabels(mfs), err
+}
+
+// scrapeContext returns the context for a scrape request. It is cancelled
+// when the connection gets closed or, if Prometheus sent its scrape timeout,
+// once the timeout minus the offset has passed, so in-flight queries are
+// cancelled before Prometheus gives up on the scrape.
+func scrapeContext(r *http.Request, offset float64, logger log.Logger) (context.Context, context.CancelFunc) {
+	v := r.Header.Get("X-Prometheus-Scrape-Timeout-Seconds")
+	if v == "" {
+		return context.WithCancel(r.Context())
+	}
+	timeoutSeconds, err := strconv.ParseFloat(v, 64)
+	if err != nil || timeoutSeconds <= 0 {
+		level.Error(logger).Log("msg", "Failed to parse timeout from Prometheus header", "timeout", v, "err", err)
+		return context.WithCancel(r.Context())
 	}
+	if offset >= timeoutSeconds {
+		// Ignore timeout offset if it doesn't leave time to scrape.
+		level.Error(logger).Log("msg", "Timeout offset should be lower than prometheus scrape timeout", "offset", offset, "prometheus_scrape_timeout", timeoutSeconds)
+	} else {
+		// Subtract timeout offset from timeout.
+		timeoutSeconds -= offset
+	}
+	return context.WithTimeout(r.Context(), time.Duration(timeoutSeconds*float64(time.Second)))
 }
 
 func newHandler(scrapers []collector.Scraper, logger log.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		collect := r.URL.Query()["collect[]"]
-		// Use request context for cancellation when connection gets closed.
-		ctx := r.Context()
-		// If a timeout is configured via the Prometheus header, add it to the context.
-		if v := r.Header.Get("X-Prometheus-Scrape-Timeout-Seconds"); v != "" {
-			timeoutSeconds, err := strconv.ParseFloat(v, 64)
-			if err != nil {
-				level.Error(logger).Log("msg", "Failed to parse timeout from Prometheus header", "err", err)
-			} else {
-				if *timeoutOffset >= timeoutSeconds {
-					// Ignore timeout offset if it doesn't leave time to scrape.
-					level.Error(logger).Log("msg", "Timeout offset should be lower than prometheus scrape timeout", "offset", *timeoutOffset, "prometheus_scrape_timeout", timeoutSeconds)
-				} else {
-					// Subtract timeout offset from timeout.
-					timeoutSeconds -= *timeoutOffset
-				}
-				// Create new timeout context with request context as parent.
-				var cancel context.CancelFunc
-				ctx, cancel = context.WithTimeout(ctx, time.Duration(timeoutSeconds*float64(time.Second)))
-				defer cancel()
-				// Overwrite request with timeout context.
-				r = r.WithContext(ctx)
+		ctx, cancel := scrapeContext(r, *timeoutOffset, logger)
+		defer cancel()
+		ctx, span := otel.Tracer(collector.TracerName).Start(ctx, "scrape",
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(attribute.String("http.target", r.URL.RequestURI())),
+		)
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		if tier := r.URL.Query().Get("tier"); tier != "" {
+			var ok bool
+			if scrapers, ok = tiers.filter(scrapers, tier); !ok {
+				http.Error(w, fmt.Sprintf("unknown tier %q", tier), http.StatusBadRequest)
+				return
 			}
 		}
-
 		filteredScrapers := filterScrapers(scrapers, collect)
 
+		if *streamMetrics {
+			serveStream(w, r, collector.New(ctx, dsn, filteredScrapers, logger), logger)
+			return
+		}
+
+		var gatherer prometheus.Gatherer = snapshotGatherer{
+			gatherer: newMysqlGatherers(logger, collector.New(ctx, dsn, filteredScrapers, logger)),
+			scope:    snapshotScope(r.URL.Path, r.URL.Query()),
+		}
+		if *openMetrics && openMetricsRequested(r) {
+			serveOpenMetrics(w, r, gatherer, logger)
+			return
+		}
+
 		// Delegate http serving to Prometheus client library, which will call collector.Collect.
-		h := promhttp.HandlerFor(newMysqlGatherers(collector.New(ctx, dsn, filteredScrapers, logger)), promhttp.HandlerOpts{})
+		h := promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{
+			// Exemplars linking durations to traces are only part of OpenMetrics.
+			EnableOpenMetrics: collector.TracingEnabled(),
+		})
 		h.ServeHTTP(w, r)
 	}
 }
@@ -259,12 +372,25 @@ func httpServer(enabledScrapers *[]collector.Scraper, logger log.Logger) {
 	// Register only scrapers enabled by flag.
 	collector.New(context.Background(), dsn, *enabledScrapers, logger)
 	handlerFunc := newHandler(*enabledScrapers, logger)
-	http.Handle(*metricsPath, promhttp.InstrumentMetricHandler(prometheus.DefaultRegisterer, handlerFunc))
-	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+	http.Handle(*metricsPath, instrumentHandler(*metricsPath, promhttp.InstrumentMetricHandler(prometheus.DefaultRegisterer, handlerFunc)))
+	if *pmmCompatibility {
+		for _, resolution := range pmmResolutions {
+			path := *metricsPath + "-" + resolution
+			tierScrapers, _ := tiers.filter(*enabledScrapers, resolution)
+			http.Handle(path, instrumentHandler(path, promhttp.InstrumentMetricHandler(prometheus.DefaultRegisterer, newHandler(tierScrapers, logger))))
+			level.Info(logger).Log("msg", "PMM compatible endpoint enabled", "path", path)
+		}
+	}
+	registerDebugHandlers(logger)
+	registerHistoryHandler(logger)
+	registerProbeHandler(*configMycnf, *enabledScrapers, logger)
+	http.Handle("/queries", instrumentHandler("/queries", handleQueries(*enabledScrapers, logger)))
+	http.Handle("/", instrumentHandler("/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Write(landingPage)
-	})
+	})))
 
 	level.Info(logger).Log("msg", "Listening on address", "address", *toolkitFlags.WebListenAddresses)
+	startSystemd(logger)
 	srv := &http.Server{}
 	if err := web.ListenAndServe(srv, toolkitFlags, logger); err != nil {
 		level.Error(logger).Log("msg", "Error starting HTTP server", "err", err)
@@ -273,8 +399,9 @@ func httpServer(enabledScrapers *[]collector.Scraper, logger log.Logger) {
 }
 
 func main() {
-	// Generate ON/OFF flags for all scrapers.
+	// Generate ON/OFF and minimum interval flags for all scrapers.
 	scraperFlags := map[collector.Scraper]*bool{}
+	minIntervalFlags := map[string]*time.Duration{}
 	for scraper, enabledByDefault := range scrapers {
 		defaultOn := "false"
 		if enabledByDefault {
@@ -287,6 +414,11 @@ func main() {
 		).Default(defaultOn).Bool()
 
 		scraperFlags[scraper] = f
+
+		minIntervalFlags[scraper.Name()] = kingpin.Flag(
+			"collect."+scraper.Name()+".min_interval",
+			"Minimum interval between scrapes of "+scraper.Name()+", scrapes in between return the metrics of the last one.",
+		).Default("0s").Duration()
 	}
 
 	// Parse flags.
@@ -294,21 +426,102 @@ func main() {
 	flag.AddFlags(kingpin.CommandLine, promlogConfig)
 	kingpin.Version(version.Print("mysqld_exporter"))
 	kingpin.HelpFlag.Short('h')
-	kingpin.Parse()
+	command := kingpin.Parse()
 
 	logger := promlog.New(promlogConfig)
 
+	if command == generateRulesCmd.FullCommand() {
+		enabledScrapers := []collector.Scraper{}
+		for scraper, enabled := range scraperFlags {
+			if *enabled {
+				enabledScrapers = append(enabledScrapers, scraper)
+			}
+		}
+		if err := generateRules(*generateRulesOutputDir, *generateRulesSelector, enabledScrapers); err != nil {
+			level.Error(logger).Log("msg", "Error generating rules", "err", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	level.Info(logger).Log("msg", "Starting mysqld_exporter", "version", version.Info())
 	level.Info(logger).Log("msg", "Build context", "build_context", version.BuildContext())
 
+	registerDialer()
+
 	dsn = os.Getenv("DATA_SOURCE_NAME")
+	if isVault([]byte(dsn)) {
+		plaintext, err := decryptVaultWithPassword([]byte(dsn))
+		if err != nil {
+			level.Error(logger).Log("msg", "Error decrypting DATA_SOURCE_NAME", "err", err)
+			os.Exit(1)
+		}
+		dsn = strings.TrimSpace(string(plaintext))
+	}
 	if len(dsn) == 0 {
-		var err error
-		if dsn, err = parseMycnf(*configMycnf); err != nil {
+		mycnf, err := loadMycnf(*configMycnf)
+		if err == nil {
+			dsn, err = parseMycnf(mycnf)
+		}
+		if err != nil {
 			level.Info(logger).Log("msg", "Error parsing my.cnf", "file", *configMycnf, "err", err)
 			os.Exit(1)
 		}
 	}
+	collector.FollowPrimary(dsn)
+
+	if *configDerivedMetrics != "" {
+		var err error
+		if derivedMetrics, err = parseDerivedMetrics(*configDerivedMetrics); err != nil {
+			level.Error(logger).Log("msg", "Error parsing derived metrics", "file", *configDerivedMetrics, "err", err)
+			os.Exit(1)
+		}
+	}
+
+	if *configQueryOverrides != "" {
+		overrides, err := parseQueryOverrides(*configQueryOverrides)
+		if err != nil {
+			level.Error(logger).Log("msg", "Error parsing query overrides", "file", *configQueryOverrides, "err", err)
+			os.Exit(1)
+		}
+		collector.SetQueryOverrides(overrides)
+	}
+
+	minIntervals := map[string]time.Duration{}
+	for name, interval := range minIntervalFlags {
+		if *interval > 0 {
+			minIntervals[name] = *interval
+		}
+	}
+	collector.SetMinIntervals(minIntervals)
+
+	allScrapers := make([]collector.Scraper, 0, len(scrapers))
+	for scraper := range scrapers {
+		allScrapers = append(allScrapers, scraper)
+	}
+	if *pmmCompatibility {
+		tiers = pmmScrapeTiers(allScrapers)
+	}
+	if err := collector.CheckScrapePolicies(allScrapers); err != nil {
+		level.Error(logger).Log("msg", "Error parsing scrape policies", "err", err)
+		os.Exit(1)
+	}
+	if err := collector.ParseSchemaFilters(allScrapers); err != nil {
+		level.Error(logger).Log("msg", "Error parsing schema filters", "err", err)
+		os.Exit(1)
+	}
+	if err := collector.ParseRowLimits(allScrapers); err != nil {
+		level.Error(logger).Log("msg", "Error parsing row limits", "err", err)
+		os.Exit(1)
+	}
+	userTiers, err := parseScrapeTiers(*scrapeTierFlags, allScrapers)
+	if err != nil {
+		level.Error(logger).Log("msg", "Error parsing scrape tiers", "err", err)
+		os.Exit(1)
+	}
+	for tier, names := range userTiers {
+		tiers[tier] = names
+	}
 
 	// Register only scrapers enabled by flag.
 	enabledScrapers := []collector.Scraper{}
@@ -319,7 +532,27 @@ func main() {
 		}
 	}
 
+	shutdownTracing, err := collector.SetupTracing(context.Background(), logger)
+	if err != nil {
+		level.Error(logger).Log("msg", "Error setting up tracing", "err", err)
+		os.Exit(1)
+	}
+	defer shutdownTracing(context.Background())
+
+	if err := collector.ManagePerfSchema(context.Background(), dsn, enabledScrapers, logger); err != nil {
+		level.Warn(logger).Log("msg", "Error enabling performance_schema consumers and instruments", "err", err)
+	}
+
 	filteredScrapers := filterScrapers(enabledScrapers, nil)
-	push.ReportMod(newMysqlGatherers(collector.New(context.Background(), dsn, filteredScrapers, logger)), logger)
+	if *oneshot {
+		if err := runOnce(filteredScrapers, logger); err != nil {
+			level.Error(logger).Log("msg", "Error running once", "err", err)
+			shutdownTracing(context.Background())
+			os.Exit(1)
+		}
+		return
+	}
+	collector.StartBinlogStream(context.Background(), dsn, enabledScrapers, logger)
+	push.ReportMod(newMysqlGatherers(logger, collector.New(context.Background(), dsn, filteredScrapers, logger)), logger)
 	httpServer(&enabledScrapers, logger)
 }
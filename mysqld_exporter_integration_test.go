@@ -0,0 +1,117 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build integration
+
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus/mysqld_exporter/testkit"
+)
+
+// integrationMetrics are metrics every supported server must expose, by
+// scraper. All other scrapers must expose at least one series, unless listed
+// in integrationEmpty.
+var integrationMetrics = map[string][]string{
+	"global_status":    {"mysql_global_status_uptime", "mysql_global_status_commands_total"},
+	"global_variables": {"mysql_global_variables_max_connections", "mysql_version_info"},
+	"mysql.user":       {"mysql_mysql_max_connections"},
+}
+
+// integrationSkip are scrapers which need a plugin, storage engine or
+// table a default server doesn't have.
+var integrationSkip = map[string]bool{
+	"engine_tokudb_status": true,
+	"heartbeat":            true,
+	"mariadb.columnstore":  true,
+	"mariadb.spider":       true,
+}
+
+// integrationEmpty are scrapers which don't fail on a default server, but
+// expose nothing without replication, clustering, a plugin, optional
+// tables, user data or activity.
+var integrationEmpty = map[string]bool{
+	// Replication and clustering.
+	"slave_status":             true,
+	"slave_hosts":              true,
+	"binlog_stream":            true,
+	"info_schema.replica_host": true,
+	"perf_schema.replication_applier_status_by_worker": true,
+	"perf_schema.replication_applier_workers":          true,
+	"perf_schema.replication_group_flow_control":       true,
+	"perf_schema.replication_group_member_stats":       true,
+	"perf_schema.replication_group_members":            true,
+	"cluster_quorum":                                   true,
+	"innodb_cluster_metadata":                          true,
+	"galera.flow_control":                              true,
+	// Plugins, components and server variants.
+	"info_schema.query_response_time": true,
+	"info_schema.threadpool":          true,
+	"info_schema.userstats":           true,
+	"info_schema.clientstats":         true,
+	"info_schema.tablestats":          true,
+	"info_schema.schemastats":         true,
+	"info_schema.resource_groups":     true,
+	"firewall_audit":                  true,
+	"mysqlx":                          true,
+	"query_cache":                     true,
+	// Optional tables, user data and activity.
+	"canary":                              true,
+	"innodb.deadlocks":                    true,
+	"auto_increment.columns":              true,
+	"info_schema.charset_mismatch":        true,
+	"info_schema.column_statistics":       true,
+	"info_schema.schema_changes":          true,
+	"info_schema.table_fragmentation":     true,
+	"info_schema.tables_without_pk":       true,
+	"perf_schema.eventsstatementscurrent": true,
+	"perf_schema.events_stages_current":   true,
+	"perf_schema.prepared_statements":     true,
+	"perf_schema.fulltablescans":          true,
+	"config_compliance":                   true,
+}
+
+func TestIntegrationScrapers(t *testing.T) {
+	if _, err := kingpin.CommandLine.Parse([]string{}); err != nil {
+		t.Fatal(err)
+	}
+
+	testkit.ForEachImage(t, func(t *testing.T, s *testkit.Server) {
+		t.Logf("server version %s", s.Version)
+		for scraper := range scrapers {
+			scraper := scraper
+			if integrationSkip[scraper.Name()] || !s.Supports(scraper) {
+				continue
+			}
+			t.Run(scraper.Name(), func(t *testing.T) {
+				mfs, err := s.Scrape(context.Background(), scraper)
+				if err != nil {
+					t.Fatalf("error scraping: %s", err)
+				}
+				if len(mfs) == 0 && !integrationEmpty[scraper.Name()] {
+					t.Errorf("no series exposed")
+				}
+				names := testkit.MetricNames(mfs)
+				for _, name := range integrationMetrics[scraper.Name()] {
+					if !names[name] {
+						t.Errorf("metric %s missing", name)
+					}
+				}
+			})
+		}
+	})
+}
@@ -19,6 +19,7 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"os"
 	"os/exec"
@@ -29,6 +30,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/go-kit/log"
 	"github.com/google/go-cmp/cmp"
 	"github.com/prometheus/mysqld_exporter/collector"
 )
@@ -267,3 +269,92 @@ func Test_filterScrapers(t *testing.T) {
 		})
 	}
 }
+
+func Test_scrapeTiers(t *testing.T) {
+	scrapers := []collector.Scraper{
+		collector.ScrapeGlobalStatus{},
+		collector.ScrapeSlaveStatus{},
+		collector.ScrapeGlobalVariables{},
+		collector.ScrapeInnodbMetrics{},
+	}
+
+	pmmTiers := pmmScrapeTiers(scrapers)
+	userTiers, err := parseScrapeTiers(map[string]string{
+		"fast": "global_status, info_schema.innodb_metrics",
+		"slow": "global_variables",
+	}, scrapers)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		tiers scrapeTiers
+		tier  string
+		want  []collector.Scraper
+	}{
+		{"pmm_hr", pmmTiers, pmmHighResolution, []collector.Scraper{collector.ScrapeGlobalStatus{}, collector.ScrapeInnodbMetrics{}}},
+		{"pmm_mr", pmmTiers, pmmMediumResolution, []collector.Scraper{collector.ScrapeSlaveStatus{}}},
+		{"pmm_lr", pmmTiers, pmmLowResolution, []collector.Scraper{collector.ScrapeGlobalVariables{}}},
+		{"user_fast", userTiers, "fast", []collector.Scraper{collector.ScrapeGlobalStatus{}, collector.ScrapeInnodbMetrics{}}},
+		{"user_slow", userTiers, "slow", []collector.Scraper{collector.ScrapeGlobalVariables{}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.tiers.filter(scrapers, tt.tier)
+			if !ok {
+				t.Fatalf("tier %s not found", tt.tier)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("filter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, ok := userTiers.filter(scrapers, "missing"); ok {
+		t.Error("unknown tier should not be found")
+	}
+	if _, err := parseScrapeTiers(map[string]string{"bad": "no_such_collector"}, scrapers); err == nil {
+		t.Error("expected error for unknown collector")
+	}
+}
+
+func Test_scrapeContext(t *testing.T) {
+	logger := log.NewNopLogger()
+	for _, tc := range []struct {
+		name     string
+		header   string
+		offset   float64
+		deadline bool
+		want     time.Duration
+	}{
+		{name: "no header", header: ""},
+		{name: "invalid header", header: "abc"},
+		{name: "offset subtracted", header: "10", offset: 0.25, deadline: true, want: 9750 * time.Millisecond},
+		{name: "offset too large", header: "0.2", offset: 0.25, deadline: true, want: 200 * time.Millisecond},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/metrics", nil)
+			if tc.header != "" {
+				r.Header.Set("X-Prometheus-Scrape-Timeout-Seconds", tc.header)
+			}
+			start := time.Now()
+			ctx, cancel := scrapeContext(r, tc.offset, logger)
+			defer cancel()
+
+			deadline, ok := ctx.Deadline()
+			if ok != tc.deadline {
+				t.Fatalf("got deadline %v, want %v", ok, tc.deadline)
+			}
+			if ok {
+				if got := deadline.Sub(start); got < tc.want || got > tc.want+time.Second {
+					t.Errorf("got timeout %s, want %s", got, tc.want)
+				}
+			}
+			cancel()
+			if ctx.Err() == nil {
+				t.Error("context not cancelled")
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/alecthomas/kingpin/v2"
+	remotewrite "github.com/boxjan/prometheus-remote-write"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/go-sql-driver/mysql"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/mysqld_exporter/collector"
+)
+
+var (
+	oneshot = kingpin.Flag(
+		"oneshot",
+		"Scrape MySQL once, push the metrics to --oneshot.pushgateway-url and/or --oneshot.remote-write-url and exit, e.g. to run heavy collectors from cron.",
+	).Default("false").Bool()
+	oneshotPushgatewayURL = kingpin.Flag(
+		"oneshot.pushgateway-url",
+		"URL of the Pushgateway to push the metrics of --oneshot to.",
+	).Default("").String()
+	oneshotRemoteWriteURL = kingpin.Flag(
+		"oneshot.remote-write-url",
+		"URL of the remote write endpoint to push the metrics of --oneshot to.",
+	).Default("").String()
+	oneshotJob = kingpin.Flag(
+		"oneshot.job",
+		"Job label of the metrics pushed by --oneshot.",
+	).Default("mysqld_exporter").String()
+	oneshotInstance = kingpin.Flag(
+		"oneshot.instance",
+		"Instance label of the metrics pushed by --oneshot, by default the address of the MySQL server.",
+	).Default("").String()
+	oneshotTimeout = kingpin.Flag(
+		"oneshot.timeout",
+		"Timeout of the scrape of --oneshot.",
+	).Default("5m").Duration()
+)
+
+// runOnce scrapes MySQL once and pushes the metrics. It fails if MySQL is
+// down, after pushing mysql_up, so cron reports the failure.
+func runOnce(scrapers []collector.Scraper, logger log.Logger) error {
+	if *oneshotPushgatewayURL == "" && *oneshotRemoteWriteURL == "" {
+		return fmt.Errorf("--oneshot requires --oneshot.pushgateway-url or --oneshot.remote-write-url")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), *oneshotTimeout)
+	defer cancel()
+
+	// Gather once, so all endpoints get the same samples.
+	mfs, err := newMysqlGatherers(logger, collector.New(ctx, dsn, scrapers, logger)).Gather()
+	if err != nil {
+		level.Warn(logger).Log("msg", "Error gathering metrics, pushing the others", "err", err)
+	}
+	if err := pushOnce(mfs, oneshotInstanceLabel(), logger); err != nil {
+		return err
+	}
+	if !mysqlIsUp(mfs) {
+		return fmt.Errorf("MySQL is down")
+	}
+	return nil
+}
+
+// pushOnce pushes mfs to the configured endpoints.
+func pushOnce(mfs []*dto.MetricFamily, instance string, logger log.Logger) error {
+	gatherer := prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) { return mfs, nil })
+	if *oneshotPushgatewayURL != "" {
+		err := push.New(*oneshotPushgatewayURL, *oneshotJob).
+			Gatherer(gatherer).
+			Grouping("instance", instance).
+			Push()
+		if err != nil {
+			return fmt.Errorf("error pushing to the Pushgateway: %w", err)
+		}
+		level.Info(logger).Log("msg", "Pushed metrics to the Pushgateway", "url", *oneshotPushgatewayURL)
+	}
+	if *oneshotRemoteWriteURL != "" {
+		err := remotewrite.New().
+			Gatherer(gatherer).
+			ExtraLabel("job", *oneshotJob).
+			ExtraLabel("instance", instance).
+			Push(*oneshotRemoteWriteURL)
+		if err != nil {
+			return fmt.Errorf("error pushing to the remote write endpoint: %w", err)
+		}
+		level.Info(logger).Log("msg", "Pushed metrics to the remote write endpoint", "url", *oneshotRemoteWriteURL)
+	}
+	return nil
+}
+
+// oneshotInstanceLabel returns --oneshot.instance, or the address of the
+// MySQL server.
+func oneshotInstanceLabel() string {
+	if *oneshotInstance != "" {
+		return *oneshotInstance
+	}
+	if cfg, err := mysql.ParseDSN(dsn); err == nil && cfg.Addr != "" {
+		return cfg.Addr
+	}
+	return "localhost"
+}
+
+func mysqlIsUp(mfs []*dto.MetricFamily) bool {
+	for _, mf := range mfs {
+		if mf.GetName() == "mysql_up" && len(mf.Metric) > 0 {
+			return mf.Metric[0].GetGauge().GetValue() == 1
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestPushOnce(t *testing.T) {
+	type request struct {
+		method, path, contentEncoding string
+		body                          []byte
+	}
+	requests := make(chan request, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- request{r.Method, r.URL.Path, r.Header.Get("Content-Encoding"), body}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	*oneshotPushgatewayURL = server.URL
+	*oneshotRemoteWriteURL = server.URL + "/api/v1/write"
+	*oneshotJob = "mysqld_exporter"
+	defer func() {
+		*oneshotPushgatewayURL, *oneshotRemoteWriteURL = "", ""
+	}()
+
+	up := prometheus.NewGauge(prometheus.GaugeOpts{Name: "mysql_up", Help: "Whether the MySQL server is up."})
+	up.Set(1)
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(up)
+	mfs, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := pushOnce(mfs, "db-1:3306", log.NewNopLogger()); err != nil {
+		t.Fatalf("error pushing: %s", err)
+	}
+
+	pgw := <-requests
+	if pgw.method != http.MethodPut || pgw.path != "/metrics/job/mysqld_exporter/instance/db-1:3306" {
+		t.Errorf("unexpected Pushgateway request %s %s", pgw.method, pgw.path)
+	}
+	if !strings.Contains(string(pgw.body), "mysql_up") {
+		t.Errorf("mysql_up not pushed to the Pushgateway")
+	}
+	rw := <-requests
+	if rw.method != http.MethodPost || rw.path != "/api/v1/write" || rw.contentEncoding != "snappy" {
+		t.Errorf("unexpected remote write request %s %s, encoding %q", rw.method, rw.path, rw.contentEncoding)
+	}
+
+	if !mysqlIsUp(mfs) {
+		t.Errorf("mysql_up 1 not detected")
+	}
+	if mysqlIsUp([]*dto.MetricFamily{}) {
+		t.Errorf("missing mysql_up detected as up")
+	}
+}
@@ -0,0 +1,342 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+)
+
+var (
+	openMetrics = kingpin.Flag(
+		"web.openmetrics",
+		"Expose metrics in the OpenMetrics format when the scraper asks for it, with _created series for counters and UNIT metadata for _seconds and _bytes metrics.",
+	).Default("false").Bool()
+	openMetricsStrict = kingpin.Flag(
+		"web.openmetrics-strict",
+		"Validate all metric families exposed in the OpenMetrics format and drop the ones violating the specification.",
+	).Default("false").Bool()
+)
+
+var openMetricsInvalidFamilies = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "mysql_exporter_openmetrics_invalid_families_total",
+	Help: "Number of metric families dropped from OpenMetrics responses by --web.openmetrics-strict, by reason.",
+}, []string{"reason"})
+
+func init() {
+	prometheus.MustRegister(openMetricsInvalidFamilies)
+}
+
+// openMetricsUnits are the base units announced with a UNIT line, keyed by
+// the metric name suffix.
+var openMetricsUnits = []string{"seconds", "bytes"}
+
+// globalStatusPrefix starts the names of the counters of SHOW GLOBAL STATUS.
+const globalStatusPrefix = "mysql_global_status_"
+
+// createdTTL is how long the created timestamp of a counter series no longer
+// exposed is kept.
+const createdTTL = time.Hour
+
+// counterCreated is the state of a counter series needed for its created
+// timestamp.
+type counterCreated struct {
+	value    float64
+	created  time.Time
+	reset    bool
+	lastSeen time.Time
+}
+
+// createdTracker derives created timestamps of counter series. Counters of
+// SHOW GLOBAL STATUS start at the server start time, derived from its
+// uptime, unless a reset like FLUSH STATUS was observed later. Other
+// counters, including the ones counted by the exporter like
+// mysql_binlog_stream_events_total, are created when first seen by the
+// exporter.
+type createdTracker struct {
+	mtx    sync.Mutex
+	series map[string]*counterCreated
+}
+
+var counterCreatedTimestamps = &createdTracker{series: map[string]*counterCreated{}}
+
+// serverStartTime returns the MySQL start time from the gathered uptime.
+func serverStartTime(mfs []*dto.MetricFamily, now time.Time) (time.Time, bool) {
+	for _, mf := range mfs {
+		switch mf.GetName() {
+		case "mysql_global_status_uptime", "mysql_uptime_seconds":
+		default:
+			continue
+		}
+		if len(mf.Metric) != 1 {
+			continue
+		}
+		m := mf.Metric[0]
+		uptime := m.GetUntyped().GetValue() + m.GetGauge().GetValue() + m.GetCounter().GetValue()
+		return now.Add(-time.Duration(uptime) * time.Second).Truncate(time.Second), true
+	}
+	return time.Time{}, false
+}
+
+// created returns the created timestamps of all metrics of the counter family.
+func (t *createdTracker) created(mf *dto.MetricFamily, start time.Time, now time.Time) []time.Time {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	fromServer := !start.IsZero() && strings.HasPrefix(mf.GetName(), globalStatusPrefix)
+	res := make([]time.Time, 0, len(mf.Metric))
+	for _, m := range mf.Metric {
+		key := seriesKey(mf.GetName(), m)
+		value := m.GetCounter().GetValue()
+		s, ok := t.series[key]
+		switch {
+		case !ok:
+			s = &counterCreated{created: now}
+			t.series[key] = s
+		case value < s.value:
+			s.created, s.reset = now, true
+		}
+		s.value, s.lastSeen = value, now
+
+		created := s.created
+		if fromServer && (!s.reset || s.created.Before(start)) {
+			created = start
+		}
+		res = append(res, created)
+	}
+
+	for key, s := range t.series {
+		if now.Sub(s.lastSeen) > createdTTL {
+			delete(t.series, key)
+		}
+	}
+	return res
+}
+
+// seriesKey identifies a series by metric name and label pairs.
+func seriesKey(name string, m *dto.Metric) string {
+	var b strings.Builder
+	b.WriteString(name)
+	for _, lp := range m.Label {
+		b.WriteByte(model.SeparatorByte)
+		b.WriteString(lp.GetName())
+		b.WriteByte(model.SeparatorByte)
+		b.WriteString(lp.GetValue())
+	}
+	return b.String()
+}
+
+// openMetricsFamilyName returns the name a family is announced with in the
+// OpenMetrics format.
+func openMetricsFamilyName(mf *dto.MetricFamily) string {
+	if mf.GetType() == dto.MetricType_COUNTER {
+		return strings.TrimSuffix(mf.GetName(), "_total")
+	}
+	return mf.GetName()
+}
+
+// openMetricsSampleNames returns the names of all samples of a family in the
+// OpenMetrics format.
+func openMetricsSampleNames(mf *dto.MetricFamily) []string {
+	name := openMetricsFamilyName(mf)
+	switch mf.GetType() {
+	case dto.MetricType_COUNTER:
+		return []string{name + "_total", name + "_created"}
+	case dto.MetricType_SUMMARY:
+		return []string{name, name + "_sum", name + "_count", name + "_created"}
+	case dto.MetricType_HISTOGRAM:
+		return []string{name + "_bucket", name + "_sum", name + "_count", name + "_created"}
+	}
+	return []string{name}
+}
+
+// validateOpenMetrics drops the metric families which would make the
+// response invalid OpenMetrics and returns the remaining ones.
+func validateOpenMetrics(mfs []*dto.MetricFamily, logger log.Logger) []*dto.MetricFamily {
+	samples := map[string]string{}
+	for _, mf := range mfs {
+		for _, name := range openMetricsSampleNames(mf) {
+			samples[name] = mf.GetName()
+		}
+	}
+
+	res := make([]*dto.MetricFamily, 0, len(mfs))
+	for _, mf := range mfs {
+		if reason, err := validateOpenMetricsFamily(mf, samples); err != nil {
+			level.Warn(logger).Log("msg", "Dropping invalid OpenMetrics family", "metric", mf.GetName(), "err", err)
+			openMetricsInvalidFamilies.WithLabelValues(reason).Inc()
+			continue
+		}
+		res = append(res, mf)
+	}
+	return res
+}
+
+// validateOpenMetricsFamily checks a single family, samples maps every
+// sample name of the response to its family.
+func validateOpenMetricsFamily(mf *dto.MetricFamily, samples map[string]string) (string, error) {
+	name := mf.GetName()
+	if !model.IsValidMetricName(model.LabelValue(name)) {
+		return "invalid_name", fmt.Errorf("invalid metric name %q", name)
+	}
+	if mf.GetType() == dto.MetricType_COUNTER && !strings.HasSuffix(name, "_total") {
+		return "counter_suffix", fmt.Errorf("counter %q has no _total suffix", name)
+	}
+	if owner, ok := samples[openMetricsFamilyName(mf)]; ok && owner != name {
+		return "name_clash", fmt.Errorf("metric %q clashes with samples of %q", name, owner)
+	}
+	for _, sample := range openMetricsSampleNames(mf) {
+		if owner := samples[sample]; owner != name {
+			return "name_clash", fmt.Errorf("metric %q clashes with samples of %q", name, owner)
+		}
+	}
+
+	seen := make(map[string]bool, len(mf.Metric))
+	for _, m := range mf.Metric {
+		labels := make(map[string]bool, len(m.Label))
+		for _, lp := range m.Label {
+			if !model.LabelName(lp.GetName()).IsValid() || labels[lp.GetName()] {
+				return "invalid_label", fmt.Errorf("invalid or duplicate label name %q", lp.GetName())
+			}
+			if !utf8.ValidString(lp.GetValue()) {
+				return "invalid_label", fmt.Errorf("label %q has an invalid UTF-8 value", lp.GetName())
+			}
+			labels[lp.GetName()] = true
+		}
+		key := seriesKey(name, m)
+		if seen[key] {
+			return "duplicate_series", fmt.Errorf("duplicate series in %q", name)
+		}
+		seen[key] = true
+		if mf.GetType() == dto.MetricType_COUNTER {
+			if v := m.GetCounter().GetValue(); math.IsNaN(v) || v < 0 {
+				return "invalid_value", fmt.Errorf("counter %q has value %v", name, v)
+			}
+		}
+	}
+	return "", nil
+}
+
+// writeOpenMetricsFamily writes a metric family in the OpenMetrics format,
+// adding a UNIT line for metrics with a base unit suffix and the _created
+// samples of counters.
+func writeOpenMetricsFamily(w io.Writer, mf *dto.MetricFamily, created []time.Time) error {
+	var buf bytes.Buffer
+	if _, err := expfmt.MetricFamilyToOpenMetrics(&buf, mf); err != nil {
+		return err
+	}
+	lines := strings.SplitAfter(buf.String(), "\n")
+
+	var createdLines []string
+	if mf.GetType() == dto.MetricType_COUNTER && strings.HasSuffix(mf.GetName(), "_total") && len(created) == len(mf.Metric) {
+		name := openMetricsFamilyName(mf) + "_created"
+		gauge := &dto.MetricFamily{Name: &name, Type: dto.MetricType_GAUGE.Enum()}
+		for i, m := range mf.Metric {
+			v := float64(created[i].UnixNano()) / 1e9
+			gauge.Metric = append(gauge.Metric, &dto.Metric{Label: m.Label, Gauge: &dto.Gauge{Value: &v}})
+		}
+		var createdBuf bytes.Buffer
+		if _, err := expfmt.MetricFamilyToOpenMetrics(&createdBuf, gauge); err != nil {
+			return err
+		}
+		// Skip the TYPE line of the helper family.
+		createdLines = strings.SplitAfter(createdBuf.String(), "\n")[1:]
+	}
+
+	var out strings.Builder
+	metric := 0
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+		out.WriteString(line)
+		if strings.HasPrefix(line, "# TYPE ") {
+			familyName := openMetricsFamilyName(mf)
+			for _, unit := range openMetricsUnits {
+				if strings.HasSuffix(familyName, "_"+unit) {
+					fmt.Fprintf(&out, "# UNIT %s %s\n", familyName, unit)
+				}
+			}
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		if metric < len(createdLines) {
+			out.WriteString(createdLines[metric])
+		}
+		metric++
+	}
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+// openMetricsRequested reports whether the scraper accepts the OpenMetrics format.
+func openMetricsRequested(r *http.Request) bool {
+	return expfmt.NegotiateIncludingOpenMetrics(r.Header) == expfmt.FmtOpenMetrics
+}
+
+// serveOpenMetrics gathers g and writes it in the OpenMetrics format.
+func serveOpenMetrics(w http.ResponseWriter, r *http.Request, g prometheus.Gatherer, logger log.Logger) {
+	mfs, err := g.Gather()
+	if err != nil {
+		level.Error(logger).Log("msg", "Error gathering metrics", "err", err)
+		http.Error(w, "An error has occurred while gathering metrics:\n\n"+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if *openMetricsStrict {
+		mfs = validateOpenMetrics(mfs, logger)
+	}
+
+	w.Header().Set("Content-Type", string(expfmt.FmtOpenMetrics))
+	var out io.Writer = w
+	if gzipAccepted(r.Header) {
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		defer gz.Close()
+		out = gz
+	}
+
+	now := time.Now()
+	start, _ := serverStartTime(mfs, now)
+	for _, mf := range mfs {
+		var created []time.Time
+		if mf.GetType() == dto.MetricType_COUNTER {
+			created = counterCreatedTimestamps.created(mf, start, now)
+		}
+		if err := writeOpenMetricsFamily(out, mf, created); err != nil {
+			level.Error(logger).Log("msg", "Error writing OpenMetrics response", "err", err)
+			return
+		}
+	}
+	if _, err := expfmt.FinalizeOpenMetrics(out); err != nil {
+		level.Error(logger).Log("msg", "Error writing OpenMetrics response", "err", err)
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func gatherFamilies(t *testing.T, cs ...prometheus.Collector) []*dto.MetricFamily {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(cs...)
+	mfs, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return mfs
+}
+
+func TestWriteOpenMetricsFamily(t *testing.T) {
+	c := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "mysql_global_status_bytes_received_total",
+		Help: "Generic counter from SHOW GLOBAL STATUS.",
+	}, []string{"instance"})
+	c.WithLabelValues("a").Add(10)
+	c.WithLabelValues("b").Add(20)
+	g := prometheus.NewGauge(prometheus.GaugeOpts{Name: "mysql_uptime_seconds", Help: "Uptime."})
+	g.Set(100)
+	mfs := gatherFamilies(t, c, g)
+
+	now := time.Unix(1000, 0)
+	start, ok := serverStartTime(mfs, now)
+	tracker := &createdTracker{series: map[string]*counterCreated{}}
+
+	convey.Convey("Counter with created samples", t, func() {
+		convey.So(ok, convey.ShouldBeTrue)
+		convey.So(start, convey.ShouldEqual, time.Unix(900, 0))
+
+		var b strings.Builder
+		convey.So(writeOpenMetricsFamily(&b, mfs[0], tracker.created(mfs[0], start, now)), convey.ShouldBeNil)
+		convey.So(b.String(), convey.ShouldEqual, `# HELP mysql_global_status_bytes_received Generic counter from SHOW GLOBAL STATUS.
+# TYPE mysql_global_status_bytes_received counter
+mysql_global_status_bytes_received_total{instance="a"} 10.0
+mysql_global_status_bytes_received_created{instance="a"} 900.0
+mysql_global_status_bytes_received_total{instance="b"} 20.0
+mysql_global_status_bytes_received_created{instance="b"} 900.0
+`)
+	})
+
+	convey.Convey("Gauge with unit", t, func() {
+		var b strings.Builder
+		convey.So(writeOpenMetricsFamily(&b, mfs[1], nil), convey.ShouldBeNil)
+		convey.So(b.String(), convey.ShouldEqual, `# HELP mysql_uptime_seconds Uptime.
+# TYPE mysql_uptime_seconds gauge
+# UNIT mysql_uptime_seconds seconds
+mysql_uptime_seconds 100.0
+`)
+	})
+}
+
+func TestCreatedTracker(t *testing.T) {
+	c := prometheus.NewCounter(prometheus.CounterOpts{Name: "mysql_exporter_scrapes_total", Help: "Scrapes."})
+	tracker := &createdTracker{series: map[string]*counterCreated{}}
+
+	convey.Convey("Exporter counters are created when first seen and on resets", t, func() {
+		c.Add(5)
+		first := time.Unix(1000, 0)
+		convey.So(tracker.created(gatherFamilies(t, c)[0], time.Unix(10, 0), first), convey.ShouldResemble, []time.Time{first})
+
+		c.Add(5)
+		convey.So(tracker.created(gatherFamilies(t, c)[0], time.Time{}, first.Add(time.Minute)), convey.ShouldResemble, []time.Time{first})
+
+		reset := prometheus.NewCounter(prometheus.CounterOpts{Name: "mysql_exporter_scrapes_total", Help: "Scrapes."})
+		later := first.Add(2 * time.Minute)
+		convey.So(tracker.created(gatherFamilies(t, reset)[0], time.Time{}, later), convey.ShouldResemble, []time.Time{later})
+	})
+
+	convey.Convey("Counters computed by the exporter are created when first seen", t, func() {
+		for _, name := range []string{"mysql_binlog_stream_events_total", "mysql_slave_status_source_changed_total", "mysql_schema_change_detected_total"} {
+			computed := prometheus.NewCounter(prometheus.CounterOpts{Name: name, Help: "Computed."})
+			now := time.Unix(2000, 0)
+			convey.So(tracker.created(gatherFamilies(t, computed)[0], time.Unix(10, 0), now), convey.ShouldResemble, []time.Time{now})
+		}
+	})
+
+	convey.Convey("Stale series are forgotten", t, func() {
+		other := prometheus.NewCounter(prometheus.CounterOpts{Name: "mysql_exporter_other_total", Help: "Other."})
+		tracker.created(gatherFamilies(t, other)[0], time.Time{}, time.Unix(1000, 0).Add(2*createdTTL))
+		convey.So(tracker.series, convey.ShouldHaveLength, 1)
+	})
+}
+
+func TestValidateOpenMetrics(t *testing.T) {
+	counter := prometheus.NewCounter(prometheus.CounterOpts{Name: "mysql_foo_total", Help: "Foo."})
+	clash := prometheus.NewGauge(prometheus.GaugeOpts{Name: "mysql_foo_created", Help: "Clashes with the foo counter."})
+	untyped := prometheus.NewUntypedFunc(prometheus.UntypedOpts{Name: "mysql_bar", Help: "Bar."}, func() float64 { return 1 })
+	noSuffix := prometheus.NewCounterFunc(prometheus.CounterOpts{Name: "mysql_baz", Help: "Counter without suffix."}, func() float64 { return 1 })
+
+	convey.Convey("Invalid families are dropped", t, func() {
+		mfs := validateOpenMetrics(gatherFamilies(t, counter, clash, untyped, noSuffix), log.NewNopLogger())
+		names := []string{}
+		for _, mf := range mfs {
+			names = append(names, mf.GetName())
+		}
+		convey.So(names, convey.ShouldResemble, []string{"mysql_bar", "mysql_foo_total"})
+	})
+}
@@ -0,0 +1,187 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/alecthomas/kingpin/v2"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/mysqld_exporter/collector"
+)
+
+var (
+	pmmCompatibility = kingpin.Flag(
+		"compatibility.pmm",
+		"Emit the metric names and labels of Percona PMM's mysqld_exporter and expose /metrics-hr, /metrics-mr and /metrics-lr endpoints splitting the enabled collectors by PMM's scrape resolution.",
+	).Default("false").Bool()
+)
+
+// PMM scrapes its exporter at high, medium and low resolution.
+const (
+	pmmHighResolution   = "hr"
+	pmmMediumResolution = "mr"
+	pmmLowResolution    = "lr"
+)
+
+// pmmResolutions lists the resolutions in the order the endpoints are registered.
+var pmmResolutions = []string{pmmHighResolution, pmmMediumResolution, pmmLowResolution}
+
+// pmmScraperResolutions maps scraper names to the resolution PMM collects
+// them at. Scrapers not listed here are scraped at low resolution.
+var pmmScraperResolutions = map[string]string{
+	collector.ScrapeGlobalStatus{}.Name():                        pmmHighResolution,
+	collector.ScrapeInnodbMetrics{}.Name():                       pmmHighResolution,
+	collector.ScrapeThreadPool{}.Name():                          pmmHighResolution,
+	collector.ScrapeSlaveStatus{}.Name():                         pmmMediumResolution,
+	collector.ScrapeMasterStatus{}.Name():                        pmmMediumResolution,
+	collector.ScrapeProcesslist{}.Name():                         pmmMediumResolution,
+	collector.ScrapePerfEventsWaits{}.Name():                     pmmMediumResolution,
+	collector.ScrapePerfFileEvents{}.Name():                      pmmMediumResolution,
+	collector.ScrapePerfTableLockWaits{}.Name():                  pmmMediumResolution,
+	collector.ScrapeQueryResponseTime{}.Name():                   pmmMediumResolution,
+	collector.ScrapeEngineInnodbStatus{}.Name():                  pmmMediumResolution,
+	collector.ScrapePerfReplicationGroupMemberStats{}.Name():     pmmMediumResolution,
+	collector.ScrapePerfReplicationGroupFlowControl{}.Name():     pmmMediumResolution,
+	collector.ScrapePerfReplicationApplierStatsByWorker{}.Name(): pmmMediumResolution,
+	collector.ScrapePerfReplicationApplierWorkers{}.Name():       pmmMediumResolution,
+	collector.ScrapeReplicaHost{}.Name():                         pmmMediumResolution,
+	collector.ScrapeHeartbeat{}.Name():                           pmmMediumResolution,
+	collector.ScrapeClusterQuorum{}.Name():                       pmmMediumResolution,
+}
+
+// pmmScrapeTiers returns one scrape tier per PMM resolution.
+func pmmScrapeTiers(scrapers []collector.Scraper) scrapeTiers {
+	tiers := scrapeTiers{}
+	for _, resolution := range pmmResolutions {
+		tiers[resolution] = map[string]bool{}
+	}
+	for _, scraper := range scrapers {
+		resolution, ok := pmmScraperResolutions[scraper.Name()]
+		if !ok {
+			resolution = pmmLowResolution
+		}
+		tiers[resolution][scraper.Name()] = true
+	}
+	return tiers
+}
+
+// pmmMetric is the name and labels PMM's exporter reports a metric of this
+// exporter with.
+type pmmMetric struct {
+	name string
+	// drop lists the labels PMM does not have. Series differing only in
+	// them are summed.
+	drop []string
+}
+
+// pmmMetrics maps metric names of this exporter to the ones of PMM's
+// exporter. Metrics not listed here have the same name in both.
+var pmmMetrics = map[string]pmmMetric{
+	"mysql_master_status_binlog_file_num":             {name: "mysql_binlog_file_number"},
+	"mysql_info_schema_processlist_threads":           {name: "mysql_info_schema_threads", drop: []string{"command"}},
+	"mysql_info_schema_processlist_seconds":           {name: "mysql_info_schema_threads_seconds", drop: []string{"command"}},
+	"mysql_info_schema_processlist_processes_by_user": {name: "mysql_info_schema_processes_by_user"},
+	"mysql_info_schema_processlist_processes_by_host": {name: "mysql_info_schema_processes_by_host"},
+}
+
+// pmmFamilies renames gathered metric families to the names and labels of
+// PMM's exporter. Metrics PMM does not have keep their name.
+func pmmFamilies(mfs []*dto.MetricFamily) []*dto.MetricFamily {
+	gathered := make(map[string]bool, len(mfs))
+	for _, mf := range mfs {
+		gathered[mf.GetName()] = true
+	}
+
+	res := make([]*dto.MetricFamily, 0, len(mfs))
+	for _, mf := range mfs {
+		pmm, ok := pmmMetrics[mf.GetName()]
+		if !ok {
+			res = append(res, mf)
+			continue
+		}
+		// Prefer the value PMM's name is already gathered with.
+		if gathered[pmm.name] {
+			continue
+		}
+		gathered[pmm.name] = true
+		name := pmm.name
+		res = append(res, &dto.MetricFamily{
+			Name:   &name,
+			Help:   mf.Help,
+			Type:   mf.Type,
+			Metric: dropLabels(mf.Metric, pmm.drop),
+		})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].GetName() < res[j].GetName() })
+	return res
+}
+
+// dropLabels removes the labels from the metrics and sums the values of the
+// metrics left with the same labels. Only counters, gauges and untyped
+// metrics are summed, others are returned unchanged.
+func dropLabels(metrics []*dto.Metric, labels []string) []*dto.Metric {
+	if len(labels) == 0 {
+		return metrics
+	}
+	dropped := make(map[string]bool, len(labels))
+	for _, label := range labels {
+		dropped[label] = true
+	}
+
+	res := make([]*dto.Metric, 0, len(metrics))
+	byLabels := make(map[string]*dto.Metric, len(metrics))
+	for _, m := range metrics {
+		if m.Counter == nil && m.Gauge == nil && m.Untyped == nil {
+			return metrics
+		}
+		var (
+			pairs []*dto.LabelPair
+			key   strings.Builder
+		)
+		for _, lp := range m.Label {
+			if dropped[lp.GetName()] {
+				continue
+			}
+			pairs = append(pairs, lp)
+			key.WriteString(lp.GetName() + "\xff" + lp.GetValue() + "\xff")
+		}
+		value := m.GetCounter().GetValue() + m.GetGauge().GetValue() + m.GetUntyped().GetValue()
+
+		if existing, ok := byLabels[key.String()]; ok {
+			switch {
+			case existing.Counter != nil:
+				*existing.Counter.Value += value
+			case existing.Gauge != nil:
+				*existing.Gauge.Value += value
+			default:
+				*existing.Untyped.Value += value
+			}
+			continue
+		}
+		summed := &dto.Metric{Label: pairs}
+		switch {
+		case m.Counter != nil:
+			summed.Counter = &dto.Counter{Value: &value}
+		case m.Gauge != nil:
+			summed.Gauge = &dto.Gauge{Value: &value}
+		default:
+			summed.Untyped = &dto.Untyped{Value: &value}
+		}
+		byLabels[key.String()] = summed
+		res = append(res, summed)
+	}
+	return res
+}
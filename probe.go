@@ -13,54 +13,188 @@
 
 package main
 
-//
-//import (
-//	"fmt"
-//	"net/http"
-//
-//	"github.com/go-kit/log"
-//	"github.com/go-kit/log/level"
-//	"github.com/prometheus/client_golang/prometheus"
-//	"github.com/prometheus/client_golang/prometheus/promhttp"
-//	"github.com/prometheus/mysqld_exporter/collector"
-//)
-//
-//func handleProbe(scrapers []collector.Scraper, logger log.Logger) http.HandlerFunc {
-//	return func(w http.ResponseWriter, r *http.Request) {
-//		ctx := r.Context()
-//		params := r.URL.Query()
-//		target := params.Get("target")
-//		if target == "" {
-//			http.Error(w, "target is required", http.StatusBadRequest)
-//			return
-//		}
-//		collectParams := r.URL.Query()["collect[]"]
-//
-//		authModule := params.Get("auth_module")
-//		if authModule == "" {
-//			authModule = "client"
-//		}
-//
-//		cfg := c.GetConfig()
-//		cfgsection, ok := cfg.Sections[authModule]
-//		if !ok {
-//			level.Error(logger).Log("msg", fmt.Sprintf("Could not find section [%s] from config file", authModule))
-//			http.Error(w, fmt.Sprintf("Could not find config section [%s]", authModule), http.StatusBadRequest)
-//			return
-//		}
-//		dsn, err := cfgsection.FormDSN(target)
-//		if err != nil {
-//			level.Error(logger).Log("msg", fmt.Sprintf("Failed to form dsn from section [%s]", authModule), "err", err)
-//			http.Error(w, fmt.Sprintf("Error forming dsn from config section [%s]", authModule), http.StatusBadRequest)
-//			return
-//		}
-//
-//		filteredScrapers := filterScrapers(scrapers, collectParams)
-//
-//		registry := prometheus.NewRegistry()
-//		registry.MustRegister(collector.New(ctx, dsn, filteredScrapers, logger))
-//
-//		h := promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
-//		h.ServeHTTP(w, r)
-//	}
-//}
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/mysqld_exporter/collector"
+	"github.com/prometheus/mysqld_exporter/config"
+)
+
+var (
+	mysqldAddress = kingpin.Flag(
+		"mysqld.address",
+		"Address to use for connecting to MySQL",
+	).Default("localhost:3306").String()
+	mysqldUser = kingpin.Flag(
+		"mysqld.username",
+		"Username to use for connecting to MySQL",
+	).String()
+	probeMaxInflight = kingpin.Flag(
+		"probe.max_inflight_per_target",
+		"Maximum number of concurrent /probe scrapes of a target, further scrapes of the target wait for a slot until their timeout.",
+	).Default("2").Int()
+)
+
+// authModules are the sections of config.my-cnf selected by the
+// auth_module parameter of /probe.
+var authModules = &config.MySqlConfigHandler{Config: &config.Config{}}
+
+var targetInflightScrapes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "mysql_exporter_target_inflight_scrapes",
+	Help: "The number of /probe scrapes of the target in progress, including the ones waiting for a slot.",
+}, []string{"target"})
+
+// probeTargetOther is the target label of the /probe requests for targets
+// no auth module is configured with.
+const probeTargetOther = "other"
+
+// probeTargetLabel returns target if an auth module is configured with its
+// host and port or socket, probeTargetOther otherwise, so arbitrary target
+// parameters don't create series.
+func probeTargetLabel(target string) string {
+	for _, section := range authModules.GetConfig().Sections {
+		port := section.Port
+		if port == 0 {
+			port = 3306
+		}
+		if section.Host != "" && target == net.JoinHostPort(section.Host, strconv.Itoa(port)) ||
+			section.Socket != "" && target == "unix://"+section.Socket {
+			return target
+		}
+	}
+	return probeTargetOther
+}
+
+func init() {
+	prometheus.MustRegister(targetInflightScrapes)
+}
+
+// targetSlots limits the concurrent scrapes of each target, so a slow or
+// down target only holds up its own scrapes. Every scrape opens a
+// connection of its own, the semaphore of a target bounds the connections
+// it can tie up. The slots of a target, and its in-flight series, are
+// dropped once it has no scrape in progress.
+type targetSlots struct {
+	mu    sync.Mutex
+	limit int
+	slots map[string]*targetSlot
+}
+
+// targetSlot is the semaphore of a target and its scrapes in progress,
+// including the ones waiting for the semaphore.
+type targetSlot struct {
+	sem     chan struct{}
+	scrapes int
+}
+
+func newTargetSlots(limit int) *targetSlots {
+	if limit < 1 {
+		limit = 1
+	}
+	return &targetSlots{limit: limit, slots: map[string]*targetSlot{}}
+}
+
+// acquire records a scrape of target and returns the semaphore of target.
+// The scrape must be finished with release.
+func (s *targetSlots) acquire(target string) chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	slot, ok := s.slots[target]
+	if !ok {
+		slot = &targetSlot{sem: make(chan struct{}, s.limit)}
+		s.slots[target] = slot
+	}
+	slot.scrapes++
+	targetInflightScrapes.WithLabelValues(target).Set(float64(slot.scrapes))
+	return slot.sem
+}
+
+// release finishes a scrape of target.
+func (s *targetSlots) release(target string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	slot, ok := s.slots[target]
+	if !ok {
+		return
+	}
+	slot.scrapes--
+	if slot.scrapes > 0 {
+		targetInflightScrapes.WithLabelValues(target).Set(float64(slot.scrapes))
+		return
+	}
+	delete(s.slots, target)
+	targetInflightScrapes.DeleteLabelValues(target)
+}
+
+func handleProbe(scrapers []collector.Scraper, slots *targetSlots, logger log.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		params := r.URL.Query()
+		target := params.Get("target")
+		if target == "" {
+			http.Error(w, "target is required", http.StatusBadRequest)
+			return
+		}
+		collectParams := params["collect[]"]
+
+		authModule := params.Get("auth_module")
+		if authModule == "" {
+			authModule = "client"
+		}
+
+		cfg := authModules.GetConfig()
+		cfgsection, ok := cfg.Sections[authModule]
+		if !ok {
+			level.Error(logger).Log("msg", fmt.Sprintf("Could not find section [%s] from config file", authModule))
+			http.Error(w, fmt.Sprintf("Could not find config section [%s]", authModule), http.StatusBadRequest)
+			return
+		}
+		dsn, err := cfgsection.FormDSN(target)
+		if err != nil {
+			level.Error(logger).Log("msg", fmt.Sprintf("Failed to form dsn from section [%s]", authModule), "err", err)
+			http.Error(w, fmt.Sprintf("Error forming dsn from config section [%s]", authModule), http.StatusBadRequest)
+			return
+		}
+
+		ctx, cancel := scrapeContext(r, *timeoutOffset, logger)
+		defer cancel()
+
+		sem := slots.acquire(target)
+		defer slots.release(target)
+		select {
+		case sem <- struct{}{}:
+			defer func() { <-sem }()
+		case <-ctx.Done():
+			level.Warn(logger).Log("msg", "Timed out waiting for a scrape slot", "target", target)
+			http.Error(w, fmt.Sprintf("too many scrapes of target %s in progress", target), http.StatusServiceUnavailable)
+			return
+		}
+
+		filteredScrapers := filterScrapers(scrapers, collectParams)
+
+		registry := prometheus.NewRegistry()
+		registry.MustRegister(collector.New(ctx, dsn, filteredScrapers, logger))
+
+		h := promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+		h.ServeHTTP(w, r.WithContext(ctx))
+	}
+}
+
+// registerProbeHandler loads the auth modules of configFile and registers
+// /probe on the default mux.
+func registerProbeHandler(configFile string, scrapers []collector.Scraper, logger log.Logger) {
+	if err := authModules.ReloadConfig(configFile, *mysqldAddress, *mysqldUser, *tlsInsecureSkipVerify, logger); err != nil {
+		level.Warn(logger).Log("msg", "No auth modules for /probe", "file", configFile, "err", err)
+	}
+	registerAuthModuleTunnels(authModules.GetConfig(), logger)
+	slots := newTargetSlots(*probeMaxInflight)
+	http.Handle("/probe", instrumentProbeHandler("/probe", handleProbe(scrapers, slots, logger)))
+}
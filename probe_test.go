@@ -0,0 +1,76 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/mysqld_exporter/config"
+)
+
+func TestHandleProbe(t *testing.T) {
+	authModules.Config = &config.Config{Sections: map[string]config.MySqlConfig{
+		"client": {User: "exporter", Password: "secret"},
+	}}
+	defer func() { authModules.Config = &config.Config{} }()
+	slots := newTargetSlots(1)
+	handler := handleProbe(nil, slots, log.NewNopLogger())
+
+	for _, tc := range []struct {
+		query string
+		code  int
+	}{
+		{"", http.StatusBadRequest},
+		{"?target=db1:3306&auth_module=client.unknown", http.StatusBadRequest},
+		{"?target=db1", http.StatusBadRequest},
+	} {
+		w := httptest.NewRecorder()
+		handler(w, httptest.NewRequest("GET", "/probe"+tc.query, nil))
+		if w.Code != tc.code {
+			t.Errorf("%q: want status %d, got %d", tc.query, tc.code, w.Code)
+		}
+	}
+
+	// With the only slot of db1 taken, scrapes of db1 time out waiting.
+	sem := slots.acquire("db1:3306")
+	sem <- struct{}{}
+	r := httptest.NewRequest("GET", "/probe?target=db1:3306", nil)
+	r.Header.Set("X-Prometheus-Scrape-Timeout-Seconds", "0.05")
+	w := httptest.NewRecorder()
+	handler(w, r)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("want status %d while the target has no free slot, got %d", http.StatusServiceUnavailable, w.Code)
+	}
+	if got := testutil.ToFloat64(targetInflightScrapes.WithLabelValues("db1:3306")); got != 1 {
+		t.Errorf("want only the scrape holding the slot of db1 in flight, got %v", got)
+	}
+	if slots.acquire("db2:3306") == sem {
+		t.Error("want a semaphore per target")
+	}
+	slots.release("db2:3306")
+
+	// Idle targets are forgotten.
+	<-sem
+	slots.release("db1:3306")
+	if len(slots.slots) != 0 {
+		t.Errorf("want no slots of idle targets, got %d", len(slots.slots))
+	}
+	if got := testutil.CollectAndCount(targetInflightScrapes); got != 0 {
+		t.Errorf("want no in-flight series of idle targets, got %d", got)
+	}
+}
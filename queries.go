@@ -0,0 +1,120 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/mysqld_exporter/collector"
+	"gopkg.in/ini.v1"
+)
+
+var (
+	configQueryOverrides = kingpin.Flag(
+		"config.query-overrides",
+		"Path to an ini file defining queries run instead of the collector queries listed at /queries.",
+	).Default("").String()
+)
+
+// parseQueryOverrides reads query overrides from an ini file, a section
+// per query ID, for example:
+//
+//	[1f0e4b6c2a9d]
+//	query = SELECT VARIABLE_NAME, VARIABLE_VALUE FROM performance_schema.global_status WHERE VARIABLE_NAME LIKE 'Innodb%'
+func parseQueryOverrides(config interface{}) (map[string]string, error) {
+	cfg, err := ini.Load(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading ini file: %s", err)
+	}
+	overrides := map[string]string{}
+	for _, sec := range cfg.Sections() {
+		id := sec.Name()
+		if id == ini.DefaultSection {
+			continue
+		}
+		query := sec.Key("query").String()
+		if query == "" {
+			return nil, fmt.Errorf("no query specified for query ID %q", id)
+		}
+		overrides[id] = query
+	}
+	return overrides, nil
+}
+
+// handleQueries lists the queries of the enabled collectors for the version
+// of the server, or the version set with ?version=.
+func handleQueries(scrapers []collector.Scraper, logger log.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		version := r.URL.Query().Get("version")
+		if version == "" {
+			ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+			defer cancel()
+			var err error
+			if version, err = collector.ServerVersion(ctx, dsn); err != nil {
+				level.Error(logger).Log("msg", "Error querying server version", "err", err)
+				http.Error(w, fmt.Sprintf("error querying server version, set it with ?version=: %s", err), http.StatusServiceUnavailable)
+				return
+			}
+		}
+		queries, err := collector.QueryCatalog(r.Context(), scrapers, version)
+		if err != nil {
+			level.Error(logger).Log("msg", "Error listing the queries of the collectors", "err", err)
+			http.Error(w, fmt.Sprintf("error listing the queries of the collectors: %s", err), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		writeQueryCatalog(w, version, queries)
+	}
+}
+
+// writeQueryCatalog writes the queries of the catalog by collector.
+func writeQueryCatalog(w io.Writer, version string, queries []collector.CatalogQuery) {
+	fmt.Fprintf(w, "# Queries of the enabled collectors on MySQL %s.\n", version)
+	fmt.Fprintf(w, "# Queries run for the rows of another query, such as the queries per schema, are not listed.\n")
+	for _, q := range queries {
+		fmt.Fprintf(w, "\ncollector=%s id=%s\n%s\n", q.Collector, q.ID, indentQuery(q.Query))
+		if q.Override != "" {
+			fmt.Fprintf(w, "overridden with:\n%s\n", indentQuery(q.Override))
+		}
+	}
+}
+
+// indentQuery returns query without blank lines and with its common
+// indentation replaced by two spaces.
+func indentQuery(query string) string {
+	var lines []string
+	indent := -1
+	for _, line := range strings.Split(query, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		line = strings.TrimRight(line, " \t")
+		if n := len(line) - len(strings.TrimLeft(line, " \t")); indent < 0 || n < indent {
+			indent = n
+		}
+		lines = append(lines, line)
+	}
+	for i, line := range lines {
+		lines[i] = "  " + line[indent:]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,73 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/prometheus/mysqld_exporter/collector"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestParseQueryOverrides(t *testing.T) {
+	convey.Convey("Query overrides are parsed", t, func() {
+		overrides, err := parseQueryOverrides([]byte(`
+[1db412df3426]
+query = SHOW GLOBAL STATUS LIKE 'Innodb%'
+`))
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(overrides, convey.ShouldResemble, map[string]string{"1db412df3426": "SHOW GLOBAL STATUS LIKE 'Innodb%'"})
+
+		_, err = parseQueryOverrides([]byte("[1db412df3426]\n"))
+		convey.So(err, convey.ShouldNotBeNil)
+	})
+}
+
+func TestWriteQueryCatalog(t *testing.T) {
+	var buf bytes.Buffer
+	writeQueryCatalog(&buf, "8.0.32", []collector.CatalogQuery{
+		{Collector: "global_status", ID: "1db412df3426", Query: "SHOW GLOBAL STATUS", Override: "SHOW GLOBAL STATUS LIKE 'Innodb%'"},
+		{Collector: "info_schema.tables", ID: "969c079c2c19", Query: "\n\t\tSELECT\n\t\t    TABLE_SCHEMA\n\t\t  FROM information_schema.tables\n\t\t"},
+	})
+	want := `# Queries of the enabled collectors on MySQL 8.0.32.
+# Queries run for the rows of another query, such as the queries per schema, are not listed.
+
+collector=global_status id=1db412df3426
+  SHOW GLOBAL STATUS
+overridden with:
+  SHOW GLOBAL STATUS LIKE 'Innodb%'
+
+collector=info_schema.tables id=969c079c2c19
+  SELECT
+      TABLE_SCHEMA
+    FROM information_schema.tables
+`
+	if buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestQueryCatalogAllCollectors(t *testing.T) {
+	all := make([]collector.Scraper, 0, len(scrapers))
+	for scraper := range scrapers {
+		all = append(all, scraper)
+	}
+	for _, version := range []string{"5.6.51", "5.7.40", "8.0.32", "8.4.0", "10.11.6-MariaDB"} {
+		if _, err := collector.QueryCatalog(context.Background(), all, version); err != nil {
+			t.Errorf("MySQL %s: %s", version, err)
+		}
+	}
+}
@@ -0,0 +1,463 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus/mysqld_exporter/collector"
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	// serveCmd runs the exporter, the default without a command.
+	serveCmd = kingpin.Command("serve", "Run the exporter.").Default().Hidden()
+
+	generateRulesCmd = kingpin.Command(
+		"generate-rules",
+		"Write Prometheus alerting and recording rules and a Grafana dashboard for the enabled collectors and exit.",
+	)
+	generateRulesOutputDir = generateRulesCmd.Flag(
+		"output-dir",
+		"Directory to write rules.yaml, alerts.yaml and dashboard.json to.",
+	).Default(".").String()
+	generateRulesSelector = generateRulesCmd.Flag(
+		"selector",
+		`Label matchers added to every metric selector, e.g. job="mysql".`,
+	).Default("").String()
+)
+
+// generatedRule is a Prometheus recording or alerting rule.
+type generatedRule struct {
+	// collectors are the collectors the rule needs metrics of, the rule
+	// is left out unless all are enabled.
+	collectors []string
+
+	Record      string            `yaml:"record,omitempty"`
+	Alert       string            `yaml:"alert,omitempty"`
+	Expr        string            `yaml:"expr"`
+	For         string            `yaml:"for,omitempty"`
+	Labels      map[string]string `yaml:"labels,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+}
+
+type ruleGroups struct {
+	Groups []ruleGroup `yaml:"groups"`
+}
+
+type ruleGroup struct {
+	Name  string          `yaml:"name"`
+	Rules []generatedRule `yaml:"rules"`
+}
+
+// metricSelector returns a function adding the matchers to a metric
+// selector, e.g. mysql_up{job="mysql"}.
+func metricSelector(matchers ...string) func(string) string {
+	var nonEmpty []string
+	for _, m := range matchers {
+		if m != "" {
+			nonEmpty = append(nonEmpty, m)
+		}
+	}
+	return func(metric string) string {
+		if len(nonEmpty) == 0 {
+			return metric
+		}
+		if strings.HasSuffix(metric, "}") {
+			return strings.TrimSuffix(metric, "}") + "," + strings.Join(nonEmpty, ",") + "}"
+		}
+		return metric + "{" + strings.Join(nonEmpty, ",") + "}"
+	}
+}
+
+func alertAnnotations(summary, description string) map[string]string {
+	return map[string]string{"summary": summary, "description": description}
+}
+
+// recordingRules are the curated recording rules, with metrics selected by m.
+func recordingRules(m func(string) string) []generatedRule {
+	return []generatedRule{
+		{
+			collectors: []string{"slave_status"},
+			Record:     "instance:mysql_slave_lag_seconds",
+			Expr:       m("mysql_slave_status_seconds_behind_master") + " - " + m("mysql_slave_status_sql_delay_seconds"),
+		},
+		{
+			collectors: []string{"heartbeat"},
+			Record:     "instance:mysql_heartbeat_lag_seconds",
+			Expr:       m("mysql_heartbeat_now_timestamp_seconds") + " - " + m("mysql_heartbeat_stored_timestamp_seconds"),
+		},
+		{
+			collectors: []string{"global_status"},
+			Record:     "job:mysql_transactions:rate5m",
+			Expr:       fmt.Sprintf(`sum without (command) (rate(%s[5m]))`, m(`mysql_global_status_commands_total{command=~"(commit|rollback)"}`)),
+		},
+		{
+			collectors: []string{"master_status"},
+			Record:     "instance:mysql_gtid_executed_transactions:rate5m",
+			Expr:       fmt.Sprintf(`sum without (executed_server_id, partition) (rate(%s[5m]))`, m("mysql_master_status_executed_gtid_set_end")),
+		},
+	}
+}
+
+// alertingRules are the curated alerting rules, with metrics selected by m.
+func alertingRules(m func(string) string) []generatedRule {
+	return []generatedRule{
+		{
+			Alert:       "MySQLDown",
+			Expr:        m("mysql_up") + " != 1",
+			For:         "5m",
+			Labels:      map[string]string{"severity": "critical"},
+			Annotations: alertAnnotations("MySQL not up.", "MySQL {{$labels.job}} on {{$labels.instance}} is not up."),
+		},
+		{
+			collectors:  []string{"global_status", "global_variables"},
+			Alert:       "MySQLTooManyConnections",
+			Expr:        fmt.Sprintf("%s / %s > 0.8", m("mysql_global_status_threads_connected"), m("mysql_global_variables_max_connections")),
+			For:         "5m",
+			Labels:      map[string]string{"severity": "warning"},
+			Annotations: alertAnnotations("MySQL connections close to max_connections.", "{{$labels.instance}} uses {{$value | humanizePercentage}} of max_connections."),
+		},
+		{
+			collectors:  []string{"global_status"},
+			Alert:       "MySQLInnoDBLogWaits",
+			Expr:        fmt.Sprintf("rate(%s[15m]) > 10", m("mysql_global_status_innodb_log_waits")),
+			Labels:      map[string]string{"severity": "warning"},
+			Annotations: alertAnnotations("MySQL innodb log writes stalling.", "The innodb logs are waiting for disk at a rate of {{$value}} / second"),
+		},
+		{
+			collectors:  []string{"slave_status"},
+			Alert:       "MySQLReplicationNotRunning",
+			Expr:        fmt.Sprintf("%s == 0 or %s == 0", m("mysql_slave_status_slave_io_running"), m("mysql_slave_status_slave_sql_running")),
+			For:         "2m",
+			Labels:      map[string]string{"severity": "critical"},
+			Annotations: alertAnnotations("Replication is not running.", "Replication on {{$labels.instance}} (IO or SQL) has been down for more than 2 minutes."),
+		},
+		{
+			collectors:  []string{"slave_status"},
+			Alert:       "MySQLReplicationLag",
+			Expr:        "(instance:mysql_slave_lag_seconds > 30) and on(instance) (predict_linear(instance:mysql_slave_lag_seconds[5m], 60 * 2) > 0)",
+			For:         "1m",
+			Labels:      map[string]string{"severity": "critical"},
+			Annotations: alertAnnotations("MySQL slave replication is lagging.", "Replication on {{$labels.instance}} has fallen behind and is not recovering."),
+		},
+		{
+			collectors:  []string{"slave_status"},
+			Alert:       "MySQLReplicationLagUnknown",
+			Expr:        m("mysql_slave_status_lag_unknown") + " == 1",
+			For:         "2m",
+			Labels:      map[string]string{"severity": "critical"},
+			Annotations: alertAnnotations("MySQL replication lag is unknown.", "Seconds_Behind_Master of {{$labels.instance}} channel {{$labels.channel_name}} is NULL, replication is stopped or broken."),
+		},
+		{
+			collectors:  []string{"slave_status"},
+			Alert:       "MySQLReplicationSourceChanged",
+			Expr:        fmt.Sprintf("increase(%s[15m]) > 0", m("mysql_slave_status_source_changed_total")),
+			Labels:      map[string]string{"severity": "info"},
+			Annotations: alertAnnotations("MySQL replication source changed.", "{{$labels.instance}} replicates from a different source since less than 15 minutes."),
+		},
+		{
+			collectors:  []string{"slave_status"},
+			Alert:       "MySQLReplicationGTIDStalled",
+			Expr:        fmt.Sprintf("sum by (instance) (changes(%s[10m])) == 0 and on(instance) max by (instance) (%s) > 0", m("mysql_slave_status_executed_gtid_set_end"), m("mysql_slave_status_seconds_behind_master")),
+			For:         "5m",
+			Labels:      map[string]string{"severity": "warning"},
+			Annotations: alertAnnotations("MySQL replica stopped applying transactions.", "The executed GTID set of {{$labels.instance}} has not advanced for 10 minutes while lagging."),
+		},
+		{
+			collectors:  []string{"heartbeat"},
+			Alert:       "MySQLHeartbeatLag",
+			Expr:        "(instance:mysql_heartbeat_lag_seconds > 30) and on(instance) (predict_linear(instance:mysql_heartbeat_lag_seconds[5m], 60 * 2) > 0)",
+			For:         "1m",
+			Labels:      map[string]string{"severity": "critical"},
+			Annotations: alertAnnotations("MySQL heartbeat is lagging.", "The heartbeat is lagging on {{$labels.instance}} and is not recovering."),
+		},
+		{
+			collectors:  []string{"master_status"},
+			Alert:       "MySQLBinlogDisabled",
+			Expr:        m("mysql_binlog_enabled") + " == 0",
+			For:         "15m",
+			Labels:      map[string]string{"severity": "warning"},
+			Annotations: alertAnnotations("MySQL binary log disabled.", "{{$labels.instance}} has no binary log, replicas and point in time recovery won't work."),
+		},
+		{
+			collectors:  []string{"global_status"},
+			Alert:       "MySQLGaleraNotReady",
+			Expr:        m("mysql_global_status_wsrep_ready") + " != 1",
+			For:         "5m",
+			Labels:      map[string]string{"severity": "warning"},
+			Annotations: alertAnnotations("Galera cluster node not ready.", "{{$labels.job}} on {{$labels.instance}} is not ready."),
+		},
+		{
+			collectors:  []string{"global_status", "global_variables"},
+			Alert:       "MySQLGaleraOutOfSync",
+			Expr:        fmt.Sprintf("(%s != 4 and %s == 0)", m("mysql_global_status_wsrep_local_state"), m("mysql_global_variables_wsrep_desync")),
+			For:         "5m",
+			Labels:      map[string]string{"severity": "warning"},
+			Annotations: alertAnnotations("Galera cluster node out of sync.", "{{$labels.job}} on {{$labels.instance}} is not in sync ({{$value}} != 4)."),
+		},
+		{
+			collectors:  []string{"clock"},
+			Alert:       "MySQLClockSkew",
+			Expr:        fmt.Sprintf("abs(%s) > 1", m("mysql_clock_skew_seconds")),
+			For:         "15m",
+			Labels:      map[string]string{"severity": "warning"},
+			Annotations: alertAnnotations("MySQL server clock is off.", "The clock of {{$labels.instance}} is {{$value}}s off from the exporter, check NTP."),
+		},
+		{
+			collectors:  []string{"canary"},
+			Alert:       "MySQLCanaryWriteFailing",
+			Expr:        m("mysql_canary_write_success") + " == 0",
+			For:         "5m",
+			Labels:      map[string]string{"severity": "critical"},
+			Annotations: alertAnnotations("MySQL doesn't take writes.", "The canary row can't be written on {{$labels.instance}}."),
+		},
+		{
+			collectors:  []string{"canary"},
+			Alert:       "MySQLCanaryPropagationDelay",
+			Expr:        m("mysql_canary_propagation_delay_seconds") + " > 300",
+			For:         "5m",
+			Labels:      map[string]string{"severity": "warning"},
+			Annotations: alertAnnotations("MySQL writes replicate slowly.", "Writes of server {{$labels.server_id}} take {{$value}}s to reach {{$labels.instance}}."),
+		},
+		{
+			collectors:  []string{"config_compliance"},
+			Alert:       "MySQLConfigNotCompliant",
+			Expr:        m("mysql_config_compliant") + " == 0",
+			For:         "15m",
+			Labels:      map[string]string{"severity": "warning"},
+			Annotations: alertAnnotations("MySQL configuration drift.", "{{$labels.variable}} on {{$labels.instance}} doesn't have the expected value."),
+		},
+		{
+			collectors:  []string{"binlog_stream"},
+			Alert:       "MySQLBinlogStreamDisconnected",
+			Expr:        m("mysql_binlog_stream_connected") + " == 0",
+			For:         "5m",
+			Labels:      map[string]string{"severity": "warning"},
+			Annotations: alertAnnotations("Binlog stream disconnected.", "The exporter can't follow the binlog of {{$labels.instance}}."),
+		},
+		{
+			collectors:  []string{"binlog_stream"},
+			Alert:       "MySQLLargeTransactions",
+			Expr:        fmt.Sprintf("histogram_quantile(0.99, rate(%s[5m])) > 100 * 1024 * 1024", m("mysql_binlog_stream_transaction_size_bytes_bucket")),
+			For:         "5m",
+			Labels:      map[string]string{"severity": "warning"},
+			Annotations: alertAnnotations("MySQL transactions too large for replication.", "1% of the transactions on {{$labels.instance}} write more than 100MiB to the binlog."),
+		},
+		{
+			collectors:  []string{"perf_schema.statement_efficiency"},
+			Alert:       "MySQLStatementPlanRegression",
+			Expr:        fmt.Sprintf("%s > 10 * %s and %s > 100", m("mysql_statement_efficiency_ratio"), m("mysql_statement_efficiency_baseline_ratio"), m("mysql_statement_efficiency_ratio")),
+			For:         "15m",
+			Labels:      map[string]string{"severity": "warning"},
+			Annotations: alertAnnotations("MySQL statement examines many more rows than usual.", "Digest {{$labels.digest}} on {{$labels.instance}} examines {{$value}} rows per row sent, 10 times its baseline. Its plan may have regressed."),
+		},
+	}
+}
+
+// dashboardPanel is a time series panel of the generated dashboard.
+type dashboardPanel struct {
+	collectors []string
+
+	title   string
+	unit    string
+	targets []dashboardTarget
+}
+
+type dashboardTarget struct {
+	Expr         string `json:"expr"`
+	LegendFormat string `json:"legendFormat"`
+}
+
+// dashboardPanels are the curated panels, with metrics selected by m.
+func dashboardPanels(m func(string) string) []dashboardPanel {
+	return []dashboardPanel{
+		{title: "MySQL up", targets: []dashboardTarget{{m("mysql_up"), "{{instance}}"}}},
+		{
+			collectors: []string{"global_status"},
+			title:      "Queries",
+			unit:       "reqps",
+			targets:    []dashboardTarget{{fmt.Sprintf("rate(%s[5m])", m("mysql_global_status_queries")), "{{instance}}"}},
+		},
+		{
+			collectors: []string{"global_status"},
+			title:      "Top commands",
+			unit:       "reqps",
+			targets:    []dashboardTarget{{fmt.Sprintf("topk(10, rate(%s[5m]))", m("mysql_global_status_commands_total")), "{{instance}} {{command}}"}},
+		},
+		{
+			collectors: []string{"global_status"},
+			title:      "Threads",
+			targets: []dashboardTarget{
+				{m("mysql_global_status_threads_connected"), "{{instance}} connected"},
+				{m("mysql_global_status_threads_running"), "{{instance}} running"},
+			},
+		},
+		{
+			collectors: []string{"global_variables"},
+			title:      "Max connections",
+			targets:    []dashboardTarget{{m("mysql_global_variables_max_connections"), "{{instance}}"}},
+		},
+		{
+			collectors: []string{"slave_status"},
+			title:      "Replication lag",
+			unit:       "s",
+			targets:    []dashboardTarget{{m("mysql_slave_status_seconds_behind_master") + " - " + m("mysql_slave_status_sql_delay_seconds"), "{{instance}} {{channel_name}}"}},
+		},
+		{
+			collectors: []string{"slave_status"},
+			title:      "Applied GTID transactions",
+			unit:       "ops",
+			targets:    []dashboardTarget{{fmt.Sprintf("rate(%s[5m])", m("mysql_slave_status_executed_gtid_set_end")), "{{instance}} {{executed_server_id}}"}},
+		},
+		{
+			collectors: []string{"master_status"},
+			title:      "Executed GTID transactions",
+			unit:       "ops",
+			targets:    []dashboardTarget{{fmt.Sprintf("rate(%s[5m])", m("mysql_master_status_executed_gtid_set_end")), "{{instance}} {{executed_server_id}}"}},
+		},
+		{
+			collectors: []string{"master_status"},
+			title:      "Binlog position",
+			targets: []dashboardTarget{
+				{m("mysql_master_status_binlog_file_num"), "{{instance}} file"},
+				{m("mysql_master_status_binlog_pos"), "{{instance}} position"},
+			},
+		},
+		{
+			collectors: []string{"heartbeat"},
+			title:      "Heartbeat lag",
+			unit:       "s",
+			targets:    []dashboardTarget{{m("mysql_heartbeat_now_timestamp_seconds") + " - " + m("mysql_heartbeat_stored_timestamp_seconds"), "{{instance}}"}},
+		},
+		{
+			collectors: []string{"binlog_stream"},
+			title:      "Binlog events",
+			unit:       "ops",
+			targets:    []dashboardTarget{{fmt.Sprintf("rate(%s[5m])", m("mysql_binlog_stream_events_total")), "{{instance}} {{type}}"}},
+		},
+		{
+			collectors: []string{"binlog_stream"},
+			title:      "Transaction size p99",
+			unit:       "bytes",
+			targets:    []dashboardTarget{{fmt.Sprintf("histogram_quantile(0.99, sum by (instance, le) (rate(%s[5m])))", m("mysql_binlog_stream_transaction_size_bytes_bucket")), "{{instance}}"}},
+		},
+	}
+}
+
+// collectorsEnabled returns whether all collectors are in enabled.
+func collectorsEnabled(collectors []string, enabled map[string]bool) bool {
+	for _, c := range collectors {
+		if !enabled[c] {
+			return false
+		}
+	}
+	return true
+}
+
+func filterRules(rules []generatedRule, enabled map[string]bool) []generatedRule {
+	var res []generatedRule
+	for _, rule := range rules {
+		if collectorsEnabled(rule.collectors, enabled) {
+			res = append(res, rule)
+		}
+	}
+	return res
+}
+
+// generateDashboard returns a Grafana dashboard with the panels of the
+// enabled collectors.
+func generateDashboard(selector string, enabled map[string]bool) map[string]interface{} {
+	var panels []map[string]interface{}
+	for _, panel := range dashboardPanels(metricSelector(selector, `instance=~"$instance"`)) {
+		if !collectorsEnabled(panel.collectors, enabled) {
+			continue
+		}
+		i := len(panels)
+		targets := make([]map[string]interface{}, len(panel.targets))
+		for j, t := range panel.targets {
+			targets[j] = map[string]interface{}{
+				"expr":         t.Expr,
+				"legendFormat": t.LegendFormat,
+				"refId":        string(rune('A' + j)),
+			}
+		}
+		panels = append(panels, map[string]interface{}{
+			"id":          i + 1,
+			"type":        "timeseries",
+			"title":       panel.title,
+			"datasource":  "$datasource",
+			"gridPos":     map[string]int{"h": 8, "w": 12, "x": 12 * (i % 2), "y": 8 * (i / 2)},
+			"fieldConfig": map[string]interface{}{"defaults": map[string]string{"unit": panel.unit}},
+			"targets":     targets,
+		})
+	}
+	return map[string]interface{}{
+		"title":         "MySQL",
+		"uid":           "mysqld-exporter",
+		"schemaVersion": 36,
+		"tags":          []string{"mysql"},
+		"time":          map[string]string{"from": "now-6h", "to": "now"},
+		"refresh":       "1m",
+		"templating": map[string]interface{}{
+			"list": []map[string]interface{}{
+				{"name": "datasource", "type": "datasource", "query": "prometheus"},
+				{
+					"name":       "instance",
+					"type":       "query",
+					"datasource": "$datasource",
+					"query":      fmt.Sprintf("label_values(%s, instance)", metricSelector(selector)("mysql_up")),
+					"multi":      true,
+					"includeAll": true,
+					"refresh":    2,
+				},
+			},
+		},
+		"panels": panels,
+	}
+}
+
+// generateRules writes the rules and dashboard for the enabled scrapers to
+// dir.
+func generateRules(dir, selector string, scrapers []collector.Scraper) error {
+	enabled := make(map[string]bool, len(scrapers))
+	for _, scraper := range scrapers {
+		enabled[scraper.Name()] = true
+	}
+	m := metricSelector(selector)
+
+	files := map[string]interface{}{
+		"rules.yaml":  ruleGroups{[]ruleGroup{{"mysqld_rules", filterRules(recordingRules(m), enabled)}}},
+		"alerts.yaml": ruleGroups{[]ruleGroup{{"MySQLdAlerts", filterRules(alertingRules(m), enabled)}}},
+	}
+	for name, groups := range files {
+		b, err := yaml.Marshal(groups)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), b, 0o644); err != nil {
+			return err
+		}
+	}
+	b, err := json.MarshalIndent(generateDashboard(selector, enabled), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "dashboard.json"), append(b, '\n'), 0o644)
+}
@@ -0,0 +1,89 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/prometheus/mysqld_exporter/collector"
+	"gopkg.in/yaml.v2"
+)
+
+func TestMetricSelector(t *testing.T) {
+	for _, tc := range []struct {
+		matchers []string
+		metric   string
+		want     string
+	}{
+		{nil, "mysql_up", "mysql_up"},
+		{[]string{""}, "mysql_up", "mysql_up"},
+		{[]string{`job="mysql"`}, "mysql_up", `mysql_up{job="mysql"}`},
+		{[]string{`job="mysql"`, `instance=~"$instance"`}, `mysql_global_status_commands_total{command="commit"}`, `mysql_global_status_commands_total{command="commit",job="mysql",instance=~"$instance"}`},
+	} {
+		if got := metricSelector(tc.matchers...)(tc.metric); got != tc.want {
+			t.Errorf("metricSelector(%q)(%q): want %s, got %s", tc.matchers, tc.metric, tc.want, got)
+		}
+	}
+}
+
+func TestGenerateRules(t *testing.T) {
+	dir := t.TempDir()
+	scrapers := []collector.Scraper{collector.ScrapeGlobalStatus{}, collector.ScrapeSlaveStatus{}}
+	if err := generateRules(dir, `job="mysql"`, scrapers); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(filepath.Join(dir, "alerts.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var alerts ruleGroups
+	if err := yaml.Unmarshal(b, &alerts); err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, rule := range alerts.Groups[0].Rules {
+		names = append(names, rule.Alert)
+	}
+	want := []string{
+		"MySQLDown", "MySQLInnoDBLogWaits", "MySQLReplicationNotRunning", "MySQLReplicationLag",
+		"MySQLReplicationLagUnknown", "MySQLReplicationSourceChanged", "MySQLReplicationGTIDStalled", "MySQLGaleraNotReady",
+	}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("want alerts %q, got %q", want, names)
+	}
+	if got := alerts.Groups[0].Rules[0].Expr; got != `mysql_up{job="mysql"} != 1` {
+		t.Errorf("selector not applied: %s", got)
+	}
+
+	b, err = os.ReadFile(filepath.Join(dir, "dashboard.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dashboard struct {
+		Panels []struct {
+			Title string `json:"title"`
+		} `json:"panels"`
+	}
+	if err := json.Unmarshal(b, &dashboard); err != nil {
+		t.Fatal(err)
+	}
+	if len(dashboard.Panels) != 6 {
+		t.Errorf("want 6 panels, got %d", len(dashboard.Panels))
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sdk is the stable API for Go programs embedding scrapers of
+// mysqld_exporter, e.g. to expose replication metrics from a failover
+// daemon:
+//
+//	registry := sdk.NewRegistry()
+//	registry.MustRegister(collector.ScrapeSlaveStatus{})
+//	prometheus.MustRegister(registry.Collector(ctx, db, logger))
+//
+// Scrapers read their settings, like the collect.* flags, from the flags of
+// kingpin.CommandLine. The flag defaults only apply once the program called
+// kingpin.CommandLine.Parse, before that the settings are zero values: a
+// program with its own flag handling calls kingpin.CommandLine.Parse(nil)
+// before running scrapers.
+//
+// The collector package changes with the exporter, the API of this package
+// follows semantic versioning as declared by APIVersion: it only changes
+// incompatibly with a new major version.
+package sdk
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/mysqld_exporter/collector"
+)
+
+// APIVersion is the semantic version of the API of this package.
+const APIVersion = "1.0.0"
+
+// Scraper collects metrics from a MySQL server, see collector.Scraper.
+type Scraper = collector.Scraper
+
+// GlobalTransactionIdentifier is a parsed GTID set of one server.
+type GlobalTransactionIdentifier = collector.GlobalTransactionIdentifier
+
+// TransactionDetail is an interval of a GTID set.
+type TransactionDetail = collector.TransactionDetail
+
+// ParseStatus parses a status or variable value: booleans like ON/OFF,
+// replication states, timestamps, log file numbers and numbers. It returns
+// false for values which can't be parsed.
+func ParseStatus(value []byte) (float64, bool) {
+	return collector.ParseStatus(value)
+}
+
+// ParseGTID parses a GTID set, like Executed_Gtid_Set of SHOW MASTER STATUS.
+func ParseGTID(s string) ([]GlobalTransactionIdentifier, error) {
+	return collector.ParseGTID(s)
+}
+
+// Registry is a set of scrapers with unique names.
+type Registry struct {
+	mtx      sync.RWMutex
+	scrapers map[string]Scraper
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{scrapers: map[string]Scraper{}}
+}
+
+// Register adds s to the registry. It fails if a scraper with the same name
+// is already registered.
+func (r *Registry) Register(s Scraper) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if _, ok := r.scrapers[s.Name()]; ok {
+		return fmt.Errorf("scraper %q already registered", s.Name())
+	}
+	r.scrapers[s.Name()] = s
+	return nil
+}
+
+// MustRegister is like Register but panics on error.
+func (r *Registry) MustRegister(scrapers ...Scraper) {
+	for _, s := range scrapers {
+		if err := r.Register(s); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Get returns the scraper registered with name.
+func (r *Registry) Get(name string) (Scraper, bool) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	s, ok := r.scrapers[name]
+	return s, ok
+}
+
+// Scrapers returns the registered scrapers sorted by name.
+func (r *Registry) Scrapers() []Scraper {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	res := make([]Scraper, 0, len(r.scrapers))
+	for _, s := range r.scrapers {
+		res = append(res, s)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name() < res[j].Name() })
+	return res
+}
+
+// Collector returns a prometheus.Collector running the registered scrapers
+// against db on every collection. Failing scrapers are logged and don't
+// prevent the others from running. The collector is unchecked, as the
+// metrics of scrapers are only known at scrape time.
+func (r *Registry) Collector(ctx context.Context, db *sql.DB, logger log.Logger) prometheus.Collector {
+	return &registryCollector{ctx: ctx, db: db, registry: r, logger: logger}
+}
+
+type registryCollector struct {
+	ctx      context.Context
+	db       *sql.DB
+	registry *Registry
+	logger   log.Logger
+}
+
+// Describe implements prometheus.Collector.
+func (c *registryCollector) Describe(ch chan<- *prometheus.Desc) {}
+
+// Collect implements prometheus.Collector.
+func (c *registryCollector) Collect(ch chan<- prometheus.Metric) {
+	var wg sync.WaitGroup
+	for _, s := range c.registry.Scrapers() {
+		wg.Add(1)
+		go func(s Scraper) {
+			defer wg.Done()
+			logger := log.With(c.logger, "scraper", s.Name())
+			if err := s.Scrape(c.ctx, c.db, ch, logger); err != nil {
+				level.Error(logger).Log("msg", "Error from scraper", "err", err)
+			}
+		}(s)
+	}
+	wg.Wait()
+}
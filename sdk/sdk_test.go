@@ -0,0 +1,126 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sdk_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/mysqld_exporter/collector"
+	"github.com/prometheus/mysqld_exporter/sdk"
+	"github.com/prometheus/mysqld_exporter/sdk/sdktest"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestRegistry(t *testing.T) {
+	registry := sdk.NewRegistry()
+	registry.MustRegister(collector.ScrapeSlaveStatus{}, collector.ScrapeMasterStatus{})
+
+	convey.Convey("Scrapers are registered by name", t, func() {
+		convey.So(registry.Register(collector.ScrapeMasterStatus{}), convey.ShouldNotBeNil)
+
+		s, ok := registry.Get("master_status")
+		convey.So(ok, convey.ShouldBeTrue)
+		convey.So(s, convey.ShouldResemble, collector.ScrapeMasterStatus{})
+
+		names := []string{}
+		for _, s := range registry.Scrapers() {
+			names = append(names, s.Name())
+		}
+		convey.So(names, convey.ShouldResemble, []string{"master_status", "slave_status"})
+	})
+}
+
+func TestRegistryCollector(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sdktest.SanitizeQuery("SHOW MASTER STATUS")).WillReturnRows(
+		sqlmock.NewRows([]string{"File", "Position", "Binlog_Do_DB", "Binlog_Ignore_DB", "Executed_Gtid_Set"}).
+			AddRow("binlog.000006", "49066", "", "", ""))
+
+	registry := sdk.NewRegistry()
+	registry.MustRegister(collector.ScrapeMasterStatus{})
+
+	convey.Convey("Registered scrapers are collected", t, func() {
+		reg := prometheus.NewRegistry()
+		reg.MustRegister(registry.Collector(context.Background(), db, log.NewNopLogger()))
+		count, err := testutil.GatherAndCount(reg, "mysql_binlog_enabled", "mysql_master_status_binlog_file_num", "mysql_master_status_binlog_pos")
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(count, convey.ShouldEqual, 3)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrape(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sdktest.SanitizeQuery("SHOW MASTER STATUS")).WillReturnRows(
+		sqlmock.NewRows([]string{"File", "Position"}).AddRow("binlog.000006", "49066"))
+
+	convey.Convey("Metrics comparison", t, func() {
+		metrics, err := sdktest.Scrape(context.Background(), db, collector.ScrapeMasterStatus{})
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(metrics, convey.ShouldResemble, []sdktest.Metric{
+			{Name: "mysql_binlog_enabled", Labels: map[string]string{}, Value: 1, Type: dto.MetricType_GAUGE},
+			{Name: "mysql_master_status_binlog_file_num", Labels: map[string]string{}, Value: 6, Type: dto.MetricType_GAUGE},
+			{Name: "mysql_master_status_binlog_pos", Labels: map[string]string{}, Value: 49066, Type: dto.MetricType_GAUGE},
+		})
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestReadMetricHistogram(t *testing.T) {
+	desc := prometheus.NewDesc("mysql_test_duration_seconds", "Test histogram.", nil, nil)
+	m := prometheus.MustNewConstHistogram(desc, 3, 1.5, map[float64]uint64{0.1: 1, 1: 3})
+
+	convey.Convey("Histograms are read with their count and buckets", t, func() {
+		convey.So(sdktest.ReadMetric(m), convey.ShouldResemble, sdktest.Metric{
+			Name: "mysql_test_duration_seconds", Labels: map[string]string{}, Value: 1.5, Type: dto.MetricType_HISTOGRAM,
+			Count: 3, Buckets: map[float64]uint64{0.1: 1, 1: 3},
+		})
+	})
+}
+
+func TestParse(t *testing.T) {
+	convey.Convey("Values are parsed like the scrapers do", t, func() {
+		value, ok := sdk.ParseStatus([]byte("ON"))
+		convey.So(ok, convey.ShouldBeTrue)
+		convey.So(value, convey.ShouldEqual, 1)
+
+		gtids, err := sdk.ParseGTID("3E11FA47-71CA-11E1-9E33-C80AA9429562:1-5")
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(gtids[0].ServerId, convey.ShouldEqual, "3E11FA47-71CA-11E1-9E33-C80AA9429562")
+	})
+}
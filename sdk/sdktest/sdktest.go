@@ -0,0 +1,119 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sdktest helps testing scrapers against stub databases, as the
+// scrapers of mysqld_exporter are tested with go-sqlmock.
+package sdktest
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/mysqld_exporter/sdk"
+)
+
+// Metric is a metric read back from a scraper. The Value of histograms and
+// summaries is their sample sum.
+type Metric struct {
+	Name   string
+	Labels map[string]string
+	Value  float64
+	Type   dto.MetricType
+	// Count is the sample count of histograms and summaries.
+	Count uint64
+	// Buckets are the cumulative counts of histograms by upper bound.
+	Buckets map[float64]uint64
+	// Quantiles are the values of summaries by quantile.
+	Quantiles map[float64]float64
+}
+
+// ReadMetric returns the name, labels, value and type of m.
+func ReadMetric(m prometheus.Metric) Metric {
+	pb := &dto.Metric{}
+	m.Write(pb)
+	res := Metric{
+		Name:   fqName(m.Desc()),
+		Labels: make(map[string]string, len(pb.Label)),
+	}
+	for _, lp := range pb.Label {
+		res.Labels[lp.GetName()] = lp.GetValue()
+	}
+	switch {
+	case pb.Gauge != nil:
+		res.Value, res.Type = pb.GetGauge().GetValue(), dto.MetricType_GAUGE
+	case pb.Counter != nil:
+		res.Value, res.Type = pb.GetCounter().GetValue(), dto.MetricType_COUNTER
+	case pb.Untyped != nil:
+		res.Value, res.Type = pb.GetUntyped().GetValue(), dto.MetricType_UNTYPED
+	case pb.Histogram != nil:
+		h := pb.GetHistogram()
+		res.Value, res.Count, res.Type = h.GetSampleSum(), h.GetSampleCount(), dto.MetricType_HISTOGRAM
+		res.Buckets = make(map[float64]uint64, len(h.Bucket))
+		for _, b := range h.Bucket {
+			res.Buckets[b.GetUpperBound()] = b.GetCumulativeCount()
+		}
+	case pb.Summary != nil:
+		sm := pb.GetSummary()
+		res.Value, res.Count, res.Type = sm.GetSampleSum(), sm.GetSampleCount(), dto.MetricType_SUMMARY
+		res.Quantiles = make(map[float64]float64, len(sm.Quantile))
+		for _, q := range sm.Quantile {
+			res.Quantiles[q.GetQuantile()] = q.GetValue()
+		}
+	default:
+		panic("Unsupported metric type")
+	}
+	return res
+}
+
+// fqName extracts the name from the string form of desc, the only way
+// client_golang exposes it.
+func fqName(desc *prometheus.Desc) string {
+	s := desc.String()
+	const prefix = `Desc{fqName: "`
+	if !strings.HasPrefix(s, prefix) {
+		return ""
+	}
+	s = s[len(prefix):]
+	return s[:strings.Index(s, `"`)]
+}
+
+// Scrape runs scraper against db and returns the metrics it sent along with
+// its error.
+func Scrape(ctx context.Context, db *sql.DB, scraper sdk.Scraper) ([]Metric, error) {
+	ch := make(chan prometheus.Metric)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- scraper.Scrape(ctx, db, ch, log.NewNopLogger())
+		close(ch)
+	}()
+
+	var res []Metric
+	for m := range ch {
+		res = append(res, ReadMetric(m))
+	}
+	return res, <-errc
+}
+
+// SanitizeQuery escapes query to be used as an expectation of go-sqlmock,
+// which matches queries as regular expressions.
+func SanitizeQuery(query string) string {
+	query = strings.Join(strings.Fields(query), " ")
+	return strings.NewReplacer(
+		"(", "\\(", ")", "\\)", "*", "\\*", "+", "\\+", "?", "\\?",
+		".", "\\.", "$", "\\$", "[", "\\[", "]", "\\]", "^", "\\^", "|", "\\|",
+	).Replace(query)
+}
@@ -0,0 +1,260 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+const (
+	// defaultDiffLimit is the number of series listed per section of
+	// /debug/diff.
+	defaultDiffLimit = 20
+	// maxSnapshotScopes bounds the scopes recorded, the least recently
+	// scraped scope is dropped for a new one.
+	maxSnapshotScopes = 32
+)
+
+// seriesSnapshot holds the sample values of a scrape by series.
+type seriesSnapshot struct {
+	time   time.Time
+	values map[string]float64
+}
+
+// scrapeSnapshots keeps the last two scrapes of every scope, the endpoint,
+// tier and collectors of the scrape request, as scrapes of different tiers
+// or collectors can't be compared.
+type scrapeSnapshots struct {
+	mtx    sync.Mutex
+	scopes map[string][2]*seriesSnapshot
+}
+
+var snapshots = &scrapeSnapshots{scopes: map[string][2]*seriesSnapshot{}}
+
+// record stores mfs as the latest scrape of scope.
+func (s *scrapeSnapshots) record(scope string, mfs []*dto.MetricFamily, now time.Time) {
+	snapshot := &seriesSnapshot{time: now, values: map[string]float64{}}
+	for _, mf := range mfs {
+		for _, m := range mf.Metric {
+			addSeries(snapshot.values, mf.GetName(), m)
+		}
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if _, ok := s.scopes[scope]; !ok && len(s.scopes) >= maxSnapshotScopes {
+		var oldest string
+		for other, last := range s.scopes {
+			if oldest == "" || last[1].time.Before(s.scopes[oldest][1].time) {
+				oldest = other
+			}
+		}
+		delete(s.scopes, oldest)
+	}
+	s.scopes[scope] = [2]*seriesSnapshot{s.scopes[scope][1], snapshot}
+}
+
+// get returns the previous and latest scrapes of scope.
+func (s *scrapeSnapshots) get(scope string) (*seriesSnapshot, *seriesSnapshot) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	last := s.scopes[scope]
+	return last[0], last[1]
+}
+
+// addSeries adds the samples of m to values. Histograms and summaries are
+// represented by their count and sum.
+func addSeries(values map[string]float64, name string, m *dto.Metric) {
+	labels := make([]string, 0, len(m.Label))
+	for _, lp := range m.Label {
+		labels = append(labels, fmt.Sprintf("%s=%q", lp.GetName(), lp.GetValue()))
+	}
+	sort.Strings(labels)
+	suffix := "{" + strings.Join(labels, ",") + "}"
+
+	switch {
+	case m.Gauge != nil:
+		values[name+suffix] = m.GetGauge().GetValue()
+	case m.Counter != nil:
+		values[name+suffix] = m.GetCounter().GetValue()
+	case m.Untyped != nil:
+		values[name+suffix] = m.GetUntyped().GetValue()
+	case m.Histogram != nil:
+		values[name+"_count"+suffix] = float64(m.GetHistogram().GetSampleCount())
+		values[name+"_sum"+suffix] = m.GetHistogram().GetSampleSum()
+	case m.Summary != nil:
+		values[name+"_count"+suffix] = float64(m.GetSummary().GetSampleCount())
+		values[name+"_sum"+suffix] = m.GetSummary().GetSampleSum()
+	}
+}
+
+// snapshotGatherer records the scrapes of the wrapped gatherer for
+// /debug/diff.
+type snapshotGatherer struct {
+	gatherer prometheus.Gatherer
+	scope    string
+}
+
+// Gather implements prometheus.Gatherer.
+func (g snapshotGatherer) Gather() ([]*dto.MetricFamily, error) {
+	mfs, err := g.gatherer.Gather()
+	if *enableDebug {
+		snapshots.record(g.scope, mfs, time.Now())
+	}
+	return mfs, err
+}
+
+// snapshotScope returns the scope of a scrape of path with query: the path
+// with the tier and the sorted collect[] parameters. Other parameters don't
+// change the series scraped.
+func snapshotScope(path string, query url.Values) string {
+	var params []string
+	if tier := query.Get("tier"); tier != "" {
+		params = append(params, "tier="+tier)
+	}
+	collect := append([]string(nil), query["collect[]"]...)
+	sort.Strings(collect)
+	for _, c := range collect {
+		params = append(params, "collect[]="+c)
+	}
+	if len(params) == 0 {
+		return path
+	}
+	return path + "?" + strings.Join(params, "&")
+}
+
+// handleDiff serves /debug/diff?scope=<path and query of the scrape>&limit=<n>.
+func handleDiff(w http.ResponseWriter, r *http.Request) {
+	scope := r.URL.Query().Get("scope")
+	if scope == "" {
+		scope = *metricsPath
+	}
+	if u, err := url.Parse(scope); err == nil {
+		scope = snapshotScope(u.Path, u.Query())
+	}
+	limit := defaultDiffLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		var err error
+		if limit, err = strconv.Atoi(v); err != nil || limit <= 0 {
+			http.Error(w, fmt.Sprintf("invalid limit %q", v), http.StatusBadRequest)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	prev, latest := snapshots.get(scope)
+	if prev == nil {
+		fmt.Fprintf(w, "fewer than two scrapes of %s recorded yet\n", scope)
+		return
+	}
+	fmt.Fprintf(w, "scrapes of %s at %s and %s\n", scope, prev.time.Format(time.RFC3339), latest.time.Format(time.RFC3339))
+	writeDiff(w, prev, latest, limit)
+}
+
+// seriesChange is the change of a series between two scrapes.
+type seriesChange struct {
+	series   string
+	old, new float64
+}
+
+func (c seriesChange) delta() float64 {
+	return math.Abs(c.new - c.old)
+}
+
+func (c seriesChange) relativeDelta() float64 {
+	return c.delta() / math.Abs(c.old)
+}
+
+// writeDiff writes the series which appeared, disappeared and changed the
+// most between prev and latest.
+func writeDiff(w io.Writer, prev, latest *seriesSnapshot, limit int) {
+	var appeared, disappeared []string
+	var changes, relativeChanges []seriesChange
+	for series, value := range latest.values {
+		old, ok := prev.values[series]
+		if !ok {
+			appeared = append(appeared, series)
+			continue
+		}
+		c := seriesChange{series: series, old: old, new: value}
+		// NaN never equals itself, changes from or to NaN aren't ranked.
+		if math.IsNaN(c.delta()) || c.delta() == 0 {
+			continue
+		}
+		changes = append(changes, c)
+		if old != 0 {
+			relativeChanges = append(relativeChanges, c)
+		}
+	}
+	for series := range prev.values {
+		if _, ok := latest.values[series]; !ok {
+			disappeared = append(disappeared, series)
+		}
+	}
+	sort.Strings(appeared)
+	sort.Strings(disappeared)
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].delta() != changes[j].delta() {
+			return changes[i].delta() > changes[j].delta()
+		}
+		return changes[i].series < changes[j].series
+	})
+	sort.Slice(relativeChanges, func(i, j int) bool {
+		if relativeChanges[i].relativeDelta() != relativeChanges[j].relativeDelta() {
+			return relativeChanges[i].relativeDelta() > relativeChanges[j].relativeDelta()
+		}
+		return relativeChanges[i].series < relativeChanges[j].series
+	})
+
+	writeSeriesList(w, "appeared", appeared, limit)
+	writeSeriesList(w, "disappeared", disappeared, limit)
+
+	fmt.Fprintf(w, "\n%d series changed, largest absolute changes:\n", len(changes))
+	for i, c := range changes {
+		if i == limit {
+			break
+		}
+		fmt.Fprintf(w, "  %s %g -> %g (%+g)\n", c.series, c.old, c.new, c.new-c.old)
+	}
+	fmt.Fprintf(w, "\nlargest relative changes:\n")
+	for i, c := range relativeChanges {
+		if i == limit {
+			break
+		}
+		fmt.Fprintf(w, "  %s %g -> %g (%+.1f%%)\n", c.series, c.old, c.new, (c.new-c.old)/math.Abs(c.old)*100)
+	}
+}
+
+func writeSeriesList(w io.Writer, what string, series []string, limit int) {
+	fmt.Fprintf(w, "\n%d series %s:\n", len(series), what)
+	for i, s := range series {
+		if i == limit {
+			fmt.Fprintf(w, "  ... %d more\n", len(series)-limit)
+			break
+		}
+		fmt.Fprintf(w, "  %s\n", s)
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func gatherGauges(t *testing.T, values map[string]float64) []*dto.MetricFamily {
+	registry := prometheus.NewRegistry()
+	gauges := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "mysql_test", Help: "Test."}, []string{"name"})
+	registry.MustRegister(gauges)
+	for name, value := range values {
+		gauges.WithLabelValues(name).Set(value)
+	}
+	mfs, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return mfs
+}
+
+func TestWriteDiff(t *testing.T) {
+	s := &scrapeSnapshots{scopes: map[string][2]*seriesSnapshot{}}
+	now := time.Unix(1000, 0)
+	s.record("/metrics", gatherGauges(t, map[string]float64{"gone": 1, "same": 5, "small": 10, "large": 1000, "zero": 0}), now)
+	s.record("/metrics?tier=lr", gatherGauges(t, map[string]float64{"other": 1}), now)
+	s.record("/metrics", gatherGauges(t, map[string]float64{"new": 1, "same": 5, "small": 20, "large": 1100, "zero": 3}), now.Add(15*time.Second))
+
+	prev, latest := s.get("/metrics")
+	var b strings.Builder
+	writeDiff(&b, prev, latest, 2)
+
+	want := `
+1 series appeared:
+  mysql_test{name="new"}
+
+1 series disappeared:
+  mysql_test{name="gone"}
+
+3 series changed, largest absolute changes:
+  mysql_test{name="large"} 1000 -> 1100 (+100)
+  mysql_test{name="small"} 10 -> 20 (+10)
+
+largest relative changes:
+  mysql_test{name="small"} 10 -> 20 (+100.0%)
+  mysql_test{name="large"} 1000 -> 1100 (+10.0%)
+`
+	if b.String() != want {
+		t.Errorf("want:\n%s\ngot:\n%s", want, b.String())
+	}
+
+	if prev, _ := s.get("/metrics?tier=lr"); prev != nil {
+		t.Errorf("scopes not recorded separately")
+	}
+}
+
+func TestSnapshotScope(t *testing.T) {
+	for query, want := range map[string]string{
+		"":                                       "/metrics",
+		"collect[]=b&collect[]=a":                "/metrics?collect[]=a&collect[]=b",
+		"collect[]=a&collect[]=b":                "/metrics?collect[]=a&collect[]=b",
+		"collect[]=a&tier=lr&cache_buster=12345": "/metrics?tier=lr&collect[]=a",
+		"cache_buster=12345":                     "/metrics",
+	} {
+		values, err := url.ParseQuery(query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := snapshotScope("/metrics", values); got != want {
+			t.Errorf("%q: want %q, got %q", query, want, got)
+		}
+	}
+}
+
+func TestSnapshotScopesBounded(t *testing.T) {
+	s := &scrapeSnapshots{scopes: map[string][2]*seriesSnapshot{}}
+	now := time.Unix(1000, 0)
+	for i := 0; i <= maxSnapshotScopes; i++ {
+		s.record(fmt.Sprintf("/metrics?collect[]=%d", i), nil, now.Add(time.Duration(i)*time.Second))
+	}
+	if len(s.scopes) != maxSnapshotScopes {
+		t.Errorf("want %d scopes, got %d", maxSnapshotScopes, len(s.scopes))
+	}
+	if _, ok := s.scopes["/metrics?collect[]=0"]; ok {
+		t.Error("want the least recently scraped scope dropped")
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/mysqld_exporter/collector"
+)
+
+var (
+	streamMetrics = kingpin.Flag(
+		"web.stream-metrics",
+		"Write metrics to the response one collector at a time instead of gathering all of them first. Derived metrics are not computed in this mode.",
+	).Default("false").Bool()
+)
+
+// streamWriter encodes batches of metric families to a (possibly gzipped)
+// response and flushes after every batch.
+type streamWriter struct {
+	w       http.ResponseWriter
+	gz      *gzip.Writer
+	encoder expfmt.Encoder
+	naming  string
+}
+
+func newStreamWriter(w http.ResponseWriter, r *http.Request, naming string) *streamWriter {
+	format := expfmt.Negotiate(r.Header)
+	w.Header().Set("Content-Type", string(format))
+
+	sw := &streamWriter{w: w, naming: naming}
+	var out io.Writer = w
+	if gzipAccepted(r.Header) {
+		w.Header().Set("Content-Encoding", "gzip")
+		sw.gz = gzip.NewWriter(w)
+		out = sw.gz
+	}
+	sw.encoder = expfmt.NewEncoder(out, format)
+	return sw
+}
+
+// write encodes and flushes a batch of metric families.
+func (sw *streamWriter) write(mfs []*dto.MetricFamily) error {
+	for _, mf := range renameFamilies(mfs, sw.naming) {
+		if err := sw.encoder.Encode(mf); err != nil {
+			return err
+		}
+	}
+	if sw.gz != nil {
+		if err := sw.gz.Flush(); err != nil {
+			return err
+		}
+	}
+	if f, ok := sw.w.(http.Flusher); ok {
+		f.Flush()
+	}
+	return nil
+}
+
+// close finishes the response.
+func (sw *streamWriter) close() error {
+	if closer, ok := sw.encoder.(expfmt.Closer); ok {
+		if err := closer.Close(); err != nil {
+			return err
+		}
+	}
+	if sw.gz != nil {
+		return sw.gz.Close()
+	}
+	return nil
+}
+
+// serveStream writes the exporter's own metrics followed by the metrics of
+// the MySQL exporter, one scraper at a time.
+func serveStream(w http.ResponseWriter, r *http.Request, exporter *collector.Exporter, logger log.Logger) {
+	sw := newStreamWriter(w, r, metricNaming())
+	defer func() {
+		if err := sw.close(); err != nil {
+			level.Error(logger).Log("msg", "Error finishing metrics stream", "err", err)
+		}
+	}()
+
+	mfs, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		level.Error(logger).Log("msg", "Error gathering exporter metrics", "err", err)
+	}
+	if err := sw.write(mfs); err != nil {
+		level.Error(logger).Log("msg", "Error streaming metrics", "err", err)
+		return
+	}
+	// Headers have been sent already, on error the response is truncated.
+	if err := exporter.Stream(sw.write); err != nil {
+		level.Error(logger).Log("msg", "Error streaming metrics", "err", err)
+	}
+}
+
+func gzipAccepted(header http.Header) bool {
+	for _, part := range strings.Split(header.Get("Accept-Encoding"), ",") {
+		part = strings.TrimSpace(part)
+		if part == "gzip" || strings.HasPrefix(part, "gzip;") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestStreamWriter(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	g := prometheus.NewGauge(prometheus.GaugeOpts{Name: "mysql_up", Help: "Whether the MySQL server is up."})
+	g.Set(1)
+	registry.MustRegister(g)
+	mfs, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "# HELP mysql_up Whether the MySQL server is up.\n# TYPE mysql_up gauge\nmysql_up 1\n"
+
+	convey.Convey("Plain response", t, func() {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		sw := newStreamWriter(rec, req, namingFork)
+		convey.So(sw.write(mfs), convey.ShouldBeNil)
+		convey.So(sw.close(), convey.ShouldBeNil)
+		convey.So(rec.Header().Get("Content-Encoding"), convey.ShouldEqual, "")
+		convey.So(rec.Body.String(), convey.ShouldEqual, expected)
+	})
+
+	convey.Convey("Gzipped response", t, func() {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		req.Header.Set("Accept-Encoding", "deflate, gzip;q=1.0")
+		sw := newStreamWriter(rec, req, namingFork)
+		convey.So(sw.write(mfs), convey.ShouldBeNil)
+		convey.So(rec.Flushed, convey.ShouldBeTrue)
+		convey.So(sw.close(), convey.ShouldBeNil)
+		convey.So(rec.Header().Get("Content-Encoding"), convey.ShouldEqual, "gzip")
+
+		gz, err := gzip.NewReader(rec.Body)
+		convey.So(err, convey.ShouldBeNil)
+		body, err := io.ReadAll(gz)
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(string(body), convey.ShouldEqual, expected)
+	})
+}
@@ -0,0 +1,121 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/mysqld_exporter/collector"
+)
+
+// sdNotify sends state to the service manager listening at $NOTIFY_SOCKET,
+// as sd_notify(3). It returns false when not started by systemd with
+// Type=notify.
+func sdNotify(state string) (bool, error) {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return false, nil
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// watchdogInterval returns WatchdogSec= of the service as passed in
+// $WATCHDOG_USEC, 0 if the watchdog is disabled or meant for another
+// process.
+func watchdogInterval() time.Duration {
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0
+	}
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0
+	}
+	return time.Duration(usec) * time.Microsecond
+}
+
+// runWatchdog runs check every half interval until ctx is done and pings
+// the watchdog of systemd after each successful check. A check hanging
+// longer than the interval gets the exporter restarted.
+func runWatchdog(ctx context.Context, interval time.Duration, check func(context.Context) error, logger log.Logger) {
+	ticker := time.NewTicker(interval / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		checkCtx, cancel := context.WithTimeout(ctx, interval/2)
+		err := check(checkCtx)
+		cancel()
+		if err != nil {
+			level.Warn(logger).Log("msg", "Watchdog self-scrape failed", "err", err)
+			sdNotify("STATUS=Self-scrape failed: " + err.Error())
+			continue
+		}
+		if _, err := sdNotify("WATCHDOG=1\nSTATUS=Ready"); err != nil {
+			level.Error(logger).Log("msg", "Error pinging the systemd watchdog", "err", err)
+		}
+	}
+}
+
+// selfScrape connects to MySQL and runs the ping query, like a scrape
+// without collectors. It fails if MySQL is down.
+func selfScrape(ctx context.Context, logger log.Logger) error {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collector.New(ctx, dsn, nil, logger))
+	mfs, err := registry.Gather()
+	if err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if !mysqlIsUp(mfs) {
+		return errors.New("MySQL is down")
+	}
+	return nil
+}
+
+// startSystemd tells systemd the exporter is ready and, with WatchdogSec=
+// set, starts pinging the watchdog after each successful self-scrape.
+func startSystemd(logger log.Logger) {
+	if notified, err := sdNotify("READY=1"); err != nil {
+		level.Error(logger).Log("msg", "Error notifying systemd", "err", err)
+	} else if !notified {
+		return
+	}
+	if interval := watchdogInterval(); interval > 0 {
+		level.Info(logger).Log("msg", "Systemd watchdog enabled", "interval", interval)
+		go runWatchdog(context.Background(), interval, func(ctx context.Context) error {
+			return selfScrape(ctx, logger)
+		}, logger)
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+)
+
+// listenNotify listens at $NOTIFY_SOCKET like systemd.
+func listenNotify(t *testing.T) *net.UnixConn {
+	socket := filepath.Join(t.TempDir(), "notify")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	t.Setenv("NOTIFY_SOCKET", socket)
+	return conn
+}
+
+func readNotify(t *testing.T, conn *net.UnixConn) string {
+	buf := make([]byte, 1024)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(buf[:n])
+}
+
+func TestSdNotify(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+	if notified, err := sdNotify("READY=1"); notified || err != nil {
+		t.Errorf("want no notification without NOTIFY_SOCKET, got %v, %v", notified, err)
+	}
+
+	conn := listenNotify(t)
+	if notified, err := sdNotify("READY=1"); !notified || err != nil {
+		t.Fatalf("want a notification, got %v, %v", notified, err)
+	}
+	if got := readNotify(t, conn); got != "READY=1" {
+		t.Errorf("want READY=1, got %q", got)
+	}
+}
+
+func TestWatchdogInterval(t *testing.T) {
+	for _, tc := range []struct {
+		usec, pid string
+		want      time.Duration
+	}{
+		{"", "", 0},
+		{"garbage", "", 0},
+		{"30000000", "", 30 * time.Second},
+		{"30000000", strconv.Itoa(os.Getpid()), 30 * time.Second},
+		{"30000000", "1", 0},
+	} {
+		t.Setenv("WATCHDOG_USEC", tc.usec)
+		t.Setenv("WATCHDOG_PID", tc.pid)
+		if got := watchdogInterval(); got != tc.want {
+			t.Errorf("WATCHDOG_USEC=%q WATCHDOG_PID=%q: want %s, got %s", tc.usec, tc.pid, tc.want, got)
+		}
+	}
+}
+
+func TestRunWatchdog(t *testing.T) {
+	conn := listenNotify(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	checks := make(chan error, 2)
+	checks <- errors.New("MySQL is down")
+	checks <- nil
+	go runWatchdog(ctx, 20*time.Millisecond, func(context.Context) error {
+		select {
+		case err := <-checks:
+			return err
+		default:
+			<-ctx.Done()
+			return ctx.Err()
+		}
+	}, log.NewNopLogger())
+
+	if got, want := readNotify(t, conn), "STATUS=Self-scrape failed: MySQL is down"; got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+	if got, want := readNotify(t, conn), "WATCHDOG=1\nSTATUS=Ready"; got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
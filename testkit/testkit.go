@@ -0,0 +1,222 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package testkit runs scrapers end-to-end against MySQL and MariaDB servers
+// started in docker containers with dockertest.
+//
+// Tests using it should be guarded by the integration build tag:
+//
+//	func TestScrapers(t *testing.T) {
+//		testkit.ForEachImage(t, func(t *testing.T, s *testkit.Server) {
+//			mfs, err := s.Scrape(context.Background(), collector.ScrapeGlobalStatus{})
+//			...
+//		})
+//	}
+package testkit
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/ory/dockertest/v3"
+	dc "github.com/ory/dockertest/v3/docker"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/mysqld_exporter/collector"
+)
+
+// ImagesEnv overrides the Images to test against, as a comma separated list.
+const ImagesEnv = "MYSQLD_EXPORTER_TEST_IMAGES"
+
+// Images are the server images tested against by default.
+var Images = []string{
+	"mysql:5.7",
+	"mysql:8.0",
+	"mysql:8.4",
+	"mariadb:10.6",
+	"mariadb:11",
+}
+
+// StartTimeout is how long to wait for a server to accept connections.
+var StartTimeout = 3 * time.Minute
+
+var versionRE = regexp.MustCompile(`^\d+\.\d+`)
+
+// Server is a MySQL or MariaDB server running in a docker container.
+type Server struct {
+	Image string
+	// DSN connects as root, without a password.
+	DSN string
+	DB  *sql.DB
+	// Version is the full version string, VersionNum its major.minor part
+	// to compare with Scraper.Version.
+	Version    string
+	VersionNum float64
+
+	pool     *dockertest.Pool
+	resource *dockertest.Resource
+}
+
+// images returns the images to test against.
+func images() []string {
+	if env := os.Getenv(ImagesEnv); env != "" {
+		return strings.Split(env, ",")
+	}
+	return Images
+}
+
+// ForEachImage starts a server for each image and runs f against it in a
+// subtest. The test is skipped if docker is not available.
+func ForEachImage(t *testing.T, f func(t *testing.T, s *Server)) {
+	for _, image := range images() {
+		image := strings.TrimSpace(image)
+		t.Run(image, func(t *testing.T) {
+			f(t, Start(t, image))
+		})
+	}
+}
+
+// Start starts a server from image, it is removed when the test finishes.
+func Start(t testing.TB, image string) *Server {
+	t.Helper()
+	pool, err := dockertest.NewPool("")
+	if err == nil {
+		err = pool.Client.Ping()
+	}
+	if err != nil {
+		t.Skipf("docker is not available, skipping integration test: %s", err)
+	}
+	pool.MaxWait = StartTimeout
+
+	repository, tag, _ := strings.Cut(image, ":")
+	resource, err := pool.RunWithOptions(&dockertest.RunOptions{
+		Repository: repository,
+		Tag:        tag,
+		Env: []string{
+			"MYSQL_ALLOW_EMPTY_PASSWORD=yes",
+			"MARIADB_ALLOW_EMPTY_ROOT_PASSWORD=yes",
+			"MYSQL_ROOT_HOST=%",
+		},
+	}, func(config *dc.HostConfig) {
+		config.AutoRemove = true
+	})
+	if err != nil {
+		t.Fatalf("error starting %s: %s", image, err)
+	}
+	s := &Server{Image: image, pool: pool, resource: resource}
+	t.Cleanup(s.remove)
+
+	s.DSN = fmt.Sprintf("root@tcp(%s)/", resource.GetHostPort("3306/tcp"))
+	if err := s.wait(); err != nil {
+		t.Fatalf("error waiting for %s: %s", image, err)
+	}
+	return s
+}
+
+// wait connects to the server once it accepts connections.
+func (s *Server) wait() error {
+	db, err := sql.Open("mysql", s.DSN)
+	if err != nil {
+		return err
+	}
+	db.SetMaxOpenConns(1)
+
+	err = s.pool.Retry(func() error {
+		return db.QueryRow("SELECT @@version").Scan(&s.Version)
+	})
+	if err != nil {
+		db.Close()
+		return fmt.Errorf("%w, server logs:\n%s", err, s.logs())
+	}
+	s.DB = db
+	s.VersionNum, _ = strconv.ParseFloat(versionRE.FindString(s.Version), 64)
+	return nil
+}
+
+// logs returns the last lines logged by the server.
+func (s *Server) logs() string {
+	var b bytes.Buffer
+	s.pool.Client.Logs(dc.LogsOptions{
+		Container:    s.resource.Container.ID,
+		OutputStream: &b,
+		ErrorStream:  &b,
+		Stdout:       true,
+		Stderr:       true,
+		Tail:         "20",
+	})
+	return b.String()
+}
+
+func (s *Server) remove() {
+	if s.DB != nil {
+		s.DB.Close()
+	}
+	s.pool.Purge(s.resource)
+}
+
+// Supports returns whether the server is recent enough for scraper.
+func (s *Server) Supports(scraper collector.Scraper) bool {
+	return s.VersionNum >= scraper.Version()
+}
+
+// Scrape runs scraper against the server and returns the gathered metrics.
+func (s *Server) Scrape(ctx context.Context, scraper collector.Scraper) ([]*dto.MetricFamily, error) {
+	return Scrape(ctx, s.DB, scraper)
+}
+
+// Scrape runs scraper against db and returns the gathered metrics.
+func Scrape(ctx context.Context, db *sql.DB, scraper collector.Scraper) ([]*dto.MetricFamily, error) {
+	c := &scraperCollector{ctx: ctx, db: db, scraper: scraper}
+	registry := prometheus.NewPedanticRegistry()
+	if err := registry.Register(c); err != nil {
+		return nil, err
+	}
+	mfs, err := registry.Gather()
+	if err != nil {
+		return mfs, err
+	}
+	return mfs, c.err
+}
+
+// MetricNames returns the names of the metric families in mfs.
+func MetricNames(mfs []*dto.MetricFamily) map[string]bool {
+	names := make(map[string]bool, len(mfs))
+	for _, mf := range mfs {
+		names[mf.GetName()] = true
+	}
+	return names
+}
+
+// scraperCollector is an unchecked collector running a single scraper.
+type scraperCollector struct {
+	ctx     context.Context
+	db      *sql.DB
+	scraper collector.Scraper
+	err     error
+}
+
+func (c *scraperCollector) Describe(ch chan<- *prometheus.Desc) {}
+
+func (c *scraperCollector) Collect(ch chan<- prometheus.Metric) {
+	c.err = c.scraper.Scrape(c.ctx, c.db, ch, log.NewNopLogger())
+}
@@ -0,0 +1,74 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus/mysqld_exporter/collector"
+)
+
+var (
+	scrapeTierFlags = kingpin.Flag(
+		"web.scrape-tier",
+		"Scrape tier served at /metrics?tier=<name>, as <name>=<collector>,<collector>,... May be repeated.",
+	).StringMap()
+)
+
+// scrapeTiers maps a tier name to the names of the scrapers it collects.
+type scrapeTiers map[string]map[string]bool
+
+// parseScrapeTiers builds scrape tiers from `--web.scrape-tier` definitions.
+// The collector names are validated against the known scrapers.
+func parseScrapeTiers(defs map[string]string, known []collector.Scraper) (scrapeTiers, error) {
+	names := make(map[string]bool, len(known))
+	for _, scraper := range known {
+		names[scraper.Name()] = true
+	}
+	tiers := scrapeTiers{}
+	for tier, list := range defs {
+		if tier == "" {
+			return nil, fmt.Errorf("empty tier name")
+		}
+		tiers[tier] = map[string]bool{}
+		for _, name := range strings.Split(list, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			if !names[name] {
+				return nil, fmt.Errorf("unknown collector %q in tier %q", name, tier)
+			}
+			tiers[tier][name] = true
+		}
+	}
+	return tiers, nil
+}
+
+// filter returns the scrapers belonging to the given tier and whether the tier exists.
+func (t scrapeTiers) filter(scrapers []collector.Scraper, tier string) ([]collector.Scraper, bool) {
+	names, ok := t[tier]
+	if !ok {
+		return nil, false
+	}
+	var res []collector.Scraper
+	for _, scraper := range scrapers {
+		if names[scraper.Name()] {
+			res = append(res, scraper)
+		}
+	}
+	return res, true
+}
@@ -0,0 +1,221 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/go-sql-driver/mysql"
+	"github.com/prometheus/mysqld_exporter/collector"
+	"github.com/prometheus/mysqld_exporter/config"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+	"golang.org/x/net/proxy"
+	"gopkg.in/ini.v1"
+)
+
+// tunnelNet is the DSN network for connections through the SSH bastion or
+// SOCKS5 proxy of [client]. The auth modules of /probe have a network of
+// their own, see config.TunnelNet.
+const tunnelNet = "tunnel"
+
+// dialContextFunc dials addr, like mysql.DialContextFunc.
+type dialContextFunc func(ctx context.Context, addr string) (net.Conn, error)
+
+// tunnelConfig holds the ssh-host, ssh-user, ssh-key and ssh-known-hosts or
+// the socks5-proxy, socks5-user and socks5-password keys of a cnf section.
+type tunnelConfig struct {
+	sshHost, sshUser, sshKey, sshKnownHosts string
+	socksProxy, socksUser, socksPassword    string
+}
+
+// sectionTunnelConfig returns the tunnel keys of section.
+func sectionTunnelConfig(section *ini.Section) tunnelConfig {
+	return tunnelConfig{
+		sshHost:       section.Key("ssh-host").String(),
+		sshUser:       section.Key("ssh-user").String(),
+		sshKey:        section.Key("ssh-key").String(),
+		sshKnownHosts: section.Key("ssh-known-hosts").String(),
+		socksProxy:    section.Key("socks5-proxy").String(),
+		socksUser:     section.Key("socks5-user").String(),
+		socksPassword: section.Key("socks5-password").String(),
+	}
+}
+
+// authModuleTunnelConfig returns the tunnel keys of an auth module.
+func authModuleTunnelConfig(m config.MySqlConfig) tunnelConfig {
+	return tunnelConfig{
+		sshHost:       m.SSHHost,
+		sshUser:       m.SSHUser,
+		sshKey:        m.SSHKey,
+		sshKnownHosts: m.SSHKnownHosts,
+		socksProxy:    m.Socks5Proxy,
+		socksUser:     m.Socks5User,
+		socksPassword: m.Socks5Password,
+	}
+}
+
+// parseTunnel returns the dialer configured by the tunnel keys, nil if none
+// is configured.
+func parseTunnel(c tunnelConfig) (dialContextFunc, error) {
+	switch {
+	case c.sshHost != "" && c.socksProxy != "":
+		return nil, errors.New("only one of ssh-host and socks5-proxy can be specified")
+	case c.sshHost != "":
+		return newSSHDialer(c.sshHost, c.sshUser, c.sshKey, c.sshKnownHosts)
+	case c.socksProxy != "":
+		return newSOCKS5Dialer(c.socksProxy, c.socksUser, c.socksPassword)
+	}
+	return nil, nil
+}
+
+// registerTunnel registers dial as the dialer of the network DSN network.
+func registerTunnel(network string, dial dialContextFunc) {
+	collector.RegisterDialContext(network, mysql.DialContextFunc(dial))
+}
+
+// registerAuthModuleTunnels registers the tunnels of the auth modules of
+// /probe, each on the network of its auth module.
+func registerAuthModuleTunnels(cfg *config.Config, logger log.Logger) {
+	for name, section := range cfg.Sections {
+		if !section.HasTunnel() {
+			continue
+		}
+		dial, err := parseTunnel(authModuleTunnelConfig(section))
+		if err != nil {
+			level.Error(logger).Log("msg", "Failed to configure tunnel of auth module", "auth_module", name, "err", err)
+			continue
+		}
+		registerTunnel(config.TunnelNet(name), dial)
+	}
+}
+
+// newSOCKS5Dialer returns a dialer connecting through the SOCKS5 proxy at address.
+func newSOCKS5Dialer(address, user, password string) (dialContextFunc, error) {
+	var auth *proxy.Auth
+	if user != "" {
+		auth = &proxy.Auth{User: user, Password: password}
+	}
+	dialer, err := proxy.SOCKS5(tcpNetwork(), address, auth, newDialer())
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure SOCKS5 proxy %s: %w", address, err)
+	}
+	contextDialer, ok := dialer.(proxy.ContextDialer)
+	if !ok {
+		return nil, fmt.Errorf("SOCKS5 proxy %s does not support contexts", address)
+	}
+	return func(ctx context.Context, addr string) (net.Conn, error) {
+		return contextDialer.DialContext(ctx, "tcp", addr)
+	}, nil
+}
+
+// sshTunnel dials through a shared SSH connection to a bastion, which is
+// re-established once it fails.
+type sshTunnel struct {
+	address string
+	config  *ssh.ClientConfig
+
+	mu     sync.Mutex
+	client *ssh.Client
+}
+
+// newSSHDialer returns a dialer connecting through the SSH bastion at
+// address with key authentication. The bastion host key is checked against
+// knownHostsFile.
+func newSSHDialer(address, user, keyFile, knownHostsFile string) (dialContextFunc, error) {
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = joinHostPort(address, 22)
+	}
+	if user == "" || keyFile == "" || knownHostsFile == "" {
+		return nil, errors.New("ssh-user, ssh-key and ssh-known-hosts must be specified with ssh-host")
+	}
+	key, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, err
+	}
+	signer, err := ssh.ParsePrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse SSH key %s: %w", keyFile, err)
+	}
+	hostKeyCallback, err := knownhosts.New(knownHostsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SSH known hosts %s: %w", knownHostsFile, err)
+	}
+	t := &sshTunnel{
+		address: address,
+		config: &ssh.ClientConfig{
+			User:            user,
+			Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+			HostKeyCallback: hostKeyCallback,
+		},
+	}
+	return t.dial, nil
+}
+
+// dial connects to addr from the bastion.
+func (t *sshTunnel) dial(ctx context.Context, addr string) (net.Conn, error) {
+	client, err := t.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := client.Dial(tcpNetwork(), addr)
+	if err != nil {
+		// The SSH connection may be broken, reconnect on the next dial.
+		t.reset(client)
+		return nil, err
+	}
+	return conn, nil
+}
+
+// connect returns the SSH connection to the bastion, connecting if needed.
+func (t *sshTunnel) connect(ctx context.Context) (*ssh.Client, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.client != nil {
+		return t.client, nil
+	}
+	conn, err := newDialer().DialContext(ctx, tcpNetwork(), t.address)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	c, chans, reqs, err := ssh.NewClientConn(conn, t.address, t.config)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to connect to SSH bastion %s: %w", t.address, err)
+	}
+	conn.SetDeadline(time.Time{})
+	t.client = ssh.NewClient(c, chans, reqs)
+	return t.client, nil
+}
+
+// reset closes client if it is still the current SSH connection.
+func (t *sshTunnel) reset(client *ssh.Client) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.client == client {
+		t.client.Close()
+		t.client = nil
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strconv"
+	"testing"
+
+	"gopkg.in/ini.v1"
+)
+
+func TestParseMycnfTunnel(t *testing.T) {
+	dsn, err := parseMycnf([]byte("[client]\nuser=root\npassword=abc\nhost=db\nsocks5-proxy=127.0.0.1:1080\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "root:abc@tunnel(db:3306)/"; dsn != want {
+		t.Errorf("want %q, got %q", want, dsn)
+	}
+
+	for _, config := range []string{
+		"[client]\nuser=root\npassword=abc\nsocket=/tmp/mysql.sock\nsocks5-proxy=127.0.0.1:1080\n",
+		"[client]\nuser=root\npassword=abc\nssh-host=bastion\nsocks5-proxy=127.0.0.1:1080\n",
+		"[client]\nuser=root\npassword=abc\nssh-host=bastion\n",
+	} {
+		if _, err := parseMycnf([]byte(config)); err == nil {
+			t.Errorf("expected an error for %q", config)
+		}
+	}
+}
+
+func TestSOCKS5Dialer(t *testing.T) {
+	target, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer target.Close()
+	go func() {
+		conn, err := target.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.Write([]byte("hello"))
+	}()
+
+	socks, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer socks.Close()
+	go serveSOCKS5(t, socks)
+
+	cfg, err := ini.Load([]byte("[client]\nsocks5-proxy=" + socks.Addr().String() + "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dial, err := parseTunnel(sectionTunnelConfig(cfg.Section("client")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := dial(context.Background(), target.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	got, err := io.ReadAll(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "hello" {
+		t.Errorf("want %q, got %q", "hello", got)
+	}
+}
+
+// serveSOCKS5 serves a single unauthenticated SOCKS5 CONNECT to an IPv4 address.
+func serveSOCKS5(t *testing.T, l net.Listener) {
+	conn, err := l.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	// Greeting: version, number of methods, methods.
+	greeting := make([]byte, 2)
+	if _, err := io.ReadFull(conn, greeting); err != nil {
+		t.Error(err)
+		return
+	}
+	if _, err := io.ReadFull(conn, make([]byte, greeting[1])); err != nil {
+		t.Error(err)
+		return
+	}
+	conn.Write([]byte{5, 0})
+
+	// Request: version, CONNECT, reserved, IPv4, address, port.
+	req := make([]byte, 10)
+	if _, err := io.ReadFull(conn, req); err != nil {
+		t.Error(err)
+		return
+	}
+	addr := net.JoinHostPort(net.IP(req[4:8]).String(), strconv.Itoa(int(binary.BigEndian.Uint16(req[8:]))))
+	upstream, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer upstream.Close()
+	conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0})
+	io.Copy(conn, upstream)
+}
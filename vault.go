@@ -0,0 +1,175 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/alecthomas/kingpin/v2"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+var (
+	vaultPasswordFile = kingpin.Flag(
+		"config.vault-password-file",
+		"Path to the file holding the password of an Ansible Vault encrypted config.my-cnf or DATA_SOURCE_NAME.",
+	).Default("").String()
+	vaultPasswordCommand = kingpin.Flag(
+		"config.vault-password-command",
+		"Command run with /bin/sh printing the password of an Ansible Vault encrypted config.my-cnf or DATA_SOURCE_NAME, e.g. to decrypt it with a KMS.",
+	).Default("").String()
+)
+
+const (
+	// vaultHeader starts the files encrypted by ansible-vault.
+	vaultHeader = "$ANSIBLE_VAULT;"
+	// vaultPasswordCommandTimeout bounds the run time of
+	// --config.vault-password-command.
+	vaultPasswordCommandTimeout = 30 * time.Second
+)
+
+// vaultSource is a decrypted my.cnf, printed as its path in errors.
+type vaultSource struct {
+	*bytes.Reader
+	path string
+}
+
+func (s vaultSource) String() string {
+	return s.path
+}
+
+// isVault returns whether data is encrypted by ansible-vault.
+func isVault(data []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(data), []byte(vaultHeader))
+}
+
+// loadMycnf returns the ini source of the my.cnf at path, decrypted if it
+// is encrypted by ansible-vault.
+func loadMycnf(path string) (interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil || !isVault(data) {
+		// Let parseMycnf report unreadable files.
+		return path, nil
+	}
+	plaintext, err := decryptVaultWithPassword(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt %s: %w", path, err)
+	}
+	return vaultSource{Reader: bytes.NewReader(plaintext), path: path}, nil
+}
+
+// decryptVaultWithPassword decrypts data with the password of
+// MYSQLD_EXPORTER_VAULT_PASSWORD, --config.vault-password-file or
+// --config.vault-password-command.
+func decryptVaultWithPassword(data []byte) ([]byte, error) {
+	password, err := vaultPassword()
+	if err != nil {
+		return nil, err
+	}
+	return decryptVault(data, password)
+}
+
+func vaultPassword() ([]byte, error) {
+	switch {
+	case os.Getenv("MYSQLD_EXPORTER_VAULT_PASSWORD") != "":
+		return []byte(os.Getenv("MYSQLD_EXPORTER_VAULT_PASSWORD")), nil
+	case *vaultPasswordFile != "":
+		password, err := os.ReadFile(*vaultPasswordFile)
+		if err != nil {
+			return nil, err
+		}
+		return bytes.TrimRight(password, "\r\n"), nil
+	case *vaultPasswordCommand != "":
+		ctx, cancel := context.WithTimeout(context.Background(), vaultPasswordCommandTimeout)
+		defer cancel()
+		password, err := exec.CommandContext(ctx, "/bin/sh", "-c", *vaultPasswordCommand).Output()
+		if err != nil {
+			return nil, fmt.Errorf("failed to run --config.vault-password-command: %w", err)
+		}
+		return bytes.TrimRight(password, "\r\n"), nil
+	}
+	return nil, errors.New("encrypted configuration requires MYSQLD_EXPORTER_VAULT_PASSWORD, --config.vault-password-file or --config.vault-password-command")
+}
+
+// decryptVault decrypts data in the format 1.1 or 1.2 of ansible-vault:
+// AES256 in CTR mode with keys derived from password by PBKDF2, and an
+// HMAC-SHA256 of the ciphertext.
+func decryptVault(data, password []byte) ([]byte, error) {
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	header := strings.Split(strings.TrimSpace(lines[0]), ";")
+	if len(header) < 3 || header[0]+";" != vaultHeader {
+		return nil, errors.New("not an ansible-vault file")
+	}
+	if version := header[1]; version != "1.1" && version != "1.2" {
+		return nil, fmt.Errorf("unsupported ansible-vault format %s", version)
+	}
+	if header[2] != "AES256" {
+		return nil, fmt.Errorf("unsupported ansible-vault cipher %s", header[2])
+	}
+
+	var body []byte
+	for _, line := range lines[1:] {
+		b, err := hex.DecodeString(strings.TrimSpace(line))
+		if err != nil {
+			return nil, fmt.Errorf("malformed ansible-vault file: %w", err)
+		}
+		body = append(body, b...)
+	}
+	parts := strings.Split(string(body), "\n")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed ansible-vault file")
+	}
+	var salt, mac, ciphertext []byte
+	for i, dst := range []*[]byte{&salt, &mac, &ciphertext} {
+		var err error
+		if *dst, err = hex.DecodeString(parts[i]); err != nil {
+			return nil, fmt.Errorf("malformed ansible-vault file: %w", err)
+		}
+	}
+
+	key := pbkdf2.Key(password, salt, 10000, 2*32+aes.BlockSize, sha256.New)
+	h := hmac.New(sha256.New, key[32:64])
+	h.Write(ciphertext)
+	if !hmac.Equal(h.Sum(nil), mac) {
+		return nil, errors.New("wrong ansible-vault password or corrupted file")
+	}
+	block, err := aes.NewCipher(key[:32])
+	if err != nil {
+		return nil, err
+	}
+	plaintext := make([]byte, len(ciphertext))
+	cipher.NewCTR(block, key[64:]).XORKeyStream(plaintext, ciphertext)
+
+	// Remove the PKCS#7 padding.
+	if len(plaintext) == 0 {
+		return nil, errors.New("malformed ansible-vault file")
+	}
+	padding := int(plaintext[len(plaintext)-1])
+	if padding == 0 || padding > aes.BlockSize || padding > len(plaintext) {
+		return nil, errors.New("malformed ansible-vault padding")
+	}
+	return plaintext[:len(plaintext)-padding], nil
+}
@@ -0,0 +1,98 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// vaultMycnf is "[client]\nuser=exporter\npassword=s3cret\n" encrypted
+// with the password vaultpass.
+const vaultMycnf = `$ANSIBLE_VAULT;1.1;AES256
+30303031303230333034303530363037303830393061306230633064306530663130313131323133
+3134313531363137313831393161316231633164316531660a383266313531323331343962303431
+66623264646661313730373134333961666661376165313761366161313238373037313665663637
+6332666236353235620a636364313661373137363566663734346265353336653336303433396263
+66633932633837333532313465336563623263396333666262396332363137666664333862316365
+3134376362643362653462333837333835663335333038306430
+`
+
+func TestDecryptVault(t *testing.T) {
+	plaintext, err := decryptVault([]byte(vaultMycnf), []byte("vaultpass"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "[client]\nuser=exporter\npassword=s3cret\n"; string(plaintext) != want {
+		t.Errorf("want %q, got %q", want, plaintext)
+	}
+
+	if _, err := decryptVault([]byte(vaultMycnf), []byte("wrong")); err == nil {
+		t.Error("want an error with a wrong password")
+	}
+	if _, err := decryptVault([]byte(strings.Replace(vaultMycnf, "AES256", "AES", 1)), []byte("vaultpass")); err == nil {
+		t.Error("want an error with an unsupported cipher")
+	}
+}
+
+func TestLoadMycnfVault(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".my.cnf")
+	if err := os.WriteFile(path, []byte(vaultMycnf), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("MYSQLD_EXPORTER_VAULT_PASSWORD", "")
+	if _, err := loadMycnf(path); err == nil {
+		t.Error("want an error without the vault password")
+	}
+
+	t.Setenv("MYSQLD_EXPORTER_VAULT_PASSWORD", "vaultpass")
+	mycnf, err := loadMycnf(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dsn, err := parseMycnf(mycnf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "exporter:s3cret@tcp(localhost:3306)/"; dsn != want {
+		t.Errorf("want %q, got %q", want, dsn)
+	}
+
+	// Plaintext files are left to parseMycnf.
+	plain := filepath.Join(t.TempDir(), ".my.cnf")
+	if err := os.WriteFile(plain, []byte("[client]\nuser=root\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if mycnf, err := loadMycnf(plain); err != nil || mycnf != plain {
+		t.Errorf("want the path of a plaintext file, got %v, %v", mycnf, err)
+	}
+}
+
+func TestVaultPasswordCommand(t *testing.T) {
+	t.Setenv("MYSQLD_EXPORTER_VAULT_PASSWORD", "")
+	defaultCommand := *vaultPasswordCommand
+	defer func() { *vaultPasswordCommand = defaultCommand }()
+
+	*vaultPasswordCommand = "printf '%s\\n' vaultpass"
+	if password, err := vaultPassword(); err != nil || string(password) != "vaultpass" {
+		t.Errorf("want vaultpass, got %q, %v", password, err)
+	}
+	*vaultPasswordCommand = "exit 1"
+	if _, err := vaultPassword(); err == nil {
+		t.Error("want the error of a failing command")
+	}
+}